@@ -2,12 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
@@ -17,9 +19,18 @@ import (
 )
 
 func main() {
+	outputDir := flag.String("output-dir", ".", "directory the full report JSON files are written to")
+	noFiles := flag.Bool("no-files", false, "only print reports to stdout without writing report files")
+	flag.Parse()
+
 	fmt.Println("🎯 Educational Reporting Framework - Demo")
 	fmt.Println("==========================================")
 
+	output, err := newReportOutput(*outputDir, *noFiles)
+	if err != nil {
+		log.Fatalf("Invalid output directory: %v", err)
+	}
+
 	// Initialize database
 	db, err := initializeDatabase()
 	if err != nil {
@@ -38,27 +49,44 @@ func main() {
 
 	// Run report demonstrations
 	reportsService := services.NewReportsService(db)
+	engagementScore := services.DefaultEngagementScoreConfig()
+	if value, err := strconv.ParseFloat(getEnv("ENGAGEMENT_CONSISTENCY_WEIGHT", ""), 64); err == nil {
+		engagementScore.ConsistencyWeight = value
+	}
+	if value, err := strconv.ParseFloat(getEnv("ENGAGEMENT_INTENSITY_WEIGHT", ""), 64); err == nil {
+		engagementScore.IntensityWeight = value
+	}
+	if value, err := strconv.ParseFloat(getEnv("ENGAGEMENT_INTENSITY_CAP_MINUTES", ""), 64); err == nil {
+		engagementScore.IntensityCapMinutes = value
+	}
+	if err := reportsService.SetEngagementScoreConfig(engagementScore); err != nil {
+		log.Fatalf("Invalid engagement score config: %v", err)
+	}
 
 	fmt.Println("\n📊 DEMONSTRATING THREE TYPES OF REPORTS")
 	fmt.Println("=========================================")
 
 	// 1. Student Performance Analysis
-	if err := demonstrateStudentPerformanceReport(reportsService, db); err != nil {
+	if err := demonstrateStudentPerformanceReport(reportsService, db, output); err != nil {
 		log.Printf("Error in student performance demo: %v", err)
 	}
 
 	// 2. Classroom Engagement Metrics
-	if err := demonstrateClassroomEngagementReport(reportsService, db); err != nil {
+	if err := demonstrateClassroomEngagementReport(reportsService, db, output); err != nil {
 		log.Printf("Error in classroom engagement demo: %v", err)
 	}
 
 	// 3. Content Effectiveness Evaluation
-	if err := demonstrateContentEffectivenessReport(reportsService, db); err != nil {
+	if err := demonstrateContentEffectivenessReport(reportsService, db, output); err != nil {
 		log.Printf("Error in content effectiveness demo: %v", err)
 	}
 
 	// 4. Demonstrate Cube.dev style queries
-	if err := demonstrateGenericQueries(db); err != nil {
+	minQuizSessions := reportsService.Thresholds().Content.MinQuizSessions
+	if value, err := strconv.Atoi(getEnv("MIN_QUIZ_SESSIONS", "")); err == nil && value > 0 {
+		minQuizSessions = value
+	}
+	if err := demonstrateGenericQueries(db, minQuizSessions); err != nil {
 		log.Printf("Error in generic queries demo: %v", err)
 	}
 
@@ -66,7 +94,7 @@ func main() {
 	fmt.Println("📝 Check the generated reports above and the API endpoints at http://localhost:8080")
 }
 
-func demonstrateStudentPerformanceReport(reportsService *services.ReportsService, db *gorm.DB) error {
+func demonstrateStudentPerformanceReport(reportsService *services.ReportsService, db *gorm.DB, output *reportOutput) error {
 	fmt.Println("\n1️⃣  STUDENT PERFORMANCE ANALYSIS")
 	fmt.Println("==================================")
 
@@ -81,7 +109,7 @@ func demonstrateStudentPerformanceReport(reportsService *services.ReportsService
 	dateTo := time.Now()
 
 	report, err := reportsService.GenerateStudentPerformanceReport(
-		student.ID, nil, dateFrom, dateTo)
+		student.ID, nil, dateFrom, dateTo, nil)
 	if err != nil {
 		return fmt.Errorf("failed to generate student report: %w", err)
 	}
@@ -106,15 +134,14 @@ func demonstrateStudentPerformanceReport(reportsService *services.ReportsService
 	}
 
 	// Save report to file
-	reportJSON, _ := json.MarshalIndent(report, "", "  ")
-	filename := fmt.Sprintf("student_performance_report_%s.json", student.ID.String()[:8])
-	os.WriteFile(filename, reportJSON, 0644)
-	fmt.Printf("\n💾 Full report saved to: %s\n", filename)
+	if err := output.save(fmt.Sprintf("student_performance_report_%s.json", student.ID.String()[:8]), report); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
 
 	return nil
 }
 
-func demonstrateClassroomEngagementReport(reportsService *services.ReportsService, db *gorm.DB) error {
+func demonstrateClassroomEngagementReport(reportsService *services.ReportsService, db *gorm.DB, output *reportOutput) error {
 	fmt.Println("\n2️⃣  CLASSROOM ENGAGEMENT METRICS")
 	fmt.Println("==================================")
 
@@ -163,15 +190,14 @@ func demonstrateClassroomEngagementReport(reportsService *services.ReportsServic
 	}
 
 	// Save report to file
-	reportJSON, _ := json.MarshalIndent(report, "", "  ")
-	filename := fmt.Sprintf("classroom_engagement_report_%s.json", classroom.ID.String()[:8])
-	os.WriteFile(filename, reportJSON, 0644)
-	fmt.Printf("\n💾 Full report saved to: %s\n", filename)
+	if err := output.save(fmt.Sprintf("classroom_engagement_report_%s.json", classroom.ID.String()[:8]), report); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
 
 	return nil
 }
 
-func demonstrateContentEffectivenessReport(reportsService *services.ReportsService, db *gorm.DB) error {
+func demonstrateContentEffectivenessReport(reportsService *services.ReportsService, db *gorm.DB, output *reportOutput) error {
 	fmt.Println("\n3️⃣  CONTENT EFFECTIVENESS EVALUATION")
 	fmt.Println("======================================")
 
@@ -218,38 +244,25 @@ func demonstrateContentEffectivenessReport(reportsService *services.ReportsServi
 	}
 
 	// Save report to file
-	reportJSON, _ := json.MarshalIndent(report, "", "  ")
-	filename := fmt.Sprintf("content_effectiveness_report_%s.json", school.ID.String()[:8])
-	os.WriteFile(filename, reportJSON, 0644)
-	fmt.Printf("\n💾 Full report saved to: %s\n", filename)
+	if err := output.save(fmt.Sprintf("content_effectiveness_report_%s.json", school.ID.String()[:8]), report); err != nil {
+		return fmt.Errorf("failed to save report: %w", err)
+	}
 
 	return nil
 }
 
-func demonstrateGenericQueries(db *gorm.DB) error {
+func demonstrateGenericQueries(db *gorm.DB, minQuizSessions int) error {
 	fmt.Println("\n4️⃣  CUBE.DEV STYLE GENERIC QUERIES")
 	fmt.Println("===================================")
 
+	// The queries are canned templates checked against the column allow-list
+	queries := services.DefaultCannedQueryRegistry()
+	lastWeek := map[string]string{"days": "7"}
+
 	// Example 1: Event counts by application type
 	fmt.Println("\n📊 Query 1: Event counts by application type (last 7 days)")
-	query1 := `
-		SELECT
-			COALESCE(application, 'unknown') as application,
-			COUNT(*) as event_count,
-			COUNT(DISTINCT user_id) as unique_users
-		FROM events
-		WHERE timestamp >= NOW() - INTERVAL '7 days'
-		GROUP BY application
-		ORDER BY event_count DESC
-	`
-
-	var result1 []struct {
-		Application string `json:"application"`
-		EventCount  int    `json:"event_count"`
-		UniqueUsers int    `json:"unique_users"`
-	}
-
-	if err := db.Raw(query1).Scan(&result1).Error; err != nil {
+	result1, err := services.RunCannedQuery[services.ApplicationEventCount](queries, db, services.CannedQueryEventsByApplication, lastWeek)
+	if err != nil {
 		return fmt.Errorf("query 1 failed: %w", err)
 	}
 
@@ -261,26 +274,8 @@ func demonstrateGenericQueries(db *gorm.DB) error {
 
 	// Example 2: Daily engagement trends
 	fmt.Println("\n📈 Query 2: Daily engagement trends (last 7 days)")
-	query2 := `
-		SELECT
-			DATE(timestamp) as date,
-			COUNT(*) as daily_events,
-			COUNT(DISTINCT user_id) as active_users,
-			COUNT(DISTINCT session_id) as sessions
-		FROM events
-		WHERE timestamp >= NOW() - INTERVAL '7 days'
-		GROUP BY DATE(timestamp)
-		ORDER BY date DESC
-	`
-
-	var result2 []struct {
-		Date        time.Time `json:"date"`
-		DailyEvents int       `json:"daily_events"`
-		ActiveUsers int       `json:"active_users"`
-		Sessions    int       `json:"sessions"`
-	}
-
-	if err := db.Raw(query2).Scan(&result2).Error; err != nil {
+	result2, err := services.RunCannedQuery[services.DailyEngagementRow](queries, db, services.CannedQueryDailyEngagement, lastWeek)
+	if err != nil {
 		return fmt.Errorf("query 2 failed: %w", err)
 	}
 
@@ -290,37 +285,26 @@ func demonstrateGenericQueries(db *gorm.DB) error {
 			row.Date.Format("2006-01-02"), row.DailyEvents, row.ActiveUsers, row.Sessions)
 	}
 
-	// Example 3: Quiz performance by classroom
+	// Example 3: Quiz performance by classroom. Quizzes with fewer completed
+	// sessions than minQuizSessions are left out so one student can't skew a ranking.
 	fmt.Println("\n🎯 Query 3: Quiz performance by classroom")
-	query3 := `
-		SELECT
-			c.name as classroom_name,
-			c.subject,
-			COUNT(qs.id) as quiz_sessions,
-			AVG(qs.percentage_score) as avg_score,
-			AVG(qs.time_spent_seconds / 60.0) as avg_time_minutes
-		FROM quiz_sessions qs
-		JOIN quizzes q ON qs.quiz_id = q.id
-		JOIN classrooms c ON q.classroom_id = c.id
-		WHERE qs.is_completed = true
-		GROUP BY c.id, c.name, c.subject
-		ORDER BY avg_score DESC
-		LIMIT 5
-	`
-
-	var result3 []struct {
-		ClassroomName   string   `json:"classroom_name"`
-		Subject         *string  `json:"subject"`
-		QuizSessions    int      `json:"quiz_sessions"`
-		AvgScore        *float64 `json:"avg_score"`
-		AvgTimeMinutes  *float64 `json:"avg_time_minutes"`
-	}
-
-	if err := db.Raw(query3).Scan(&result3).Error; err != nil {
+	quizParams := map[string]string{"min_sessions": strconv.Itoa(minQuizSessions), "limit": "5"}
+	result3, err := services.RunCannedQuery[services.ClassroomQuizPerformance](queries, db, services.CannedQueryClassroomQuizPerformance, quizParams)
+	if err != nil {
 		return fmt.Errorf("query 3 failed: %w", err)
 	}
 
-	fmt.Printf("Results (Top 5 performing classrooms):\n")
+	sparse, err := services.RunCannedQuery[services.SparseQuizCount](queries, db, services.CannedQuerySparseQuizCount, quizParams)
+	if err != nil {
+		return fmt.Errorf("query 3 excluded count failed: %w", err)
+	}
+	var excludedQuizzes int
+	if len(sparse) > 0 {
+		excludedQuizzes = sparse[0].Quizzes
+	}
+
+	fmt.Printf("Results (Top 5 performing classrooms, %d quizzes with fewer than %d sessions excluded):\n",
+		excludedQuizzes, minQuizSessions)
 	for i, row := range result3 {
 		subject := "N/A"
 		if row.Subject != nil {
@@ -341,6 +325,40 @@ func demonstrateGenericQueries(db *gorm.DB) error {
 	return nil
 }
 
+// reportOutput writes each demo report's full JSON to a directory, unless
+// files are turned off
+type reportOutput struct {
+	dir     string
+	noFiles bool
+}
+
+// newReportOutput creates dir when report files are written to it
+func newReportOutput(dir string, noFiles bool) (*reportOutput, error) {
+	if !noFiles {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &reportOutput{dir: dir, noFiles: noFiles}, nil
+}
+
+// save writes report as indented JSON to filename in the output directory
+func (o *reportOutput) save(filename string, report interface{}) error {
+	if o.noFiles {
+		return nil
+	}
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(o.dir, filename)
+	if err := os.WriteFile(path, reportJSON, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("\n💾 Full report saved to: %s\n", path)
+	return nil
+}
+
 func initializeDatabase() (*gorm.DB, error) {
 	dsn := getDatabaseDSN()
 	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
@@ -372,7 +390,7 @@ func seedTestData(db *gorm.DB) error {
 	}
 
 	fmt.Println("🌱 Seeding test data...")
-	seedManager := seedutils.NewSeedManager(db)
+	seedManager := seedutils.NewSeedManager(db, 0)
 	return seedManager.SeedAllData()
 }
 
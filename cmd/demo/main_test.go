@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReportOutputWritesFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	output, err := newReportOutput(dir, false)
+	if err != nil {
+		t.Fatalf("newReportOutput: %v", err)
+	}
+	if err := output.save("report.json", map[string]int{"students": 3}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var report map[string]int
+	if err := json.Unmarshal(data, &report); err != nil || report["students"] != 3 {
+		t.Errorf("report = %s, %v", data, err)
+	}
+}
+
+func TestReportOutputNoFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports")
+	output, err := newReportOutput(dir, true)
+	if err != nil {
+		t.Fatalf("newReportOutput: %v", err)
+	}
+	if err := output.save("report.json", map[string]int{"students": 3}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("stat %s = %v, want the directory never created", dir, err)
+	}
+}
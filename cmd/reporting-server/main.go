@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"reporting-framework/internal/config"
 	"reporting-framework/internal/domain/reporting"
 	"reporting-framework/internal/handlers"
+	"reporting-framework/internal/metrics"
+	"reporting-framework/internal/middleware"
 	"reporting-framework/internal/seedutils"
+	"reporting-framework/internal/services"
 )
 
 func main() {
@@ -41,6 +49,7 @@ func main() {
 	fmt.Printf("🚀 Educational Reporting Framework Server starting on port %s\n", port)
 	fmt.Printf("📊 API Documentation available at http://localhost:%s/docs\n", port)
 	fmt.Printf("🔍 Health check: http://localhost:%s/health\n", port)
+	fmt.Printf("📈 Metrics: http://localhost:%s/metrics\n", port)
 
 	if err := router.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -90,6 +99,7 @@ func autoMigrate(db *gorm.DB) error {
 		&reporting.QuizSession{},
 		&reporting.QuizSubmission{},
 		&reporting.Event{},
+		&reporting.SavedQuery{},
 	)
 
 	if err != nil {
@@ -109,11 +119,17 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 
 	router := gin.Default()
 
+	// Only X-Forwarded-For from these proxies is used to determine the client IP
+	if err := router.SetTrustedProxies(getTrustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, If-None-Match, If-Modified-Since, X-Request-ID, Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "ETag, Last-Modified, X-Request-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -123,6 +139,9 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 		c.Next()
 	})
 
+	// Tag requests with an id so async work they trigger can be traced in logs
+	router.Use(middleware.RequestID())
+
 	// Add request logging middleware
 	router.Use(gin.Logger())
 
@@ -136,51 +155,183 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 		})
 	})
 
+	// Prometheus metrics endpoint
+	router.GET("/metrics", metrics.Handler())
+
+	// Initialize reporting handler
+	reportingHandler := handlers.NewReportingHandler(db)
+	reportingHandler.SetReportCacheMaxAge(getReportCacheMaxAge())
+	reportingHandler.SetMinSampleSize(getMinSampleSize())
+	reportingHandler.SetEventDedupWindow(getEventDedupWindow())
+	if err := reportingHandler.SetEventClockSkewPolicy(getEventClockSkewPolicy()); err != nil {
+		log.Fatalf("Invalid event clock skew policy: %v", err)
+	}
+	reportingHandler.SetExportLimits(getExportLimits())
+	reportingHandler.SetQueryCache(getQueryCache())
+	if err := reportingHandler.SetAppUsageThresholds(getAppUsageThresholds()); err != nil {
+		log.Fatalf("Invalid app usage thresholds: %v", err)
+	}
+	reportingHandler.SetRedactedRoles(getRedactedRoles())
+	engagementScoring, err := handlers.ParseEngagementWeights(getEnv("ENGAGEMENT_WEIGHTS", ""))
+	if err == nil {
+		err = reportingHandler.SetEngagementScoring(engagementScoring)
+	}
+	if err != nil {
+		log.Fatalf("Invalid ENGAGEMENT_WEIGHTS: %v", err)
+	}
+	if err := reportingHandler.SetEngagementScoreConfig(getEngagementScoreConfig()); err != nil {
+		log.Fatalf("Invalid engagement score config: %v", err)
+	}
+	for section, orderBy := range getSectionOrders() {
+		if err := reportingHandler.SetDefaultSectionOrder(section, orderBy); err != nil {
+			log.Fatalf("Invalid REPORT_SECTION_ORDERS: %v", err)
+		}
+	}
+	onNetwork, err := handlers.ParseCIDRList(getEnv("ON_NETWORK_CIDRS", ""))
+	if err != nil {
+		log.Fatalf("Invalid ON_NETWORK_CIDRS: %v", err)
+	}
+	reportingHandler.SetSessionNetworkPolicy(onNetwork, getEnv("SESSION_IP_PRIVACY", "false") != "true")
+	reportLocation, err := time.LoadLocation(getEnv("REPORT_TIMEZONE", "UTC"))
+	if err != nil {
+		log.Fatalf("Invalid REPORT_TIMEZONE: %v", err)
+	}
+	reportingHandler.SetReportLocation(reportLocation)
+	weekStart, err := handlers.ParseWeekStart(getEnv("REPORT_WEEK_START", "monday"))
+	if err != nil {
+		log.Fatalf("Invalid REPORT_WEEK_START: %v", err)
+	}
+	reportingHandler.SetWeekStart(weekStart)
+	fiscalYearStart, err := handlers.ParseFiscalYearStart(getEnv("FISCAL_YEAR_START_MONTH", "1"))
+	if err == nil {
+		err = reportingHandler.SetFiscalYearStart(fiscalYearStart)
+	}
+	if err != nil {
+		log.Fatalf("Invalid FISCAL_YEAR_START_MONTH: %v", err)
+	}
+	featureFlags, err := handlers.ParseFeatureFlags(getEnv("FEATURE_FLAGS", ""))
+	if err == nil {
+		err = reportingHandler.SetFeatureFlags(featureFlags)
+	}
+	if err != nil {
+		log.Fatalf("Invalid FEATURE_FLAGS: %v", err)
+	}
+
+	// Run aggregation jobs on their cron schedules
+	scheduler := handlers.NewAggregationScheduler()
+	for _, job := range reportingHandler.AggregationJobs() {
+		spec := getEnv("AGGREGATION_SCHEDULE_"+strings.ToUpper(job.Name), job.DefaultSchedule)
+		if err := scheduler.AddJob(job.Name, spec, job.Run); err != nil {
+			log.Fatalf("Invalid aggregation schedule: %v", err)
+		}
+	}
+	scheduler.Start(context.Background())
+	reportingHandler.SetAggregationScheduler(scheduler)
+
 	// API documentation endpoint
 	router.GET("/docs", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"service": "Educational Reporting Framework API",
 			"version": "1.0.0",
+			"default_section_order": handlers.DefaultSectionOrders,
+			"features": reportingHandler.FeatureFlags(),
+			"time_format": "Report, analytics, school, classroom and student endpoints accept ?time_format=iso (default), epoch_ms or epoch_s for timestamps",
 			"endpoints": gin.H{
 				"events": gin.H{
-					"POST /api/v1/events": "Ingest batch events",
-					"POST /api/v1/sessions/batch": "Ingest session data with events",
+					"POST /api/v1/events": "Ingest batch events (an Idempotency-Key header or per-event client_event_id makes retries no-ops; clock-skewed timestamps are clamped or rejected per EVENT_CLOCK_SKEW_ACTION)",
+					"POST /api/v1/events/import": "Bulk-import events from CSV/TSV (?delimiter=tab)",
+					"POST /api/v1/sessions/batch": "Ingest session data with events (at most 100 sessions of 1000 chronologically ordered events, each at most 24h long)",
 				},
 				"reports": gin.H{
-					"GET /api/v1/reports/student-performance": "Student performance analytics",
-					"GET /api/v1/reports/classroom-engagement": "Classroom engagement metrics",
-					"GET /api/v1/reports/content-effectiveness": "Content effectiveness analysis",
+					"GET /api/v1/reports/student-performance": "Student performance analytics (?format=csv for one row per quiz, ?order_by=percentage_score:desc orders quiz_performance, ?compare_to=previous adds percentage changes against the prior period of equal length)",
+					"GET /api/v1/reports/classroom-engagement": "Classroom engagement metrics (?format=zip for a CSV bundle, ?format=csv for one row per student or ?format=pdf for a printable summary, ?order_by=avg_quiz_score:desc orders student_breakdown, ?include_ci=true adds 95% confidence intervals)",
+					"GET /api/v1/reports/content-effectiveness": "Content effectiveness analysis (?format=zip for a CSV bundle or ?format=csv for one row per content type, ?order_by=view_count:desc orders most_engaging_content, ?include_ci=true adds 95% confidence intervals, ?mode=resilient returns the sections that succeeded with partial and errors)",
 					"GET /api/v1/reports/school-overview": "School-level overview",
+					"GET /api/v1/reports/creator-content-effectiveness": "Content effectiveness per creator vs school baseline",
+					"GET /api/v1/reports/onboarding-latency": "Hours from enrollment to first session or event, with never-activated count (?school_id=&classroom_id=)",
+					"GET /api/v1/reports/class-size-engagement": "Per-classroom enrollment vs average engagement with the correlation coefficient (?school_id=&date_from=&date_to=)",
+					"GET /api/v1/reports/school-comparison": "Side-by-side engagement, active students, quiz score and adoption for up to 20 schools, ranked by engagement (?school_ids=a,b,c&date_from=&date_to=)",
+					"GET /api/v1/reports/content-type-trend": "Content created per type in each week or month, for a school's content mix over time (?school_id=&granularity=week|month&date_from=&date_to=)",
+					"GET /api/v1/reports/teacher/:id/subject-breakdown": "Average class score, participation rate and quiz count per subject across a teacher's classrooms, with the lagging subject (admin or the teacher only)",
 				},
 				"analytics": gin.H{
-					"GET /api/v1/analytics/real-time/active-sessions": "Real-time active sessions",
-					"GET /api/v1/analytics/trends/engagement": "Engagement trends over time",
+					"GET /api/v1/analytics/real-time/active-sessions": "Real-time active sessions, most recent heartbeat first (?school_id=&limit=50&offset=0, max limit 500)",
+					"GET /api/v1/analytics/trends/engagement": "Engagement trends over time (optional ?forecast_days=14 projection)",
 					"GET /api/v1/analytics/quiz-analytics/:quiz_id": "Detailed quiz analytics",
+					"GET /api/v1/analytics/quiz-analytics/:quiz_id/abandonment": "Average questions answered per session and where incomplete sessions stopped",
+					"GET /api/v1/analytics/quiz-analytics/:quiz_id/timing": "Time spent percentiles (p25/p50/p75/p90) across the quiz and per question, flagging high-variation questions",
+					"GET /api/v1/analytics/engagement-anomalies": "Classroom days with engagement drops below the rolling baseline",
+					"GET /api/v1/analytics/grading-latency": "Time from submission to manual grading per teacher and classroom",
+					"GET /api/v1/analytics/stickiness": "DAU, WAU, MAU and DAU/MAU ratio for a school (?school_id=&date=)",
+					"GET /api/v1/analytics/content-freshness": "Views and effectiveness by content age at view time (?classroom_id=&date_from=&date_to=)",
+					"GET /api/v1/analytics/engagement-by-weekday": "Average sessions, participation and engagement per day of week in the report timezone (?classroom_id=&date_from=&date_to=)",
+					"GET /api/v1/analytics/content-by-creation-hour": "Content count, average views and effectiveness per hour of day the content was created, in the report timezone (?classroom_id=&date_from=&date_to=)",
+					"GET /api/v1/analytics/quiz-completion-by-assignment-hour": "Average quiz completion rate per hour of day the quiz was published, in the school timezone or ?tz=; also counts unpublished quizzes (?classroom_id=&date_from=&date_to=)",
+					"GET /api/v1/analytics/session-duration-histogram": "Sessions per duration bucket with mean, median and p90 minutes (?school_id=&date_from=&date_to=&buckets=5,15,30,60)",
+				},
+				"schools": gin.H{
+					"GET /api/v1/schools/:id/classroom-rankings": "Classrooms ranked by engagement, participation or avg score (?max_participation=70&min_avg_score=60)",
+				},
+				"classrooms": gin.H{
+					"GET /api/v1/classrooms/:id/engagement-explain": "Engagement score recomputed with each component's raw value, weight and contribution",
+					"GET /api/v1/classrooms/:id/equity": "Gini coefficient and Lorenz curve of student activity, showing how concentrated participation is (?metric=events|minutes&date_from=&date_to=)",
+					"GET /api/v1/classrooms/:id/presence": "Attendance-style matrix of students by local date with presence and active minutes per cell, for heatmaps (?date_from=&date_to=, at most 92 days, default last 14)",
+				},
+				"students": gin.H{
+					"GET /api/v1/students/:id/grade-comparison": "Student engagement and quiz averages vs same-grade peers",
+					"GET /api/v1/students/:id/pending-quizzes": "Published quizzes the student has not completed, most urgent first (?include_overdue=true)",
+					"GET /api/v1/students/:id/growth": "Per-month (or week) quiz average, engagement and active days with deltas (?granularity=month)",
+					"GET /api/v1/students/:id/activity": "Chronological event feed with session and classroom context (?types=&application=&cursor=&limit=)",
+					"GET /api/v1/students/:id/recommended-content": "Most effective content in the student's classrooms they have not viewed, excluding their own (?classroom_id=&limit=10, max 50)",
+					"GET /api/v1/students/:id/app-usage": "Sessions and minutes per application with a whiteboard/notebook classification (only, mostly, balanced) (?date_from=&date_to=)",
+				},
+				"quizzes": gin.H{
+					"GET /api/v1/quizzes/:id/retakes": "Attempts per student, share of students who retook and average score change per additional attempt",
+					"GET /api/v1/quizzes/:id/pending-grading": "Ungraded short-answer and essay submissions, grouped by student (the classroom's teacher or an admin of its school only)",
+				},
+				"submissions": gin.H{
+					"POST /api/v1/submissions/:id/grade": "Grade a short-answer or essay submission ({points_earned, is_correct}) and recompute its quiz session score (the classroom's teacher or an admin of its school only)",
+				},
+				"teachers": gin.H{
+					"GET /api/v1/teachers/:id/load": "Classrooms, students across them, quizzes and content authored in the period, with a load level (admin or the teacher only)",
+					"GET /api/v1/teachers/:id/content-cadence": "Content created per week or month and the gaps between creations, flagging dry spells (?granularity=week|month&dry_spell_days=14; admin or the teacher only)",
 				},
 				"query": gin.H{
-					"POST /api/v1/query": "Generic cube.dev style queries",
+					"POST /api/v1/query": "Generic cube.dev style queries, validated up front with every problem returned as a 422 (Accept: application/x-ndjson streams rows one per line; identical queries are served from cache for QUERY_CACHE_TTL seconds, meta.cached says so, ?no_cache=true bypasses it)",
 					"GET /api/v1/query/schema": "Available measures and dimensions",
+					"GET /api/v1/query/dimension-values": "Distinct values of a dimension (?dimension=events.type&q=&limit=)",
+					"POST /api/v1/query/saved": "Save a named query for the authenticated user (replaces one with the same name)",
+					"GET /api/v1/query/saved": "The authenticated user's saved queries",
+					"POST /api/v1/query/saved/:name/run": "Run a saved query (optional body {\"dateRange\": [from, to]} overrides every time dimension)",
 				},
 				"admin": gin.H{
 					"POST /api/v1/admin/schools": "Create school",
 					"POST /api/v1/admin/classrooms": "Create classroom",
 					"POST /api/v1/admin/users": "Create user",
 					"POST /api/v1/admin/refresh-metrics": "Refresh aggregated metrics",
+					"POST /api/v1/admin/refresh-content-metrics": "Recompute view counts, unique viewers, shares and effectiveness for content viewed or shared in a date range (?date_from=&date_to=, default last 2 days, max 366 days)",
+					"POST /api/v1/admin/reports/warm": "Run every aggregation reports depend on (daily, weekly, content, quiz analytics) in dependency order over ?days= of history (default 365) and report each job's status",
+					"GET /api/v1/admin/ingestion-stats": "Events ingested per minute over the last hour, ingestion lag and aggregation watermark",
+					"GET /api/v1/admin/aggregation-schedule": "Cron schedule, last run and next run of each aggregation job",
+					"GET /api/v1/admin/canned-reports": "Canned reports and their integer parameters",
+					"GET /api/v1/admin/canned-reports/:name": "Run a canned report; its SQL is a fixed template checked against a table/column allow-list (?<param>=<int>)",
 				},
 			},
 		})
 	})
 
-	// Initialize reporting handler
-	reportingHandler := handlers.NewReportingHandler(db)
-
-	// Register API routes
-	api := router.Group("/api")
+	// Register API routes; every /api/v1 route needs a bearer JWT or API key
+	authConfig := config.Load()
+	if authConfig.JWTSecret == "" {
+		fmt.Println("⚠️  JWT_SECRET is not set; bearer tokens will be rejected and only API keys accepted")
+	}
+	api := router.Group("/api", middleware.AuthMiddleware(authConfig))
 	reportingHandler.RegisterRoutes(api)
 
 	// Add schema endpoint for generic queries
 	api.GET("/v1/query/schema", func(c *gin.Context) {
 		queryBuilder := handlers.NewGenericQueryBuilder(db)
+		queryBuilder.SetFiscalYearStart(fiscalYearStart)
 		schema := queryBuilder.GetAvailableMetrics()
 		c.JSON(200, schema)
 	})
@@ -193,7 +344,7 @@ func setupRouter(db *gorm.DB) *gin.Engine {
 func seedDatabase(db *gorm.DB) error {
 	fmt.Println("🌱 Seeding database with test data...")
 
-	seedManager := seedutils.NewSeedManager(db)
+	seedManager := seedutils.NewSeedManager(db, getRandomSeed())
 
 	// Check if data already exists
 	var schoolCount int64
@@ -229,6 +380,147 @@ func shouldSeedData() bool {
 	return getEnv("SEED_DATA", "true") == "true"
 }
 
+// getReportCacheMaxAge returns REPORT_CACHE_MAX_AGE (seconds) as a duration
+func getReportCacheMaxAge() time.Duration {
+	seconds, err := strconv.Atoi(getEnv("REPORT_CACHE_MAX_AGE", "60"))
+	if err != nil || seconds < 0 {
+		return handlers.DefaultReportCacheMaxAge
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// getMinSampleSize returns MIN_SAMPLE_SIZE, the fewest data points behind a reported average
+func getMinSampleSize() int {
+	n, err := strconv.Atoi(getEnv("MIN_SAMPLE_SIZE", strconv.Itoa(handlers.DefaultMinSampleSize)))
+	if err != nil {
+		return handlers.DefaultMinSampleSize
+	}
+	return n
+}
+
+// getEventDedupWindow returns EVENT_DEDUP_WINDOW_MS as a duration; unset disables deduplication
+func getEventDedupWindow() time.Duration {
+	ms, err := strconv.Atoi(getEnv("EVENT_DEDUP_WINDOW_MS", "0"))
+	if err != nil || ms < 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// getEventClockSkewPolicy returns EVENT_MAX_FUTURE_SKEW_SECONDS and
+// EVENT_MAX_AGE_HOURS, how far an event timestamp may be ahead of or behind
+// server time (0 disables either check), and EVENT_CLOCK_SKEW_ACTION, reject
+// or clamp
+func getEventClockSkewPolicy() handlers.EventClockSkewPolicy {
+	policy := handlers.DefaultEventClockSkewPolicy
+	if seconds, err := strconv.Atoi(getEnv("EVENT_MAX_FUTURE_SKEW_SECONDS", "")); err == nil {
+		policy.MaxFuture = time.Duration(seconds) * time.Second
+	}
+	if hours, err := strconv.Atoi(getEnv("EVENT_MAX_AGE_HOURS", "")); err == nil {
+		policy.MaxAge = time.Duration(hours) * time.Hour
+	}
+	policy.Action = getEnv("EVENT_CLOCK_SKEW_ACTION", policy.Action)
+	return policy
+}
+
+// getExportLimits returns EXPORT_MAX_CONCURRENT, the report exports run at
+// once (0 for no limit), and EXPORT_MAX_QUEUED, how many more may wait
+func getExportLimits() (int, int) {
+	concurrency, err := strconv.Atoi(getEnv("EXPORT_MAX_CONCURRENT", strconv.Itoa(handlers.DefaultExportConcurrency)))
+	if err != nil {
+		concurrency = handlers.DefaultExportConcurrency
+	}
+	queueSize, err := strconv.Atoi(getEnv("EXPORT_MAX_QUEUED", strconv.Itoa(handlers.DefaultExportQueueSize)))
+	if err != nil || queueSize < 0 {
+		queueSize = handlers.DefaultExportQueueSize
+	}
+	return concurrency, queueSize
+}
+
+// getQueryCache returns QUERY_CACHE_SIZE, the generic query results cached
+// per instance (0 disables caching), and QUERY_CACHE_TTL, the seconds a
+// cached result is served
+func getQueryCache() (int, time.Duration) {
+	size, err := strconv.Atoi(getEnv("QUERY_CACHE_SIZE", strconv.Itoa(handlers.DefaultQueryCacheSize)))
+	if err != nil {
+		size = handlers.DefaultQueryCacheSize
+	}
+	seconds, err := strconv.Atoi(getEnv("QUERY_CACHE_TTL", ""))
+	if err != nil || seconds < 0 {
+		return size, handlers.DefaultQueryCacheTTL
+	}
+	return size, time.Duration(seconds) * time.Second
+}
+
+// getAppUsageThresholds returns APP_USAGE_ONLY_SHARE and APP_USAGE_MOSTLY_SHARE,
+// the shares of whiteboard plus notebook usage that count as only or mostly one app
+func getAppUsageThresholds() handlers.AppUsageThresholds {
+	thresholds := handlers.DefaultAppUsageThresholds
+	if share, err := strconv.ParseFloat(getEnv("APP_USAGE_ONLY_SHARE", ""), 64); err == nil {
+		thresholds.OnlyShare = share
+	}
+	if share, err := strconv.ParseFloat(getEnv("APP_USAGE_MOSTLY_SHARE", ""), 64); err == nil {
+		thresholds.MostlyShare = share
+	}
+	return thresholds
+}
+
+// getRedactedRoles returns REPORT_REDACTED_ROLES, the comma-separated roles
+// that only see their own row in student breakdowns
+func getRedactedRoles() []string {
+	return strings.Split(getEnv("REPORT_REDACTED_ROLES", strings.Join(handlers.DefaultRedactedRoles, ",")), ",")
+}
+
+// getSectionOrders returns REPORT_SECTION_ORDERS, comma-separated
+// section=field[:asc|desc] overrides of the default report list ordering
+func getSectionOrders() map[string]string {
+	orders := map[string]string{}
+	for _, entry := range strings.Split(getEnv("REPORT_SECTION_ORDERS", ""), ",") {
+		if section, orderBy, ok := strings.Cut(strings.TrimSpace(entry), "="); ok {
+			orders[strings.TrimSpace(section)] = strings.TrimSpace(orderBy)
+		}
+	}
+	return orders
+}
+
+// getTrustedProxies returns TRUSTED_PROXIES, the comma-separated proxy
+// addresses or CIDR ranges whose X-Forwarded-For header is trusted; none by default
+func getTrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// getEngagementScoreConfig returns the student engagement score weighting
+// from ENGAGEMENT_CONSISTENCY_WEIGHT, ENGAGEMENT_INTENSITY_WEIGHT and
+// ENGAGEMENT_INTENSITY_CAP_MINUTES over the defaults
+func getEngagementScoreConfig() services.EngagementScoreConfig {
+	cfg := services.DefaultEngagementScoreConfig()
+	for key, value := range map[string]*float64{
+		"ENGAGEMENT_CONSISTENCY_WEIGHT":    &cfg.ConsistencyWeight,
+		"ENGAGEMENT_INTENSITY_WEIGHT":      &cfg.IntensityWeight,
+		"ENGAGEMENT_INTENSITY_CAP_MINUTES": &cfg.IntensityCapMinutes,
+	} {
+		if f, err := strconv.ParseFloat(getEnv(key, ""), 64); err == nil {
+			*value = f
+		}
+	}
+	return cfg
+}
+
+// getRandomSeed returns SEED_RANDOM_SEED, or 0 for time-based seeding
+func getRandomSeed() int64 {
+	seed, err := strconv.ParseInt(getEnv("SEED_RANDOM_SEED", "0"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return seed
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -51,7 +51,7 @@ func (ds *DataSeeder) ExecuteSeedingWorkflow(cfg *config.Config) error {
 
 	// Ensure database schema is current before seeding
 	// The Migrate function now includes auto-seeding via seedmigrations
-	if err := database.Migrate(db); err != nil {
+	if err := database.Migrate(db, cfg.SeedRandomSeed); err != nil {
 		return err
 	}
 
@@ -18,6 +18,9 @@ func main() {
 
 	// Load configuration
 	cfg := config.Load()
+	if cfg.JWTSecret == "" {
+		log.Println("JWT_SECRET is not set; bearer tokens will be rejected and only API keys accepted")
+	}
 
 	// Initialize database
 	db, err := database.InitDB(cfg.DatabaseURL)
@@ -26,7 +29,7 @@ func main() {
 	}
 
 	// Run database migrations
-	if err := database.Migrate(db); err != nil {
+	if err := database.Migrate(db, cfg.SeedRandomSeed); err != nil {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
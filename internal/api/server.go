@@ -1,8 +1,11 @@
 package api
 
 import (
+	"time"
+
 	"reporting-framework/internal/config"
 	"reporting-framework/internal/handlers"
+	"reporting-framework/internal/metrics"
 	"reporting-framework/internal/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -31,9 +34,15 @@ func (s *Server) setupRoutes() {
 	eventHandler := handlers.NewEventHandler(s.db)
 	sessionHandler := handlers.NewSessionHandler(s.db)
 	quizHandler := handlers.NewQuizHandler(s.db)
+	quizHandler.SetShuffleSeed(s.config.QuizShuffleSeed)
 	reportHandler := handlers.NewReportHandler(s.db)
 	analyticsHandler := handlers.NewAnalyticsHandler(s.db)
 	crudHandler := handlers.NewCRUDHandler(s.db)
+	liveHub := handlers.NewLiveHub(s.db, s.config.MaxLiveConnectionsPerClassroom)
+	liveHub.SetWindows(
+		time.Duration(s.config.LiveActivityWindowMinutes)*time.Minute,
+		time.Duration(s.config.LiveRecentEventsWindowMinutes)*time.Minute,
+	)
 
 	// Middleware
 	s.router.Use(middleware.CORS())
@@ -44,6 +53,9 @@ func (s *Server) setupRoutes() {
 		c.JSON(200, gin.H{"status": "healthy"})
 	})
 
+	// Prometheus metrics
+	s.router.GET("/metrics", metrics.Handler())
+
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
 	{
@@ -68,12 +80,21 @@ func (s *Server) setupRoutes() {
 		// Quiz management endpoints
 		quizzes := protected.Group("/quizzes")
 		{
+			quizzes.GET("", quizHandler.ListQuizzes)
 			quizzes.POST("", quizHandler.CreateQuiz)
 			quizzes.PUT("/:id", quizHandler.UpdateQuiz)
 			quizzes.POST("/:id/responses", quizHandler.SubmitResponse)
 			quizzes.GET("/:id", quizHandler.GetQuiz)
+			quizzes.GET("/:id/questions", quizHandler.GetQuizQuestions)
+			quizzes.GET("/:id/reliability", quizHandler.GetQuizReliability)
+			quizzes.GET("/:id/item-analysis", quizHandler.GetQuizItemAnalysis)
+			quizzes.POST("/:id/archive", quizHandler.ArchiveQuiz)
+			quizzes.GET("/:id/archive", quizHandler.GetQuizArchive)
 		}
 
+		// Assessment coverage of a classroom's learning objectives (quiz tags)
+		protected.GET("/classrooms/:id/objective-coverage", quizHandler.GetObjectiveCoverage)
+
 		// Reporting endpoints
 		reports := protected.Group("/reports")
 		{
@@ -92,9 +113,7 @@ func (s *Server) setupRoutes() {
 		// WebSocket endpoint for real-time data
 		live := v1.Group("/live")
 		{
-			live.GET("/classroom/:id", func(c *gin.Context) {
-				handlers.HandleWebSocket(c, s.db)
-			})
+			live.GET("/classroom/:id", liveHub.HandleWebSocket)
 		}
 
 		// CRUD endpoints for basic data management
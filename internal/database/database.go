@@ -20,8 +20,9 @@ func InitDB(databaseURL string) (*gorm.DB, error) {
 	return db, nil
 }
 
-// Migrate runs database migrations and ensures seed data exists
-func Migrate(db *gorm.DB) error {
+// Migrate runs database migrations and ensures seed data exists.
+// A non-zero randomSeed makes the generated seed data reproducible.
+func Migrate(db *gorm.DB, randomSeed int64) error {
 	// Run schema migrations first
 	err := db.AutoMigrate(
 		&models.School{},
@@ -35,6 +36,7 @@ func Migrate(db *gorm.DB) error {
 		&models.QuizResponse{},
 		&models.DailyUserStats{},
 		&models.ClassroomAnalytics{},
+		&models.QuizAnalyticsSnapshot{},
 	)
 	if err != nil {
 		return err
@@ -42,7 +44,7 @@ func Migrate(db *gorm.DB) error {
 
 	// Run seed migrations to ensure data exists
 	// This is non-destructive and only runs if no data exists
-	seedmigrations.AutoSeedOnStartup(db)
+	seedmigrations.AutoSeedOnStartup(db, randomSeed)
 
 	return nil
 }
\ No newline at end of file
@@ -20,6 +20,11 @@ func (j JSONB) Value() (driver.Value, error) {
 	return json.Marshal(j)
 }
 
+// GormDataType lets gorm map JSONB fields to jsonb columns
+func (JSONB) GormDataType() string {
+	return "jsonb"
+}
+
 // Scan implements the sql.Scanner interface for JSONB
 func (j *JSONB) Scan(value interface{}) error {
 	if value == nil {
@@ -42,6 +47,7 @@ type School struct {
 	District     *string    `json:"district"`
 	Region       *string    `json:"region"`
 	ContactEmail *string    `json:"contact_email"`
+	Timezone     *string    `json:"timezone" gorm:"size:64"` // IANA name; the report timezone applies when unset
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	DeletedAt    *gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -121,6 +127,7 @@ type Session struct {
 	DurationSeconds *int       `json:"duration_seconds"`
 	DeviceInfo      JSONB      `json:"device_info"`
 	IPAddress       *string    `json:"ip_address" gorm:"type:inet"`
+	NetworkBucket   *string    `json:"network_bucket" gorm:"type:varchar(20)"` // on_network, off_network, unknown
 	CreatedAt       time.Time  `json:"created_at"`
 
 	// Relationships
@@ -162,6 +169,7 @@ type Quiz struct {
 	IsActive      bool       `json:"is_active" gorm:"default:false"`
 	StartTime     *time.Time `json:"start_time"`
 	EndTime       *time.Time `json:"end_time"`
+	PublishedAt   *time.Time `json:"published_at"` // nil until the quiz is assigned to students
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
 	DeletedAt     *gorm.DeletedAt `json:"deleted_at" gorm:"index"`
@@ -223,6 +231,8 @@ type QuizSubmission struct {
 	TimeSpentSeconds *int      `json:"time_spent_seconds"`
 	AttemptNumber   int        `json:"attempt_number" gorm:"default:1"`
 	SubmittedAt     time.Time  `json:"submitted_at" gorm:"default:CURRENT_TIMESTAMP"`
+	GradedAt        *time.Time `json:"graded_at"` // set when a teacher manually grades the answer
+	GradedBy        *uuid.UUID `json:"graded_by"`
 
 	// Relationships
 	Quiz     Quiz         `json:"quiz,omitempty" gorm:"foreignKey:QuizID"`
@@ -234,7 +244,7 @@ type QuizSubmission struct {
 type Event struct {
 	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
 	EventType   string     `json:"event_type" gorm:"not null"`
-	UserID      *uuid.UUID `json:"user_id"`
+	UserID      *uuid.UUID `json:"user_id" gorm:"uniqueIndex:idx_events_user_client_event"`
 	SessionID   *uuid.UUID `json:"session_id"`
 	ClassroomID *uuid.UUID `json:"classroom_id"`
 	SchoolID    *uuid.UUID `json:"school_id"`
@@ -242,7 +252,8 @@ type Event struct {
 	Timestamp   time.Time  `json:"timestamp" gorm:"not null"`
 	Metadata    JSONB      `json:"metadata"`
 	DeviceInfo  JSONB      `json:"device_info"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ClientEventID *string  `json:"client_event_id,omitempty" gorm:"size:100;uniqueIndex:idx_events_user_client_event"` // idempotency key, unique per user
+	CreatedAt   time.Time  `json:"created_at" gorm:"index"`
 
 	// Relationships
 	User      *User      `json:"user,omitempty" gorm:"foreignKey:UserID"`
@@ -251,6 +262,17 @@ type Event struct {
 	School    *School    `json:"school,omitempty" gorm:"foreignKey:SchoolID"`
 }
 
+// SavedQuery is a named generic query definition owned by one user
+type SavedQuery struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID      uuid.UUID `json:"user_id" gorm:"not null;uniqueIndex:idx_saved_queries_user_name"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex:idx_saved_queries_user_name"`
+	Description *string   `json:"description"`
+	Query       JSONB     `json:"query" gorm:"type:jsonb;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
 // EventRequest represents the API request structure for event ingestion
 type EventRequest struct {
 	Events []EventData `json:"events" validate:"required,min=1,max=100"`
@@ -266,6 +288,7 @@ type EventData struct {
 	Application *string                `json:"application"`
 	Metadata    map[string]interface{} `json:"metadata"`
 	DeviceInfo  map[string]interface{} `json:"device_info"`
+	ClientEventID *string              `json:"client_event_id"` // retries with the same id are not stored twice
 }
 
 // EventResponse represents the API response for event ingestion
@@ -274,6 +297,9 @@ type EventResponse struct {
 	ProcessedCount int   `json:"processed_count"`
 	Message       string `json:"message"`
 	EventIDs      []uuid.UUID `json:"event_ids,omitempty"`
+	DeduplicatedCount int `json:"deduplicated_count"`
+	ReplayedCount int `json:"replayed_count"` // events already stored under the same idempotency key
+	ClockSkewAdjustedCount int `json:"clock_skew_adjusted_count"` // events whose skewed timestamps were replaced with server time
 }
 
 // TableName methods for GORM
@@ -287,4 +313,5 @@ func (Quiz) TableName() string          { return "quizzes" }
 func (QuizQuestion) TableName() string  { return "quiz_questions" }
 func (QuizSession) TableName() string   { return "quiz_sessions" }
 func (QuizSubmission) TableName() string { return "quiz_submissions" }
-func (Event) TableName() string         { return "events" }
\ No newline at end of file
+func (Event) TableName() string         { return "events" }
+func (SavedQuery) TableName() string    { return "saved_queries" }
\ No newline at end of file
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"io"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PDFContentType is the media type of rendered PDF reports
+const PDFContentType = "application/pdf"
+
+// Field is a labelled value in a report's header or summary block
+type Field struct {
+	Label string
+	Value string
+}
+
+// Table is a report section rendered as a grid. Widths are relative column
+// weights; when empty the columns share the page width equally.
+type Table struct {
+	Title   string
+	Columns []string
+	Widths  []float64
+	Rows    [][]string
+}
+
+// Report is a printable report laid out top to bottom: title, header
+// fields, a summary block, tables, then notes such as insights
+type Report struct {
+	Title      string
+	Header     []Field
+	Summary    []Field
+	Tables     []Table
+	NotesTitle string
+	Notes      []string
+}
+
+const (
+	pdfMargin     = 15.0
+	pdfLineHeight = 6.0
+	pdfRowHeight  = 7.0
+)
+
+// RenderPDF writes the report as an A4 portrait PDF. Text is rendered in
+// the built-in Helvetica font, so characters outside Windows-1252 are
+// replaced.
+func RenderPDF(w io.Writer, report Report) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(contentWidth, 10, tr(report.Title), "", 1, "L", false, 0, "")
+	writeFields(pdf, tr, report.Header, contentWidth)
+
+	if len(report.Summary) > 0 {
+		writeSectionTitle(pdf, tr, "Summary", contentWidth)
+		writeFields(pdf, tr, report.Summary, contentWidth)
+	}
+
+	for _, table := range report.Tables {
+		writeSectionTitle(pdf, tr, table.Title, contentWidth)
+		writeTable(pdf, tr, table, contentWidth)
+	}
+
+	if len(report.Notes) > 0 {
+		writeSectionTitle(pdf, tr, report.NotesTitle, contentWidth)
+		pdf.SetFont("Helvetica", "", 10)
+		for _, note := range report.Notes {
+			pdf.MultiCell(contentWidth, pdfLineHeight, tr("- "+note), "", "L", false)
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+func writeSectionTitle(pdf *fpdf.Fpdf, tr func(string) string, title string, width float64) {
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(width, 8, tr(title), "B", 1, "L", false, 0, "")
+	pdf.Ln(1)
+}
+
+// writeFields writes one "label: value" line per field with the labels in a
+// fixed-width column
+func writeFields(pdf *fpdf.Fpdf, tr func(string) string, fields []Field, width float64) {
+	labelWidth := width * 0.4
+	for _, field := range fields {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(labelWidth, pdfLineHeight, tr(field.Label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(width-labelWidth, pdfLineHeight, tr(field.Value), "", "L", false)
+	}
+}
+
+// writeTable writes the table with a shaded header row, repeating the header
+// on every page the table continues onto
+func writeTable(pdf *fpdf.Fpdf, tr func(string) string, table Table, width float64) {
+	widths := columnWidths(table, width)
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, column := range table.Columns {
+			pdf.CellFormat(widths[i], pdfRowHeight, tr(column), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	header()
+	if len(table.Rows) == 0 {
+		pdf.CellFormat(width, pdfRowHeight, "No rows", "1", 1, "C", false, 0, "")
+		return
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	for _, row := range table.Rows {
+		if pdf.GetY()+pdfRowHeight > pageHeight-pdfMargin {
+			pdf.AddPage()
+			header()
+		}
+		for i := range table.Columns {
+			var cell string
+			if i < len(row) {
+				cell = tr(row[i])
+			}
+			// Truncate rather than wrap so rows keep a fixed height
+			for cell != "" && pdf.GetStringWidth(cell) > widths[i]-2 {
+				cell = cell[:len(cell)-1]
+			}
+			pdf.CellFormat(widths[i], pdfRowHeight, cell, "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// columnWidths scales the table's relative widths to the page
+func columnWidths(table Table, width float64) []float64 {
+	widths := make([]float64, len(table.Columns))
+	var total float64
+	for i := range widths {
+		weight := 1.0
+		if i < len(table.Widths) && table.Widths[i] > 0 {
+			weight = table.Widths[i]
+		}
+		widths[i] = weight
+		total += weight
+	}
+	for i := range widths {
+		widths[i] = widths[i] / total * width
+	}
+	return widths
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"time"
+)
+
+// Aggregation job names; the environment variable AGGREGATION_SCHEDULE_<NAME>
+// overrides each job's default schedule
+const (
+	AggregationJobDaily   = "daily"
+	AggregationJobWeekly  = "weekly"
+	AggregationJobContent = "content"
+)
+
+// aggregationLookbackDays is how many days each run recomputes, so late
+// events for yesterday are still picked up
+const aggregationLookbackDays = 2
+
+// AggregationJob is an aggregation that can be scheduled
+type AggregationJob struct {
+	Name            string
+	DefaultSchedule string
+	Run             func(ctx context.Context) error
+}
+
+// AggregationJobs lists the aggregation jobs in dependency order: weekly
+// school metrics read the daily tables
+func (h *ReportingHandler) AggregationJobs() []AggregationJob {
+	return []AggregationJob{
+		{Name: AggregationJobDaily, DefaultSchedule: "*/15 * * * *", Run: h.aggregateDailyMetrics},
+		{Name: AggregationJobWeekly, DefaultSchedule: "0 * * * *", Run: h.aggregateWeeklySchoolMetrics},
+		{Name: AggregationJobContent, DefaultSchedule: "*/30 * * * *", Run: h.aggregateContentMetrics},
+	}
+}
+
+// aggregationSince is the start of the recomputed window
+func aggregationSince() time.Time {
+	return time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(aggregationLookbackDays - 1))
+}
+
+// aggregateDailyMetrics recomputes daily user and classroom metrics for the
+// lookback window
+func (h *ReportingHandler) aggregateDailyMetrics(ctx context.Context) error {
+	return h.recomputeDailyMetrics(ctx, aggregationSince())
+}
+
+// recomputeDailyMetrics recomputes daily user and classroom metrics for every
+// day since the given time from raw sessions and events, then refreshes the
+// classroom performance view. Days are local days in each school's time zone,
+// or the report location for schools without one.
+func (h *ReportingHandler) recomputeDailyMetrics(ctx context.Context, since time.Time) error {
+	db := h.db.WithContext(ctx)
+	tz := h.reportLocation.String()
+	sinceDate := since.Format(DateFormat)
+	// Local days start up to 14 hours before UTC midnight
+	rawSince := since.AddDate(0, 0, -1)
+
+	err := db.Exec(`
+		WITH ev AS (
+			SELECT e.user_id, d.date,
+				COUNT(*) as events_count,
+				COUNT(*) FILTER (WHERE e.event_type = 'content_viewed') as content_viewed_count,
+				COUNT(*) FILTER (WHERE e.application = 'whiteboard') as whiteboard_events,
+				COUNT(*) FILTER (WHERE e.application = 'notebook') as notebook_events
+			FROM events e
+			JOIN users u ON u.id = e.user_id
+			LEFT JOIN schools sc ON sc.id = u.school_id
+			CROSS JOIN LATERAL (SELECT `+localDateSQL("e.timestamp", schoolTimezoneSQL)+` as date) d
+			WHERE e.timestamp >= ? AND d.date >= ?::date
+			GROUP BY 1, 2
+		), se AS (
+			SELECT s.user_id, d.date,
+				COUNT(*) as session_count,
+				COALESCE(SUM(s.duration_seconds), 0) as total_duration
+			FROM sessions s
+			JOIN users u ON u.id = s.user_id
+			LEFT JOIN schools sc ON sc.id = u.school_id
+			CROSS JOIN LATERAL (SELECT `+localDateSQL("s.start_time", schoolTimezoneSQL)+` as date) d
+			WHERE s.start_time >= ? AND d.date >= ?::date
+			GROUP BY 1, 2
+		)
+		INSERT INTO daily_user_metrics (
+			user_id, school_id, date, session_count, total_session_duration_seconds,
+			avg_session_duration_seconds, events_count, content_viewed_count,
+			whiteboard_events, notebook_events, created_at, updated_at
+		)
+		SELECT
+			u.id, u.school_id, COALESCE(ev.date, se.date),
+			COALESCE(se.session_count, 0),
+			COALESCE(se.total_duration, 0),
+			COALESCE(se.total_duration::decimal / NULLIF(se.session_count, 0), 0),
+			COALESCE(ev.events_count, 0),
+			COALESCE(ev.content_viewed_count, 0),
+			COALESCE(ev.whiteboard_events, 0),
+			COALESCE(ev.notebook_events, 0),
+			NOW(), NOW()
+		FROM ev
+		FULL OUTER JOIN se ON ev.user_id = se.user_id AND ev.date = se.date
+		JOIN users u ON u.id = COALESCE(ev.user_id, se.user_id)
+		ON CONFLICT (user_id, date) DO UPDATE SET
+			session_count = EXCLUDED.session_count,
+			total_session_duration_seconds = EXCLUDED.total_session_duration_seconds,
+			avg_session_duration_seconds = EXCLUDED.avg_session_duration_seconds,
+			events_count = EXCLUDED.events_count,
+			content_viewed_count = EXCLUDED.content_viewed_count,
+			whiteboard_events = EXCLUDED.whiteboard_events,
+			notebook_events = EXCLUDED.notebook_events,
+			updated_at = NOW()
+	`, tz, rawSince, sinceDate, tz, rawSince, sinceDate).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec(`
+		INSERT INTO daily_classroom_metrics (
+			classroom_id, school_id, date, total_students, active_students_count,
+			participation_rate, total_sessions, avg_session_duration_minutes,
+			created_at, updated_at
+		)
+		SELECT
+			s.classroom_id, c.school_id, d.date,
+			enrolled.total,
+			COUNT(DISTINCT s.user_id) FILTER (WHERE u.role = 'student'),
+			LEAST(COUNT(DISTINCT s.user_id) FILTER (WHERE u.role = 'student') * 100.0 / NULLIF(enrolled.total, 0), 100),
+			COUNT(*),
+			COALESCE(AVG(s.duration_seconds) / 60.0, 0),
+			NOW(), NOW()
+		FROM sessions s
+		JOIN classrooms c ON c.id = s.classroom_id
+		JOIN users u ON u.id = s.user_id
+		LEFT JOIN schools sc ON sc.id = c.school_id
+		CROSS JOIN LATERAL (SELECT `+localDateSQL("s.start_time", schoolTimezoneSQL)+` as date) d
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) as total FROM user_classrooms uc
+			WHERE uc.classroom_id = s.classroom_id AND uc.role = 'student' AND uc.is_active = true
+		) enrolled
+		WHERE s.start_time >= ? AND d.date >= ?::date
+		GROUP BY s.classroom_id, c.school_id, d.date, enrolled.total
+		ON CONFLICT (classroom_id, date) DO UPDATE SET
+			total_students = EXCLUDED.total_students,
+			active_students_count = EXCLUDED.active_students_count,
+			participation_rate = EXCLUDED.participation_rate,
+			total_sessions = EXCLUDED.total_sessions,
+			avg_session_duration_minutes = EXCLUDED.avg_session_duration_minutes,
+			updated_at = NOW()
+	`, tz, rawSince, sinceDate).Error
+	if err != nil {
+		return err
+	}
+
+	if err := db.Exec("SELECT refresh_classroom_performance_mv()").Error; err != nil {
+		return err
+	}
+	h.metricsRefreshedAt.Store(time.Now().UnixNano())
+	return nil
+}
+
+// aggregateWeeklySchoolMetrics rolls the daily tables up into the school's
+// metrics for every week touched by the lookback window
+func (h *ReportingHandler) aggregateWeeklySchoolMetrics(ctx context.Context) error {
+	return h.recomputeWeeklySchoolMetrics(ctx, aggregationSince())
+}
+
+// recomputeWeeklySchoolMetrics rolls the daily tables up into the school's
+// metrics for every week from the one containing since
+func (h *ReportingHandler) recomputeWeeklySchoolMetrics(ctx context.Context, since time.Time) error {
+	return h.db.WithContext(ctx).Exec(`
+		WITH classroom_weeks AS (
+			SELECT school_id, DATE_TRUNC('week', date)::date as week_start_date,
+				COUNT(DISTINCT classroom_id) FILTER (WHERE active_students_count > 0) as active_classrooms,
+				SUM(total_sessions) as total_sessions,
+				AVG(engagement_score) as avg_engagement
+			FROM daily_classroom_metrics
+			WHERE date >= DATE_TRUNC('week', ?::date)
+			GROUP BY 1, 2
+		), user_weeks AS (
+			SELECT school_id, DATE_TRUNC('week', date)::date as week_start_date,
+				COUNT(DISTINCT user_id) as active_users
+			FROM daily_user_metrics
+			WHERE date >= DATE_TRUNC('week', ?::date)
+			GROUP BY 1, 2
+		)
+		INSERT INTO weekly_school_metrics (
+			school_id, week_start_date, total_classrooms, active_classrooms,
+			total_users, active_users, total_sessions, avg_daily_sessions,
+			avg_school_engagement, created_at, updated_at
+		)
+		SELECT
+			cw.school_id, cw.week_start_date,
+			(SELECT COUNT(*) FROM classrooms c WHERE c.school_id = cw.school_id),
+			cw.active_classrooms,
+			(SELECT COUNT(*) FROM users u WHERE u.school_id = cw.school_id),
+			COALESCE(uw.active_users, 0),
+			COALESCE(cw.total_sessions, 0),
+			COALESCE(cw.total_sessions, 0) / 7.0,
+			COALESCE(cw.avg_engagement, 0),
+			NOW(), NOW()
+		FROM classroom_weeks cw
+		LEFT JOIN user_weeks uw ON uw.school_id = cw.school_id AND uw.week_start_date = cw.week_start_date
+		ON CONFLICT (school_id, week_start_date) DO UPDATE SET
+			total_classrooms = EXCLUDED.total_classrooms,
+			active_classrooms = EXCLUDED.active_classrooms,
+			total_users = EXCLUDED.total_users,
+			active_users = EXCLUDED.active_users,
+			total_sessions = EXCLUDED.total_sessions,
+			avg_daily_sessions = EXCLUDED.avg_daily_sessions,
+			avg_school_engagement = EXCLUDED.avg_school_engagement,
+			updated_at = NOW()
+	`, since, since).Error
+}
+
+// Effectiveness score weights: each component is scaled to 0-1 and the
+// blend to 0-100
+const (
+	effectivenessDurationWeight = 0.5
+	effectivenessReachWeight    = 0.3
+	effectivenessShareWeight    = 0.2
+
+	// fullViewDurationSeconds is the average view duration that earns the
+	// whole duration component
+	fullViewDurationSeconds = 300
+)
+
+// aggregateContentMetrics recomputes content metrics for content viewed or
+// shared during the lookback window
+func (h *ReportingHandler) aggregateContentMetrics(ctx context.Context) error {
+	_, err := h.refreshContentMetrics(ctx, aggregationSince(), time.Now().UTC())
+	return err
+}
+
+// refreshContentMetrics recomputes the all-time totals of every content item
+// with a content_viewed or content_shared event in [from, to), and returns
+// how many content rows were written. Events carry the content id in
+// metadata.content_id and views their duration in seconds in
+// metadata.duration. The effectiveness score blends average view duration,
+// reach (unique viewers over the classroom's enrolled students) and shares
+// per viewer.
+func (h *ReportingHandler) refreshContentMetrics(ctx context.Context, from, to time.Time) (int64, error) {
+	result := h.db.WithContext(ctx).Exec(`
+		WITH touched AS (
+			SELECT DISTINCT metadata->>'content_id' as content_id FROM events
+			WHERE event_type IN ('content_viewed', 'content_shared')
+			AND timestamp >= ? AND timestamp < ?
+		), totals AS (
+			SELECT
+				c.id as content_id, c.classroom_id, cl.school_id, c.content_type,
+				COUNT(e.id) FILTER (WHERE e.event_type = 'content_viewed') as view_count,
+				COUNT(DISTINCT e.user_id) FILTER (WHERE e.event_type = 'content_viewed') as unique_viewers,
+				COALESCE(AVG((e.metadata->>'duration')::decimal) FILTER (
+					WHERE e.event_type = 'content_viewed' AND e.metadata->>'duration' ~ '^[0-9]+(\.[0-9]+){0,1}$'
+				), 0) as avg_view_duration,
+				COUNT(e.id) FILTER (WHERE e.event_type = 'content_shared') as share_count,
+				MAX(e.timestamp) FILTER (WHERE e.event_type = 'content_viewed') as last_viewed_at
+			FROM content c
+			JOIN touched t ON t.content_id = c.id::text
+			JOIN events e ON e.event_type IN ('content_viewed', 'content_shared') AND e.metadata->>'content_id' = t.content_id
+			LEFT JOIN classrooms cl ON cl.id = c.classroom_id
+			WHERE c.deleted_at IS NULL
+			GROUP BY c.id, c.classroom_id, cl.school_id, c.content_type
+		)
+		INSERT INTO content_metrics (
+			content_id, classroom_id, school_id, content_type,
+			view_count, unique_viewers, avg_view_duration_seconds, share_count,
+			effectiveness_score, last_viewed_at, created_at, updated_at
+		)
+		SELECT
+			t.content_id, t.classroom_id, t.school_id, t.content_type,
+			t.view_count, t.unique_viewers, t.avg_view_duration, t.share_count,
+			ROUND((100 * (
+				? * LEAST(t.avg_view_duration / ?, 1) +
+				? * COALESCE(LEAST(t.unique_viewers::decimal / NULLIF(enrolled.total, 0), 1), 0) +
+				? * COALESCE(LEAST(t.share_count::decimal / NULLIF(t.unique_viewers, 0), 1), 0)
+			))::decimal, 2),
+			t.last_viewed_at, NOW(), NOW()
+		FROM totals t
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) as total FROM user_classrooms uc
+			WHERE uc.classroom_id = t.classroom_id AND uc.role = 'student' AND uc.is_active = true
+		) enrolled
+		ON CONFLICT (content_id) DO UPDATE SET
+			view_count = EXCLUDED.view_count,
+			unique_viewers = EXCLUDED.unique_viewers,
+			avg_view_duration_seconds = EXCLUDED.avg_view_duration_seconds,
+			share_count = EXCLUDED.share_count,
+			effectiveness_score = EXCLUDED.effectiveness_score,
+			last_viewed_at = EXCLUDED.last_viewed_at,
+			updated_at = NOW()
+	`, from, to,
+		effectivenessDurationWeight, fullViewDurationSeconds,
+		effectivenessReachWeight,
+		effectivenessShareWeight)
+	return result.RowsAffected, result.Error
+}
+
+// refreshQuizAnalytics recomputes the all-time totals of every quiz with a
+// quiz session started since the given time. Scores and time spent average
+// completed sessions only; difficulty is 100 minus the average score.
+func (h *ReportingHandler) refreshQuizAnalytics(ctx context.Context, since time.Time) error {
+	return h.db.WithContext(ctx).Exec(`
+		INSERT INTO quiz_analytics (
+			quiz_id, classroom_id, total_attempts, unique_participants,
+			completion_rate, avg_score, avg_time_spent_minutes, difficulty_score,
+			last_attempt_at, created_at, updated_at
+		)
+		SELECT
+			q.id, q.classroom_id,
+			COUNT(*),
+			COUNT(DISTINCT qs.student_id),
+			COUNT(*) FILTER (WHERE qs.is_completed) * 100.0 / COUNT(*),
+			COALESCE(AVG(qs.percentage_score) FILTER (WHERE qs.is_completed), 0),
+			COALESCE(AVG(qs.time_spent_seconds) FILTER (WHERE qs.is_completed) / 60.0, 0),
+			100 - COALESCE(AVG(qs.percentage_score) FILTER (WHERE qs.is_completed), 0),
+			MAX(qs.started_at),
+			NOW(), NOW()
+		FROM quizzes q
+		JOIN quiz_sessions qs ON qs.quiz_id = q.id
+		WHERE q.id IN (SELECT quiz_id FROM quiz_sessions WHERE started_at >= ?)
+		GROUP BY q.id, q.classroom_id
+		ON CONFLICT (quiz_id) DO UPDATE SET
+			classroom_id = EXCLUDED.classroom_id,
+			total_attempts = EXCLUDED.total_attempts,
+			unique_participants = EXCLUDED.unique_participants,
+			completion_rate = EXCLUDED.completion_rate,
+			avg_score = EXCLUDED.avg_score,
+			avg_time_spent_minutes = EXCLUDED.avg_time_spent_minutes,
+			difficulty_score = EXCLUDED.difficulty_score,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			updated_at = NOW()
+	`, since).Error
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"reporting-framework/internal/metrics"
+)
+
+// Retry limits for aggregating ingested batches: attempts per batch, batches
+// waiting for a retry, and the delay before the first retry, doubled for
+// each one after
+const (
+	maxAggregationAttempts    = 4
+	aggregationRetryQueueSize = 256
+	aggregationRetryBaseDelay = 2 * time.Second
+)
+
+// aggregationStep is one statement of an aggregation; a step that fails
+// must leave nothing applied, so retrying it is safe
+type aggregationStep struct {
+	name string
+	run  func() error
+}
+
+// aggregationTask is the remaining steps of one ingested batch's aggregation
+type aggregationTask struct {
+	requestID string
+	steps     []aggregationStep
+	attempts  int // failed attempts so far
+	retryAt   time.Time
+}
+
+// aggregationRetryQueue runs aggregation tasks and retries failed ones from
+// the failed step, with exponential backoff, on a single worker
+type aggregationRetryQueue struct {
+	tasks       chan *aggregationTask
+	maxAttempts int
+	baseDelay   time.Duration
+	startWorker sync.Once
+}
+
+func newAggregationRetryQueue(queueSize, maxAttempts int, baseDelay time.Duration) *aggregationRetryQueue {
+	return &aggregationRetryQueue{
+		tasks:       make(chan *aggregationTask, queueSize),
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+	}
+}
+
+// run executes the task's steps in order. A failed step is logged with the
+// request id and counted, then the task is queued to resume from that step
+// until it has failed maxAttempts times.
+func (q *aggregationRetryQueue) run(task *aggregationTask) {
+	for len(task.steps) > 0 {
+		step := task.steps[0]
+		if err := step.run(); err != nil {
+			task.attempts++
+			metrics.AggregationErrors.Inc()
+			if task.attempts >= q.maxAttempts {
+				metrics.AggregationBatchesDropped.Inc()
+				log.Printf("request_id=%s aggregation step %q failed on attempt %d, giving up: %v", task.requestID, step.name, task.attempts, err)
+				return
+			}
+			log.Printf("request_id=%s aggregation step %q failed on attempt %d, retrying: %v", task.requestID, step.name, task.attempts, err)
+			q.retry(task)
+			return
+		}
+		task.steps = task.steps[1:]
+	}
+}
+
+// retry queues a failed task for its next attempt, dropping it when the
+// queue is full rather than blocking ingestion
+func (q *aggregationRetryQueue) retry(task *aggregationTask) {
+	q.startWorker.Do(func() { go q.work() })
+
+	task.retryAt = time.Now().Add(q.baseDelay << (task.attempts - 1))
+	select {
+	case q.tasks <- task:
+	default:
+		metrics.AggregationBatchesDropped.Inc()
+		log.Printf("request_id=%s aggregation retry queue is full, dropping batch", task.requestID)
+	}
+}
+
+// work runs queued retries once their backoff has passed
+func (q *aggregationRetryQueue) work() {
+	for task := range q.tasks {
+		if wait := time.Until(task.retryAt); wait > 0 {
+			time.Sleep(wait)
+		}
+		q.run(task)
+	}
+}
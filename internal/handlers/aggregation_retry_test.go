@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"reporting-framework/internal/metrics"
+)
+
+// stepRecorder records which aggregation steps ran, in order
+type stepRecorder struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+// step returns a step that fails its first failures runs
+func (r *stepRecorder) step(name string, failures int) aggregationStep {
+	return aggregationStep{name: name, run: func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.runs = append(r.runs, name)
+		if failures > 0 {
+			failures--
+			return errors.New("deadlock detected")
+		}
+		return nil
+	}}
+}
+
+func (r *stepRecorder) ran() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.runs...)
+}
+
+// waitForRuns polls until n steps have run or a second has passed
+func (r *stepRecorder) waitForRuns(t *testing.T, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(r.ran()) < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return r.ran()
+}
+
+func TestAggregationRetryResumesFromFailedStep(t *testing.T) {
+	errorsBefore := testutil.ToFloat64(metrics.AggregationErrors)
+	q := newAggregationRetryQueue(4, 4, time.Millisecond)
+	var r stepRecorder
+
+	q.run(&aggregationTask{requestID: "req-1", steps: []aggregationStep{
+		r.step("daily_user_metrics", 0),
+		r.step("daily_classroom_metrics", 2),
+		r.step("content_metrics", 0),
+	}})
+
+	want := []string{"daily_user_metrics", "daily_classroom_metrics", "daily_classroom_metrics", "daily_classroom_metrics", "content_metrics"}
+	if got := r.waitForRuns(t, len(want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %v, want %v", got, want)
+	}
+	if got := testutil.ToFloat64(metrics.AggregationErrors) - errorsBefore; got != 2 {
+		t.Errorf("counted %v aggregation errors, want 2", got)
+	}
+}
+
+func TestAggregationRetryGivesUp(t *testing.T) {
+	droppedBefore := testutil.ToFloat64(metrics.AggregationBatchesDropped)
+	q := newAggregationRetryQueue(4, 3, time.Millisecond)
+	var r stepRecorder
+
+	q.run(&aggregationTask{requestID: "req-2", steps: []aggregationStep{r.step("daily_user_metrics", 10)}})
+
+	if got := r.waitForRuns(t, 3); len(got) != 3 {
+		t.Fatalf("ran %d attempts, want 3", len(got))
+	}
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(metrics.AggregationBatchesDropped) == droppedBefore && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := len(r.ran()); got != 3 {
+		t.Errorf("ran %d attempts, want no more after the last", got)
+	}
+	if got := testutil.ToFloat64(metrics.AggregationBatchesDropped) - droppedBefore; got != 1 {
+		t.Errorf("dropped %v batches, want 1", got)
+	}
+}
+
+func TestAggregationRetryQueueFull(t *testing.T) {
+	droppedBefore := testutil.ToFloat64(metrics.AggregationBatchesDropped)
+	q := newAggregationRetryQueue(0, 4, time.Hour)
+	var r stepRecorder
+
+	q.run(&aggregationTask{requestID: "req-3", steps: []aggregationStep{r.step("daily_user_metrics", 1)}})
+
+	if got := testutil.ToFloat64(metrics.AggregationBatchesDropped) - droppedBefore; got != 1 {
+		t.Errorf("dropped %v batches, want the retry dropped", got)
+	}
+}
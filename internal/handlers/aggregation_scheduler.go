@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AggregationScheduleOff disables a job's schedule
+const AggregationScheduleOff = "off"
+
+// AggregationJobStatus reports a scheduled job's configuration and last run
+type AggregationJobStatus struct {
+	Name            string     `json:"name"`
+	Schedule        string     `json:"schedule"`
+	Enabled         bool       `json:"enabled"`
+	Running         bool       `json:"running"`
+	LastStartedAt   *time.Time `json:"last_started_at"`
+	LastFinishedAt  *time.Time `json:"last_finished_at"`
+	LastDurationMs  *int64     `json:"last_duration_ms"`
+	LastError       *string    `json:"last_error"`
+	NextRunAt       *time.Time `json:"next_run_at"`
+	Runs            int        `json:"runs"`
+	SkippedOverlaps int        `json:"skipped_overlaps"`
+}
+
+// scheduledJob is one job with its cron schedule and run state. mu guards
+// status, including Running, which is how overlapping ticks are detected.
+type scheduledJob struct {
+	run      func(ctx context.Context) error
+	schedule *cronSchedule
+
+	mu     sync.Mutex
+	status AggregationJobStatus
+}
+
+// AggregationScheduler runs aggregation jobs on per-job cron schedules. A
+// tick that arrives while the job's previous run is still going is skipped.
+type AggregationScheduler struct {
+	jobs []*scheduledJob
+	now  func() time.Time
+}
+
+// NewAggregationScheduler creates a scheduler with no jobs
+func NewAggregationScheduler() *AggregationScheduler {
+	return &AggregationScheduler{now: time.Now}
+}
+
+// AddJob registers a job under a cron expression (see parseCron), or
+// AggregationScheduleOff to list the job without running it. Jobs must be
+// added before Start.
+func (s *AggregationScheduler) AddJob(name, spec string, run func(ctx context.Context) error) error {
+	job := &scheduledJob{
+		run:    run,
+		status: AggregationJobStatus{Name: name, Schedule: spec},
+	}
+	if spec != AggregationScheduleOff {
+		schedule, err := parseCron(spec)
+		if err != nil {
+			return fmt.Errorf("%s job: %w", name, err)
+		}
+		job.schedule = schedule
+		job.status.Enabled = true
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start runs every enabled job on its schedule until ctx is cancelled
+func (s *AggregationScheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		if job.schedule != nil {
+			go s.loop(ctx, job)
+		}
+	}
+}
+
+// loop waits for each scheduled time and starts the job in the background,
+// so a slow run doesn't delay the schedule, only causes skipped ticks
+func (s *AggregationScheduler) loop(ctx context.Context, job *scheduledJob) {
+	for {
+		next := job.schedule.next(s.now())
+		job.mu.Lock()
+		if next.IsZero() {
+			job.status.NextRunAt = nil
+		} else {
+			job.status.NextRunAt = &next
+		}
+		job.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.begin(job) {
+			continue
+		}
+		go s.execute(ctx, job)
+	}
+}
+
+// begin marks the job running, or records a skipped overlap and returns false
+func (s *AggregationScheduler) begin(job *scheduledJob) bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.status.Running {
+		job.status.SkippedOverlaps++
+		log.Printf("Skipping %s aggregation: previous run still in progress", job.status.Name)
+		return false
+	}
+	started := s.now()
+	job.status.Running = true
+	job.status.LastStartedAt = &started
+	return true
+}
+
+// execute runs a job that begin has marked running and records the outcome
+func (s *AggregationScheduler) execute(ctx context.Context, job *scheduledJob) {
+	err := job.run(ctx)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	finished := s.now()
+	duration := finished.Sub(*job.status.LastStartedAt).Milliseconds()
+	job.status.Running = false
+	job.status.LastFinishedAt = &finished
+	job.status.LastDurationMs = &duration
+	job.status.Runs++
+	job.status.LastError = nil
+	if err != nil {
+		message := err.Error()
+		job.status.LastError = &message
+		log.Printf("%s aggregation failed: %v", job.status.Name, err)
+	}
+}
+
+// Status returns a snapshot of every job's status in registration order
+func (s *AggregationScheduler) Status() []AggregationJobStatus {
+	statuses := make([]AggregationJobStatus, len(s.jobs))
+	for i, job := range s.jobs {
+		job.mu.Lock()
+		statuses[i] = job.status
+		job.mu.Unlock()
+	}
+	return statuses
+}
+
+// SetAggregationScheduler exposes the scheduler's status on the admin endpoint
+func (h *ReportingHandler) SetAggregationScheduler(s *AggregationScheduler) {
+	h.aggregationScheduler = s
+}
+
+// GetAggregationSchedule returns each aggregation job's schedule, last run
+// and next run
+func (h *ReportingHandler) GetAggregationSchedule(c *gin.Context) {
+	if h.aggregationScheduler == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "jobs": []AggregationJobStatus{}})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "jobs": h.aggregationScheduler.Status()})
+}
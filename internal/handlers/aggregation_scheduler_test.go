@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAggregationSchedulerRunsJobs(t *testing.T) {
+	scheduler := NewAggregationScheduler()
+	// Start the clock just before a minute boundary so "* * * * *" fires at once
+	base := time.Date(2026, 3, 4, 10, 16, 59, 950_000_000, time.UTC)
+	started := time.Now()
+	scheduler.now = func() time.Time { return base.Add(time.Since(started)) }
+
+	ran := make(chan struct{}, 1)
+	if err := scheduler.AddJob("daily_metrics", "* * * * *", func(context.Context) error {
+		ran <- struct{}{}
+		return errors.New("lock timeout")
+	}); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	if err := scheduler.AddJob("content_metrics", AggregationScheduleOff, func(context.Context) error {
+		t.Error("disabled job ran")
+		return nil
+	}); err != nil {
+		t.Fatalf("AddJob off: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduler.Start(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run")
+	}
+
+	var status []AggregationJobStatus
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status = scheduler.Status()
+		if status[0].Runs == 1 && status[0].NextRunAt != nil && status[0].NextRunAt.After(base.Add(time.Minute)) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %+v, want one finished run", status[0])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	job := status[0]
+	if !job.Enabled || job.Running || job.LastStartedAt == nil || job.LastFinishedAt == nil || job.LastDurationMs == nil {
+		t.Errorf("status = %+v, want a finished run", job)
+	}
+	if job.LastError == nil || *job.LastError != "lock timeout" {
+		t.Errorf("last_error = %v, want the job's error", job.LastError)
+	}
+	if want := base.Add(61 * time.Second).Truncate(time.Minute); !job.NextRunAt.Equal(want) {
+		t.Errorf("next_run_at = %v, want %v", job.NextRunAt, want)
+	}
+	if off := status[1]; off.Enabled || off.Runs != 0 || off.NextRunAt != nil || off.Schedule != AggregationScheduleOff {
+		t.Errorf("disabled job status = %+v", off)
+	}
+}
+
+func TestAggregationSchedulerSkipsOverlaps(t *testing.T) {
+	scheduler := NewAggregationScheduler()
+	if err := scheduler.AddJob("daily_metrics", "@hourly", func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	job := scheduler.jobs[0]
+
+	if !scheduler.begin(job) {
+		t.Fatal("first tick did not start the job")
+	}
+	if scheduler.begin(job) {
+		t.Fatal("overlapping tick started the job again")
+	}
+	if status := scheduler.Status()[0]; !status.Running || status.SkippedOverlaps != 1 {
+		t.Errorf("status = %+v, want running with one skipped overlap", status)
+	}
+
+	scheduler.execute(context.Background(), job)
+	if status := scheduler.Status()[0]; status.Running || status.Runs != 1 || status.LastError != nil {
+		t.Errorf("status = %+v, want one clean run", status)
+	}
+	if !scheduler.begin(job) {
+		t.Error("tick after the run finished was skipped")
+	}
+}
+
+func TestAggregationSchedulerRejectsBadSchedules(t *testing.T) {
+	if err := NewAggregationScheduler().AddJob("daily_metrics", "every hour", nil); err == nil {
+		t.Error("AddJob accepted an invalid cron expression")
+	}
+}
+
+func TestGetAggregationSchedule(t *testing.T) {
+	h := NewReportingHandler(nil)
+	w := testRequest(h.GetAggregationSchedule, "/admin/aggregation-schedule", http.MethodGet, "/admin/aggregation-schedule", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if body := decodeBody(t, w); body["enabled"] != false || len(body["jobs"].([]interface{})) != 0 {
+		t.Errorf("body = %v, want the scheduler reported off", body)
+	}
+
+	scheduler := NewAggregationScheduler()
+	if err := scheduler.AddJob("daily_metrics", "@daily", func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	h.SetAggregationScheduler(scheduler)
+	w = testRequest(h.GetAggregationSchedule, "/admin/aggregation-schedule", http.MethodGet, "/admin/aggregation-schedule", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	jobs := decodeBody(t, w)["jobs"].([]interface{})
+	if len(jobs) != 1 || jobs[0].(map[string]interface{})["name"] != "daily_metrics" || jobs[0].(map[string]interface{})["schedule"] != "@daily" {
+		t.Errorf("jobs = %v, want the daily_metrics job", jobs)
+	}
+}
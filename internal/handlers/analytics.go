@@ -68,6 +68,80 @@ var dimensionMap = map[string]string{
 	"quiz.difficulty":      "q.status",
 }
 
+// analyticsFilterOperators are the operators buildFilterCondition accepts
+var analyticsFilterOperators = map[string]filterOperator{
+	"eq":       {kind: filterEquals},
+	"=":        {kind: filterEquals},
+	"ne":       {kind: filterOther},
+	"!=":       {kind: filterOther},
+	"gt":       {kind: filterLower},
+	">":        {kind: filterLower},
+	"gte":      {kind: filterLower, inclusive: true},
+	">=":       {kind: filterLower, inclusive: true},
+	"lt":       {kind: filterUpper},
+	"<":        {kind: filterUpper},
+	"lte":      {kind: filterUpper, inclusive: true},
+	"<=":       {kind: filterUpper, inclusive: true},
+	"in":       {kind: filterIn, multi: true},
+	"contains": {kind: filterOther},
+}
+
+// analyticsSchema describes measureMap and dimensionMap for ValidateQuery.
+// Only time.date is a timestamp; the other time.* dimensions are numbers.
+func analyticsSchema() CubeSchema {
+	schema := CubeSchema{
+		Measures:        make(map[string]MeasureDefinition, len(measureMap)),
+		Dimensions:      make(map[string]DimensionDefinition, len(dimensionMap)),
+		filterOperators: analyticsFilterOperators,
+		granularities:   map[string]bool{"hour": true, "day": true, "week": true, "month": true, "quarter": true, "year": true},
+	}
+	for name, sql := range measureMap {
+		schema.Measures[name] = MeasureDefinition{SQL: sql}
+	}
+	for name, sql := range dimensionMap {
+		dimensionType := "string"
+		switch name {
+		case "time.date":
+			dimensionType = "time"
+		case "time.hour", "time.day_of_week":
+			dimensionType = "number"
+		}
+		schema.Dimensions[name] = DimensionDefinition{Type: dimensionType, SQL: sql}
+	}
+	return schema
+}
+
+// validate runs ValidateQuery over the request in cube form, adding the
+// offset, which cube queries don't have
+func (req QueryRequest) validate() []QueryValidationError {
+	query := cubeQuery{Measures: req.Measures, Dimensions: req.Dimensions}
+	for _, filter := range req.Filters {
+		var values []string
+		switch value := filter.Value.(type) {
+		case nil:
+		case []interface{}:
+			for _, v := range value {
+				values = append(values, fmt.Sprintf("%v", v))
+			}
+		default:
+			values = []string{fmt.Sprintf("%v", value)}
+		}
+		query.Filters = append(query.Filters, cubeFilter{Member: filter.Dimension, Operator: filter.Operator, Values: values})
+	}
+	if req.TimeDimension != nil {
+		query.TimeDimensions = []cubeTimeDimension{{Dimension: req.TimeDimension.Dimension, Granularity: req.TimeDimension.Granularity}}
+	}
+	if req.Limit != nil {
+		query.Limit = *req.Limit
+	}
+
+	problems := ValidateQuery(query, analyticsSchema())
+	if req.Offset != nil && *req.Offset < 0 {
+		problems = append(problems, QueryValidationError{Member: "offset", Reason: "offset must not be negative"})
+	}
+	return problems
+}
+
 func NewAnalyticsHandler(db *gorm.DB) *AnalyticsHandler {
 	return &AnalyticsHandler{db: db}
 }
@@ -85,10 +159,21 @@ func (h *AnalyticsHandler) ExecuteQuery(c *gin.Context) {
 		return
 	}
 
+	if problems := req.validate(); len(problems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid query",
+				"details": problems,
+			},
+		})
+		return
+	}
+
 	startTime := time.Now()
 
 	// Build the SQL query
-	query, err := h.buildQuery(req)
+	query, args, err := h.buildQuery(req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": map[string]interface{}{
@@ -102,7 +187,7 @@ func (h *AnalyticsHandler) ExecuteQuery(c *gin.Context) {
 
 	// Execute the query
 	var results []map[string]interface{}
-	if err := h.db.Raw(query).Scan(&results).Error; err != nil {
+	if err := h.db.Raw(query, args...).Scan(&results).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
 				"code":    "QUERY_EXECUTION_ERROR",
@@ -126,7 +211,9 @@ func (h *AnalyticsHandler) ExecuteQuery(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
+// buildQuery builds the SQL for a query request. Measure and dimension SQL
+// comes from the fixed maps; filter values are returned as bind arguments.
+func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, []interface{}, error) {
 	// Build SELECT clause
 	var selects []string
 
@@ -135,7 +222,7 @@ func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
 		if sqlExpr, exists := measureMap[measure]; exists {
 			selects = append(selects, fmt.Sprintf("%s as \"%s\"", sqlExpr, measure))
 		} else {
-			return "", fmt.Errorf("unknown measure: %s", measure)
+			return "", nil, fmt.Errorf("unknown measure: %s", measure)
 		}
 	}
 
@@ -144,7 +231,7 @@ func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
 		if sqlExpr, exists := dimensionMap[dimension]; exists {
 			selects = append(selects, fmt.Sprintf("%s as \"%s\"", sqlExpr, dimension))
 		} else {
-			return "", fmt.Errorf("unknown dimension: %s", dimension)
+			return "", nil, fmt.Errorf("unknown dimension: %s", dimension)
 		}
 	}
 
@@ -152,7 +239,7 @@ func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
 	if req.TimeDimension != nil {
 		timeExpr, err := h.buildTimeDimension(*req.TimeDimension)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		selects = append(selects, fmt.Sprintf("%s as \"time\"", timeExpr))
 	}
@@ -163,9 +250,9 @@ func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
 	fromClause := h.buildFromClause(req)
 
 	// Build WHERE clause
-	whereClause, err := h.buildWhereClause(req.Filters)
+	whereClause, args, err := h.buildWhereClause(req.Filters)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// Build GROUP BY clause
@@ -205,7 +292,7 @@ func (h *AnalyticsHandler) buildQuery(req QueryRequest) (string, error) {
 		query += " " + limitClause
 	}
 
-	return query, nil
+	return query, args, nil
 }
 
 func (h *AnalyticsHandler) buildFromClause(req QueryRequest) string {
@@ -313,55 +400,61 @@ func (h *AnalyticsHandler) buildFromClause(req QueryRequest) string {
 	return fromClause
 }
 
-func (h *AnalyticsHandler) buildWhereClause(filters []Filter) (string, error) {
+func (h *AnalyticsHandler) buildWhereClause(filters []Filter) (string, []interface{}, error) {
 	if len(filters) == 0 {
-		return "", nil
+		return "", nil, nil
 	}
 
 	var conditions []string
+	var args []interface{}
 	for _, filter := range filters {
-		condition, err := h.buildFilterCondition(filter)
+		condition, arg, err := h.buildFilterCondition(filter)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		conditions = append(conditions, condition)
+		args = append(args, arg)
 	}
 
-	return strings.Join(conditions, " AND "), nil
+	return strings.Join(conditions, " AND "), args, nil
 }
 
-func (h *AnalyticsHandler) buildFilterCondition(filter Filter) (string, error) {
+// buildFilterCondition returns the condition for a filter with a single ?
+// placeholder and the value to bind to it
+func (h *AnalyticsHandler) buildFilterCondition(filter Filter) (string, interface{}, error) {
 	dimension, exists := dimensionMap[filter.Dimension]
 	if !exists {
-		return "", fmt.Errorf("unknown filter dimension: %s", filter.Dimension)
+		return "", nil, fmt.Errorf("unknown filter dimension: %s", filter.Dimension)
 	}
 
+	// Values are bound as text, as they were compared when inlined as literals
+	value := fmt.Sprintf("%v", filter.Value)
 	switch filter.Operator {
 	case "eq", "=":
-		return fmt.Sprintf("%s = '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s = ?", dimension), value, nil
 	case "ne", "!=":
-		return fmt.Sprintf("%s != '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s != ?", dimension), value, nil
 	case "gt", ">":
-		return fmt.Sprintf("%s > '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s > ?", dimension), value, nil
 	case "gte", ">=":
-		return fmt.Sprintf("%s >= '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s >= ?", dimension), value, nil
 	case "lt", "<":
-		return fmt.Sprintf("%s < '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s < ?", dimension), value, nil
 	case "lte", "<=":
-		return fmt.Sprintf("%s <= '%v'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s <= ?", dimension), value, nil
 	case "in":
-		if values, ok := filter.Value.([]interface{}); ok {
-			var strValues []string
-			for _, v := range values {
-				strValues = append(strValues, fmt.Sprintf("'%v'", v))
+		if values, ok := filter.Value.([]interface{}); ok && len(values) > 0 {
+			strValues := make([]string, len(values))
+			for i, v := range values {
+				strValues[i] = fmt.Sprintf("%v", v)
 			}
-			return fmt.Sprintf("%s IN (%s)", dimension, strings.Join(strValues, ", ")), nil
+			return fmt.Sprintf("%s IN ?", dimension), strValues, nil
 		}
-		return "", fmt.Errorf("'in' operator requires array value")
+		return "", nil, fmt.Errorf("'in' operator requires a non-empty array value")
 	case "contains":
-		return fmt.Sprintf("%s ILIKE '%%%v%%'", dimension, filter.Value), nil
+		return fmt.Sprintf("%s ILIKE ?", dimension), "%" + likeEscaper.Replace(value) + "%", nil
 	default:
-		return "", fmt.Errorf("unsupported operator: %s", filter.Operator)
+		return "", nil, fmt.Errorf("unsupported operator: %s", filter.Operator)
 	}
 }
 
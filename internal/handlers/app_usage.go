@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// App usage classifications, from the whiteboard/notebook split of a
+// student's session minutes
+const (
+	AppUsageInactive         = "inactive"
+	AppUsageWhiteboardOnly   = "whiteboard_only"
+	AppUsageMostlyWhiteboard = "mostly_whiteboard"
+	AppUsageBalanced         = "balanced"
+	AppUsageMostlyNotebook   = "mostly_notebook"
+	AppUsageNotebookOnly     = "notebook_only"
+)
+
+// AppUsageThresholds are the shares of whiteboard plus notebook usage one app
+// needs before a student counts as using mostly or only that app
+type AppUsageThresholds struct {
+	OnlyShare   float64 `json:"only_share"`
+	MostlyShare float64 `json:"mostly_share"`
+}
+
+// DefaultAppUsageThresholds treat 95% as only one app and 65% as mostly one
+var DefaultAppUsageThresholds = AppUsageThresholds{OnlyShare: 0.95, MostlyShare: 0.65}
+
+// SetAppUsageThresholds configures the app usage classification. Shares must
+// satisfy 0.5 < mostly <= only <= 1.
+func (h *ReportingHandler) SetAppUsageThresholds(thresholds AppUsageThresholds) error {
+	if thresholds.MostlyShare <= 0.5 || thresholds.MostlyShare > thresholds.OnlyShare || thresholds.OnlyShare > 1 {
+		return fmt.Errorf("app usage shares must satisfy 0.5 < mostly <= only <= 1, got mostly=%g only=%g", thresholds.MostlyShare, thresholds.OnlyShare)
+	}
+	h.appUsageThresholds = thresholds
+	return nil
+}
+
+// AppUsage is a student's sessions and minutes in one application
+type AppUsage struct {
+	Application string  `json:"application"`
+	Sessions    int     `json:"sessions"`
+	Minutes     float64 `json:"minutes"`
+	Share       float64 `json:"share"` // of all minutes in the period, 0-1
+}
+
+// GetStudentAppUsage returns a student's session count and minutes per
+// application and classifies their whiteboard/notebook split. The split uses
+// minutes, or session counts when no session in the period has a duration.
+// Students may only read their own usage.
+func (h *ReportingHandler) GetStudentAppUsage(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's app usage") {
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage := []AppUsage{}
+	err = h.db.Table("sessions").
+		Select("application, COUNT(*) as sessions, COALESCE(SUM(duration_seconds), 0) / 60.0 as minutes").
+		Where("user_id = ? AND start_time BETWEEN ? AND ?", studentID, dateFrom, dateTo).
+		Group("application").
+		Order("minutes DESC, application ASC").
+		Scan(&usage).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions", "details": err.Error()})
+		return
+	}
+
+	var totalMinutes float64
+	var totalSessions int
+	var whiteboard, notebook, whiteboardSessions, notebookSessions float64
+	for _, u := range usage {
+		totalMinutes += u.Minutes
+		totalSessions += u.Sessions
+		switch u.Application {
+		case "whiteboard":
+			whiteboard, whiteboardSessions = u.Minutes, float64(u.Sessions)
+		case "notebook":
+			notebook, notebookSessions = u.Minutes, float64(u.Sessions)
+		}
+	}
+	for i := range usage {
+		usage[i].Minutes = roundTo(usage[i].Minutes, 2)
+		if totalMinutes > 0 {
+			usage[i].Share = roundTo(usage[i].Minutes/totalMinutes, 4)
+		}
+	}
+
+	basis := "minutes"
+	if whiteboard+notebook == 0 {
+		basis = "sessions"
+		whiteboard, notebook = whiteboardSessions, notebookSessions
+	}
+	classification, whiteboardShare := classifyAppUsage(whiteboard, notebook, h.appUsageThresholds)
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":       studentID,
+		"period":           gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"applications":     usage,
+		"total_sessions":   totalSessions,
+		"total_minutes":    roundTo(totalMinutes, 2),
+		"classification":   classification,
+		"whiteboard_share": whiteboardShare,
+		"split_basis":      basis,
+		"thresholds":       h.appUsageThresholds,
+	})
+}
+
+// classifyAppUsage classifies a whiteboard/notebook split and returns the
+// whiteboard share of the two, which is nil without any usage of either
+func classifyAppUsage(whiteboard, notebook float64, thresholds AppUsageThresholds) (string, *float64) {
+	total := whiteboard + notebook
+	if total <= 0 {
+		return AppUsageInactive, nil
+	}
+	share := whiteboard / total
+	rounded := roundTo(share, 4)
+
+	switch {
+	case share >= thresholds.OnlyShare:
+		return AppUsageWhiteboardOnly, &rounded
+	case 1-share >= thresholds.OnlyShare:
+		return AppUsageNotebookOnly, &rounded
+	case share >= thresholds.MostlyShare:
+		return AppUsageMostlyWhiteboard, &rounded
+	case 1-share >= thresholds.MostlyShare:
+		return AppUsageMostlyNotebook, &rounded
+	default:
+		return AppUsageBalanced, &rounded
+	}
+}
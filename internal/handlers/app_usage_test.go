@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSetAppUsageThresholds(t *testing.T) {
+	h := NewReportingHandler(nil)
+	for _, thresholds := range []AppUsageThresholds{
+		{OnlyShare: 0.9, MostlyShare: 0.5},
+		{OnlyShare: 0.8, MostlyShare: 0.9},
+		{OnlyShare: 1.1, MostlyShare: 0.7},
+	} {
+		if err := h.SetAppUsageThresholds(thresholds); err == nil {
+			t.Errorf("SetAppUsageThresholds(%+v) succeeded", thresholds)
+		}
+	}
+	if h.appUsageThresholds != DefaultAppUsageThresholds {
+		t.Errorf("rejected thresholds replaced the defaults: %+v", h.appUsageThresholds)
+	}
+}
+
+func TestClassifyAppUsage(t *testing.T) {
+	tests := []struct {
+		whiteboard, notebook float64
+		want                 string
+		share                float64
+	}{
+		{96, 4, AppUsageWhiteboardOnly, 0.96},
+		{70, 30, AppUsageMostlyWhiteboard, 0.7},
+		{50, 50, AppUsageBalanced, 0.5},
+		{35, 65, AppUsageMostlyNotebook, 0.35},
+		{0, 10, AppUsageNotebookOnly, 0},
+	}
+	for _, tt := range tests {
+		got, share := classifyAppUsage(tt.whiteboard, tt.notebook, DefaultAppUsageThresholds)
+		if got != tt.want || share == nil || *share != tt.share {
+			t.Errorf("classifyAppUsage(%g, %g) = %s, %v, want %s, %g", tt.whiteboard, tt.notebook, got, share, tt.want, tt.share)
+		}
+	}
+	if got, share := classifyAppUsage(0, 0, DefaultAppUsageThresholds); got != AppUsageInactive || share != nil {
+		t.Errorf("classifyAppUsage(0, 0) = %s, %v, want inactive", got, share)
+	}
+}
+
+func TestStudentAppUsageFallsBackToSessions(t *testing.T) {
+	fake, db := newFakeDB(t)
+	// Only the quiz app recorded durations, so the split counts sessions
+	fake.rows([]string{`FROM "sessions"`, "GROUP BY"}, []string{"application", "sessions", "minutes"},
+		[]driver.Value{"quiz", int64(2), 30.0},
+		[]driver.Value{"whiteboard", int64(3), 0.0},
+		[]driver.Value{"notebook", int64(1), 0.0})
+	h := NewReportingHandler(db)
+	studentID := uuid.New()
+
+	w := testRequest(h.GetStudentAppUsage, "/students/:id/app-usage", http.MethodGet, "/students/"+studentID.String()+"/app-usage", "",
+		map[string]interface{}{"user_id": studentID, "user_role": "student"})
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	if body["split_basis"] != "sessions" || body["classification"] != AppUsageMostlyWhiteboard || body["whiteboard_share"] != 0.75 {
+		t.Errorf("split = %v, %v, %v, want mostly whiteboard at 0.75 of sessions", body["split_basis"], body["classification"], body["whiteboard_share"])
+	}
+	if body["total_sessions"] != 6.0 || body["total_minutes"] != 30.0 {
+		t.Errorf("totals = %v sessions, %v minutes", body["total_sessions"], body["total_minutes"])
+	}
+	if quiz := body["applications"].([]interface{})[0].(map[string]interface{}); quiz["share"] != 1.0 {
+		t.Errorf("quiz share = %v, want all the minutes", quiz["share"])
+	}
+}
+
+func TestStudentAppUsageForbidsOtherStudents(t *testing.T) {
+	fake, db := newFakeDB(t)
+	w := testRequest(NewReportingHandler(db).GetStudentAppUsage, "/students/:id/app-usage", http.MethodGet, "/students/"+uuid.NewString()+"/app-usage", "",
+		map[string]interface{}{"user_id": uuid.New(), "user_role": "student"})
+	expectStatus(t, w, http.StatusForbidden)
+	if len(fake.ran()) != 0 {
+		t.Error("a forbidden request reached the database")
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"reporting-framework/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetCannedQueries replaces the queries offered as canned reports
+func (h *ReportingHandler) SetCannedQueries(registry *services.CannedQueryRegistry) {
+	h.cannedQueries = registry
+}
+
+// ListCannedReports lists the canned reports with their parameters
+func (h *ReportingHandler) ListCannedReports(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reports": h.cannedQueries.Queries()})
+}
+
+// RunCannedReport runs a canned report, reading its integer parameters from
+// the query string. Only the parameter values come from the request; the SQL
+// is a registered template checked against the column allow-list.
+func (h *ReportingHandler) RunCannedReport(c *gin.Context) {
+	name := c.Param("name")
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	rows, err := h.cannedQueries.Run(h.db, name, params)
+	var paramErr *services.CannedQueryParamError
+	switch {
+	case errors.Is(err, services.ErrUnknownCannedQuery):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.As(err, &paramErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": paramErr.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run canned report", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report": name,
+		"params": params,
+		"data":   rows,
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"reporting-framework/internal/services"
+)
+
+func TestRunCannedReport(t *testing.T) {
+	route := "/canned/:name"
+
+	t.Run("binds parameters", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows([]string{"FROM events"}, []string{"application", "event_count", "unique_users"},
+			[]driver.Value{"whiteboard", int64(40), int64(12)})
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.RunCannedReport, route, http.MethodGet, "/canned/"+services.CannedQueryEventsByApplication+"?days=30", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		rows := decodeBody(t, w)["data"].([]interface{})
+		if len(rows) != 1 || rows[0].(map[string]interface{})["event_count"] != 40.0 {
+			t.Errorf("data = %v", rows)
+		}
+
+		ran := fake.ran("FROM events")
+		if len(ran) != 1 || !reflect.DeepEqual(ran[0].Args, []interface{}{30}) {
+			t.Errorf("statements = %v, want days bound as the only argument", ran)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.RunCannedReport, route, http.MethodGet, "/canned/"+services.CannedQueryClassroomQuizPerformance, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		if ran := fake.ran("FROM quiz_sessions"); len(ran) != 1 || !reflect.DeepEqual(ran[0].Args, []interface{}{3, 5}) {
+			t.Errorf("statements = %v, want the default min_sessions and limit", ran)
+		}
+	})
+
+	t.Run("unknown report", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		w := testRequest(NewReportingHandler(db).RunCannedReport, route, http.MethodGet, "/canned/users", "", nil)
+		expectStatus(t, w, http.StatusNotFound)
+	})
+
+	t.Run("bad parameter", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		w := testRequest(NewReportingHandler(db).RunCannedReport, route, http.MethodGet, "/canned/"+services.CannedQueryDailyEngagement+"?days=1%3BDROP", "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+		if len(fake.ran()) != 0 {
+			t.Error("a rejected parameter reached the database")
+		}
+	})
+}
+
+func TestListCannedReports(t *testing.T) {
+	w := testRequest(NewReportingHandler(nil).ListCannedReports, "/canned", http.MethodGet, "/canned", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if reports := decodeBody(t, w)["reports"].([]interface{}); len(reports) != len(services.DefaultCannedQueries()) {
+		t.Errorf("listed %d reports, want %d", len(reports), len(services.DefaultCannedQueries()))
+	}
+}
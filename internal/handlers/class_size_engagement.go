@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ClassSizePoint pairs a classroom's size with its average engagement
+type ClassSizePoint struct {
+	ClassroomID      uuid.UUID `json:"classroom_id"`
+	ClassroomName    string    `json:"classroom_name"`
+	EnrolledStudents int       `json:"enrolled_students"`
+	MaxStudents      int       `json:"max_students"`
+	FillRate         *float64  `json:"fill_rate"` // enrollment as a percentage of max_students
+	AvgEngagement    *float64  `json:"avg_engagement"`
+	MetricDays       int       `json:"metric_days"`
+}
+
+// GetClassSizeEngagement pairs each classroom's active enrollment with its
+// average daily engagement and reports the Pearson correlation across the
+// school's classrooms, against both enrollment and fill rate. Classrooms
+// without engagement data in the period are listed but left out of the
+// correlation, which is null when fewer than two classrooms remain or the
+// sizes don't vary.
+func (h *ReportingHandler) GetClassSizeEngagement(c *gin.Context) {
+	schoolID, err := uuid.Parse(c.Query("school_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Valid school_id is required"})
+		return
+	}
+
+	loc, err := h.requestLocation(c, h.schoolTimezone(schoolID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	points := []ClassSizePoint{}
+	err = h.db.Table("classrooms cl").
+		Select(`
+			cl.id as classroom_id,
+			cl.name as classroom_name,
+			COALESCE(cl.max_students, 0) as max_students,
+			(SELECT COUNT(*) FROM user_classrooms uc
+				WHERE uc.classroom_id = cl.id AND uc.role = 'student' AND uc.is_active = true) as enrolled_students,
+			m.avg_engagement,
+			COALESCE(m.metric_days, 0) as metric_days
+		`).
+		Joins(`LEFT JOIN (
+			SELECT classroom_id, AVG(engagement_score) as avg_engagement, COUNT(*) as metric_days
+			FROM daily_classroom_metrics
+			WHERE date BETWEEN ? AND ? AND engagement_score IS NOT NULL
+			GROUP BY classroom_id
+		) m ON m.classroom_id = cl.id`, dateFrom, dateTo).
+		Where("cl.school_id = ?", schoolID).
+		Order("enrolled_students ASC, cl.name ASC").
+		Scan(&points).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch classrooms", "details": err.Error()})
+		return
+	}
+
+	var sizes, fillRates, engagement []float64
+	for i := range points {
+		p := &points[i]
+		if p.MaxStudents > 0 {
+			fill := roundTo(float64(p.EnrolledStudents)*100/float64(p.MaxStudents), 2)
+			p.FillRate = &fill
+		}
+		if p.AvgEngagement == nil {
+			continue
+		}
+		rounded := roundTo(*p.AvgEngagement, 2)
+		p.AvgEngagement = &rounded
+		sizes = append(sizes, float64(p.EnrolledStudents))
+		engagement = append(engagement, *p.AvgEngagement)
+		if p.FillRate != nil {
+			fillRates = append(fillRates, *p.FillRate)
+		}
+	}
+
+	correlation := func(xs, ys []float64) *float64 {
+		if len(xs) != len(ys) {
+			return nil
+		}
+		r, ok := pearsonCorrelation(xs, ys)
+		if !ok {
+			return nil
+		}
+		r = roundTo(r, 4)
+		return &r
+	}
+	sizeCorrelation := correlation(sizes, engagement)
+	// Fill rate only correlates when every paired classroom has a capacity
+	fillCorrelation := correlation(fillRates, engagement)
+
+	response := gin.H{
+		"school_id":             schoolID,
+		"period":                gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"classrooms":            points,
+		"paired_classrooms":     len(engagement),
+		"correlation":           sizeCorrelation,
+		"fill_rate_correlation": fillCorrelation,
+	}
+	if sizeCorrelation == nil {
+		response["correlation_unavailable_reason"] = "Needs at least two classrooms with engagement data and differing enrollment"
+	}
+	c.JSON(http.StatusOK, response)
+}
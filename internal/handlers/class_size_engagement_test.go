@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var classSizeColumns = []string{"classroom_id", "classroom_name", "max_students", "enrolled_students", "avg_engagement", "metric_days"}
+
+func TestClassSizeEngagement(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM classrooms cl", "daily_classroom_metrics"}, classSizeColumns,
+		[]driver.Value{uuid.NewString(), "Quiet", int64(10), int64(5), nil, int64(0)},
+		[]driver.Value{uuid.NewString(), "Small", int64(20), int64(10), 50.0, int64(20)},
+		[]driver.Value{uuid.NewString(), "Medium", int64(25), int64(20), 70.004, int64(20)},
+		[]driver.Value{uuid.NewString(), "Uncapped", int64(0), int64(30), 90.0, int64(20)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetClassSizeEngagement, "/analytics/class-size-engagement", http.MethodGet,
+		"/analytics/class-size-engagement?school_id="+uuid.NewString(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	// The quiet classroom is listed but has no engagement to pair
+	if body["paired_classrooms"] != 3.0 || body["correlation"] != 1.0 {
+		t.Errorf("paired, correlation = %v, %v, want 3 classrooms correlating perfectly", body["paired_classrooms"], body["correlation"])
+	}
+	if body["fill_rate_correlation"] != nil {
+		t.Errorf("fill_rate_correlation = %v, want null while a paired classroom has no capacity", body["fill_rate_correlation"])
+	}
+	classrooms := body["classrooms"].([]interface{})
+	if len(classrooms) != 4 {
+		t.Fatalf("listed %d classrooms, want 4", len(classrooms))
+	}
+	quiet, medium, uncapped := classrooms[0].(map[string]interface{}), classrooms[2].(map[string]interface{}), classrooms[3].(map[string]interface{})
+	if quiet["avg_engagement"] != nil || quiet["fill_rate"] != 50.0 {
+		t.Errorf("quiet classroom = %v, want no engagement and a 50%% fill rate", quiet)
+	}
+	if medium["avg_engagement"] != 70.0 || medium["fill_rate"] != 80.0 {
+		t.Errorf("medium classroom = %v, want rounded engagement 70 and fill rate 80", medium)
+	}
+	if uncapped["fill_rate"] != nil {
+		t.Errorf("uncapped fill_rate = %v, want null", uncapped["fill_rate"])
+	}
+}
+
+func TestClassSizeEngagementWithoutVariation(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM classrooms cl"}, classSizeColumns,
+		[]driver.Value{uuid.NewString(), "A", int64(20), int64(10), 40.0, int64(5)},
+		[]driver.Value{uuid.NewString(), "B", int64(20), int64(10), 60.0, int64(5)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetClassSizeEngagement, "/analytics/class-size-engagement", http.MethodGet,
+		"/analytics/class-size-engagement?school_id="+uuid.NewString(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	if body["correlation"] != nil || body["fill_rate_correlation"] != nil || body["correlation_unavailable_reason"] == nil {
+		t.Errorf("body = %v, want null correlations and a reason when sizes don't vary", body)
+	}
+}
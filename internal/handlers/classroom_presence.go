@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxPresenceDays bounds the number of days in a presence matrix
+const maxPresenceDays = 92
+
+// PresenceCell is one student's activity on one day; days without daily
+// metrics are absent with zero minutes
+type PresenceCell struct {
+	Present       bool    `json:"present"`
+	ActiveMinutes float64 `json:"active_minutes"`
+}
+
+// PresenceRow is a student's row of the presence matrix, one cell per date
+type PresenceRow struct {
+	StudentID   uuid.UUID      `json:"student_id"`
+	FirstName   *string        `json:"first_name"`
+	LastName    *string        `json:"last_name"`
+	DaysPresent int            `json:"days_present"`
+	Cells       []PresenceCell `json:"cells"`
+}
+
+// presenceStudent is an actively enrolled student of the classroom
+type presenceStudent struct {
+	ID        uuid.UUID
+	FirstName *string
+	LastName  *string
+}
+
+// presenceActivity is a student's daily metrics row
+type presenceActivity struct {
+	UserID  uuid.UUID
+	Date    time.Time
+	Minutes float64
+}
+
+// GetClassroomPresence returns an attendance-style matrix of the classroom's
+// actively enrolled students by local date, marking a student present on the
+// days they have daily metrics. Each row has one cell per entry of dates;
+// daily_present counts the students present on each date. The range defaults
+// to the last 14 days and is capped at maxPresenceDays. Viewers in redacted
+// roles only get their own row.
+func (h *ReportingHandler) GetClassroomPresence(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+		return
+	}
+
+	loc, err := h.requestLocation(c, h.classroomTimezone(classroomID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -13, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, loc)
+	to := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day(), 0, 0, 0, 0, loc)
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+	dates := presenceDates(from, to)
+	if len(dates) > maxPresenceDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("date range must be at most %d days", maxPresenceDays)})
+		return
+	}
+
+	var students []presenceStudent
+	err = h.db.Table("user_classrooms uc").
+		Select("u.id, u.first_name, u.last_name").
+		Joins("JOIN users u ON u.id = uc.user_id").
+		Where("uc.classroom_id = ? AND uc.is_active = true AND u.role = 'student'", classroomID).
+		Order("u.last_name, u.first_name, u.id").
+		Scan(&students).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch students", "details": err.Error()})
+		return
+	}
+
+	var activity []presenceActivity
+	err = h.db.Table("daily_user_metrics dum").
+		Select("dum.user_id, dum.date, dum.total_session_duration_seconds / 60.0 as minutes").
+		Joins("JOIN user_classrooms uc ON uc.user_id = dum.user_id AND uc.classroom_id = ? AND uc.is_active = true", classroomID).
+		Where("dum.date BETWEEN ? AND ?", from.Format(DateFormat), to.Format(DateFormat)).
+		Scan(&activity).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily activity", "details": err.Error()})
+		return
+	}
+
+	rows, dailyPresent := buildPresenceMatrix(students, activity, dates)
+
+	// Redacted roles see the daily counts but only their own row
+	viewer := requestViewer(c)
+	redacted := h.redacts(viewer)
+	if redacted {
+		own := []PresenceRow{}
+		for _, row := range rows {
+			if viewer.UserID != nil && row.StudentID == *viewer.UserID {
+				own = append(own, row)
+			}
+		}
+		rows = own
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":      classroomID,
+		"period":            gin.H{"from": from.Format(DateFormat), "to": to.Format(DateFormat)},
+		"timezone":          loc.String(),
+		"dates":             dates,
+		"daily_present":     dailyPresent,
+		"students":          rows,
+		"students_redacted": redacted,
+	})
+}
+
+// presenceDates lists every date from from to to inclusive
+func presenceDates(from, to time.Time) []string {
+	dates := []string{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, day.Format(DateFormat))
+	}
+	return dates
+}
+
+// buildPresenceMatrix lays the activity out as one row per student and one
+// cell per date, in the given orders. Activity of other users or outside the
+// dates is ignored.
+func buildPresenceMatrix(students []presenceStudent, activity []presenceActivity, dates []string) ([]PresenceRow, []int) {
+	dateIndex := make(map[string]int, len(dates))
+	for i, date := range dates {
+		dateIndex[date] = i
+	}
+
+	rows := make([]PresenceRow, len(students))
+	rowIndex := make(map[uuid.UUID]int, len(students))
+	for i, student := range students {
+		rows[i] = PresenceRow{
+			StudentID: student.ID,
+			FirstName: student.FirstName,
+			LastName:  student.LastName,
+			Cells:     make([]PresenceCell, len(dates)),
+		}
+		rowIndex[student.ID] = i
+	}
+
+	dailyPresent := make([]int, len(dates))
+	for _, day := range activity {
+		i, ok := rowIndex[day.UserID]
+		if !ok {
+			continue
+		}
+		j, ok := dateIndex[day.Date.Format(DateFormat)]
+		if !ok {
+			continue
+		}
+		cell := &rows[i].Cells[j]
+		if !cell.Present {
+			cell.Present = true
+			rows[i].DaysPresent++
+			dailyPresent[j]++
+		}
+		cell.ActiveMinutes = roundTo(cell.ActiveMinutes+day.Minutes, 2)
+	}
+	return rows, dailyPresent
+}
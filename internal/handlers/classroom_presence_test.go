@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPresenceDates(t *testing.T) {
+	from := time.Date(2026, 2, 27, 0, 0, 0, 0, time.UTC)
+	if got := presenceDates(from, from.AddDate(0, 0, 2)); !slices.Equal(got, []string{"2026-02-27", "2026-02-28", "2026-03-01"}) {
+		t.Errorf("presenceDates = %v", got)
+	}
+	if got := presenceDates(from, from); len(got) != 1 {
+		t.Errorf("presenceDates(one day) = %v", got)
+	}
+}
+
+func TestBuildPresenceMatrix(t *testing.T) {
+	ada, ben := uuid.New(), uuid.New()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	dates := []string{"2026-03-02", "2026-03-03", "2026-03-04"}
+	rows, daily := buildPresenceMatrix(
+		[]presenceStudent{{ID: ada}, {ID: ben}},
+		[]presenceActivity{
+			{UserID: ada, Date: day(2), Minutes: 10},
+			{UserID: ada, Date: day(2), Minutes: 5.5},
+			{UserID: ada, Date: day(4), Minutes: 20},
+			{UserID: ben, Date: day(4), Minutes: 0},
+			{UserID: uuid.New(), Date: day(3), Minutes: 30},
+			{UserID: ben, Date: day(9), Minutes: 30},
+		},
+		dates)
+
+	// Two rows for one day count once; strangers and days outside the range are ignored
+	if !slices.Equal(daily, []int{1, 0, 2}) {
+		t.Errorf("daily present = %v, want [1 0 2]", daily)
+	}
+	if rows[0].DaysPresent != 2 || rows[0].Cells[0] != (PresenceCell{Present: true, ActiveMinutes: 15.5}) || rows[0].Cells[1].Present {
+		t.Errorf("ada = %+v", rows[0])
+	}
+	if rows[1].DaysPresent != 1 || rows[1].Cells[2] != (PresenceCell{Present: true}) {
+		t.Errorf("ben = %+v, want present with zero minutes on the 4th", rows[1])
+	}
+}
+
+func TestClassroomPresence(t *testing.T) {
+	fake, db := newFakeDB(t)
+	ada, ben := uuid.New(), uuid.New()
+	fake.rows([]string{"FROM user_classrooms uc", "JOIN users u"}, []string{"id", "first_name", "last_name"},
+		[]driver.Value{ada.String(), "Ada", "Byron"},
+		[]driver.Value{ben.String(), "Ben", "Carter"})
+	fake.rows([]string{"FROM daily_user_metrics dum"}, []string{"user_id", "date", "minutes"},
+		[]driver.Value{ada.String(), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), 12.0},
+		[]driver.Value{ben.String(), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), 8.0})
+	h := NewReportingHandler(db)
+	target := "/classrooms/" + uuid.NewString() + "/presence?date_from=2026-03-02&date_to=2026-03-03"
+
+	w := testRequest(h.GetClassroomPresence, "/classrooms/:id/presence", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	if dates := body["dates"].([]interface{}); len(dates) != 2 || len(body["students"].([]interface{})) != 2 {
+		t.Fatalf("body = %v, want 2 dates and 2 students", body)
+	}
+	if daily := body["daily_present"].([]interface{}); daily[0] != 2.0 || daily[1] != 0.0 {
+		t.Errorf("daily_present = %v, want [2 0]", daily)
+	}
+	if ran := fake.ran("FROM daily_user_metrics dum"); len(ran) != 1 || !argsContain(ran[0].Args, "2026-03-02") || !argsContain(ran[0].Args, "2026-03-03") {
+		t.Errorf("activity statements = %v, want the local dates bound", ran)
+	}
+
+	// A student sees everyone's daily counts but only their own row
+	w = testRequest(h.GetClassroomPresence, "/classrooms/:id/presence", http.MethodGet, target, "",
+		map[string]interface{}{"user_id": ben, "user_role": "student"})
+	expectStatus(t, w, http.StatusOK)
+	body = decodeBody(t, w)
+	students := body["students"].([]interface{})
+	if body["students_redacted"] != true || len(students) != 1 || students[0].(map[string]interface{})["student_id"] != ben.String() {
+		t.Errorf("students = %v, want only the viewer's row", students)
+	}
+	if daily := body["daily_present"].([]interface{}); daily[0] != 2.0 {
+		t.Errorf("redacted daily_present = %v, want the class count", daily)
+	}
+}
+
+func TestClassroomPresenceRejectsLongRanges(t *testing.T) {
+	fake, db := newFakeDB(t)
+	target := "/classrooms/" + uuid.NewString() + "/presence?date_from=2026-01-01&date_to=2026-04-03"
+	w := testRequest(NewReportingHandler(db).GetClassroomPresence, "/classrooms/:id/presence", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusBadRequest)
+	if len(fake.ran("FROM user_classrooms uc")) != 0 {
+		t.Error("an over-long range reached the database")
+	}
+}
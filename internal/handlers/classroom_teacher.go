@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// teacherAssignmentError explains why a user can't teach a classroom
+type teacherAssignmentError struct {
+	reason string
+}
+
+func (e *teacherAssignmentError) Error() string {
+	return e.reason
+}
+
+// validateClassroomTeacher checks that the teacher exists, has the teacher
+// role and belongs to the classroom's school, so cross-school assignments
+// can't skew teacher reports. It returns a *teacherAssignmentError for an
+// invalid assignment and the database error if the lookup fails.
+func validateClassroomTeacher(db *gorm.DB, schoolID, teacherID uuid.UUID) error {
+	var teacher struct {
+		Role     string
+		SchoolID uuid.UUID
+	}
+	err := db.Table("users").Select("role, school_id").Where("id = ?", teacherID).Take(&teacher).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &teacherAssignmentError{reason: "Teacher not found"}
+	}
+	if err != nil {
+		return err
+	}
+
+	if teacher.Role != "teacher" {
+		return &teacherAssignmentError{reason: "Assigned user does not have the teacher role"}
+	}
+	if teacher.SchoolID != schoolID {
+		return &teacherAssignmentError{reason: "Teacher belongs to a different school than the classroom"}
+	}
+	return nil
+}
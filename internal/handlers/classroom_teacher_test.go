@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCreateClassroomValidatesTeacher(t *testing.T) {
+	schoolID, otherSchoolID, teacherID := uuid.New(), uuid.New(), uuid.New()
+	teacher := func(role string, school uuid.UUID) func(*fakeDB) {
+		return func(fake *fakeDB) {
+			fake.rows([]string{`FROM "users"`, "role, school_id"}, []string{"role", "school_id"}, []driver.Value{role, school.String()})
+		}
+	}
+	tests := []struct {
+		name    string
+		teacher func(*fakeDB)
+		want    int
+		message string
+	}{
+		{"teacher at the school", teacher("teacher", schoolID), http.StatusCreated, ""},
+		{"teacher at another school", teacher("teacher", otherSchoolID), http.StatusUnprocessableEntity, "different school"},
+		{"not a teacher", teacher("student", schoolID), http.StatusUnprocessableEntity, "teacher role"},
+		{"unknown user", func(*fakeDB) {}, http.StatusUnprocessableEntity, "Teacher not found"},
+		{"lookup fails", func(fake *fakeDB) { fake.fail([]string{`FROM "users"`}, errors.New("connection reset")) }, http.StatusInternalServerError, "verify teacher"},
+	}
+	body := fmt.Sprintf(`{"school_id":%q,"name":"Algebra I","teacher_id":%q}`, schoolID, teacherID)
+
+	for _, tt := range tests {
+		for _, stack := range []string{"reporting", "crud"} {
+			t.Run(stack+"/"+tt.name, func(t *testing.T) {
+				fake, db := newFakeDB(t)
+				tt.teacher(fake)
+				var handler gin.HandlerFunc = NewReportingHandler(db).CreateClassroom
+				if stack == "crud" {
+					handler = NewCRUDHandler(db).CreateClassroom
+				}
+
+				w := testRequest(handler, "/classrooms", http.MethodPost, "/classrooms", body, nil)
+				expectStatus(t, w, tt.want)
+				if !strings.Contains(w.Body.String(), tt.message) {
+					t.Errorf("body = %s, want it to mention %q", w.Body, tt.message)
+				}
+				if inserted := len(fake.ran(`INSERT INTO "classrooms"`)) > 0; inserted != (tt.want == http.StatusCreated) {
+					t.Errorf("classroom inserted = %v with status %d", inserted, tt.want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Network buckets recorded on sessions
+const (
+	NetworkBucketOnNetwork  = "on_network"
+	NetworkBucketOffNetwork = "off_network"
+	NetworkBucketUnknown    = "unknown"
+)
+
+// ParseCIDRList parses a comma-separated list of CIDR ranges. Bare addresses
+// are accepted as single-host ranges.
+func ParseCIDRList(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// SetSessionNetworkPolicy configures which ranges count as on-network and
+// whether session IP addresses are stored. With storeIP false only the bucket
+// is kept. The client IP itself comes from gin's ClientIP, so X-Forwarded-For
+// is honored only from the engine's trusted proxies.
+func (h *ReportingHandler) SetSessionNetworkPolicy(onNetwork []*net.IPNet, storeIP bool) {
+	h.onNetworkRanges = onNetwork
+	h.storeClientIP = storeIP
+}
+
+// networkBucket classifies a client IP against the on-network ranges. Without
+// configured ranges, or without a parsable IP, the bucket is unknown.
+func (h *ReportingHandler) networkBucket(ip net.IP) string {
+	if ip == nil || len(h.onNetworkRanges) == 0 {
+		return NetworkBucketUnknown
+	}
+	for _, network := range h.onNetworkRanges {
+		if network.Contains(ip) {
+			return NetworkBucketOnNetwork
+		}
+	}
+	return NetworkBucketOffNetwork
+}
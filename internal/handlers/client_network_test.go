@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestParseCIDRList(t *testing.T) {
+	networks, err := ParseCIDRList(" 10.0.0.0/8, 192.0.2.7 ,2001:db8::/32,2001:db8:ffff::1,")
+	if err != nil {
+		t.Fatalf("ParseCIDRList: %v", err)
+	}
+	want := []string{"10.0.0.0/8", "192.0.2.7/32", "2001:db8::/32", "2001:db8:ffff::1/128"}
+	if len(networks) != len(want) {
+		t.Fatalf("parsed %v, want %v", networks, want)
+	}
+	for i, network := range networks {
+		if network.String() != want[i] {
+			t.Errorf("networks[%d] = %s, want %s", i, network, want[i])
+		}
+	}
+
+	for _, list := range []string{"10.0.0.0/33", "10.0.0", "campus"} {
+		if _, err := ParseCIDRList(list); err == nil {
+			t.Errorf("ParseCIDRList(%q) succeeded", list)
+		}
+	}
+}
+
+func TestNetworkBucket(t *testing.T) {
+	ranges, err := ParseCIDRList("10.0.0.0/8,192.0.2.7,2001:db8::/32")
+	if err != nil {
+		t.Fatalf("ParseCIDRList: %v", err)
+	}
+	h := NewReportingHandler(nil)
+	h.SetSessionNetworkPolicy(ranges, true)
+
+	tests := map[string]string{
+		"10.200.3.4":       NetworkBucketOnNetwork,
+		"11.0.0.1":         NetworkBucketOffNetwork,
+		"192.0.2.7":        NetworkBucketOnNetwork,
+		"192.0.2.8":        NetworkBucketOffNetwork,
+		"::ffff:10.0.0.1":  NetworkBucketOnNetwork,
+		"2001:db8:1234::5": NetworkBucketOnNetwork,
+		"2001:db9::5":      NetworkBucketOffNetwork,
+	}
+	for ip, want := range tests {
+		if got := h.networkBucket(net.ParseIP(ip)); got != want {
+			t.Errorf("networkBucket(%s) = %s, want %s", ip, got, want)
+		}
+	}
+	if got := h.networkBucket(nil); got != NetworkBucketUnknown {
+		t.Errorf("networkBucket(nil) = %s, want unknown", got)
+	}
+
+	h.SetSessionNetworkPolicy(nil, true)
+	if got := h.networkBucket(net.ParseIP("10.0.0.1")); got != NetworkBucketUnknown {
+		t.Errorf("without ranges the bucket is %s, want unknown", got)
+	}
+}
+
+func TestSessionBatchCapturesClientNetwork(t *testing.T) {
+	const remote = "192.0.2.1" // httptest's RemoteAddr
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		forwardedFor   string
+		storeIP        bool
+		wantIP         string // "" when no address is stored
+		wantBucket     string
+	}{
+		{"direct client", nil, "", true, remote, NetworkBucketOffNetwork},
+		{"trusted proxy", []string{remote}, "10.1.2.3", true, "10.1.2.3", NetworkBucketOnNetwork},
+		{"untrusted proxy", []string{"203.0.113.0/24"}, "10.1.2.3", true, remote, NetworkBucketOffNetwork},
+		// Hops are read from the right; the first untrusted one is the client,
+		// so a forged leftmost entry is ignored
+		{"multi-hop", []string{remote, "172.16.0.0/12"}, "10.1.1.1, 198.51.100.7, 172.16.0.2", true, "198.51.100.7", NetworkBucketOffNetwork},
+		{"multi-hop to campus", []string{remote, "172.16.0.0/12"}, "198.51.100.7, 10.4.4.4, 172.16.0.2", true, "10.4.4.4", NetworkBucketOnNetwork},
+		{"IPv6 client", []string{remote}, "2001:db8::42", true, "2001:db8::42", NetworkBucketOnNetwork},
+		{"capture disabled", []string{remote}, "10.1.2.3", false, "", NetworkBucketOnNetwork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			h := NewReportingHandler(db)
+			ranges, err := ParseCIDRList("10.0.0.0/8,2001:db8::/32")
+			if err != nil {
+				t.Fatalf("ParseCIDRList: %v", err)
+			}
+			h.SetSessionNetworkPolicy(ranges, tt.storeIP)
+
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatalf("SetTrustedProxies: %v", err)
+			}
+			router.POST("/sessions/batch", func(c *gin.Context) {
+				c.Set("user_id", uuid.New())
+			}, h.IngestSessionBatch)
+
+			body := `{"sessions":[{"application":"whiteboard","start_time":"2024-03-04T09:00:00Z"}]}`
+			req := httptest.NewRequest(http.MethodPost, "/sessions/batch", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			expectStatus(t, w, http.StatusCreated)
+
+			inserts := fake.ran(`INSERT INTO "sessions"`)
+			if len(inserts) != 1 {
+				t.Fatalf("ran %d session inserts, want 1", len(inserts))
+			}
+			var strs []string
+			for _, arg := range inserts[0].Args {
+				if s, ok := arg.(*string); ok && s != nil {
+					strs = append(strs, *s)
+				}
+			}
+			if !slices.Contains(strs, tt.wantBucket) {
+				t.Errorf("insert args %q, want bucket %s", strs, tt.wantBucket)
+			}
+			if tt.wantIP != "" && !slices.Contains(strs, tt.wantIP) {
+				t.Errorf("insert args %q, want IP %s", strs, tt.wantIP)
+			}
+			for _, s := range strs {
+				if net.ParseIP(s) != nil && s != tt.wantIP {
+					t.Errorf("stored IP %s, want %q", s, tt.wantIP)
+				}
+			}
+		})
+	}
+}
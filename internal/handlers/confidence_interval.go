@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"math"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConfidenceLevel is the coverage of the intervals returned with ?include_ci=true
+const ConfidenceLevel = 0.95
+
+// ConfidenceInterval is a 95% confidence interval around a reported mean,
+// from the sample standard error and Student's t distribution
+type ConfidenceInterval struct {
+	Lower         float64 `json:"lower"`
+	Upper         float64 `json:"upper"`
+	StandardError float64 `json:"standard_error"`
+	SampleSize    int     `json:"sample_size"`
+}
+
+// tCritical95 holds the two-sided 95% critical values of Student's t for 1
+// to 30 degrees of freedom
+var tCritical95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// wantsConfidenceIntervals reports whether the request asked for confidence
+// intervals alongside the report averages
+func wantsConfidenceIntervals(c *gin.Context) bool {
+	return c.Query("include_ci") == "true"
+}
+
+// tCritical returns the two-sided 95% critical value for df degrees of
+// freedom. Between tabulated values the next smaller df is used, which
+// slightly widens the interval rather than narrowing it.
+func tCritical(df int) float64 {
+	switch {
+	case df <= len(tCritical95):
+		return tCritical95[df-1]
+	case df < 40:
+		return tCritical95[len(tCritical95)-1]
+	case df < 60:
+		return 2.021
+	case df < 120:
+		return 2.000
+	default:
+		return 1.980
+	}
+}
+
+// confidenceInterval returns the 95% interval for a mean of n samples with
+// sample standard deviation stddev. It is nil when the mean was withheld, the
+// deviation is unknown, or n is below the minimum sample size (and always
+// below 2, where the standard error is undefined).
+func (h *ReportingHandler) confidenceInterval(mean, stddev *float64, n int) *ConfidenceInterval {
+	if mean == nil || stddev == nil || n < 2 || n < h.minSampleSize {
+		return nil
+	}
+	se := *stddev / math.Sqrt(float64(n))
+	margin := tCritical(n-1) * se
+	return &ConfidenceInterval{
+		Lower:         roundTo(*mean-margin, 2),
+		Upper:         roundTo(*mean+margin, 2),
+		StandardError: roundTo(se, 4),
+		SampleSize:    n,
+	}
+}
+
+// percentageInterval is confidenceInterval for a mean of 0-100 percentages,
+// such as participation rates, with the bounds clamped to that range so
+// averages near 0% or 100% don't get impossible error bars
+func (h *ReportingHandler) percentageInterval(mean, stddev *float64, n int) *ConfidenceInterval {
+	ci := h.confidenceInterval(mean, stddev, n)
+	if ci != nil {
+		ci.Lower = math.Max(ci.Lower, 0)
+		ci.Upper = math.Min(ci.Upper, 100)
+	}
+	return ci
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTCritical(t *testing.T) {
+	for df, want := range map[int]float64{1: 12.706, 7: 2.365, 30: 2.042, 35: 2.042, 40: 2.021, 59: 2.021, 60: 2.000, 120: 1.980, 10000: 1.980} {
+		if got := tCritical(df); got != want {
+			t.Errorf("tCritical(%d) = %v, want %v", df, got, want)
+		}
+	}
+}
+
+func TestConfidenceInterval(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	// The sample 2, 4, 4, 4, 5, 5, 7, 9 has mean 5 and sample standard
+	// deviation sqrt(32/7); the margin is t(7) * sd / sqrt(8) = 2.365 * 0.75593
+	sampleSD := math.Sqrt(32.0 / 7)
+	tests := []struct {
+		name       string
+		minSample  int
+		mean, sd   *float64
+		n          int
+		want       *ConfidenceInterval
+		percentage *ConfidenceInterval
+	}{
+		{"known sample", DefaultMinSampleSize, f(5), f(sampleSD), 8,
+			&ConfidenceInterval{Lower: 3.21, Upper: 6.79, StandardError: 0.7559, SampleSize: 8},
+			&ConfidenceInterval{Lower: 3.21, Upper: 6.79, StandardError: 0.7559, SampleSize: 8}},
+		// Three samples 10, 20, 30: a wide t(2) interval that dips below zero
+		{"small sample", DefaultMinSampleSize, f(20), f(10), 3,
+			&ConfidenceInterval{Lower: -4.84, Upper: 44.84, StandardError: 5.7735, SampleSize: 3},
+			&ConfidenceInterval{Lower: 0, Upper: 44.84, StandardError: 5.7735, SampleSize: 3}},
+		// A 96% average participation can't have an upper bound past 100%
+		{"near the ceiling", DefaultMinSampleSize, f(96), f(5), 4,
+			&ConfidenceInterval{Lower: 88.05, Upper: 103.96, StandardError: 2.5, SampleSize: 4},
+			&ConfidenceInterval{Lower: 88.05, Upper: 100, StandardError: 2.5, SampleSize: 4}},
+		{"two samples without a minimum", 0, f(10), f(2), 2,
+			&ConfidenceInterval{Lower: -7.97, Upper: 27.97, StandardError: 1.4142, SampleSize: 2},
+			&ConfidenceInterval{Lower: 0, Upper: 27.97, StandardError: 1.4142, SampleSize: 2}},
+		{"below the minimum sample", DefaultMinSampleSize, f(10), f(2), 2, nil, nil},
+		{"one sample", 0, f(10), f(0), 1, nil, nil},
+		{"no samples", 0, f(10), f(0), 0, nil, nil},
+		{"withheld mean", 0, nil, f(2), 10, nil, nil},
+		{"unknown deviation", 0, f(10), nil, 10, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewReportingHandler(nil)
+			h.SetMinSampleSize(tt.minSample)
+			if got := h.confidenceInterval(tt.mean, tt.sd, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("confidenceInterval = %+v, want %+v", got, tt.want)
+			}
+			if got := h.percentageInterval(tt.mean, tt.sd, tt.n); !reflect.DeepEqual(got, tt.percentage) {
+				t.Errorf("percentageInterval = %+v, want %+v", got, tt.percentage)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreationHourContent summarizes the content created in one local hour of the day
+type CreationHourContent struct {
+	Hour                  int      `json:"hour"` // 0-23 in the requested timezone
+	ContentCount          int      `json:"content_count"`
+	TotalViews            int      `json:"total_views"`
+	AvgViews              *float64 `json:"avg_views"`
+	AvgEffectivenessScore *float64 `json:"avg_effectiveness_score"`
+	MetricSamples         int      `json:"-"`
+	InsufficientSample    []string `json:"insufficient_sample"`
+}
+
+// GetContentByCreationHour groups content by the hour of day it was created,
+// in the classroom school's time zone (or ?tz=, else the report timezone),
+// with average views and effectiveness per hour, so
+// content made during class can be compared with content made after hours.
+// All 24 hours are returned; hours without content have zero counts and null
+// averages.
+func (h *ReportingHandler) GetContentByCreationHour(c *gin.Context) {
+	var classroomID *uuid.UUID
+	if classroomIDStr := c.Query("classroom_id"); classroomIDStr != "" {
+		id, err := uuid.Parse(classroomIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		classroomID = &id
+	}
+
+	var schoolTimezone string
+	if classroomID != nil {
+		schoolTimezone = h.classroomTimezone(*classroomID)
+	}
+	loc, err := h.requestLocation(c, schoolTimezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -90, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day()+1, 0, 0, 0, 0, loc)
+	if !start.Before(end) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+
+	// Content timestamps are stored in UTC
+	query := h.db.Table("content c").
+		Select(`
+			EXTRACT(HOUR FROM c.created_at AT TIME ZONE 'UTC' AT TIME ZONE ?)::int as hour,
+			COUNT(*) as content_count,
+			COALESCE(SUM(cm.view_count), 0) as total_views,
+			AVG(COALESCE(cm.view_count, 0)) as avg_views,
+			AVG(cm.effectiveness_score) as avg_effectiveness_score,
+			COUNT(cm.content_id) as metric_samples
+		`, loc.String()).
+		Joins("LEFT JOIN content_metrics cm ON cm.content_id = c.id").
+		Where("c.deleted_at IS NULL AND c.created_at >= ? AND c.created_at < ?", start.UTC(), end.UTC())
+	if classroomID != nil {
+		query = query.Where("c.classroom_id = ?", *classroomID)
+	}
+
+	var rows []CreationHourContent
+	if err := query.Group("hour").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to group content by creation hour", "details": err.Error()})
+		return
+	}
+
+	hours := make([]CreationHourContent, 24)
+	for i := range hours {
+		hours[i] = CreationHourContent{Hour: i, InsufficientSample: []string{}}
+	}
+	for _, row := range rows {
+		if row.Hour < 0 || row.Hour > 23 {
+			continue
+		}
+		hourGuard := h.newSampleGuard()
+		row.AvgViews = hourGuard.average("avg_views", row.AvgViews, row.ContentCount)
+		row.AvgEffectivenessScore = hourGuard.average("avg_effectiveness_score", row.AvgEffectivenessScore, row.MetricSamples)
+		row.InsufficientSample = hourGuard.flagged()
+		for _, v := range []*float64{row.AvgViews, row.AvgEffectivenessScore} {
+			if v != nil {
+				*v = roundTo(*v, 2)
+			}
+		}
+		hours[row.Hour] = row
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":       classroomID,
+		"period":             gin.H{"from": start.Format(DateFormat), "to": end.AddDate(0, 0, -1).Format(DateFormat)},
+		"timezone":           loc.String(),
+		"hours":              hours,
+		"thresholds_applied": h.thresholdsApplied(),
+	})
+}
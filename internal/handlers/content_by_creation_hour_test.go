@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContentByCreationHour(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM content c", "EXTRACT(HOUR"}, []string{"hour", "content_count", "total_views", "avg_views", "avg_effectiveness_score", "metric_samples"},
+		[]driver.Value{int64(9), int64(10), int64(200), 20.0, 0.756, int64(8)},
+		[]driver.Value{int64(20), int64(2), int64(30), 15.0, 0.9, int64(2)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetContentByCreationHour, "/analytics/content-by-creation-hour", http.MethodGet,
+		"/analytics/content-by-creation-hour?tz=America/New_York&date_from=2026-03-02&date_to=2026-03-02", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	hours := body["hours"].([]interface{})
+	if len(hours) != 24 {
+		t.Fatalf("got %d hours, want all 24", len(hours))
+	}
+	if quiet := hours[3].(map[string]interface{}); quiet["hour"] != 3.0 || quiet["content_count"] != 0.0 || quiet["avg_views"] != nil {
+		t.Errorf("hour 3 = %v, want zero content and null averages", quiet)
+	}
+	if morning := hours[9].(map[string]interface{}); morning["content_count"] != 10.0 || morning["avg_effectiveness_score"] != 0.76 {
+		t.Errorf("hour 9 = %v, want 10 items and rounded effectiveness", morning)
+	}
+	// Two metric samples fall under the default minimum of three
+	evening := hours[20].(map[string]interface{})
+	flagged := make([]string, 0)
+	for _, field := range evening["insufficient_sample"].([]interface{}) {
+		flagged = append(flagged, field.(string))
+	}
+	if evening["avg_views"] != nil || evening["avg_effectiveness_score"] != nil || !slices.Equal(flagged, []string{"avg_views", "avg_effectiveness_score"}) {
+		t.Errorf("hour 20 = %v, want both averages withheld", evening)
+	}
+
+	// The local day runs from 05:00 to 05:00 UTC while New York is on EST
+	ran := fake.ran("EXTRACT(HOUR")
+	start := time.Date(2026, 3, 2, 5, 0, 0, 0, time.UTC)
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{`GROUP BY "hour"`}) ||
+		!argsContain(ran[0].Args, "America/New_York") || !argsContain(ran[0].Args, start) || !argsContain(ran[0].Args, start.AddDate(0, 0, 1)) {
+		t.Errorf("content statements = %v, want local hours grouped by their alias over the local day", ran)
+	}
+}
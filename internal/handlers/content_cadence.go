@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultDrySpellDays is the gap between content creations, in days, from
+// which the gap is flagged as a dry spell
+const DefaultDrySpellDays = 14
+
+// CadencePoint is the content a teacher created in one period
+type CadencePoint struct {
+	PeriodStart string `json:"period_start"`
+	Created     int    `json:"created"`
+}
+
+// ContentGap is the time between two consecutive content creations. The
+// ongoing gap runs from the last creation to the end of the range.
+type ContentGap struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Days     float64   `json:"days"`
+	DrySpell bool      `json:"dry_spell"`
+	Ongoing  bool      `json:"ongoing"`
+}
+
+// GetTeacherContentCadence reports how regularly a teacher creates content:
+// the content created per week or month and the gaps between consecutive
+// creations, flagging gaps of at least ?dry_spell_days= as dry spells.
+// Deleted content is left out. Periods are in the teacher's school time zone
+// unless ?tz= overrides it. Only admins and the teacher themselves may read
+// it over a user token.
+func (h *ReportingHandler) GetTeacherContentCadence(c *gin.Context) {
+	teacherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, teacherID, adminRole, "Not allowed to view this teacher's content cadence") {
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "week")
+	if granularity != "week" && granularity != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be week or month"})
+		return
+	}
+
+	drySpellDays := DefaultDrySpellDays
+	if v := c.Query("dry_spell_days"); v != "" {
+		drySpellDays, err = strconv.Atoi(v)
+		if err != nil || drySpellDays < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_spell_days must be a positive integer"})
+			return
+		}
+	}
+
+	var teacher struct {
+		Role     string
+		SchoolID uuid.UUID
+	}
+	err = h.db.Table("users").Select("role, school_id").Where("id = ?", teacherID).Take(&teacher).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && teacher.Role != "teacher") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Teacher not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teacher", "details": err.Error()})
+		return
+	}
+
+	loc, err := h.requestLocation(c, h.schoolTimezone(teacher.SchoolID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -90, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day()+1, 0, 0, 0, 0, loc)
+	if !start.Before(end) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+
+	var created []time.Time
+	err = h.db.Table("content").
+		Where("creator_id = ? AND deleted_at IS NULL", teacherID).
+		Where("created_at >= ? AND created_at < ?", start.UTC(), end.UTC()).
+		Order("created_at").
+		Pluck("created_at", &created).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch content", "details": err.Error()})
+		return
+	}
+	// Content timestamps are stored in UTC without a zone
+	for i, t := range created {
+		created[i] = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC).In(loc)
+	}
+
+	// The ongoing gap can't run past the present
+	until := end
+	if now := time.Now().In(loc); now.Before(until) {
+		until = now
+	}
+	gaps := contentGaps(created, until, time.Duration(drySpellDays)*24*time.Hour)
+
+	drySpells := 0
+	var longest *float64
+	gapDays := make([]float64, 0, len(gaps))
+	for _, gap := range gaps {
+		if gap.DrySpell {
+			drySpells++
+		}
+		if longest == nil || gap.Days > *longest {
+			days := gap.Days
+			longest = &days
+		}
+		if !gap.Ongoing {
+			gapDays = append(gapDays, gap.Days)
+		}
+	}
+	var medianGap *float64
+	if len(gapDays) > 0 {
+		median := roundTo(medianOf(gapDays), 2)
+		medianGap = &median
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teacher_id":       teacherID,
+		"granularity":      granularity,
+		"period":           gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"timezone":         loc.String(),
+		"total_created":    len(created),
+		"series":           cadenceSeries(created, dateFrom, dateTo, granularity),
+		"gaps":             gaps,
+		"median_gap_days":  medianGap,
+		"longest_gap_days": longest,
+		"dry_spells":       drySpells,
+		"dry_spell_days":   drySpellDays,
+	})
+}
+
+// cadenceSeries counts the creations per period from dateFrom to dateTo,
+// including periods without any. created must be in the report time zone.
+func cadenceSeries(created []time.Time, dateFrom, dateTo time.Time, granularity string) []CadencePoint {
+	counts := make(map[string]int)
+	for _, t := range created {
+		counts[truncateToPeriod(t, granularity).Format(DateFormat)]++
+	}
+
+	series := []CadencePoint{}
+	last := truncateToPeriod(dateTo, granularity)
+	for period := truncateToPeriod(dateFrom, granularity); !period.After(last); period = nextPeriod(period, granularity) {
+		key := period.Format(DateFormat)
+		series = append(series, CadencePoint{PeriodStart: key, Created: counts[key]})
+	}
+	return series
+}
+
+// contentGaps lists the gaps between consecutive creations, sorted oldest
+// first, followed by the ongoing gap from the last creation to until. Gaps of
+// at least drySpell are flagged. Without creations there are no gaps.
+func contentGaps(created []time.Time, until time.Time, drySpell time.Duration) []ContentGap {
+	gaps := []ContentGap{}
+	gap := func(from, to time.Time, ongoing bool) ContentGap {
+		length := to.Sub(from)
+		return ContentGap{
+			From:     from,
+			To:       to,
+			Days:     roundTo(length.Hours()/24, 2),
+			DrySpell: length >= drySpell,
+			Ongoing:  ongoing,
+		}
+	}
+	for i := 1; i < len(created); i++ {
+		gaps = append(gaps, gap(created[i-1], created[i], false))
+	}
+	if len(created) > 0 && until.After(created[len(created)-1]) {
+		gaps = append(gaps, gap(created[len(created)-1], until, true))
+	}
+	return gaps
+}
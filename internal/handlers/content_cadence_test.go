@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestContentGaps(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC) }
+	gaps := contentGaps([]time.Time{at(1, 9), at(1, 21), at(15, 9)}, at(16, 9), 14*24*time.Hour)
+	if len(gaps) != 3 {
+		t.Fatalf("gaps = %+v, want 3", gaps)
+	}
+	if gaps[0].Days != 0.5 || gaps[0].DrySpell || gaps[0].Ongoing {
+		t.Errorf("gaps[0] = %+v, want half a day", gaps[0])
+	}
+	if gaps[1].Days != 13.5 || gaps[1].DrySpell {
+		t.Errorf("gaps[1] = %+v, want 13.5 days without a dry spell", gaps[1])
+	}
+	if !gaps[2].Ongoing || gaps[2].Days != 1 {
+		t.Errorf("gaps[2] = %+v, want the ongoing day", gaps[2])
+	}
+	// Exactly the threshold counts as a dry spell
+	if gaps := contentGaps([]time.Time{at(1, 9), at(15, 9)}, at(15, 9), 14*24*time.Hour); len(gaps) != 1 || !gaps[0].DrySpell {
+		t.Errorf("gaps = %+v, want one 14 day dry spell and no ongoing gap", gaps)
+	}
+	if gaps := contentGaps(nil, at(16, 9), time.Hour); len(gaps) != 0 {
+		t.Errorf("gaps without creations = %+v", gaps)
+	}
+}
+
+func TestCadenceSeries(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 2, d, 10, 0, 0, 0, time.UTC) }
+	series := cadenceSeries([]time.Time{day(2), day(4), day(20)}, day(2), day(20), "week")
+	want := []CadencePoint{{"2026-02-02", 2}, {"2026-02-09", 0}, {"2026-02-16", 1}}
+	if len(series) != len(want) {
+		t.Fatalf("series = %+v, want %+v", series, want)
+	}
+	for i := range want {
+		if series[i] != want[i] {
+			t.Errorf("series[%d] = %+v, want %+v", i, series[i], want[i])
+		}
+	}
+}
+
+func TestTeacherContentCadence(t *testing.T) {
+	fake, db := newFakeDB(t)
+	teacherID := uuid.New()
+	fake.rows([]string{`FROM "users"`, "role, school_id"}, []string{"role", "school_id"}, []driver.Value{"teacher", uuid.NewString()})
+	at := func(day int) time.Time { return time.Date(2026, 2, day, 9, 0, 0, 0, time.UTC) }
+	fake.rows([]string{`FROM "content"`, "creator_id = $1"}, []string{"created_at"},
+		[]driver.Value{at(2)}, []driver.Value{at(3)}, []driver.Value{at(14)})
+	h := NewReportingHandler(db)
+
+	target := "/teachers/" + teacherID.String() + "/content-cadence?tz=UTC&date_from=2026-02-02&date_to=2026-02-15&dry_spell_days=10"
+	w := testRequest(h.GetTeacherContentCadence, "/teachers/:id/content-cadence", http.MethodGet, target, "",
+		map[string]interface{}{"user_id": teacherID, "user_role": "teacher"})
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	// Gaps of 1 and 11 days, then 1.63 days to the end of the range
+	if body["total_created"] != 3.0 || body["dry_spells"] != 1.0 || body["median_gap_days"] != 6.0 || body["longest_gap_days"] != 11.0 {
+		t.Errorf("summary = %v", body)
+	}
+	gaps := body["gaps"].([]interface{})
+	if last := gaps[len(gaps)-1].(map[string]interface{}); last["ongoing"] != true || last["days"] != 1.63 {
+		t.Errorf("last gap = %v, want the ongoing gap to the end of the range", last)
+	}
+	if series := body["series"].([]interface{}); len(series) != 2 || series[0].(map[string]interface{})["created"] != 2.0 {
+		t.Errorf("series = %v, want two weeks starting with 2 creations", series)
+	}
+}
+
+func TestTeacherContentCadenceRejects(t *testing.T) {
+	teacherID := uuid.New()
+	tests := []struct {
+		name   string
+		role   string
+		values map[string]interface{}
+		want   int
+	}{
+		{"another teacher", "teacher", map[string]interface{}{"user_id": uuid.New(), "user_role": "teacher"}, http.StatusForbidden},
+		{"not a teacher", "student", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			fake.rows([]string{`FROM "users"`}, []string{"role", "school_id"}, []driver.Value{tt.role, uuid.NewString()})
+			w := testRequest(NewReportingHandler(db).GetTeacherContentCadence, "/teachers/:id/content-cadence", http.MethodGet,
+				"/teachers/"+teacherID.String()+"/content-cadence", "", tt.values)
+			expectStatus(t, w, tt.want)
+			if len(fake.ran(`FROM "content"`)) != 0 {
+				t.Error("content was fetched for a rejected request")
+			}
+		})
+	}
+}
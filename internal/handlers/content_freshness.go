@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// contentAgeBuckets are the content ages, in whole days at view time, that
+// views are grouped into. A nil MaxDays leaves the bucket open-ended.
+var contentAgeBuckets = []struct {
+	Label   string
+	MinDays int
+	MaxDays *int
+}{
+	{"0-7", 0, intPtr(7)},
+	{"8-30", 8, intPtr(30)},
+	{"31-90", 31, intPtr(90)},
+	{"90+", 91, nil},
+}
+
+func intPtr(v int) *int { return &v }
+
+// ContentFreshnessBucket summarises the views content received while it was
+// within an age bucket
+type ContentFreshnessBucket struct {
+	AgeBucket             string   `json:"age_bucket"`
+	MinAgeDays            int      `json:"min_age_days"`
+	MaxAgeDays            *int     `json:"max_age_days"`
+	Views                 int      `json:"views"`
+	ContentViewed         int      `json:"content_viewed"`
+	AvgViewsPerContent    *float64 `json:"avg_views_per_content"`
+	AvgEffectivenessScore *float64 `json:"avg_effectiveness_score"`
+}
+
+// GetContentFreshness buckets content_viewed events by the age of the viewed
+// content at view time to test whether engagement decays as content ages.
+// Content that received no views in the period is counted separately, since
+// it has no view-time age to bucket by.
+func (h *ReportingHandler) GetContentFreshness(c *gin.Context) {
+	var classroomID *uuid.UUID
+	if classroomIDStr := c.Query("classroom_id"); classroomIDStr != "" {
+		id, err := uuid.Parse(classroomIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		classroomID = &id
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -90, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Views before the content's created_at (client clock skew) count as age 0
+	views := h.db.Table("events e").
+		Select(`
+			c.id as content_id,
+			GREATEST(FLOOR(EXTRACT(EPOCH FROM e.timestamp - c.created_at) / 86400), 0)::int as age_days
+		`).
+		Joins("JOIN content c ON c.id::text = e.metadata->>'content_id'").
+		Where("e.event_type = ?", "content_viewed").
+		Where("e.timestamp BETWEEN ? AND ?", dateFrom, dateTo).
+		Where("c.deleted_at IS NULL")
+	if classroomID != nil {
+		views = views.Where("c.classroom_id = ?", *classroomID)
+	}
+
+	var perContent []struct {
+		ContentID          uuid.UUID
+		AgeDays            int
+		Views              int
+		EffectivenessScore *float64
+	}
+	err = h.db.Table("(?) v", views).
+		Select("v.content_id, v.age_days, COUNT(*) as views, MAX(cm.effectiveness_score) as effectiveness_score").
+		Joins("LEFT JOIN content_metrics cm ON cm.content_id = v.content_id").
+		Group("v.content_id, v.age_days").
+		Scan(&perContent).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate content freshness", "details": err.Error()})
+		return
+	}
+
+	// Fold per-day counts into buckets, counting each content once per bucket
+	type bucketTotals struct {
+		views   int
+		content map[uuid.UUID]*float64
+	}
+	totals := make([]bucketTotals, len(contentAgeBuckets))
+	viewed := map[uuid.UUID]bool{}
+	for _, row := range perContent {
+		for i, bucket := range contentAgeBuckets {
+			if row.AgeDays < bucket.MinDays || (bucket.MaxDays != nil && row.AgeDays > *bucket.MaxDays) {
+				continue
+			}
+			if totals[i].content == nil {
+				totals[i].content = map[uuid.UUID]*float64{}
+			}
+			totals[i].views += row.Views
+			totals[i].content[row.ContentID] = row.EffectivenessScore
+			break
+		}
+		viewed[row.ContentID] = true
+	}
+
+	buckets := make([]ContentFreshnessBucket, len(contentAgeBuckets))
+	for i, bucket := range contentAgeBuckets {
+		buckets[i] = ContentFreshnessBucket{
+			AgeBucket:     bucket.Label,
+			MinAgeDays:    bucket.MinDays,
+			MaxAgeDays:    bucket.MaxDays,
+			Views:         totals[i].views,
+			ContentViewed: len(totals[i].content),
+		}
+		if len(totals[i].content) == 0 {
+			continue
+		}
+		avgViews := roundTo(float64(totals[i].views)/float64(len(totals[i].content)), 2)
+		buckets[i].AvgViewsPerContent = &avgViews
+
+		var scoreSum float64
+		var scored int
+		for _, score := range totals[i].content {
+			if score != nil {
+				scoreSum += *score
+				scored++
+			}
+		}
+		if scored > 0 {
+			avgScore := roundTo(scoreSum/float64(scored), 2)
+			buckets[i].AvgEffectivenessScore = &avgScore
+		}
+	}
+
+	// Content that existed during the period but was never viewed
+	var existing int64
+	contentQuery := h.db.Table("content c").
+		Where("c.deleted_at IS NULL AND c.created_at <= ?", dateTo)
+	if classroomID != nil {
+		contentQuery = contentQuery.Where("c.classroom_id = ?", *classroomID)
+	}
+	if err := contentQuery.Count(&existing).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count content", "details": err.Error()})
+		return
+	}
+	unviewed := int(existing) - len(viewed)
+	if unviewed < 0 {
+		unviewed = 0
+	}
+
+	// Ratio of fresh to stale views per content; above 1 supports the decay hypothesis
+	var decayRatio *float64
+	fresh, stale := buckets[0].AvgViewsPerContent, buckets[len(buckets)-1].AvgViewsPerContent
+	if fresh != nil && stale != nil && *stale > 0 {
+		ratio := roundTo(*fresh / *stale, 2)
+		decayRatio = &ratio
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":     classroomID,
+		"period":           gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"buckets":          buckets,
+		"content_viewed":   len(viewed),
+		"content_unviewed": unviewed,
+		"decay_ratio":      decayRatio,
+		"generated_at":     time.Now().UTC(),
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestContentFreshness(t *testing.T) {
+	fake, db := newFakeDB(t)
+	a, b, c, d := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	fake.rows([]string{"FROM (SELECT", "v.age_days"}, []string{"content_id", "age_days", "views", "effectiveness_score"},
+		[]driver.Value{a, int64(2), int64(10), 80.0},
+		[]driver.Value{a, int64(5), int64(6), 80.0},
+		[]driver.Value{b, int64(3), int64(4), nil},
+		[]driver.Value{d, int64(8), int64(2), nil},
+		[]driver.Value{a, int64(40), int64(3), 80.0},
+		[]driver.Value{c, int64(120), int64(4), 50.0})
+	fake.rows([]string{"count(*) FROM content c"}, []string{"count"}, []driver.Value{int64(7)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetContentFreshness, "/analytics/content-freshness", http.MethodGet, "/analytics/content-freshness?date_from=2024-01-01&date_to=2024-03-31", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	// Content is counted once per bucket however many days it was viewed on
+	want := []struct {
+		label         string
+		views         float64
+		contentViewed float64
+		avgViews      interface{}
+		avgScore      interface{}
+	}{
+		{"0-7", 20, 2, 10.0, 80.0},
+		{"8-30", 2, 1, 2.0, nil},
+		{"31-90", 3, 1, 3.0, 80.0},
+		{"90+", 4, 1, 4.0, 50.0},
+	}
+	buckets := body["buckets"].([]interface{})
+	for i, w := range want {
+		bucket := buckets[i].(map[string]interface{})
+		if bucket["age_bucket"] != w.label || bucket["views"] != w.views || bucket["content_viewed"] != w.contentViewed ||
+			bucket["avg_views_per_content"] != w.avgViews || bucket["avg_effectiveness_score"] != w.avgScore {
+			t.Errorf("buckets[%d] = %v, want %+v", i, bucket, w)
+		}
+	}
+	if body["content_viewed"] != 4.0 || body["content_unviewed"] != 3.0 || body["decay_ratio"] != 2.5 {
+		t.Errorf("viewed %v, unviewed %v, decay %v, want 4, 3 and 2.5", body["content_viewed"], body["content_unviewed"], body["decay_ratio"])
+	}
+}
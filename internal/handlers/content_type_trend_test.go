@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestContentTypeTrend(t *testing.T) {
+	fake, db := newFakeDB(t)
+	month := func(m time.Month) time.Time { return time.Date(2026, m, 1, 0, 0, 0, 0, time.UTC) }
+	fake.rows([]string{"FROM content c", "DATE_TRUNC"}, []string{"period", "content_type", "count"},
+		[]driver.Value{month(time.January), "notebook", int64(3)},
+		[]driver.Value{month(time.January), "whiteboard", int64(1)},
+		[]driver.Value{month(time.March), "notebook", int64(2)})
+	h := NewReportingHandler(db)
+
+	target := "/analytics/content-type-trend?school_id=" + uuid.NewString() + "&granularity=month&tz=UTC&date_from=2026-01-15&date_to=2026-03-10"
+	w := testRequest(h.GetContentTypeTrend, "/analytics/content-type-trend", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	types := body["content_types"].([]interface{})
+	if len(types) != 2 || types[0] != "notebook" || types[1] != "whiteboard" {
+		t.Errorf("content_types = %v, want notebook and whiteboard", types)
+	}
+	// February had no content but still gets a zeroed point for every type
+	want := []struct {
+		start                       string
+		total, notebook, whiteboard float64
+	}{
+		{"2026-01-01", 4, 3, 1},
+		{"2026-02-01", 0, 0, 0},
+		{"2026-03-01", 2, 2, 0},
+	}
+	series := body["series"].([]interface{})
+	if len(series) != len(want) {
+		t.Fatalf("series = %v, want %d months", series, len(want))
+	}
+	for i, expected := range want {
+		point := series[i].(map[string]interface{})
+		counts := point["counts"].(map[string]interface{})
+		if point["period_start"] != expected.start || point["total"] != expected.total ||
+			counts["notebook"] != expected.notebook || counts["whiteboard"] != expected.whiteboard {
+			t.Errorf("series[%d] = %v, want %+v", i, point, expected)
+		}
+	}
+
+	ran := fake.ran("DATE_TRUNC")
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{"GROUP BY 1, 2"}) || !argsContain(ran[0].Args, "month") {
+		t.Errorf("content statements = %v, want monthly periods grouped by ordinal", ran)
+	}
+}
+
+func TestContentTypeTrendRejectsGranularity(t *testing.T) {
+	fake, db := newFakeDB(t)
+	target := "/analytics/content-type-trend?school_id=" + uuid.NewString() + "&granularity=day"
+	w := testRequest(NewReportingHandler(db).GetContentTypeTrend, "/analytics/content-type-trend", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusBadRequest)
+	if len(fake.ran()) != 0 {
+		t.Error("an invalid granularity reached the database")
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreatorEffectiveness summarizes how one creator's content performs
+type CreatorEffectiveness struct {
+	Rank               int       `json:"rank"`
+	CreatorID          uuid.UUID `json:"creator_id"`
+	CreatorName        string    `json:"creator_name"`
+	ContentCount       int       `json:"content_count"`
+	TotalViews         int       `json:"total_views"`
+	AvgEffectiveness   *float64  `json:"avg_effectiveness"`
+	VsSchoolBaseline   *float64  `json:"vs_school_baseline"`
+	InsufficientSample bool      `json:"insufficient_sample"`
+}
+
+// GetCreatorContentEffectiveness ranks content creators in a school by the
+// average effectiveness of their content, alongside the school-wide baseline
+func (h *ReportingHandler) GetCreatorContentEffectiveness(c *gin.Context) {
+	schoolID, err := uuid.Parse(c.Query("school_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required and must be a valid UUID"})
+		return
+	}
+
+	var creatorID *uuid.UUID
+	if creatorIDStr := c.Query("creator_id"); creatorIDStr != "" {
+		id, err := uuid.Parse(creatorIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid creator_id format"})
+			return
+		}
+		creatorID = &id
+	}
+
+	loc, err := h.requestLocation(c, h.schoolTimezone(schoolID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Every creator is aggregated so ranks stay school-wide even when filtering to one
+	var rows []struct {
+		CreatorID          uuid.UUID
+		FirstName          *string
+		LastName           *string
+		ContentCount       int
+		TotalViews         int
+		AvgEffectiveness   *float64
+		ContentWithMetrics int
+	}
+	err = h.db.Table("content ct").
+		Select(`
+			ct.creator_id,
+			u.first_name,
+			u.last_name,
+			COUNT(ct.id) as content_count,
+			COALESCE(SUM(cm.view_count), 0) as total_views,
+			AVG(cm.effectiveness_score) as avg_effectiveness,
+			COUNT(cm.content_id) as content_with_metrics
+		`).
+		Joins("JOIN users u ON u.id = ct.creator_id").
+		Joins("LEFT JOIN content_metrics cm ON cm.content_id = ct.id").
+		Where("u.school_id = ? AND ct.deleted_at IS NULL", schoolID).
+		Where("ct.created_at >= ? AND ct.created_at < ?", dateFrom, dateTo.AddDate(0, 0, 1)).
+		Group("ct.creator_id, u.first_name, u.last_name").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute creator effectiveness", "details": err.Error()})
+		return
+	}
+
+	creators := make([]CreatorEffectiveness, len(rows))
+	var weightedSum float64
+	var scoredContent int
+	for i, row := range rows {
+		guard := h.newSampleGuard()
+		creators[i] = CreatorEffectiveness{
+			CreatorID:        row.CreatorID,
+			CreatorName:      creatorDisplayName(row.FirstName, row.LastName),
+			ContentCount:     row.ContentCount,
+			TotalViews:       row.TotalViews,
+			AvgEffectiveness: guard.average("avg_effectiveness", row.AvgEffectiveness, row.ContentWithMetrics),
+		}
+		creators[i].InsufficientSample = len(guard.flagged()) > 0
+
+		if row.AvgEffectiveness != nil {
+			weightedSum += *row.AvgEffectiveness * float64(row.ContentWithMetrics)
+			scoredContent += row.ContentWithMetrics
+		}
+	}
+
+	// Baseline is the per-content average across the whole school, not an average of creators
+	var baseline *float64
+	if scoredContent > 0 {
+		b := roundTo(weightedSum/float64(scoredContent), 2)
+		baseline = &b
+	}
+
+	rankCreators(creators, baseline)
+
+	if creatorID != nil {
+		filtered := []CreatorEffectiveness{}
+		for _, creator := range creators {
+			if creator.CreatorID == *creatorID {
+				filtered = append(filtered, creator)
+			}
+		}
+		creators = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"school_id": schoolID,
+		"period":    gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"school_baseline": gin.H{
+			"avg_effectiveness": baseline,
+			"content_count":     scoredContent,
+			"creator_count":     len(rows),
+		},
+		"creators":     creators,
+		"generated_at": time.Now(),
+	})
+}
+
+// rankCreators sorts creators by average effectiveness (descending), assigns
+// competition ranks, and fills in each creator's difference from the baseline.
+// Creators without a reportable average are placed last and share the final rank.
+func rankCreators(creators []CreatorEffectiveness, baseline *float64) {
+	sort.SliceStable(creators, func(i, j int) bool {
+		a, b := creators[i].AvgEffectiveness, creators[j].AvgEffectiveness
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if *a != *b {
+			return *a > *b
+		}
+		return creators[i].CreatorName < creators[j].CreatorName
+	})
+
+	for i := range creators {
+		switch {
+		case i == 0:
+			creators[i].Rank = 1
+		case sameMetric(creators[i].AvgEffectiveness, creators[i-1].AvgEffectiveness):
+			creators[i].Rank = creators[i-1].Rank
+		default:
+			creators[i].Rank = i + 1
+		}
+
+		if baseline != nil && creators[i].AvgEffectiveness != nil {
+			diff := roundTo(*creators[i].AvgEffectiveness-*baseline, 2)
+			creators[i].VsSchoolBaseline = &diff
+		}
+	}
+}
+
+// creatorDisplayName joins whichever name parts are present, falling back
+// when the creator has no name on record
+func creatorDisplayName(firstName, lastName *string) string {
+	var parts []string
+	for _, part := range []*string{firstName, lastName} {
+		if part != nil && strings.TrimSpace(*part) != "" {
+			parts = append(parts, strings.TrimSpace(*part))
+		}
+	}
+	if len(parts) == 0 {
+		return "Unknown Creator"
+	}
+	return strings.Join(parts, " ")
+}
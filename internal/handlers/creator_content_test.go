@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreatorContentEffectiveness(t *testing.T) {
+	fake, db := newFakeDB(t)
+	ada, grace, alan, anon := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	// Grace's average rests on too few scored items to report, but still
+	// counts toward the school baseline: (80*4 + 60*2 + 80*3) / 9
+	fake.rows([]string{"FROM content ct", "content_with_metrics"},
+		[]string{"creator_id", "first_name", "last_name", "content_count", "total_views", "avg_effectiveness", "content_with_metrics"},
+		[]driver.Value{grace.String(), "Grace", "Hopper", int64(2), int64(30), 60.0, int64(2)},
+		[]driver.Value{alan.String(), "Alan", "Turing", int64(3), int64(90), 80.0, int64(3)},
+		[]driver.Value{anon.String(), nil, " ", int64(1), int64(0), nil, int64(0)},
+		[]driver.Value{ada.String(), "Ada", "Lovelace", int64(5), int64(120), 80.0, int64(4)})
+	h := NewReportingHandler(db)
+
+	target := "/reports/creator-content-effectiveness?school_id=" + uuid.NewString() + "&date_from=2024-03-01&date_to=2024-03-31"
+	w := testRequest(h.GetCreatorContentEffectiveness, "/reports/creator-content-effectiveness", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	baseline := body["school_baseline"].(map[string]interface{})
+	if baseline["avg_effectiveness"] != 75.56 || baseline["content_count"] != 9.0 || baseline["creator_count"] != 4.0 {
+		t.Errorf("school_baseline = %v, want 75.56 over 9 items from 4 creators", baseline)
+	}
+
+	want := []struct {
+		name     string
+		rank     float64
+		avg      interface{}
+		vs       interface{}
+		withheld bool
+	}{
+		{"Ada Lovelace", 1, 80.0, 4.44, false},
+		{"Alan Turing", 1, 80.0, 4.44, false},
+		{"Grace Hopper", 3, nil, nil, true},
+		{"Unknown Creator", 3, nil, nil, false},
+	}
+	creators := body["creators"].([]interface{})
+	if len(creators) != len(want) {
+		t.Fatalf("got %d creators, want %d", len(creators), len(want))
+	}
+	for i, w := range want {
+		creator := creators[i].(map[string]interface{})
+		if creator["creator_name"] != w.name || creator["rank"] != w.rank || creator["avg_effectiveness"] != w.avg ||
+			creator["vs_school_baseline"] != w.vs || creator["insufficient_sample"] != w.withheld {
+			t.Errorf("creators[%d] = %v, want %+v", i, creator, w)
+		}
+	}
+
+	// Filtering to one creator keeps their school-wide rank
+	w = testRequest(h.GetCreatorContentEffectiveness, "/reports/creator-content-effectiveness", http.MethodGet, target+"&creator_id="+alan.String(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	creators = decodeBody(t, w)["creators"].([]interface{})
+	if len(creators) != 1 || creators[0].(map[string]interface{})["creator_id"] != alan.String() || creators[0].(map[string]interface{})["rank"] != 1.0 {
+		t.Errorf("filtered creators = %v, want only Alan at rank 1", creators)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	bits := func(values ...int) uint64 {
+		var set uint64
+		for _, v := range values {
+			set |= 1 << uint(v)
+		}
+		return set
+	}
+	span := func(lo, hi int) uint64 {
+		var set uint64
+		for v := lo; v <= hi; v++ {
+			set |= 1 << uint(v)
+		}
+		return set
+	}
+	tests := []struct {
+		spec string
+		want cronSchedule
+	}{
+		{"@hourly", cronSchedule{minute: bits(0), hour: span(0, 23), dom: span(1, 31),
+			month: span(1, 12), dow: span(0, 6), domStar: true, dowStar: true}},
+		{"@weekly", cronSchedule{minute: bits(0), hour: bits(0), dom: span(1, 31),
+			month: span(1, 12), dow: bits(0), domStar: true}},
+		{"*/15 9-17/4 1,15 */5 1-5", cronSchedule{minute: bits(0, 15, 30, 45), hour: bits(9, 13, 17),
+			dom: bits(1, 15), month: bits(1, 6, 11), dow: span(1, 5)}},
+		{"30 2 * * 7", cronSchedule{minute: bits(30), hour: bits(2), dom: span(1, 31),
+			month: span(1, 12), dow: bits(0), domStar: true}},
+		{"0 0 * * 5-7", cronSchedule{minute: bits(0), hour: bits(0), dom: span(1, 31),
+			month: span(1, 12), dow: bits(0, 5, 6), domStar: true}},
+		{"0 12 */2 * */3", cronSchedule{minute: bits(0), hour: bits(12),
+			dom:   bits(1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31),
+			month: span(1, 12), dow: bits(0, 3, 6), domStar: true, dowStar: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("parseCron: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("parseCron = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCronRejects(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+	} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	from := time.Date(2026, 3, 4, 10, 17, 30, 0, time.UTC) // a Wednesday
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"17 10 * * *", time.Date(2026, 3, 5, 10, 17, 0, 0, time.UTC)},
+		{"0 9 * * 1", time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 20th or any Friday, whichever is first
+		{"0 0 20 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 5 * 1", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		// A stepped "*" day of week leaves day of month in charge
+		{"0 0 20 * */2", time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := parseCron(tt.spec)
+			if err != nil {
+				t.Fatalf("parseCron: %v", err)
+			}
+			if got := schedule.next(from); !got.Equal(tt.want) {
+				t.Errorf("next = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
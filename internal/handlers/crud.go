@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"reporting-framework/internal/models"
 
@@ -18,10 +20,52 @@ func NewCRUDHandler(db *gorm.DB) *CRUDHandler {
 	return &CRUDHandler{db: db}
 }
 
+// Page sizes for GET /schools?with_stats=true
+const (
+	defaultSchoolStatsLimit = 50
+	maxSchoolStatsLimit     = 200
+)
+
+// SchoolWithStats is a school with the counts shown on the superadmin dashboard
+type SchoolWithStats struct {
+	models.School
+	ClassroomCount       int      `json:"classroom_count"`
+	UserCount            int      `json:"user_count"`
+	LatestWeekEngagement *float64 `json:"latest_week_engagement"` // average classroom engagement over the last 7 days
+}
+
 // School CRUD operations
 func (h *CRUDHandler) GetSchools(c *gin.Context) {
+	if c.Query("with_stats") == "true" {
+		h.getSchoolsWithStats(c)
+		return
+	}
+
+	p, err := parsePage(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	var total int64
+	if err := h.db.Model(&models.School{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to count schools",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
 	var schools []models.School
-	if err := h.db.Find(&schools).Error; err != nil {
+	if err := h.db.Order("name ASC, id ASC").Limit(p.Limit).Offset(p.Offset).Find(&schools).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
 				"code":    "DATABASE_ERROR",
@@ -32,7 +76,69 @@ func (h *CRUDHandler) GetSchools(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, schools)
+	c.JSON(http.StatusOK, gin.H{"schools": schools, "pagination": p.envelope(total)})
+}
+
+// getSchoolsWithStats lists a page of schools by name with classroom and
+// user counts and last week's engagement, computed in one grouped query
+func (h *CRUDHandler) getSchoolsWithStats(c *gin.Context) {
+	p, err := parsePage(c, defaultSchoolStatsLimit, maxSchoolStatsLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
+	var total int64
+	if err := h.db.Model(&models.School{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to count schools",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	weekStart := time.Now().AddDate(0, 0, -6).Format("2006-01-02")
+	schools := []SchoolWithStats{}
+	err = h.db.Table("schools").
+		Select(`
+			schools.*,
+			COALESCE(cl.classroom_count, 0) as classroom_count,
+			COALESCE(u.user_count, 0) as user_count,
+			e.latest_week_engagement
+		`).
+		Joins("LEFT JOIN (SELECT school_id, COUNT(*) as classroom_count FROM classrooms GROUP BY school_id) cl ON cl.school_id = schools.id").
+		Joins("LEFT JOIN (SELECT school_id, COUNT(*) as user_count FROM users GROUP BY school_id) u ON u.school_id = schools.id").
+		Joins(`LEFT JOIN (
+			SELECT c.school_id, AVG(ca.average_engagement_score) as latest_week_engagement
+			FROM classroom_analytics ca
+			JOIN classrooms c ON c.id = ca.classroom_id
+			WHERE ca.date >= ?
+			GROUP BY c.school_id
+		) e ON e.school_id = schools.id`, weekStart).
+		Order("schools.name ASC, schools.id ASC").
+		Limit(p.Limit).
+		Offset(p.Offset).
+		Scan(&schools).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve school stats",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"schools": schools, "pagination": p.envelope(total)})
 }
 
 func (h *CRUDHandler) GetSchool(c *gin.Context) {
@@ -101,15 +207,41 @@ func (h *CRUDHandler) CreateSchool(c *gin.Context) {
 
 // User CRUD operations
 func (h *CRUDHandler) GetUsers(c *gin.Context) {
+	p, err := parsePage(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
 	var users []models.User
 	role := c.Query("role")
 
-	query := h.db.Preload("School")
-	if role != "" {
-		query = query.Where("role = ?", role)
+	filtered := func() *gorm.DB {
+		query := h.db.Model(&models.User{})
+		if role != "" {
+			query = query.Where("role = ?", role)
+		}
+		return query
+	}
+
+	var total int64
+	if err := filtered().Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to count users",
+				"details": err.Error(),
+			},
+		})
+		return
 	}
 
-	if err := query.Find(&users).Error; err != nil {
+	if err := filtered().Preload("School").Order("last_name ASC, first_name ASC, id ASC").Limit(p.Limit).Offset(p.Offset).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
 				"code":    "DATABASE_ERROR",
@@ -120,7 +252,7 @@ func (h *CRUDHandler) GetUsers(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, gin.H{"users": users, "pagination": p.envelope(total)})
 }
 
 func (h *CRUDHandler) GetUser(c *gin.Context) {
@@ -192,17 +324,43 @@ func (h *CRUDHandler) CreateUser(c *gin.Context) {
 
 // Classroom CRUD operations
 func (h *CRUDHandler) GetClassrooms(c *gin.Context) {
+	p, err := parsePage(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": err.Error(),
+			},
+		})
+		return
+	}
+
 	var classrooms []models.Classroom
 	schoolID := c.Query("school_id")
 
-	query := h.db.Preload("School").Preload("Teacher")
-	if schoolID != "" {
-		if id, err := uuid.Parse(schoolID); err == nil {
-			query = query.Where("school_id = ?", id)
+	filtered := func() *gorm.DB {
+		query := h.db.Model(&models.Classroom{})
+		if schoolID != "" {
+			if id, err := uuid.Parse(schoolID); err == nil {
+				query = query.Where("school_id = ?", id)
+			}
 		}
+		return query
+	}
+
+	var total int64
+	if err := filtered().Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to count classrooms",
+				"details": err.Error(),
+			},
+		})
+		return
 	}
 
-	if err := query.Find(&classrooms).Error; err != nil {
+	if err := filtered().Preload("School").Preload("Teacher").Order("name ASC, id ASC").Limit(p.Limit).Offset(p.Offset).Find(&classrooms).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
 				"code":    "DATABASE_ERROR",
@@ -213,7 +371,7 @@ func (h *CRUDHandler) GetClassrooms(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, classrooms)
+	c.JSON(http.StatusOK, gin.H{"classrooms": classrooms, "pagination": p.envelope(total)})
 }
 
 func (h *CRUDHandler) GetClassroom(c *gin.Context) {
@@ -266,6 +424,29 @@ func (h *CRUDHandler) CreateClassroom(c *gin.Context) {
 		return
 	}
 
+	if classroom.TeacherID != uuid.Nil {
+		if err := validateClassroomTeacher(h.db, classroom.SchoolID, classroom.TeacherID); err != nil {
+			var assignmentErr *teacherAssignmentError
+			if errors.As(err, &assignmentErr) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error": map[string]interface{}{
+						"code":    "VALIDATION_ERROR",
+						"message": assignmentErr.Error(),
+					},
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": map[string]interface{}{
+					"code":    "DATABASE_ERROR",
+					"message": "Failed to verify teacher",
+					"details": err.Error(),
+				},
+			})
+			return
+		}
+	}
+
 	if err := h.db.Create(&classroom).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": map[string]interface{}{
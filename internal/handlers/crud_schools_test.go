@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetSchoolsWithStats(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "schools"`, "count(*)"}, []string{"count"}, []driver.Value{int64(3)})
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	fake.rows([]string{`FROM "schools"`, "classroom_count", "user_count"},
+		[]string{"id", "name", "district", "region", "timezone", "created_at", "updated_at", "classroom_count", "user_count", "latest_week_engagement"},
+		[]driver.Value{uuid.NewString(), "Hillside", "North", "East", "UTC", now, now, int64(4), int64(120), 71.5},
+		[]driver.Value{uuid.NewString(), "New School", "North", "East", "UTC", now, now, int64(0), int64(0), nil})
+	h := NewCRUDHandler(db)
+
+	w := testRequest(h.GetSchools, "/schools", http.MethodGet, "/schools?with_stats=true&limit=500&offset=1", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	pagination := body["pagination"].(map[string]interface{})
+	if pagination["limit"] != float64(maxSchoolStatsLimit) || pagination["offset"] != 1.0 || pagination["total"] != 3.0 {
+		t.Errorf("pagination = %v, want the limit capped at %d", pagination, maxSchoolStatsLimit)
+	}
+	schools := body["schools"].([]interface{})
+	if len(schools) != 2 {
+		t.Fatalf("got %d schools, want 2", len(schools))
+	}
+	first, second := schools[0].(map[string]interface{}), schools[1].(map[string]interface{})
+	if first["name"] != "Hillside" || first["classroom_count"] != 4.0 || first["user_count"] != 120.0 || first["latest_week_engagement"] != 71.5 {
+		t.Errorf("first school = %v, want its counts and engagement", first)
+	}
+	if second["classroom_count"] != 0.0 || second["latest_week_engagement"] != nil {
+		t.Errorf("second school = %v, want zero counts and null engagement", second)
+	}
+
+	ran := fake.ran("classroom_count")
+	weekStart := time.Now().AddDate(0, 0, -6).Format("2006-01-02")
+	if len(ran) != 1 || !argsContain(ran[0].Args, weekStart) || !containsAll(ran[0].SQL, []string{"ORDER BY schools.name ASC", "LIMIT 200", "OFFSET 1"}) {
+		t.Errorf("stats statements = %v, want one paged query over the last 7 days", ran)
+	}
+}
+
+func TestGetSchoolsWithStatsRejectsPaging(t *testing.T) {
+	for _, target := range []string{"/schools?with_stats=true&limit=0", "/schools?with_stats=true&limit=x", "/schools?with_stats=true&offset=-1"} {
+		fake, db := newFakeDB(t)
+		w := testRequest(NewCRUDHandler(db).GetSchools, "/schools", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+		if len(fake.ran()) != 0 {
+			t.Errorf("%s reached the database", target)
+		}
+	}
+}
+
+func TestGetSchoolsWithoutStats(t *testing.T) {
+	fake, db := newFakeDB(t)
+	w := testRequest(NewCRUDHandler(db).GetSchools, "/schools", http.MethodGet, "/schools", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if len(fake.ran("classroom_count")) != 0 {
+		t.Error("plain listing computed stats")
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultDimensionValuesLimit is the number of values returned when no limit is given
+	defaultDimensionValuesLimit = 100
+	// maxDimensionValuesLimit caps results so high-cardinality dimensions can't dump a table
+	maxDimensionValuesLimit = 1000
+)
+
+// tableAliasPattern matches a leading "alias." qualifier in a dimension's SQL
+var tableAliasPattern = regexp.MustCompile(`^([a-z_][a-z0-9_]*)\.`)
+
+// likeEscaper escapes LIKE wildcards so a prefix filter matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// DimensionValues returns up to limit distinct non-null values of a string or
+// number dimension, optionally restricted to values starting with prefix.
+// The second return value reports whether more values exist beyond the limit.
+func (q *GenericQueryBuilder) DimensionValues(dimension, prefix string, limit int) ([]interface{}, bool, error) {
+	def, exists := q.GetSchema().Dimensions[dimension]
+	if !exists {
+		return nil, false, fmt.Errorf("unknown dimension %s", dimension)
+	}
+	if def.Type == "time" {
+		return nil, false, fmt.Errorf("time dimension %s has no discrete values", dimension)
+	}
+
+	// Dimensions such as "c.subject" expect their table under that alias
+	from := def.Table
+	if m := tableAliasPattern.FindStringSubmatch(def.SQL); m != nil {
+		from = fmt.Sprintf("%s %s", def.Table, m[1])
+	}
+
+	query := q.db.Table(from).
+		Select(fmt.Sprintf("DISTINCT %s AS value", def.SQL)).
+		Where(fmt.Sprintf("%s IS NOT NULL", def.SQL))
+	if prefix != "" {
+		query = query.Where(fmt.Sprintf("CAST(%s AS TEXT) ILIKE ?", def.SQL), likeEscaper.Replace(prefix)+"%")
+	}
+
+	// Fetch one extra row to detect truncation
+	var rows []struct{ Value interface{} }
+	if err := query.Order("value ASC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to list dimension values: %w", err)
+	}
+
+	truncated := len(rows) > limit
+	if truncated {
+		rows = rows[:limit]
+	}
+	values := make([]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = row.Value
+	}
+	return values, truncated, nil
+}
+
+// GetDimensionValues lists distinct values for a schema dimension, for filter dropdowns
+func (h *ReportingHandler) GetDimensionValues(c *gin.Context) {
+	dimension := c.Query("dimension")
+	if dimension == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dimension is required"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDimensionValuesLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	limit = min(limit, maxDimensionValuesLimit)
+
+	queryBuilder := h.queryBuilder()
+	def, exists := queryBuilder.GetSchema().Dimensions[dimension]
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown dimension %s", dimension)})
+		return
+	}
+	if def.Type == "time" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("time dimension %s has no discrete values", dimension)})
+		return
+	}
+
+	values, truncated, err := queryBuilder.DimensionValues(dimension, c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list dimension values", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dimension": dimension,
+		"values":    values,
+		"limit":     limit,
+		"truncated": truncated,
+	})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultAnomalyWindow is the number of prior days used as the baseline
+	defaultAnomalyWindow = 7
+	// defaultAnomalyThreshold is how many standard deviations below the mean count as a drop
+	defaultAnomalyThreshold = 2.0
+	// minAnomalyBaselineDays is the fewest prior days needed before a day can be flagged
+	minAnomalyBaselineDays = 3
+	// minEngagementStdDev floors the baseline deviation so near-constant series
+	// don't flag trivial dips (engagement scores are on a 0-100 scale)
+	minEngagementStdDev = 2.0
+)
+
+// EngagementAnomaly is a day where a classroom's engagement fell well below its recent baseline
+type EngagementAnomaly struct {
+	Date         string  `json:"date"`
+	Expected     float64 `json:"expected"`
+	Actual       float64 `json:"actual"`
+	StdDev       float64 `json:"std_dev"`
+	Deviations   float64 `json:"deviations"`
+	LowVariance  bool    `json:"low_variance"`
+	BaselineDays int     `json:"baseline_days"`
+}
+
+// ClassroomAnomalies groups the flagged days for one classroom
+type ClassroomAnomalies struct {
+	ClassroomID   uuid.UUID           `json:"classroom_id"`
+	ClassroomName string              `json:"classroom_name"`
+	Anomalies     []EngagementAnomaly `json:"anomalies"`
+}
+
+// dailyEngagement is one day of a classroom's engagement series
+type dailyEngagement struct {
+	Date  time.Time
+	Score float64
+}
+
+// GetEngagementAnomalies flags days where a classroom's engagement dropped more
+// than `threshold` standard deviations below its trailing `window`-day mean
+func (h *ReportingHandler) GetEngagementAnomalies(c *gin.Context) {
+	schoolID, err := uuid.Parse(c.Query("school_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required and must be a valid UUID"})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", strconv.Itoa(defaultAnomalyWindow)))
+	if err != nil || window < minAnomalyBaselineDays || window > 90 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be an integer between 3 and 90"})
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(c.DefaultQuery("threshold", strconv.FormatFloat(defaultAnomalyThreshold, 'f', -1, 64)), 64)
+	if err != nil || threshold <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be a positive number of standard deviations"})
+		return
+	}
+
+	// Load the baseline window before date_from so the first days can be evaluated
+	var rows []struct {
+		ClassroomID     uuid.UUID
+		ClassroomName   string
+		Date            time.Time
+		EngagementScore float64
+	}
+	err = h.db.Table("daily_classroom_metrics dcm").
+		Select("dcm.classroom_id, c.name as classroom_name, dcm.date, dcm.engagement_score").
+		Joins("JOIN classrooms c ON c.id = dcm.classroom_id").
+		Where("dcm.school_id = ? AND dcm.date BETWEEN ? AND ?", schoolID, dateFrom.AddDate(0, 0, -window), dateTo).
+		Order("dcm.classroom_id, dcm.date ASC").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load engagement series", "details": err.Error()})
+		return
+	}
+
+	var results []ClassroomAnomalies
+	series := []dailyEngagement{}
+	for i, row := range rows {
+		series = append(series, dailyEngagement{Date: row.Date, Score: row.EngagementScore})
+
+		// Rows are ordered by classroom, so a change of classroom ends the series
+		if i == len(rows)-1 || rows[i+1].ClassroomID != row.ClassroomID {
+			if anomalies := detectEngagementDrops(series, window, threshold, dateFrom); len(anomalies) > 0 {
+				results = append(results, ClassroomAnomalies{
+					ClassroomID:   row.ClassroomID,
+					ClassroomName: row.ClassroomName,
+					Anomalies:     anomalies,
+				})
+			}
+			series = []dailyEngagement{}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"school_id":  schoolID,
+		"period":     gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"window":     window,
+		"threshold":  threshold,
+		"classrooms": results,
+	})
+}
+
+// detectEngagementDrops compares each day on or after evaluateFrom against the
+// mean and standard deviation of the prior `window` days of the series. Days
+// with fewer than minAnomalyBaselineDays of history are skipped, and the
+// deviation is floored at minEngagementStdDev so flat series stay quiet.
+func detectEngagementDrops(series []dailyEngagement, window int, threshold float64, evaluateFrom time.Time) []EngagementAnomaly {
+	var anomalies []EngagementAnomaly
+	evaluateFrom = evaluateFrom.Truncate(24 * time.Hour)
+
+	for i, day := range series {
+		if day.Date.Before(evaluateFrom) {
+			continue
+		}
+
+		// Baseline is the prior `window` calendar days; missing days simply aren't counted
+		var baseline []float64
+		windowStart := day.Date.AddDate(0, 0, -window)
+		for j := i - 1; j >= 0 && !series[j].Date.Before(windowStart); j-- {
+			baseline = append(baseline, series[j].Score)
+		}
+		if len(baseline) < minAnomalyBaselineDays {
+			continue
+		}
+
+		mean := meanOf(baseline)
+		stdDev := math.Sqrt(sampleVariance(baseline))
+		lowVariance := stdDev < minEngagementStdDev
+		if lowVariance {
+			stdDev = minEngagementStdDev
+		}
+
+		deviations := (mean - day.Score) / stdDev
+		if deviations > threshold {
+			anomalies = append(anomalies, EngagementAnomaly{
+				Date:         day.Date.Format(DateFormat),
+				Expected:     math.Round(mean*100) / 100,
+				Actual:       day.Score,
+				StdDev:       math.Round(stdDev*100) / 100,
+				Deviations:   math.Round(deviations*100) / 100,
+				LowVariance:  lowVariance,
+				BaselineDays: len(baseline),
+			})
+		}
+	}
+
+	return anomalies
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// engagementSeries builds one day per score starting at start; a negative
+// score leaves that day out of the series
+func engagementSeries(start time.Time, scores ...float64) []dailyEngagement {
+	var series []dailyEngagement
+	for i, score := range scores {
+		if score >= 0 {
+			series = append(series, dailyEngagement{Date: start.AddDate(0, 0, i), Score: score})
+		}
+	}
+	return series
+}
+
+func TestDetectEngagementDrops(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("flags a drop below the baseline", func(t *testing.T) {
+		series := engagementSeries(start, 60, 80, 65, 75, 70, 40, 70)
+		anomalies := detectEngagementDrops(series, 7, 2, start)
+		if len(anomalies) != 1 {
+			t.Fatalf("anomalies = %+v, want one", anomalies)
+		}
+		got := anomalies[0]
+		if got.Date != "2024-03-06" || got.Expected != 70 || got.Actual != 40 || got.BaselineDays != 5 || got.LowVariance {
+			t.Errorf("anomaly = %+v", got)
+		}
+	})
+
+	t.Run("floors the deviation of a flat series", func(t *testing.T) {
+		quiet := detectEngagementDrops(engagementSeries(start, 50, 50, 50, 50, 47), 7, 2, start)
+		if len(quiet) != 0 {
+			t.Errorf("a 3 point dip in a flat series was flagged: %+v", quiet)
+		}
+		loud := detectEngagementDrops(engagementSeries(start, 50, 50, 50, 50, 45), 7, 2, start)
+		if len(loud) != 1 || !loud[0].LowVariance || loud[0].StdDev != minEngagementStdDev {
+			t.Errorf("anomalies = %+v, want a low-variance drop", loud)
+		}
+	})
+
+	t.Run("needs enough baseline days", func(t *testing.T) {
+		if anomalies := detectEngagementDrops(engagementSeries(start, 80, 80, 10), 7, 2, start); len(anomalies) != 0 {
+			t.Errorf("flagged with a two-day baseline: %+v", anomalies)
+		}
+		// Days outside the window don't count toward the baseline
+		gap := engagementSeries(start, 80, 80, 80, -1, -1, -1, -1, -1, -1, 10)
+		if anomalies := detectEngagementDrops(gap, 3, 2, start); len(anomalies) != 0 {
+			t.Errorf("flagged against a stale baseline: %+v", anomalies)
+		}
+	})
+
+	t.Run("only evaluates days in the period", func(t *testing.T) {
+		series := engagementSeries(start, 70, 70, 70, 10, 70)
+		if anomalies := detectEngagementDrops(series, 7, 2, start.AddDate(0, 0, 4)); len(anomalies) != 0 {
+			t.Errorf("flagged a baseline day: %+v", anomalies)
+		}
+	})
+}
+
+func TestGetEngagementAnomaliesValidation(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	for _, target := range []string{
+		"/anomalies",
+		"/anomalies?school_id=00000000-0000-0000-0000-000000000001&window=2",
+		"/anomalies?school_id=00000000-0000-0000-0000-000000000001&threshold=0",
+	} {
+		w := testRequest(h.GetEngagementAnomalies, "/anomalies", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+	}
+}
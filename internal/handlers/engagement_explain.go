@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Engagement score components, in the order they are reported
+const (
+	EngagementParticipation   = "participation"
+	EngagementSessionDuration = "session_duration"
+	EngagementQuizCompletion  = "quiz_completion"
+	EngagementCollaboration   = "collaboration"
+)
+
+// EngagementScoring configures how classroom engagement components are
+// normalized to 0-100 and weighted into the engagement score
+type EngagementScoring struct {
+	Weights map[string]float64 `json:"weights"`
+	// Average session length that normalizes to 100
+	TargetSessionMinutes float64 `json:"target_session_minutes"`
+	// Daily sync and sharing events per active student that normalize to 100
+	TargetCollaborationPerStudent float64 `json:"target_collaboration_per_student"`
+}
+
+// DefaultEngagementScoring weights participation most heavily
+var DefaultEngagementScoring = EngagementScoring{
+	Weights: map[string]float64{
+		EngagementParticipation:   0.40,
+		EngagementSessionDuration: 0.25,
+		EngagementQuizCompletion:  0.20,
+		EngagementCollaboration:   0.15,
+	},
+	TargetSessionMinutes:          45,
+	TargetCollaborationPerStudent: 2,
+}
+
+var engagementComponents = []string{
+	EngagementParticipation,
+	EngagementSessionDuration,
+	EngagementQuizCompletion,
+	EngagementCollaboration,
+}
+
+// ParseEngagementWeights parses comma-separated component=weight pairs over
+// DefaultEngagementScoring; components left out keep their default weight
+func ParseEngagementWeights(s string) (EngagementScoring, error) {
+	scoring := DefaultEngagementScoring
+	scoring.Weights = make(map[string]float64, len(DefaultEngagementScoring.Weights))
+	for component, weight := range DefaultEngagementScoring.Weights {
+		scoring.Weights[component] = weight
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		component, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return scoring, fmt.Errorf("expected component=weight, got %q", pair)
+		}
+		component = strings.TrimSpace(component)
+		if _, known := scoring.Weights[component]; !known {
+			return scoring, fmt.Errorf("unknown engagement component %q", component)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return scoring, fmt.Errorf("weight for %s must be a non-negative number", component)
+		}
+		scoring.Weights[component] = weight
+	}
+	return scoring, nil
+}
+
+// SetEngagementScoring configures the classroom engagement scoring. Weights
+// are normalized to sum to 1, so they only need to be relative.
+func (h *ReportingHandler) SetEngagementScoring(scoring EngagementScoring) error {
+	var total float64
+	for _, component := range engagementComponents {
+		weight := scoring.Weights[component]
+		if weight < 0 {
+			return fmt.Errorf("weight for %s must not be negative", component)
+		}
+		total += weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("engagement weights must not all be zero")
+	}
+	if scoring.TargetSessionMinutes <= 0 || scoring.TargetCollaborationPerStudent <= 0 {
+		return fmt.Errorf("engagement normalization targets must be positive")
+	}
+
+	normalized := scoring
+	normalized.Weights = make(map[string]float64, len(engagementComponents))
+	for _, component := range engagementComponents {
+		normalized.Weights[component] = scoring.Weights[component] / total
+	}
+	h.engagementScoring = normalized
+	return nil
+}
+
+// EngagementComponent is one term of the engagement score
+type EngagementComponent struct {
+	Component    string   `json:"component"`
+	RawValue     *float64 `json:"raw_value"` // null when the period has no data for it
+	Unit         string   `json:"unit"`
+	Normalized   float64  `json:"normalized"`
+	Weight       float64  `json:"weight"`
+	Contribution float64  `json:"contribution"`
+}
+
+// GetClassroomEngagementExplain recomputes a classroom's engagement score over
+// the period from daily_classroom_metrics and returns each component's raw
+// value, normalized value, weight and contribution. Contributions are rounded
+// first and summed, so they always add up to the reported score.
+func (h *ReportingHandler) GetClassroomEngagementExplain(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var raw struct {
+		Days                  int
+		Participation         *float64
+		SessionMinutes        *float64
+		QuizCompletion        *float64
+		CollaborationPerDay   *float64
+		StoredEngagementScore *float64
+	}
+	err = h.db.Table("daily_classroom_metrics").
+		Select(`
+			COUNT(*) as days,
+			AVG(participation_rate) as participation,
+			AVG(avg_session_duration_minutes) as session_minutes,
+			AVG(avg_quiz_completion_rate) FILTER (WHERE total_quiz_sessions > 0) as quiz_completion,
+			SUM(sync_events_count + content_shared_count)::float /
+				NULLIF(SUM(active_students_count), 0) as collaboration_per_day,
+			AVG(engagement_score) as stored_engagement_score
+		`).
+		Where("classroom_id = ? AND date BETWEEN ? AND ?", classroomID, dateFrom, dateTo).
+		Scan(&raw).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch classroom metrics", "details": err.Error()})
+		return
+	}
+
+	scoring := h.engagementScoring
+	components := []EngagementComponent{
+		explainComponent(EngagementParticipation, raw.Participation, "percent", 100, scoring),
+		explainComponent(EngagementSessionDuration, raw.SessionMinutes, "minutes", scoring.TargetSessionMinutes, scoring),
+		explainComponent(EngagementQuizCompletion, raw.QuizCompletion, "percent", 100, scoring),
+		explainComponent(EngagementCollaboration, raw.CollaborationPerDay, "events_per_active_student_day", scoring.TargetCollaborationPerStudent, scoring),
+	}
+
+	var score float64
+	for _, component := range components {
+		score += component.Contribution
+	}
+
+	response := gin.H{
+		"classroom_id":     classroomID,
+		"period":           gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"days_with_data":   raw.Days,
+		"engagement_score": roundTo(score, 2),
+		"components":       components,
+		"scoring":          scoring,
+	}
+	// The aggregated engagement_score comes from the metrics pipeline and may
+	// use a different formula; it is shown so the two can be compared
+	if raw.StoredEngagementScore != nil {
+		response["stored_engagement_score"] = roundTo(*raw.StoredEngagementScore, 2)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// explainComponent normalizes value against target (capped at 100) and weights
+// it. A missing value normalizes to 0.
+func explainComponent(name string, value *float64, unit string, target float64, scoring EngagementScoring) EngagementComponent {
+	component := EngagementComponent{
+		Component: name,
+		Unit:      unit,
+		Weight:    roundTo(scoring.Weights[name], 4),
+	}
+	if value != nil {
+		rawValue := roundTo(*value, 2)
+		component.RawValue = &rawValue
+
+		normalized := *value / target * 100
+		if normalized > 100 {
+			normalized = 100
+		}
+		if normalized < 0 {
+			normalized = 0
+		}
+		component.Normalized = roundTo(normalized, 2)
+	}
+	component.Contribution = roundTo(component.Normalized*scoring.Weights[name], 2)
+	return component
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseEngagementWeights(t *testing.T) {
+	scoring, err := ParseEngagementWeights("participation=2, collaboration=0")
+	if err != nil {
+		t.Fatalf("ParseEngagementWeights: %v", err)
+	}
+	if scoring.Weights[EngagementParticipation] != 2 || scoring.Weights[EngagementCollaboration] != 0 ||
+		scoring.Weights[EngagementSessionDuration] != DefaultEngagementScoring.Weights[EngagementSessionDuration] {
+		t.Errorf("weights = %v, want participation and collaboration overridden", scoring.Weights)
+	}
+	if DefaultEngagementScoring.Weights[EngagementParticipation] != 0.40 {
+		t.Error("ParseEngagementWeights changed the defaults")
+	}
+
+	for _, s := range []string{"participation", "attendance=1", "participation=-1", "participation=lots"} {
+		if _, err := ParseEngagementWeights(s); err == nil {
+			t.Errorf("ParseEngagementWeights(%q) succeeded", s)
+		}
+	}
+}
+
+func TestSetEngagementScoringNormalizesWeights(t *testing.T) {
+	h := NewReportingHandler(nil)
+	scoring, _ := ParseEngagementWeights("participation=2,session_duration=1,quiz_completion=1,collaboration=0")
+	if err := h.SetEngagementScoring(scoring); err != nil {
+		t.Fatalf("SetEngagementScoring: %v", err)
+	}
+	if got := h.engagementScoring.Weights[EngagementParticipation]; got != 0.5 {
+		t.Errorf("participation weight = %v, want 0.5", got)
+	}
+
+	zero, _ := ParseEngagementWeights("participation=0,session_duration=0,quiz_completion=0,collaboration=0")
+	if err := h.SetEngagementScoring(zero); err == nil {
+		t.Error("all-zero weights were accepted")
+	}
+}
+
+func TestClassroomEngagementExplainSumsToScore(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "daily_classroom_metrics"`, "collaboration_per_day"},
+		[]string{"days", "participation", "session_minutes", "quiz_completion", "collaboration_per_day", "stored_engagement_score"},
+		[]driver.Value{int64(5), 80.0, 30.0, nil, 3.0, 58.123})
+	h := NewReportingHandler(db)
+
+	target := "/classrooms/" + uuid.NewString() + "/engagement/explain?date_from=2024-03-01&date_to=2024-03-07"
+	w := testRequest(h.GetClassroomEngagementExplain, "/classrooms/:id/engagement/explain", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	// participation 80% x 0.40, 30 of 45 minutes x 0.25, no quizzes, and
+	// collaboration over its target capped at 100 x 0.15
+	want := map[string][2]float64{
+		EngagementParticipation:   {80, 32},
+		EngagementSessionDuration: {66.67, 16.67},
+		EngagementQuizCompletion:  {0, 0},
+		EngagementCollaboration:   {100, 15},
+	}
+	var sum float64
+	for _, raw := range body["components"].([]interface{}) {
+		component := raw.(map[string]interface{})
+		name := component["component"].(string)
+		if got := [2]float64{component["normalized"].(float64), component["contribution"].(float64)}; got != want[name] {
+			t.Errorf("%s normalized, contribution = %v, want %v", name, got, want[name])
+		}
+		if name == EngagementQuizCompletion && component["raw_value"] != nil {
+			t.Errorf("quiz_completion raw_value = %v, want null without quizzes", component["raw_value"])
+		}
+		sum += component["contribution"].(float64)
+	}
+	score := body["engagement_score"].(float64)
+	if score != 63.67 || math.Abs(sum-score) > 1e-9 {
+		t.Errorf("engagement_score = %v, contributions sum to %v, want both 63.67", score, sum)
+	}
+	if body["stored_engagement_score"] != 58.12 {
+		t.Errorf("stored_engagement_score = %v, want 58.12", body["stored_engagement_score"])
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"time"
+)
+
+const (
+	// maxForecastDays caps how far ahead engagement is projected
+	maxForecastDays = 30
+	// minForecastHistory is the fewest historical days needed to fit a trend
+	minForecastHistory = 7
+	// forecastZ is the z value for the ~95% confidence band
+	forecastZ = 1.96
+)
+
+// EngagementTrendPoint is one day of the engagement trend, historical or projected
+type EngagementTrendPoint struct {
+	Date                time.Time `json:"date"`
+	AvgEngagement       float64   `json:"avg_engagement"`
+	TotalActiveStudents int       `json:"total_active_students"`
+	Projected           bool      `json:"projected"`
+	Lower               *float64  `json:"lower,omitempty"`
+	Upper               *float64  `json:"upper,omitempty"`
+}
+
+// EngagementForecast describes the trend fitted to the history
+type EngagementForecast struct {
+	HorizonDays    int     `json:"horizon_days"`
+	SlopePerDay    float64 `json:"slope_per_day"`
+	ResidualStdErr float64 `json:"residual_std_err"`
+	Confidence     float64 `json:"confidence"`
+	HistoryDays    int     `json:"history_days"`
+}
+
+// forecastEngagement fits a linear trend to the daily history and projects it
+// `horizon` days past the last observed date. The band around each projection is
+// a prediction interval from the regression residuals. It returns nil when the
+// history is too short to fit.
+func forecastEngagement(history []EngagementTrendPoint, horizon int) ([]EngagementTrendPoint, *EngagementForecast) {
+	if horizon <= 0 || len(history) < minForecastHistory {
+		return nil, nil
+	}
+
+	// x is days since the first observation, so gaps in the history are respected
+	origin := history[0].Date
+	xs := make([]float64, len(history))
+	ys := make([]float64, len(history))
+	for i, point := range history {
+		xs[i] = point.Date.Sub(origin).Hours() / 24
+		ys[i] = point.AvgEngagement
+	}
+
+	fit, ok := fitLinear(xs, ys)
+	if !ok {
+		return nil, nil
+	}
+
+	last := history[len(history)-1].Date
+	projected := make([]EngagementTrendPoint, 0, horizon)
+	for day := 1; day <= horizon; day++ {
+		date := last.AddDate(0, 0, day)
+		x := date.Sub(origin).Hours() / 24
+		value := fit.predict(x)
+		margin := fit.predictionMargin(x, forecastZ)
+
+		// Engagement can't go negative
+		lower, upper := roundTo(max(0, value-margin), 2), roundTo(max(0, value+margin), 2)
+		projected = append(projected, EngagementTrendPoint{
+			Date:          date,
+			AvgEngagement: roundTo(max(0, value), 2),
+			Projected:     true,
+			Lower:         &lower,
+			Upper:         &upper,
+		})
+	}
+
+	return projected, &EngagementForecast{
+		HorizonDays:    horizon,
+		SlopePerDay:    roundTo(fit.Slope, 4),
+		ResidualStdErr: roundTo(fit.ResidualStdErr, 4),
+		Confidence:     0.95,
+		HistoryDays:    len(history),
+	}
+}
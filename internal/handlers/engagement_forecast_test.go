@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+// trendHistory builds daily history points from start with the given values
+func trendHistory(start time.Time, values ...float64) []EngagementTrendPoint {
+	history := make([]EngagementTrendPoint, len(values))
+	for i, v := range values {
+		history[i] = EngagementTrendPoint{Date: start.AddDate(0, 0, i), AvgEngagement: v}
+	}
+	return history
+}
+
+func TestForecastEngagement(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("projects a linear trend", func(t *testing.T) {
+		history := trendHistory(start, 50, 52, 54, 56, 58, 60, 62)
+		projected, forecast := forecastEngagement(history, 3)
+		if forecast == nil || forecast.SlopePerDay != 2 || forecast.ResidualStdErr != 0 || forecast.HistoryDays != 7 {
+			t.Fatalf("forecast = %+v", forecast)
+		}
+		if len(projected) != 3 {
+			t.Fatalf("got %d projected days, want 3", len(projected))
+		}
+		first := projected[0]
+		if !first.Date.Equal(start.AddDate(0, 0, 7)) || first.AvgEngagement != 64 || !first.Projected || *first.Lower != 64 || *first.Upper != 64 {
+			t.Errorf("first projection = %+v", first)
+		}
+	})
+
+	t.Run("widens the band with noise", func(t *testing.T) {
+		projected, _ := forecastEngagement(trendHistory(start, 50, 55, 48, 57, 52, 60, 54), 5)
+		near, far := projected[0], projected[4]
+		if *near.Lower >= near.AvgEngagement || *near.Upper <= near.AvgEngagement {
+			t.Errorf("band %v-%v does not contain %v", *near.Lower, *near.Upper, near.AvgEngagement)
+		}
+		if *far.Upper-*far.Lower <= *near.Upper-*near.Lower {
+			t.Error("band should widen further from the history")
+		}
+	})
+
+	t.Run("clamps at zero", func(t *testing.T) {
+		projected, _ := forecastEngagement(trendHistory(start, 30, 25, 20, 15, 10, 5, 1), 5)
+		last := projected[len(projected)-1]
+		if last.AvgEngagement != 0 || *last.Lower != 0 {
+			t.Errorf("last projection = %+v, want it clamped at 0", last)
+		}
+	})
+
+	t.Run("respects gaps in the history", func(t *testing.T) {
+		history := trendHistory(start, 10, 11, 12, 13, 14, 15, 16)
+		history[6].Date = start.AddDate(0, 0, 7)
+		history[6].AvgEngagement = 17
+		projected, _ := forecastEngagement(history, 1)
+		if !projected[0].Date.Equal(start.AddDate(0, 0, 8)) || projected[0].AvgEngagement < 17.5 {
+			t.Errorf("projection = %+v, want the day after the last observation", projected[0])
+		}
+	})
+
+	t.Run("needs enough history", func(t *testing.T) {
+		if projected, forecast := forecastEngagement(trendHistory(start, 1, 2, 3), 5); projected != nil || forecast != nil {
+			t.Error("expected no forecast from three days")
+		}
+		if projected, forecast := forecastEngagement(trendHistory(start, 1, 2, 3, 4, 5, 6, 7), 0); projected != nil || forecast != nil {
+			t.Error("expected no forecast for a zero horizon")
+		}
+	})
+}
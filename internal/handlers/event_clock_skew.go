@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+// Actions for event timestamps outside the accepted clock skew
+const (
+	ClockSkewReject = "reject" // reject the batch with a 400 listing the events
+	ClockSkewClamp  = "clamp"  // store the events with the server's receive time
+)
+
+// EventClockSkewPolicy bounds how far an ingested event's timestamp may be
+// from the server's receive time (the event's created_at) before the client
+// clock is assumed wrong. A zero bound disables that side of the check.
+type EventClockSkewPolicy struct {
+	MaxFuture time.Duration
+	MaxAge    time.Duration
+	Action    string
+}
+
+// DefaultEventClockSkewPolicy clamps events stamped more than 5 minutes in
+// the future. Old timestamps are accepted unless MaxAge is configured, since
+// clients legitimately backfill historical events.
+var DefaultEventClockSkewPolicy = EventClockSkewPolicy{
+	MaxFuture: 5 * time.Minute,
+	Action:    ClockSkewClamp,
+}
+
+// maxClockSkewErrors is the number of skewed events listed in a 400 response
+const maxClockSkewErrors = 20
+
+// SetEventClockSkewPolicy configures how IngestEvents treats events whose
+// timestamps suggest a wrong client clock
+func (h *ReportingHandler) SetEventClockSkewPolicy(policy EventClockSkewPolicy) error {
+	if policy.Action != ClockSkewReject && policy.Action != ClockSkewClamp {
+		return fmt.Errorf("clock skew action must be %s or %s, got %q", ClockSkewReject, ClockSkewClamp, policy.Action)
+	}
+	if policy.MaxFuture < 0 || policy.MaxAge < 0 {
+		return fmt.Errorf("clock skew bounds must not be negative")
+	}
+	h.clockSkew = policy
+	return nil
+}
+
+// skew describes how far an event's timestamp is outside the policy bounds,
+// or returns "" when it is within them
+func (p EventClockSkewPolicy) skew(event reporting.Event) string {
+	offset := event.Timestamp.Sub(event.CreatedAt)
+	switch {
+	case p.MaxFuture > 0 && offset > p.MaxFuture:
+		return fmt.Sprintf("timestamp is %s ahead of server time, at most %s is allowed", offset.Round(time.Second), p.MaxFuture)
+	case p.MaxAge > 0 && -offset > p.MaxAge:
+		return fmt.Sprintf("timestamp is %s behind server time, at most %s is allowed", (-offset).Round(time.Second), p.MaxAge)
+	}
+	return ""
+}
+
+// applyClockSkewPolicy checks every event's timestamp against its created_at.
+// Under the clamp action skewed timestamps are replaced with created_at and
+// counted; under reject nothing changes and the skewed events are listed.
+func (h *ReportingHandler) applyClockSkewPolicy(events []reporting.Event) (adjusted int, problems []string) {
+	for i := range events {
+		reason := h.clockSkew.skew(events[i])
+		if reason == "" {
+			continue
+		}
+		if h.clockSkew.Action == ClockSkewClamp {
+			events[i].Timestamp = events[i].CreatedAt
+			adjusted++
+			continue
+		}
+		if len(problems) < maxClockSkewErrors {
+			problems = append(problems, fmt.Sprintf("events[%d]: %s", i, reason))
+		}
+	}
+	return adjusted, problems
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+func TestSetEventClockSkewPolicy(t *testing.T) {
+	h := NewReportingHandler(nil)
+	for _, policy := range []EventClockSkewPolicy{
+		{Action: "drop"},
+		{Action: ""},
+		{MaxFuture: -time.Minute, Action: ClockSkewClamp},
+		{MaxAge: -time.Minute, Action: ClockSkewReject},
+	} {
+		if err := h.SetEventClockSkewPolicy(policy); err == nil {
+			t.Errorf("SetEventClockSkewPolicy(%+v) succeeded", policy)
+		}
+	}
+	if h.clockSkew != DefaultEventClockSkewPolicy {
+		t.Errorf("rejected policy replaced the default: %+v", h.clockSkew)
+	}
+
+	policy := EventClockSkewPolicy{MaxAge: time.Hour, Action: ClockSkewReject}
+	if err := h.SetEventClockSkewPolicy(policy); err != nil || h.clockSkew != policy {
+		t.Errorf("SetEventClockSkewPolicy(%+v) = %v, policy %+v", policy, err, h.clockSkew)
+	}
+}
+
+func TestEventClockSkew(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	policy := EventClockSkewPolicy{MaxFuture: 5 * time.Minute, MaxAge: 24 * time.Hour}
+	tests := []struct {
+		name   string
+		policy EventClockSkewPolicy
+		offset time.Duration
+		want   string
+	}{
+		{"on time", policy, 0, ""},
+		{"slightly ahead", policy, 5 * time.Minute, ""},
+		{"too far ahead", policy, 6 * time.Minute, "6m0s ahead"},
+		{"slightly behind", policy, -24 * time.Hour, ""},
+		{"too far behind", policy, -25 * time.Hour, "25h0m0s behind"},
+		{"no age bound", EventClockSkewPolicy{MaxFuture: time.Minute}, -365 * 24 * time.Hour, ""},
+		{"no future bound", EventClockSkewPolicy{MaxAge: time.Hour}, 365 * 24 * time.Hour, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.skew(reporting.Event{Timestamp: now.Add(tt.offset), CreatedAt: now})
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("skew = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyClockSkewPolicy(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	events := func() []reporting.Event {
+		return []reporting.Event{
+			{Timestamp: now.Add(-time.Minute), CreatedAt: now},
+			{Timestamp: now.Add(time.Hour), CreatedAt: now},
+			{Timestamp: now.Add(2 * time.Hour), CreatedAt: now},
+		}
+	}
+	h := NewReportingHandler(nil)
+
+	clamped := events()
+	adjusted, problems := h.applyClockSkewPolicy(clamped)
+	if adjusted != 2 || problems != nil {
+		t.Fatalf("clamp = %d, %v, want 2 adjusted", adjusted, problems)
+	}
+	if !clamped[0].Timestamp.Equal(now.Add(-time.Minute)) || !clamped[1].Timestamp.Equal(now) || !clamped[2].Timestamp.Equal(now) {
+		t.Errorf("clamped timestamps = %v, %v, %v", clamped[0].Timestamp, clamped[1].Timestamp, clamped[2].Timestamp)
+	}
+
+	h.clockSkew.Action = ClockSkewReject
+	rejected := events()
+	adjusted, problems = h.applyClockSkewPolicy(rejected)
+	if adjusted != 0 || len(problems) != 2 || !strings.HasPrefix(problems[0], "events[1]: ") || !strings.HasPrefix(problems[1], "events[2]: ") {
+		t.Fatalf("reject = %d, %v, want events 1 and 2 listed", adjusted, problems)
+	}
+	if !rejected[1].Timestamp.Equal(now.Add(time.Hour)) {
+		t.Error("reject changed a timestamp")
+	}
+
+	many := make([]reporting.Event, maxClockSkewErrors+5)
+	for i := range many {
+		many[i] = reporting.Event{Timestamp: now.Add(time.Hour), CreatedAt: now}
+	}
+	if _, problems = h.applyClockSkewPolicy(many); len(problems) != maxClockSkewErrors {
+		t.Errorf("listed %d problems, want %d", len(problems), maxClockSkewErrors)
+	}
+}
+
+func TestIngestEventsRejectsSkewedTimestamps(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	if err := h.SetEventClockSkewPolicy(EventClockSkewPolicy{MaxFuture: 5 * time.Minute, Action: ClockSkewReject}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := fmt.Sprintf(`{"events":[{"event_type":"page_view","timestamp":%q},{"event_type":"page_view","timestamp":%q}]}`,
+		time.Now().Format(time.RFC3339), time.Now().Add(time.Hour).Format(time.RFC3339))
+	w := testRequest(h.IngestEvents, "/events", http.MethodPost, "/events", body, map[string]interface{}{"user_id": uuid.New()})
+	expectStatus(t, w, http.StatusBadRequest)
+	details := decodeBody(t, w)["details"].([]interface{})
+	if len(details) != 1 || !strings.HasPrefix(details[0].(string), "events[1]: ") {
+		t.Errorf("details = %v, want the future event listed", details)
+	}
+	if len(fake.ran()) != 0 {
+		t.Error("a rejected batch reached the database")
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+// SetEventDedupWindow enables server-side deduplication of ingested events.
+// An event is dropped when another event with the same user, type and session
+// has a timestamp within window of it. Zero (the default) disables deduplication
+// so legitimate rapid-fire events are never dropped.
+func (h *ReportingHandler) SetEventDedupWindow(window time.Duration) {
+	h.eventDedupWindow = window
+}
+
+// dedupKey identifies events that may be replays of each other
+type dedupKey struct {
+	userID    uuid.UUID
+	eventType string
+	sessionID uuid.UUID // uuid.Nil when the event has no session
+}
+
+func eventDedupKey(event reporting.Event) (dedupKey, bool) {
+	if event.UserID == nil {
+		return dedupKey{}, false
+	}
+	key := dedupKey{userID: *event.UserID, eventType: event.EventType}
+	if event.SessionID != nil {
+		key.sessionID = *event.SessionID
+	}
+	return key, true
+}
+
+// dropDuplicateEvents removes events that duplicate an already stored event or
+// an earlier event in the same batch, returning the kept events and the number dropped.
+// Events without a user are never deduplicated.
+func (h *ReportingHandler) dropDuplicateEvents(events []reporting.Event) ([]reporting.Event, int, error) {
+	if h.eventDedupWindow <= 0 || len(events) == 0 {
+		return events, 0, nil
+	}
+
+	seen, err := h.recentEventTimestamps(events)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	kept := make([]reporting.Event, 0, len(events))
+	for _, event := range events {
+		key, ok := eventDedupKey(event)
+		if !ok {
+			kept = append(kept, event)
+			continue
+		}
+		if withinWindow(seen[key], event.Timestamp, h.eventDedupWindow) {
+			continue
+		}
+		seen[key] = append(seen[key], event.Timestamp)
+		kept = append(kept, event)
+	}
+
+	return kept, len(events) - len(kept), nil
+}
+
+// recentEventTimestamps loads stored events that could collide with the batch,
+// keyed by (user, type, session)
+func (h *ReportingHandler) recentEventTimestamps(events []reporting.Event) (map[dedupKey][]time.Time, error) {
+	seen := make(map[dedupKey][]time.Time)
+
+	var userIDs []uuid.UUID
+	var from, to time.Time
+	for _, event := range events {
+		if event.UserID == nil {
+			continue
+		}
+		userIDs = append(userIDs, *event.UserID)
+		if from.IsZero() || event.Timestamp.Before(from) {
+			from = event.Timestamp
+		}
+		if event.Timestamp.After(to) {
+			to = event.Timestamp
+		}
+	}
+	if len(userIDs) == 0 {
+		return seen, nil
+	}
+
+	var existing []reporting.Event
+	err := h.db.Select("user_id, event_type, session_id, timestamp").
+		Where("user_id IN ? AND timestamp BETWEEN ? AND ?", userIDs, from.Add(-h.eventDedupWindow), to.Add(h.eventDedupWindow)).
+		Find(&existing).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range existing {
+		if key, ok := eventDedupKey(event); ok {
+			seen[key] = append(seen[key], event.Timestamp)
+		}
+	}
+	return seen, nil
+}
+
+// withinWindow reports whether t is within window of any of the timestamps
+func withinWindow(timestamps []time.Time, t time.Time, window time.Duration) bool {
+	for _, existing := range timestamps {
+		diff := t.Sub(existing)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= window {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+func TestDropDuplicateEvents(t *testing.T) {
+	user, other := uuid.New(), uuid.New()
+	session := uuid.New()
+	at := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	event := func(userID *uuid.UUID, eventType string, sessionID *uuid.UUID, offset time.Duration) reporting.Event {
+		return reporting.Event{ID: uuid.New(), UserID: userID, EventType: eventType, SessionID: sessionID, Timestamp: at.Add(offset)}
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+		events := []reporting.Event{event(&user, "click", nil, 0), event(&user, "click", nil, 0)}
+
+		kept, dropped, err := h.dropDuplicateEvents(events)
+		if err != nil || len(kept) != 2 || dropped != 0 {
+			t.Errorf("dropDuplicateEvents = %d kept, %d dropped, %v", len(kept), dropped, err)
+		}
+		if len(fake.ran()) != 0 {
+			t.Error("looked up stored events with deduplication disabled")
+		}
+	})
+
+	t.Run("drops replays within the window", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows([]string{`FROM "events"`, "user_id IN"}, []string{"user_id", "event_type", "session_id", "timestamp"},
+			[]driver.Value{user.String(), "submit", session.String(), at.Add(-time.Second)})
+		h := NewReportingHandler(db)
+		h.SetEventDedupWindow(2 * time.Second)
+
+		events := []reporting.Event{
+			event(&user, "submit", &session, 0),                      // replays the stored event
+			event(&user, "submit", nil, 0),                           // different session
+			event(&user, "click", nil, 0),                            // kept
+			event(&user, "click", nil, time.Second),                  // replays the previous one
+			event(&user, "click", nil, 5*time.Second),                // outside the window
+			event(&other, "click", nil, 0),                           // different user
+			event(nil, "click", nil, 0), event(nil, "click", nil, 0), // no user, never dropped
+		}
+		kept, dropped, err := h.dropDuplicateEvents(events)
+		if err != nil {
+			t.Fatalf("dropDuplicateEvents: %v", err)
+		}
+		if dropped != 2 || len(kept) != 6 {
+			t.Fatalf("kept %d, dropped %d, want 6 and 2", len(kept), dropped)
+		}
+		for _, e := range kept {
+			if e.ID == events[0].ID || e.ID == events[3].ID {
+				t.Errorf("kept replayed event %+v", e)
+			}
+		}
+
+		lookups := fake.ran(`FROM "events"`)
+		if len(lookups) != 1 {
+			t.Fatalf("ran %d lookups, want 1", len(lookups))
+		}
+		args := lookups[0].Args
+		if from, to := args[len(args)-2], args[len(args)-1]; from != at.Add(-2*time.Second) || to != at.Add(7*time.Second) {
+			t.Errorf("lookup window = %v to %v, want the batch widened by the window", from, to)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+// IdempotencyKeyHeader names a whole event batch. Events without their own
+// client_event_id are keyed by the header and their position in the batch,
+// so retrying the identical batch is a no-op.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// eventInsertBatchSize is the number of events stored per INSERT
+const eventInsertBatchSize = 100
+
+// maxClientEventIDLength matches the events.client_event_id column
+const maxClientEventIDLength = 100
+
+// clientEventID returns the idempotency key for the event at index in a
+// batch: its own client_event_id, else one derived from the batch key
+func clientEventID(data reporting.EventData, batchKey string, index int) (*string, error) {
+	var id string
+	switch {
+	case data.ClientEventID != nil:
+		id = *data.ClientEventID
+	case batchKey != "":
+		id = fmt.Sprintf("%s:%d", batchKey, index)
+	default:
+		return nil, nil
+	}
+	if id == "" || len(id) > maxClientEventIDLength {
+		return nil, fmt.Errorf("idempotency key for event %d must be 1-%d characters", index, maxClientEventIDLength)
+	}
+	return &id, nil
+}
+
+// replayKey identifies an event by its user and client-supplied id
+type replayKey struct {
+	userID        uuid.UUID
+	clientEventID string
+}
+
+func eventReplayKey(event reporting.Event) (replayKey, bool) {
+	if event.UserID == nil || event.ClientEventID == nil {
+		return replayKey{}, false
+	}
+	return replayKey{userID: *event.UserID, clientEventID: *event.ClientEventID}, true
+}
+
+// dropReplayedEvents removes events whose idempotency key was already stored,
+// or used earlier in the same batch. It returns the kept events and, for
+// every event in the batch, the id it was stored under: the original event's
+// id for replays and its own id otherwise.
+func (h *ReportingHandler) dropReplayedEvents(events []reporting.Event) ([]reporting.Event, []uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	var userIDs []uuid.UUID
+	var clientEventIDs []string
+	for _, event := range events {
+		if key, ok := eventReplayKey(event); ok {
+			userIDs = append(userIDs, key.userID)
+			clientEventIDs = append(clientEventIDs, key.clientEventID)
+		}
+	}
+	if len(clientEventIDs) == 0 {
+		return events, ids, nil
+	}
+
+	var existing []reporting.Event
+	err := h.db.Select("id, user_id, client_event_id").
+		Where("user_id IN ? AND client_event_id IN ?", userIDs, clientEventIDs).
+		Find(&existing).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	stored := make(map[replayKey]uuid.UUID, len(existing))
+	for _, event := range existing {
+		if key, ok := eventReplayKey(event); ok {
+			stored[key] = event.ID
+		}
+	}
+
+	kept := make([]reporting.Event, 0, len(events))
+	for i, event := range events {
+		key, ok := eventReplayKey(event)
+		if !ok {
+			kept = append(kept, event)
+			continue
+		}
+		if original, replayed := stored[key]; replayed {
+			ids[i] = original
+			continue
+		}
+		stored[key] = event.ID
+		kept = append(kept, event)
+	}
+	return kept, ids, nil
+}
+
+// insertEvents stores events in one transaction and returns the ones actually
+// inserted. An event whose idempotency key a concurrent retry stored first
+// is skipped by the unique index.
+func (h *ReportingHandler) insertEvents(events []reporting.Event) ([]reporting.Event, error) {
+	inserted := make([]reporting.Event, 0, len(events))
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(events); start += eventInsertBatchSize {
+			batch := events[start:min(start+eventInsertBatchSize, len(events))]
+
+			// gorm counts rows with a preset id as affected even when the
+			// conflict skipped them, so the returned rows tell what was stored
+			returned := append([]reporting.Event(nil), batch...)
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}, clause.Returning{}).Create(&returned).Error; err != nil {
+				return err
+			}
+			stored := make(map[uuid.UUID]bool, len(returned))
+			for _, event := range returned {
+				stored[event.ID] = true
+			}
+			for _, event := range batch {
+				if stored[event.ID] {
+					inserted = append(inserted, event)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
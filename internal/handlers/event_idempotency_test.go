@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+func TestClientEventID(t *testing.T) {
+	own := "evt-7"
+	tests := []struct {
+		name     string
+		data     reporting.EventData
+		batchKey string
+		want     string
+		wantErr  bool
+	}{
+		{"own id wins", reporting.EventData{ClientEventID: &own}, "batch", "evt-7", false},
+		{"derived from the batch key", reporting.EventData{}, "batch", "batch:3", false},
+		{"no key", reporting.EventData{}, "", "", false},
+		{"too long", reporting.EventData{}, strings.Repeat("k", maxClientEventIDLength), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := clientEventID(tt.data, tt.batchKey, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("clientEventID error = %v, want error %v", err, tt.wantErr)
+			}
+			if gotValue := ""; got != nil {
+				gotValue = *got
+				if gotValue != tt.want {
+					t.Errorf("clientEventID = %q, want %q", gotValue, tt.want)
+				}
+			} else if tt.want != "" {
+				t.Errorf("clientEventID = nil, want %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestDropReplayedEvents(t *testing.T) {
+	user := uuid.New()
+	original := uuid.New()
+	key := func(s string) *string { return &s }
+	events := []reporting.Event{
+		{ID: uuid.New(), UserID: &user, ClientEventID: key("a")}, // stored by an earlier request
+		{ID: uuid.New(), UserID: &user, ClientEventID: key("b")},
+		{ID: uuid.New(), UserID: &user, ClientEventID: key("b")}, // repeated within the batch
+		{ID: uuid.New(), UserID: &user},                          // no key, always kept
+	}
+
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "events"`, "client_event_id IN"}, []string{"id", "user_id", "client_event_id"},
+		[]driver.Value{original.String(), user.String(), "a"})
+	h := NewReportingHandler(db)
+
+	kept, ids, err := h.dropReplayedEvents(events)
+	if err != nil {
+		t.Fatalf("dropReplayedEvents: %v", err)
+	}
+	if len(kept) != 2 || kept[0].ID != events[1].ID || kept[1].ID != events[3].ID {
+		t.Errorf("kept = %+v, want the first b and the unkeyed event", kept)
+	}
+	wantIDs := []uuid.UUID{original, events[1].ID, events[1].ID, events[3].ID}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("ids = %v, want replays to report the stored id %v", ids, wantIDs)
+	}
+}
+
+func TestInsertEventsSkipsConflicts(t *testing.T) {
+	events := []reporting.Event{{ID: uuid.New(), EventType: "login"}, {ID: uuid.New(), EventType: "login"}}
+	insert := []string{`INSERT INTO "events"`, "ON CONFLICT DO NOTHING RETURNING *"}
+
+	t.Run("every row inserted", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(insert, []string{"id"}, []driver.Value{events[0].ID.String()}, []driver.Value{events[1].ID.String()})
+		inserted, err := NewReportingHandler(db).insertEvents(events)
+		if err != nil || !reflect.DeepEqual(inserted, events) {
+			t.Errorf("insertEvents = %+v, %v, want both events", inserted, err)
+		}
+	})
+
+	t.Run("a concurrent retry stored one first", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(insert, []string{"id"}, []driver.Value{events[1].ID.String()})
+		inserted, err := NewReportingHandler(db).insertEvents(events)
+		if err != nil {
+			t.Fatalf("insertEvents: %v", err)
+		}
+		if len(inserted) != 1 || inserted[0].ID != events[1].ID {
+			t.Errorf("inserted = %+v, want only the second event", inserted)
+		}
+		if events[0].EventType != "login" {
+			t.Error("insertEvents modified the caller's events")
+		}
+	})
+
+	t.Run("batches larger than one insert", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		many := make([]reporting.Event, eventInsertBatchSize+1)
+		for i := range many {
+			many[i] = reporting.Event{ID: uuid.New(), EventType: "click"}
+		}
+		if _, err := NewReportingHandler(db).insertEvents(many); err != nil {
+			t.Fatalf("insertEvents: %v", err)
+		}
+		if n := len(fake.ran(insert...)); n != 2 {
+			t.Errorf("ran %d inserts, want 2", n)
+		}
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+const (
+	// maxImportBytes caps the size of an uploaded event file
+	maxImportBytes = 10 << 20
+	// importBatchSize is the number of events inserted per statement
+	importBatchSize = 500
+)
+
+// requiredImportColumns must appear in the header row of an event import
+var requiredImportColumns = []string{"event_type", "timestamp"}
+
+// ImportRowError describes why a single row of an event import was rejected
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportEvents bulk-ingests events from a CSV or TSV upload.
+// The file may be sent as the "file" multipart field or as the raw request body.
+// Valid rows are stored; invalid rows are reported back by line number.
+func (h *ReportingHandler) ImportEvents(c *gin.Context) {
+	delimiter, err := parseDelimiter(c.DefaultQuery("delimiter", ","))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	schoolID, _ := c.Get("school_id")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing upload field 'file'", "details": err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	reader := csv.NewReader(body)
+	reader.Comma = delimiter
+	// A whitespace delimiter would be trimmed away too, dropping empty fields
+	reader.TrimLeadingSpace = !unicode.IsSpace(delimiter)
+
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read header row", "details": err.Error()})
+		return
+	}
+	columns, err := importColumnIndex(header)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var events []reporting.Event
+	var eventIDs []uuid.UUID
+	var rowErrors []ImportRowError
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload", "details": err.Error()})
+				return
+			}
+			rowErrors = append(rowErrors, ImportRowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		event, rowErr := parseImportRow(record, columns)
+		if rowErr != nil {
+			rowErr.Row, _ = reader.FieldPos(0)
+			rowErrors = append(rowErrors, *rowErr)
+			continue
+		}
+
+		// Fall back to the caller's context like the JSON ingestion endpoint
+		if event.UserID == nil {
+			if uid, ok := userID.(uuid.UUID); ok {
+				event.UserID = &uid
+			}
+		}
+		if sid, ok := schoolID.(uuid.UUID); ok {
+			event.SchoolID = &sid
+		}
+
+		events = append(events, event)
+	}
+
+	events, deduplicated, err := h.dropDuplicateEvents(events)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicate events", "details": err.Error()})
+		return
+	}
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+	}
+
+	if len(events) == 0 && deduplicated > 0 && len(rowErrors) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"success":            true,
+			"processed_count":    0,
+			"failed_count":       0,
+			"deduplicated_count": deduplicated,
+			"errors":             rowErrors,
+		})
+		return
+	}
+
+	if len(events) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "No valid rows to import",
+			"errors": rowErrors,
+		})
+		return
+	}
+
+	if err := h.db.CreateInBatches(events, importBatchSize).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store events", "details": err.Error()})
+		return
+	}
+
+	go h.updateAggregatedMetrics(c.GetString("request_id"), events)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success":            len(rowErrors) == 0,
+		"processed_count":    len(events),
+		"failed_count":       len(rowErrors),
+		"deduplicated_count": deduplicated,
+		"event_ids":          eventIDs,
+		"errors":             rowErrors,
+	})
+}
+
+// parseDelimiter accepts "comma", "tab", a literal "\t", or any single character
+func parseDelimiter(value string) (rune, error) {
+	switch strings.ToLower(value) {
+	case ",", "comma", "csv":
+		return ',', nil
+	case "\t", `\t`, "tab", "tsv":
+		return '\t', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", value)
+	}
+	return runes[0], nil
+}
+
+// importColumnIndex maps header names to their positions and checks that
+// the required columns are present
+func importColumnIndex(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name != "" {
+			columns[name] = i
+		}
+	}
+	for _, required := range requiredImportColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header row is missing required column %q", required)
+		}
+	}
+	return columns, nil
+}
+
+// parseImportRow converts one record into an Event, validating every field
+func parseImportRow(record []string, columns map[string]int) (reporting.Event, *ImportRowError) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	optionalUUID := func(name string) (*uuid.UUID, *ImportRowError) {
+		value := field(name)
+		if value == "" {
+			return nil, nil
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, &ImportRowError{Column: name, Message: "invalid UUID"}
+		}
+		return &id, nil
+	}
+
+	event := reporting.Event{
+		ID:        uuid.New(),
+		EventType: field("event_type"),
+		CreatedAt: time.Now(),
+	}
+	if event.EventType == "" {
+		return event, &ImportRowError{Column: "event_type", Message: "event_type is required"}
+	}
+
+	timestamp := field("timestamp")
+	if timestamp == "" {
+		return event, &ImportRowError{Column: "timestamp", Message: "timestamp is required"}
+	}
+	ts, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return event, &ImportRowError{Column: "timestamp", Message: "timestamp must be RFC 3339, e.g. 2024-01-15T09:30:00Z"}
+	}
+	event.Timestamp = ts
+
+	var rowErr *ImportRowError
+	if event.UserID, rowErr = optionalUUID("user_id"); rowErr != nil {
+		return event, rowErr
+	}
+	if event.SessionID, rowErr = optionalUUID("session_id"); rowErr != nil {
+		return event, rowErr
+	}
+	if event.ClassroomID, rowErr = optionalUUID("classroom_id"); rowErr != nil {
+		return event, rowErr
+	}
+
+	if application := field("application"); application != "" {
+		event.Application = &application
+	}
+
+	return event, nil
+}
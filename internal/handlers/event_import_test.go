@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr bool
+	}{
+		{",", ',', false},
+		{"csv", ',', false},
+		{"TAB", '\t', false},
+		{`\t`, '\t', false},
+		{";", ';', false},
+		{`"`, 0, true},
+		{"||", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDelimiter(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDelimiter(%q) = %q, %v, want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestImportColumnIndex(t *testing.T) {
+	columns, err := importColumnIndex([]string{"\ufeffEvent_Type", " timestamp ", "user_id"})
+	if err != nil {
+		t.Fatalf("importColumnIndex: %v", err)
+	}
+	if columns["event_type"] != 0 || columns["timestamp"] != 1 || columns["user_id"] != 2 {
+		t.Errorf("columns = %v", columns)
+	}
+
+	if _, err := importColumnIndex([]string{"event_type", "user_id"}); err == nil {
+		t.Error("expected an error for a header without timestamp")
+	}
+}
+
+func TestImportEvents(t *testing.T) {
+	userID := uuid.New()
+	values := map[string]interface{}{"user_id": userID}
+
+	t.Run("stores valid rows and reports invalid ones", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+		body := "event_type\ttimestamp\tuser_id\n" +
+			"login\t2024-01-15T09:30:00Z\t\n" +
+			"\t2024-01-15T09:31:00Z\t\n" +
+			"logout\tyesterday\t\n" +
+			"logout\t2024-01-15T10:00:00Z\tnot-a-uuid\n"
+
+		w := testRequest(h.ImportEvents, "/events/import", http.MethodPost, "/events/import?delimiter=tab", body, values)
+		expectStatus(t, w, http.StatusCreated)
+		resp := decodeBody(t, w)
+
+		if resp["processed_count"] != 1.0 || resp["failed_count"] != 3.0 || resp["success"] != false {
+			t.Fatalf("response = %v, want 1 stored and 3 failed", resp)
+		}
+		wantErrors := []struct {
+			row    float64
+			column string
+		}{{3, "event_type"}, {4, "timestamp"}, {5, "user_id"}}
+		rowErrors := resp["errors"].([]interface{})
+		for i, want := range wantErrors {
+			got := rowErrors[i].(map[string]interface{})
+			if got["row"] != want.row || got["column"] != want.column {
+				t.Errorf("errors[%d] = %v, want row %v column %s", i, got, want.row, want.column)
+			}
+		}
+
+		inserts := fake.ran(`INSERT INTO "events"`)
+		if len(inserts) != 1 {
+			t.Fatalf("ran %d event inserts, want 1", len(inserts))
+		}
+		found := false
+		for _, arg := range inserts[0].Args {
+			if id, ok := arg.(*uuid.UUID); ok && id != nil && *id == userID {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("insert args %v do not fall back to the caller's user_id", inserts[0].Args)
+		}
+	})
+
+	t.Run("no valid rows", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.ImportEvents, "/events/import", http.MethodPost, "/events/import", "event_type,timestamp\nlogin,\n", values)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+
+	t.Run("missing required column", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.ImportEvents, "/events/import", http.MethodPost, "/events/import", "event_type\nlogin\n", values)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+}
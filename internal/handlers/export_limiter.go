@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default export limits: exports running at once per instance, and exports
+// allowed to wait for a slot before further ones are rejected
+const (
+	DefaultExportConcurrency = 4
+	DefaultExportQueueSize   = 8
+)
+
+// ExportQueuePositionHeader tells a client how many exports were ahead of its
+// request when it had to wait for a slot
+const ExportQueuePositionHeader = "X-Export-Queue-Position"
+
+var errExportQueueFull = errors.New("export queue is full")
+
+// exportLimiter caps concurrent report exports and queues the overflow in
+// arrival order, up to a fixed queue length
+type exportLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	maxQueue int
+	active   int
+	queue    []chan struct{}
+}
+
+func newExportLimiter(limit, maxQueue int) *exportLimiter {
+	return &exportLimiter{limit: limit, maxQueue: maxQueue}
+}
+
+// acquire takes an export slot, waiting in the queue if all are busy. queued
+// is called with the 1-based queue position before waiting. It fails with
+// errExportQueueFull when the queue is full, or with the context's error when
+// ctx ends first.
+func (l *exportLimiter) acquire(ctx context.Context, queued func(position int)) error {
+	l.mu.Lock()
+	if l.active < l.limit && len(l.queue) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return nil
+	}
+	if len(l.queue) >= l.maxQueue {
+		l.mu.Unlock()
+		return errExportQueueFull
+	}
+	ready := make(chan struct{})
+	l.queue = append(l.queue, ready)
+	position := len(l.queue)
+	l.mu.Unlock()
+
+	queued(position)
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for i, waiter := range l.queue {
+			if waiter == ready {
+				l.queue = append(l.queue[:i], l.queue[i+1:]...)
+				l.mu.Unlock()
+				return ctx.Err()
+			}
+		}
+		l.mu.Unlock()
+		// The slot was handed over just as the client left; pass it on
+		l.release()
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, handing it straight to the longest-waiting export
+func (l *exportLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.queue) > 0 {
+		next := l.queue[0]
+		l.queue = l.queue[1:]
+		close(next)
+		return
+	}
+	l.active--
+}
+
+// SetExportLimits caps report exports at concurrency running at once, with up
+// to queueSize more waiting; further exports get a 429. A concurrency below 1
+// removes the limit.
+func (h *ReportingHandler) SetExportLimits(concurrency, queueSize int) {
+	if concurrency < 1 {
+		h.exportLimiter = nil
+		return
+	}
+	h.exportLimiter = newExportLimiter(concurrency, max(queueSize, 0))
+}
+
+// limitExports holds the requests isExport picks out, such as report file
+// exports or streamed query results, to the export limits. Waiting requests
+// give up their place when the client disconnects.
+func (h *ReportingHandler) limitExports(isExport func(*gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := h.exportLimiter
+		if limiter == nil || !isExport(c) {
+			c.Next()
+			return
+		}
+
+		err := limiter.acquire(c.Request.Context(), func(position int) {
+			c.Header(ExportQueuePositionHeader, strconv.Itoa(position))
+		})
+		if errors.Is(err, errExportQueueFull) {
+			c.Header("Retry-After", "5")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many exports in progress, try again shortly"})
+			return
+		}
+		if err != nil {
+			// The client is gone; there is nobody to answer
+			c.Abort()
+			return
+		}
+		defer limiter.release()
+		c.Next()
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// waitQueued acquires a slot in the background and returns once the request
+// is queued, with a channel that receives acquire's result
+func waitQueued(t *testing.T, l *exportLimiter, ctx context.Context, wantPosition int) chan error {
+	t.Helper()
+	queued := make(chan int, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- l.acquire(ctx, func(position int) { queued <- position })
+	}()
+	select {
+	case position := <-queued:
+		if position != wantPosition {
+			t.Errorf("queue position = %d, want %d", position, wantPosition)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("export was not queued")
+	}
+	return done
+}
+
+func TestExportLimiterQueuesInOrder(t *testing.T) {
+	l := newExportLimiter(1, 2)
+	noQueue := func(int) { t.Error("queued with a free slot") }
+	if err := l.acquire(context.Background(), noQueue); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	first := waitQueued(t, l, context.Background(), 1)
+	second := waitQueued(t, l, context.Background(), 2)
+	if err := l.acquire(context.Background(), func(int) {}); !errors.Is(err, errExportQueueFull) {
+		t.Fatalf("acquire with a full queue = %v, want errExportQueueFull", err)
+	}
+
+	l.release()
+	if err := <-first; err != nil {
+		t.Fatalf("first waiter: %v", err)
+	}
+	select {
+	case <-second:
+		t.Fatal("second waiter ran before a slot was free")
+	default:
+	}
+
+	l.release()
+	if err := <-second; err != nil {
+		t.Fatalf("second waiter: %v", err)
+	}
+	l.release()
+	if l.active != 0 || len(l.queue) != 0 {
+		t.Errorf("active = %d, queued = %d after every release", l.active, len(l.queue))
+	}
+}
+
+func TestExportLimiterCancelledWaiterLeavesQueue(t *testing.T) {
+	l := newExportLimiter(1, 2)
+	if err := l.acquire(context.Background(), func(int) {}); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := waitQueued(t, l, ctx, 1)
+	next := waitQueued(t, l, context.Background(), 2)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled waiter = %v, want context.Canceled", err)
+	}
+
+	l.release()
+	if err := <-next; err != nil {
+		t.Fatalf("next waiter: %v", err)
+	}
+	if l.active != 1 || len(l.queue) != 0 {
+		t.Errorf("active = %d, queued = %d, want the slot passed to the remaining waiter", l.active, len(l.queue))
+	}
+}
+
+func TestLimitExports(t *testing.T) {
+	h := NewReportingHandler(nil)
+	h.SetExportLimits(1, 0)
+	started, release := make(chan struct{}), make(chan struct{})
+	router := gin.New()
+	router.GET("/report", h.limitExports(wantsReportExport), func(c *gin.Context) {
+		if wantsReportExport(c) {
+			close(started)
+			<-release
+		}
+		c.Status(http.StatusOK)
+	})
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	running := make(chan *httptest.ResponseRecorder)
+	go func() { running <- serve("/report?format=csv") }()
+	<-started
+
+	w := serve("/report?format=csv")
+	expectStatus(t, w, http.StatusTooManyRequests)
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+	expectStatus(t, serve("/report"), http.StatusOK)
+
+	close(release)
+	expectStatus(t, <-running, http.StatusOK)
+
+	h.SetExportLimits(0, 0)
+	if h.exportLimiter != nil {
+		t.Error("a concurrency below 1 should remove the limit")
+	}
+}
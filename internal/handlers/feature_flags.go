@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Features that deployments can switch off because they are expensive to serve
+const (
+	FeatureGenericQuery     = "generic_query"     // POST /query, dimension values and saved queries
+	FeatureReportExport     = "report_export"     // format=zip, format=csv and format=pdf report exports
+	FeatureAnomalyDetection = "anomaly_detection" // engagement anomalies
+)
+
+// Features lists every feature that can be toggled
+var Features = []string{FeatureGenericQuery, FeatureReportExport, FeatureAnomalyDetection}
+
+// ParseFeatureFlags parses comma-separated feature=on|off pairs (true/false
+// and 1/0 are accepted too). Features not mentioned stay enabled.
+func ParseFeatureFlags(s string) (map[string]bool, error) {
+	flags := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("feature flag %q must be feature=on|off", entry)
+		}
+		name = strings.TrimSpace(name)
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "on", "true", "1":
+			flags[name] = true
+		case "off", "false", "0":
+			flags[name] = false
+		default:
+			return nil, fmt.Errorf("feature %s: invalid value %q", name, value)
+		}
+	}
+	return flags, nil
+}
+
+// SetFeatureFlags enables or disables features by name; unlisted features
+// keep their current state
+func (h *ReportingHandler) SetFeatureFlags(flags map[string]bool) error {
+	for name := range flags {
+		if !isFeature(name) {
+			return fmt.Errorf("unknown feature %q (expected one of %s)", name, strings.Join(Features, ", "))
+		}
+	}
+	for name, enabled := range flags {
+		if enabled {
+			delete(h.disabledFeatures, name)
+		} else {
+			h.disabledFeatures[name] = true
+		}
+	}
+	return nil
+}
+
+// FeatureFlags returns whether each feature is enabled
+func (h *ReportingHandler) FeatureFlags() map[string]bool {
+	flags := make(map[string]bool, len(Features))
+	for _, name := range Features {
+		flags[name] = !h.disabledFeatures[name]
+	}
+	return flags
+}
+
+func isFeature(name string) bool {
+	for _, feature := range Features {
+		if feature == name {
+			return true
+		}
+	}
+	return false
+}
+
+// requireFeature answers 404 when the feature is disabled, as if the endpoint
+// did not exist. With a when func, only matching requests are gated.
+func (h *ReportingHandler) requireFeature(feature string, when func(c *gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.disabledFeatures[feature] && (when == nil || when(c)) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Feature disabled", "feature": feature})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseFeatureFlags(t *testing.T) {
+	flags, err := ParseFeatureFlags(" generic_query=off, report_export=TRUE,anomaly_detection = 0 ,")
+	if err != nil {
+		t.Fatalf("ParseFeatureFlags: %v", err)
+	}
+	want := map[string]bool{FeatureGenericQuery: false, FeatureReportExport: true, FeatureAnomalyDetection: false}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("flags = %v, want %v", flags, want)
+	}
+
+	for _, s := range []string{"generic_query", "generic_query=maybe"} {
+		if _, err := ParseFeatureFlags(s); err == nil {
+			t.Errorf("ParseFeatureFlags(%q) succeeded", s)
+		}
+	}
+}
+
+func TestSetFeatureFlags(t *testing.T) {
+	h := NewReportingHandler(nil)
+	if err := h.SetFeatureFlags(map[string]bool{FeatureGenericQuery: false, "pdf_export": false}); err == nil {
+		t.Fatal("SetFeatureFlags accepted an unknown feature")
+	}
+	if !h.FeatureFlags()[FeatureGenericQuery] {
+		t.Error("a rejected call disabled a feature")
+	}
+
+	if err := h.SetFeatureFlags(map[string]bool{FeatureGenericQuery: false}); err != nil {
+		t.Fatalf("SetFeatureFlags: %v", err)
+	}
+	want := map[string]bool{FeatureGenericQuery: false, FeatureReportExport: true, FeatureAnomalyDetection: true}
+	if got := h.FeatureFlags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FeatureFlags = %v, want %v", got, want)
+	}
+	if err := h.SetFeatureFlags(map[string]bool{FeatureGenericQuery: true}); err != nil || !h.FeatureFlags()[FeatureGenericQuery] {
+		t.Errorf("re-enabling = %v, flags %v", err, h.FeatureFlags())
+	}
+}
+
+func TestDisabledFeaturesAreUnreachable(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	if err := h.SetFeatureFlags(map[string]bool{FeatureGenericQuery: false, FeatureReportExport: false}); err != nil {
+		t.Fatalf("SetFeatureFlags: %v", err)
+	}
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api"))
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	classroomReport := "/api/v1/reports/classroom-engagement?classroom_id=4d7c3bb0-5a3e-4a53-9d51-2e0a6b1c9f10&date_from=2024-01-01&date_to=2024-01-31"
+	for _, tt := range []struct {
+		method, target, body, feature string
+	}{
+		{http.MethodPost, "/api/v1/query", `{"measures":["events.count"]}`, FeatureGenericQuery},
+		{http.MethodGet, "/api/v1/query/saved", "", FeatureGenericQuery},
+		{http.MethodGet, classroomReport + "&format=csv", "", FeatureReportExport},
+	} {
+		w := serve(tt.method, tt.target, tt.body)
+		expectStatus(t, w, http.StatusNotFound)
+		if feature := decodeBody(t, w)["feature"]; feature != tt.feature {
+			t.Errorf("%s %s: feature = %v, want %s", tt.method, tt.target, feature, tt.feature)
+		}
+	}
+	if ran := fake.ran(); len(ran) != 0 {
+		t.Errorf("disabled endpoints ran %d statements", len(ran))
+	}
+
+	// The JSON report shares its route with the disabled export and stays up
+	expectStatus(t, serve(http.MethodGet, classroomReport, ""), http.StatusOK)
+	// Anomaly detection was left on and reaches its own validation
+	expectStatus(t, serve(http.MethodGet, "/api/v1/analytics/engagement-anomalies", ""), http.StatusBadRequest)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"reporting-framework/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GradeDistribution summarizes a metric across all same-grade students
+type GradeDistribution struct {
+	StudentValue *float64 `json:"student_value"`
+	GradeMean    float64  `json:"grade_mean"`
+	GradeMedian  float64  `json:"grade_median"`
+	Percentile   *float64 `json:"percentile"`
+	SampleSize   int      `json:"sample_size"`
+}
+
+// peerMetrics holds per-student aggregates used for grade comparisons
+type peerMetrics struct {
+	UserID          uuid.UUID
+	AvgQuizScore    *float64
+	AvgDailyMinutes float64
+	ActiveDays      int
+	EnrolledAt      *time.Time // earliest active enrollment
+}
+
+// GetStudentGradeComparison compares a student's engagement and quiz averages
+// against every student in the same grade level across the student's school
+func (h *ReportingHandler) GetStudentGradeComparison(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's grade comparison") {
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRange(c.Query("date_from"), c.Query("date_to"), h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Resolve school and grade level from the student's most recent active enrollment
+	var enrollment struct {
+		SchoolID   uuid.UUID
+		GradeLevel *int
+	}
+	err = h.db.Table("users u").
+		Select("u.school_id, cl.grade_level").
+		Joins("JOIN user_classrooms uc ON uc.user_id = u.id AND uc.is_active = true").
+		Joins("JOIN classrooms cl ON cl.id = uc.classroom_id").
+		Where("u.id = ? AND u.role = 'student' AND cl.grade_level IS NOT NULL", studentID).
+		Order("uc.enrolled_at DESC").
+		Limit(1).
+		Scan(&enrollment).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve grade level", "details": err.Error()})
+		return
+	}
+	if enrollment.GradeLevel == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student has no active enrollment with a grade level"})
+		return
+	}
+
+	// Aggregate every same-grade student in the school in one query
+	var peers []peerMetrics
+	err = h.db.Table("users u").
+		Select(`
+			u.id as user_id,
+			AVG(dum.avg_quiz_score) as avg_quiz_score,
+			COALESCE(AVG(dum.total_session_duration_seconds / 60.0), 0) as avg_daily_minutes,
+			COUNT(dum.date) as active_days,
+			(SELECT MIN(en.enrolled_at) FROM user_classrooms en
+			 WHERE en.user_id = u.id AND en.role = 'student' AND en.is_active = true) as enrolled_at
+		`).
+		Joins("LEFT JOIN daily_user_metrics dum ON dum.user_id = u.id AND dum.date BETWEEN ? AND ?", dateFrom, dateTo).
+		Where("u.school_id = ? AND u.role = 'student'", enrollment.SchoolID).
+		Where("u.id IN (?)", h.gradeLevelStudents(enrollment.SchoolID, *enrollment.GradeLevel)).
+		Group("u.id").
+		Scan(&peers).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute grade distribution", "details": err.Error()})
+		return
+	}
+
+	var engagementValues, quizValues []float64
+	var studentEngagement, studentQuiz *float64
+
+	for _, peer := range peers {
+		totalDays := services.EnrolledPeriodDays(dateFrom, dateTo, peer.EnrolledAt)
+		engagement := h.calculateEngagementScore(peer.AvgDailyMinutes, peer.ActiveDays, totalDays)
+		engagementValues = append(engagementValues, engagement)
+		if peer.AvgQuizScore != nil {
+			quizValues = append(quizValues, *peer.AvgQuizScore)
+		}
+
+		if peer.UserID == studentID {
+			e := engagement
+			studentEngagement = &e
+			studentQuiz = peer.AvgQuizScore
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":  studentID,
+		"school_id":   enrollment.SchoolID,
+		"grade_level": *enrollment.GradeLevel,
+		"period":      gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"engagement":  buildGradeDistribution(engagementValues, studentEngagement),
+		"quiz_score":  buildGradeDistribution(quizValues, studentQuiz),
+	})
+}
+
+// gradeLevelStudents returns a subquery selecting students actively enrolled
+// in any classroom of the given grade level within a school
+func (h *ReportingHandler) gradeLevelStudents(schoolID uuid.UUID, gradeLevel int) *gorm.DB {
+	return h.db.Table("user_classrooms uc").
+		Select("uc.user_id").
+		Joins("JOIN classrooms cl ON cl.id = uc.classroom_id").
+		Where("cl.school_id = ? AND cl.grade_level = ? AND uc.is_active = true AND uc.role = 'student'", schoolID, gradeLevel)
+}
+
+func buildGradeDistribution(values []float64, studentValue *float64) GradeDistribution {
+	dist := GradeDistribution{
+		StudentValue: studentValue,
+		GradeMean:    meanOf(values),
+		GradeMedian:  medianOf(values),
+		SampleSize:   len(values),
+	}
+	if studentValue != nil {
+		p := percentileRank(values, *studentValue)
+		dist.Percentile = &p
+	}
+	return dist
+}
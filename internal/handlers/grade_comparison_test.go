@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetStudentGradeComparison(t *testing.T) {
+	studentID := uuid.New()
+	peerID := uuid.New()
+	schoolID := uuid.New()
+	enrollment := []string{"FROM users u", "ORDER BY uc.enrolled_at DESC"}
+	peers := []string{"LEFT JOIN daily_user_metrics dum"}
+	target := "/students/" + studentID.String() + "/grade-comparison?date_from=2024-01-01&date_to=2024-01-31"
+
+	t.Run("compares against same-grade peers", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(enrollment, []string{"school_id", "grade_level"}, []driver.Value{schoolID.String(), int64(7)})
+		fake.rows(peers, []string{"user_id", "avg_quiz_score", "avg_daily_minutes", "active_days", "enrolled_at"},
+			[]driver.Value{studentID.String(), 80.0, 30.0, int64(20), nil},
+			[]driver.Value{peerID.String(), 60.0, 10.0, int64(5), nil},
+			[]driver.Value{uuid.New().String(), nil, 0.0, int64(0), nil})
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentGradeComparison, "/students/:id/grade-comparison", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+
+		if body["grade_level"] != 7.0 {
+			t.Errorf("grade_level = %v, want 7", body["grade_level"])
+		}
+		quiz := body["quiz_score"].(map[string]interface{})
+		if quiz["sample_size"] != 2.0 || quiz["grade_mean"] != 70.0 || quiz["percentile"] != 75.0 {
+			t.Errorf("quiz_score = %v, want 2 scored peers, mean 70, percentile 75", quiz)
+		}
+		engagement := body["engagement"].(map[string]interface{})
+		if engagement["sample_size"] != 3.0 || math.Abs(engagement["percentile"].(float64)-250.0/3) > 1e-9 {
+			t.Errorf("engagement = %v, want 3 peers with the student ranked highest", engagement)
+		}
+
+		ran := fake.ran(peers...)
+		if len(ran) != 1 || !containsAll(ran[0].SQL, []string{"cl.grade_level = $", "u.school_id = $"}) {
+			t.Errorf("peer query = %v, want it scoped to the school and grade level", ran)
+		}
+	})
+
+	t.Run("student without a grade level", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentGradeComparison, "/students/:id/grade-comparison", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusNotFound)
+	})
+
+	t.Run("another student's token", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		values := map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}
+		w := testRequest(h.GetStudentGradeComparison, "/students/:id/grade-comparison", http.MethodGet, target, "", values)
+		expectStatus(t, w, http.StatusForbidden)
+		if len(fake.ran()) != 0 {
+			t.Error("a forbidden request reached the database")
+		}
+	})
+
+	t.Run("invalid student id", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentGradeComparison, "/students/:id/grade-comparison", http.MethodGet, "/students/nope/grade-comparison", "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+}
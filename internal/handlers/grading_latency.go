@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// manuallyGradedTypes are question types that need a teacher to grade them
+var manuallyGradedTypes = []string{"short_answer", "essay"}
+
+// LatencyDistribution summarizes a set of latencies in hours
+type LatencyDistribution struct {
+	AvgHours    float64 `json:"avg_hours"`
+	MedianHours float64 `json:"median_hours"`
+	P90Hours    float64 `json:"p90_hours"`
+	MinHours    float64 `json:"min_hours"`
+	MaxHours    float64 `json:"max_hours"`
+}
+
+// GradingLatency is the feedback latency for one teacher in one classroom
+type GradingLatency struct {
+	TeacherID     uuid.UUID            `json:"teacher_id"`
+	TeacherName   string               `json:"teacher_name"`
+	ClassroomID   uuid.UUID            `json:"classroom_id"`
+	ClassroomName string               `json:"classroom_name"`
+	GradedCount   int                  `json:"graded_count"`
+	PendingCount  int                  `json:"pending_count"`
+	OldestPending *time.Time           `json:"oldest_pending_submitted_at"`
+	Latency       *LatencyDistribution `json:"latency"`
+}
+
+// GetGradingLatency reports how long manually graded submissions wait between
+// submission and grading, per teacher and classroom. Auto-graded question types
+// are excluded; ungraded submissions are counted as pending.
+func (h *ReportingHandler) GetGradingLatency(c *gin.Context) {
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := h.db.Table("quiz_submissions qsub").
+		Select(`
+			q.creator_id as teacher_id,
+			COALESCE(u.first_name || ' ' || u.last_name, '') as teacher_name,
+			cl.id as classroom_id,
+			cl.name as classroom_name,
+			qsub.submitted_at,
+			qsub.graded_at
+		`).
+		Joins("JOIN quiz_questions qq ON qq.id = qsub.question_id").
+		Joins("JOIN quizzes q ON q.id = qsub.quiz_id").
+		Joins("JOIN classrooms cl ON cl.id = q.classroom_id").
+		Joins("LEFT JOIN users u ON u.id = q.creator_id").
+		Where("qq.question_type IN ?", manuallyGradedTypes).
+		Where("qsub.submitted_at >= ? AND qsub.submitted_at < ?", dateFrom, dateTo.AddDate(0, 0, 1))
+
+	if classroomIDStr := c.Query("classroom_id"); classroomIDStr != "" {
+		classroomID, err := uuid.Parse(classroomIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		query = query.Where("cl.id = ?", classroomID)
+	}
+
+	var submissions []gradingSubmission
+	if err := query.Scan(&submissions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch submissions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":  gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"latency": summarizeGradingLatency(submissions),
+	})
+}
+
+// gradingSubmission is one manually graded answer with its grading state
+type gradingSubmission struct {
+	TeacherID     uuid.UUID
+	TeacherName   string
+	ClassroomID   uuid.UUID
+	ClassroomName string
+	SubmittedAt   time.Time
+	GradedAt      *time.Time
+}
+
+// summarizeGradingLatency groups submissions by teacher and classroom and
+// computes the latency distribution over the graded ones
+func summarizeGradingLatency(submissions []gradingSubmission) []GradingLatency {
+	type groupKey struct{ teacher, classroom uuid.UUID }
+	groups := make(map[groupKey]*GradingLatency)
+	hours := make(map[groupKey][]float64)
+	var order []groupKey
+
+	for _, s := range submissions {
+		key := groupKey{s.TeacherID, s.ClassroomID}
+		group, ok := groups[key]
+		if !ok {
+			group = &GradingLatency{
+				TeacherID:     s.TeacherID,
+				TeacherName:   s.TeacherName,
+				ClassroomID:   s.ClassroomID,
+				ClassroomName: s.ClassroomName,
+			}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		if s.GradedAt == nil {
+			group.PendingCount++
+			if group.OldestPending == nil || s.SubmittedAt.Before(*group.OldestPending) {
+				submitted := s.SubmittedAt
+				group.OldestPending = &submitted
+			}
+			continue
+		}
+
+		group.GradedCount++
+		// Clamp clock skew so a grade stamped before submission counts as instant
+		hours[key] = append(hours[key], math.Max(0, s.GradedAt.Sub(s.SubmittedAt).Hours()))
+	}
+
+	results := make([]GradingLatency, 0, len(order))
+	for _, key := range order {
+		group := groups[key]
+		group.Latency = newLatencyDistribution(hours[key])
+		results = append(results, *group)
+	}
+
+	// Slowest graders first so they stand out
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Latency, results[j].Latency
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.AvgHours > b.AvgHours
+	})
+
+	return results
+}
+
+// newLatencyDistribution summarizes latencies in hours, or returns nil when
+// there are none
+func newLatencyDistribution(hours []float64) *LatencyDistribution {
+	if len(hours) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), hours...)
+	sort.Float64s(sorted)
+	return &LatencyDistribution{
+		AvgHours:    roundTo(meanOf(sorted), 2),
+		MedianHours: roundTo(medianOf(sorted), 2),
+		P90Hours:    roundTo(percentileOf(sorted, 90), 2),
+		MinHours:    roundTo(sorted[0], 2),
+		MaxHours:    roundTo(sorted[len(sorted)-1], 2),
+	}
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
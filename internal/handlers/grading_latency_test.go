@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSummarizeGradingLatency(t *testing.T) {
+	fast, slow, idle := uuid.New(), uuid.New(), uuid.New()
+	classroom := uuid.New()
+	submitted := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	after := func(hours float64) *time.Time {
+		graded := submitted.Add(time.Duration(hours * float64(time.Hour)))
+		return &graded
+	}
+
+	results := summarizeGradingLatency([]gradingSubmission{
+		{TeacherID: fast, ClassroomID: classroom, SubmittedAt: submitted, GradedAt: after(1)},
+		{TeacherID: fast, ClassroomID: classroom, SubmittedAt: submitted, GradedAt: after(-2)},
+		{TeacherID: idle, ClassroomID: classroom, SubmittedAt: submitted.Add(time.Hour)},
+		{TeacherID: idle, ClassroomID: classroom, SubmittedAt: submitted},
+		{TeacherID: slow, ClassroomID: classroom, SubmittedAt: submitted, GradedAt: after(48)},
+		{TeacherID: slow, ClassroomID: classroom, SubmittedAt: submitted, GradedAt: after(24)},
+		{TeacherID: slow, ClassroomID: classroom, SubmittedAt: submitted.Add(2 * time.Hour)},
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("got %d groups, want 3", len(results))
+	}
+	if results[0].TeacherID != slow || results[1].TeacherID != fast || results[2].TeacherID != idle {
+		t.Errorf("order = %v, %v, %v, want slowest first and ungraded last", results[0].TeacherID, results[1].TeacherID, results[2].TeacherID)
+	}
+
+	want := LatencyDistribution{AvgHours: 36, MedianHours: 36, P90Hours: 45.6, MinHours: 24, MaxHours: 48}
+	if got := results[0]; got.GradedCount != 2 || got.PendingCount != 1 || !reflect.DeepEqual(*got.Latency, want) {
+		t.Errorf("slow grader = %+v, latency %+v, want %+v", got, *got.Latency, want)
+	}
+	// A grade stamped before its submission counts as instant
+	if got := results[1].Latency; got.MinHours != 0 || got.AvgHours != 0.5 {
+		t.Errorf("fast grader latency = %+v", got)
+	}
+	if got := results[2]; got.Latency != nil || got.PendingCount != 2 || !got.OldestPending.Equal(submitted) {
+		t.Errorf("idle grader = %+v, want two pending with the oldest first submitted", got)
+	}
+}
+
+func TestGetGradingLatencyOnlyManualQuestions(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetGradingLatency, "/grading-latency", http.MethodGet, "/grading-latency?date_from=2024-03-01&date_to=2024-03-31", "", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	ran := fake.ran("FROM quiz_submissions qsub")
+	if len(ran) != 1 {
+		t.Fatalf("ran %d submission queries, want 1", len(ran))
+	}
+	if !containsAll(ran[0].SQL, []string{"qq.question_type IN ($1,$2)"}) || ran[0].Args[0] != "short_answer" || ran[0].Args[1] != "essay" {
+		t.Errorf("query %q with %v does not restrict to manually graded types", ran[0].SQL, ran[0].Args)
+	}
+
+	w = testRequest(h.GetGradingLatency, "/grading-latency", http.MethodGet, "/grading-latency?classroom_id=nope", "", nil)
+	expectStatus(t, w, http.StatusBadRequest)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ingestionStatsWindow bounds how far back ingestion stats look
+const ingestionStatsWindow = time.Hour
+
+// aggregateTables are the tables refreshed from raw events
+var aggregateTables = []reportSource{dailyUserMetricsSource, dailyClassroomMetricsSource, contentMetricsSource, weeklySchoolMetricsSource}
+
+// IngestionMinute is the number of events ingested during one minute
+type IngestionMinute struct {
+	Minute time.Time `json:"minute"`
+	Events int       `json:"events"`
+}
+
+// IngestionLag summarizes the delay between when events happened and when they were stored
+type IngestionLag struct {
+	AvgSeconds    *float64 `json:"avg_seconds"`
+	MaxSeconds    *float64 `json:"max_seconds"`
+	FutureEvents  int      `json:"future_events"` // timestamp after created_at, a sign of client clock skew
+	SampledEvents int      `json:"sampled_events"`
+}
+
+// GetIngestionStats reports per-minute event throughput and ingestion lag over
+// the last hour, along with how far aggregation trails behind now
+func (h *ReportingHandler) GetIngestionStats(c *gin.Context) {
+	now := time.Now()
+	windowStart := now.Add(-ingestionStatsWindow).Truncate(time.Minute)
+
+	var counts []IngestionMinute
+	err := h.db.Table("events").
+		Select("DATE_TRUNC('minute', created_at) as minute, COUNT(*) as events").
+		Where("created_at >= ?", windowStart).
+		Group("minute").
+		Scan(&counts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate event throughput", "details": err.Error()})
+		return
+	}
+
+	var lag IngestionLag
+	err = h.db.Table("events").
+		Select(`
+			AVG(EXTRACT(EPOCH FROM (created_at - timestamp))) as avg_seconds,
+			MAX(EXTRACT(EPOCH FROM (created_at - timestamp))) as max_seconds,
+			COUNT(*) FILTER (WHERE timestamp > created_at) as future_events,
+			COUNT(*) as sampled_events
+		`).
+		Where("created_at >= ?", windowStart).
+		Scan(&lag).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate ingestion lag", "details": err.Error()})
+		return
+	}
+	if lag.AvgSeconds != nil {
+		v := roundTo(*lag.AvgSeconds, 2)
+		lag.AvgSeconds = &v
+	}
+
+	watermark, err := h.latestAggregateUpdate(aggregateTables)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to determine aggregation watermark", "details": err.Error()})
+		return
+	}
+
+	// Gap-fill so idle minutes show up as zero throughput
+	byMinute := make(map[int64]int, len(counts))
+	total := 0
+	for _, m := range counts {
+		byMinute[m.Minute.Unix()] = m.Events
+		total += m.Events
+	}
+	perMinute := []IngestionMinute{}
+	for minute := windowStart; !minute.After(now); minute = minute.Add(time.Minute) {
+		perMinute = append(perMinute, IngestionMinute{Minute: minute, Events: byMinute[minute.Unix()]})
+	}
+
+	aggregation := gin.H{"watermark": nil, "lag_seconds": nil}
+	if !watermark.IsZero() {
+		aggregation["watermark"] = watermark
+		aggregation["lag_seconds"] = int(now.Sub(watermark).Seconds())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"as_of":                 now,
+		"window_start":          windowStart,
+		"total_events":          total,
+		"avg_events_per_minute": roundTo(float64(total)/float64(len(perMinute)), 2),
+		"events_per_minute":     perMinute,
+		"ingestion_lag":         lag,
+		"aggregation":           aggregation,
+	})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIngestionStats(t *testing.T) {
+	fake, db := newFakeDB(t)
+	minute := time.Now().UTC().Truncate(time.Minute)
+	fake.rows([]string{"DATE_TRUNC('minute', created_at)"}, []string{"minute", "events"},
+		[]driver.Value{minute.Add(-5 * time.Minute), int64(40)},
+		[]driver.Value{minute.Add(-2 * time.Minute), int64(20)})
+	fake.rows([]string{"future_events"}, []string{"avg_seconds", "max_seconds", "future_events", "sampled_events"},
+		[]driver.Value{1.23456, 30.0, int64(2), int64(60)})
+	watermark := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+	fake.rows([]string{"UNION ALL"}, []string{"max"}, []driver.Value{watermark})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetIngestionStats, "/admin/ingestion-stats", http.MethodGet, "/admin/ingestion-stats", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	perMinute := body["events_per_minute"].([]interface{})
+	// The window covers the last hour of whole minutes, idle ones as zero
+	if len(perMinute) < 60 || len(perMinute) > 62 {
+		t.Fatalf("got %d minutes, want about an hour", len(perMinute))
+	}
+	nonZero := 0
+	for _, m := range perMinute {
+		if m.(map[string]interface{})["events"].(float64) > 0 {
+			nonZero++
+		}
+	}
+	if nonZero != 2 || body["total_events"] != 60.0 {
+		t.Errorf("%d busy minutes totalling %v, want 2 totalling 60", nonZero, body["total_events"])
+	}
+	if want := roundTo(60/float64(len(perMinute)), 2); body["avg_events_per_minute"] != want {
+		t.Errorf("avg_events_per_minute = %v, want %v", body["avg_events_per_minute"], want)
+	}
+
+	lag := body["ingestion_lag"].(map[string]interface{})
+	if lag["avg_seconds"] != 1.23 || lag["max_seconds"] != 30.0 || lag["future_events"] != 2.0 || lag["sampled_events"] != 60.0 {
+		t.Errorf("ingestion_lag = %v", lag)
+	}
+
+	aggregation := body["aggregation"].(map[string]interface{})
+	if seconds := aggregation["lag_seconds"].(float64); seconds < 600 || seconds > 610 {
+		t.Errorf("aggregation lag = %v seconds, want about 600", seconds)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// liveUpdateInterval is how often live classroom data is recomputed
+const liveUpdateInterval = 5 * time.Second
+
+// Default and maximum look-back windows for live classroom data. The
+// activity window is recomputed every interval, so it is capped to keep
+// each tick's queries bounded.
+const (
+	defaultLiveActivityWindow     = time.Hour
+	defaultLiveRecentEventsWindow = 10 * time.Minute
+	maxLiveWindow                 = 6 * time.Hour
+)
+
+// liveWindows are how far back live classroom data looks
+type liveWindows struct {
+	activity     time.Duration // active students, quiz participation and responses
+	recentEvents time.Duration // the recent events feed
+}
+
+// LiveHub fans live classroom data out to WebSocket connections. Each
+// classroom with at least one viewer has a single broadcaster that queries
+// the database once per interval, however many connections are open.
+type LiveHub struct {
+	db              *gorm.DB
+	maxPerClassroom int
+	interval        time.Duration
+	windows         liveWindows
+
+	mu           sync.Mutex
+	broadcasters map[uuid.UUID]*classroomBroadcaster
+}
+
+// classroomBroadcaster computes live data for one classroom and publishes it
+// to every subscriber
+type classroomBroadcaster struct {
+	classroomID uuid.UUID
+	stop        chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[chan *WebSocketMessage]struct{}
+	latest      *ClassroomLiveData
+}
+
+// NewLiveHub creates a hub allowing up to maxPerClassroom concurrent
+// connections per classroom; zero or less means unlimited
+func NewLiveHub(db *gorm.DB, maxPerClassroom int) *LiveHub {
+	return &LiveHub{
+		db:              db,
+		maxPerClassroom: maxPerClassroom,
+		interval:        liveUpdateInterval,
+		windows:         liveWindows{activity: defaultLiveActivityWindow, recentEvents: defaultLiveRecentEventsWindow},
+		broadcasters:    make(map[uuid.UUID]*classroomBroadcaster),
+	}
+}
+
+// SetWindows configures how far back live data looks for activity and for
+// the recent events feed. Zero or less keeps the current window; longer
+// than maxLiveWindow is capped. Call before serving connections.
+func (h *LiveHub) SetWindows(activity, recentEvents time.Duration) {
+	if activity > 0 {
+		h.windows.activity = min(activity, maxLiveWindow)
+	}
+	if recentEvents > 0 {
+		h.windows.recentEvents = min(recentEvents, maxLiveWindow)
+	}
+}
+
+// join subscribes to a classroom's updates, starting its broadcaster if this
+// is the first viewer. It returns the most recently published data (nil until
+// the first tick) and false when the classroom is at its connection limit.
+func (h *LiveHub) join(classroomID uuid.UUID) (chan *WebSocketMessage, *ClassroomLiveData, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, exists := h.broadcasters[classroomID]
+	if !exists {
+		b = &classroomBroadcaster{
+			classroomID: classroomID,
+			stop:        make(chan struct{}),
+			subscribers: make(map[chan *WebSocketMessage]struct{}),
+		}
+		h.broadcasters[classroomID] = b
+		go h.run(b)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if h.maxPerClassroom > 0 && len(b.subscribers) >= h.maxPerClassroom {
+		return nil, nil, false
+	}
+
+	// Buffer of one: a slow connection only ever holds the newest update
+	sub := make(chan *WebSocketMessage, 1)
+	b.subscribers[sub] = struct{}{}
+	return sub, b.latest, true
+}
+
+// leave unsubscribes and stops the broadcaster once the classroom has no viewers
+func (h *LiveHub) leave(classroomID uuid.UUID, sub chan *WebSocketMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b, exists := h.broadcasters[classroomID]
+	if !exists {
+		return
+	}
+
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	empty := len(b.subscribers) == 0
+	b.mu.Unlock()
+
+	if empty {
+		close(b.stop)
+		delete(h.broadcasters, classroomID)
+	}
+}
+
+// run recomputes the classroom's live data every interval until stopped
+func (h *LiveHub) run(b *classroomBroadcaster) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			liveData, err := getClassroomLiveData(h.db, b.classroomID, h.windows)
+			if err != nil {
+				log.Printf("Failed to get live data: %v", err)
+				continue
+			}
+			b.publish(liveData)
+		}
+	}
+}
+
+// publish hands the update to every subscriber, replacing any update a slow
+// connection has not yet written
+func (b *classroomBroadcaster) publish(liveData *ClassroomLiveData) {
+	message := &WebSocketMessage{
+		Type:      "live_update",
+		Data:      map[string]interface{}{"classroom_data": liveData},
+		Timestamp: time.Now(),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.latest = liveData
+	for sub := range b.subscribers {
+		select {
+		case <-sub:
+		default:
+		}
+		sub <- message
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLiveHubSetWindows(t *testing.T) {
+	hub := NewLiveHub(nil, 0)
+	hub.SetWindows(24*time.Hour, 0)
+	if hub.windows.activity != maxLiveWindow || hub.windows.recentEvents != defaultLiveRecentEventsWindow {
+		t.Errorf("windows = %+v, want the activity window capped and recent events unchanged", hub.windows)
+	}
+}
+
+func TestLiveHubConnectionLimit(t *testing.T) {
+	hub := NewLiveHub(nil, 2)
+	hub.interval = time.Hour
+	classroom := uuid.New()
+
+	first, _, ok1 := hub.join(classroom)
+	second, _, ok2 := hub.join(classroom)
+	_, _, ok3 := hub.join(classroom)
+	if !ok1 || !ok2 || ok3 {
+		t.Fatalf("joins = %v, %v, %v, want the third refused", ok1, ok2, ok3)
+	}
+	if _, _, ok := hub.join(uuid.New()); !ok {
+		t.Error("the limit should be per classroom")
+	}
+
+	hub.leave(classroom, first)
+	third, _, ok := hub.join(classroom)
+	if !ok {
+		t.Fatal("a slot should free up when a viewer leaves")
+	}
+
+	b := hub.broadcasters[classroom]
+	hub.leave(classroom, second)
+	hub.leave(classroom, third)
+	if _, exists := hub.broadcasters[classroom]; exists {
+		t.Error("broadcaster kept after the last viewer left")
+	}
+	select {
+	case <-b.stop:
+	default:
+		t.Error("broadcaster was not stopped")
+	}
+}
+
+func TestClassroomBroadcasterKeepsNewestUpdate(t *testing.T) {
+	hub := NewLiveHub(nil, 0)
+	hub.interval = time.Hour
+	classroom := uuid.New()
+	sub, latest, _ := hub.join(classroom)
+	defer hub.leave(classroom, sub)
+	if latest != nil {
+		t.Fatalf("latest = %+v before any update, want nil", latest)
+	}
+
+	b := hub.broadcasters[classroom]
+	older, newer := &ClassroomLiveData{ActiveStudents: 1}, &ClassroomLiveData{ActiveStudents: 2}
+	b.publish(older)
+	b.publish(newer)
+
+	message := <-sub
+	if got := message.Data["classroom_data"]; got != newer {
+		t.Errorf("subscriber got %+v, want the newest update", got)
+	}
+	select {
+	case stale := <-sub:
+		t.Errorf("stale update still queued: %+v", stale)
+	default:
+	}
+
+	late, latest, _ := hub.join(classroom)
+	defer hub.leave(classroom, late)
+	if latest != newer {
+		t.Errorf("a late viewer got %+v, want the latest data", latest)
+	}
+}
+
+func TestLiveHubQueriesOncePerTick(t *testing.T) {
+	fake, db := newFakeDB(t)
+	hub := NewLiveHub(db, 0)
+	hub.interval = 50 * time.Millisecond
+	classroom := uuid.New()
+
+	subs := make([]chan *WebSocketMessage, 3)
+	for i := range subs {
+		subs[i], _, _ = hub.join(classroom)
+	}
+	for _, sub := range subs {
+		select {
+		case <-sub:
+		case <-time.After(time.Second):
+			t.Fatal("no live update within a second")
+		}
+	}
+	// Leaving well before the next tick stops the broadcaster after one update
+	for _, sub := range subs {
+		hub.leave(classroom, sub)
+	}
+
+	statements := fake.ran()
+	if len(statements) == 0 {
+		t.Fatal("no live data queries ran")
+	}
+	for _, statement := range statements {
+		if n := len(fake.ran(statement.SQL)); n != 1 {
+			t.Errorf("ran %d times for three viewers, want once: %s", n, statement.SQL)
+		}
+	}
+}
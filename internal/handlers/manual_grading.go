@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PendingSubmission is a manually graded answer still waiting for a grade
+type PendingSubmission struct {
+	SubmissionID    uuid.UUID `json:"submission_id"`
+	QuestionID      uuid.UUID `json:"question_id"`
+	QuestionText    string    `json:"question_text"`
+	QuestionType    string    `json:"question_type"`
+	MaxPoints       int       `json:"max_points"`
+	SubmittedAnswer *string   `json:"submitted_answer"`
+	AttemptNumber   int       `json:"attempt_number"`
+	SubmittedAt     time.Time `json:"submitted_at"`
+}
+
+// StudentPendingGrading groups a student's ungraded answers to one quiz
+type StudentPendingGrading struct {
+	StudentID   uuid.UUID           `json:"student_id"`
+	FirstName   *string             `json:"first_name"`
+	LastName    *string             `json:"last_name"`
+	Submissions []PendingSubmission `json:"submissions"`
+}
+
+// gradeSubmissionRequest is the body of POST /submissions/:id/grade
+type gradeSubmissionRequest struct {
+	PointsEarned *int  `json:"points_earned" binding:"required"`
+	IsCorrect    *bool `json:"is_correct" binding:"required"`
+}
+
+// GetQuizPendingGrading lists a quiz's short-answer and essay submissions
+// that have no is_correct yet, grouped by student, oldest submission first.
+// Until they are graded, the quiz's scores undercount those answers. Only
+// those who may grade the quiz can list them.
+func (h *ReportingHandler) GetQuizPendingGrading(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz_id"})
+		return
+	}
+
+	var quiz struct {
+		TeacherID *uuid.UUID
+		SchoolID  uuid.UUID
+	}
+	err = h.db.Table("quizzes q").
+		Select("cl.teacher_id, cl.school_id").
+		Joins("JOIN classrooms cl ON cl.id = q.classroom_id").
+		Where("q.id = ?", quizID).
+		Take(&quiz).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz", "details": err.Error()})
+		return
+	}
+	if !mayGrade(c, quiz.TeacherID, quiz.SchoolID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the classroom's teacher or an admin of its school may see its pending grading"})
+		return
+	}
+
+	var rows []struct {
+		PendingSubmission
+		StudentID uuid.UUID
+		FirstName *string
+		LastName  *string
+	}
+	err = h.db.Table("quiz_submissions qsub").
+		Select(`
+			qsub.id as submission_id,
+			qsub.question_id,
+			qq.question_text,
+			qq.question_type,
+			COALESCE(qq.points, 1) as max_points,
+			qsub.submitted_answer,
+			qsub.attempt_number,
+			qsub.submitted_at,
+			qsub.student_id,
+			u.first_name,
+			u.last_name
+		`).
+		Joins("JOIN quiz_questions qq ON qq.id = qsub.question_id").
+		Joins("LEFT JOIN users u ON u.id = qsub.student_id").
+		Where("qsub.quiz_id = ? AND qsub.is_correct IS NULL AND qq.question_type IN ?", quizID, manuallyGradedTypes).
+		Order("qsub.submitted_at, qq.order_index").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending submissions", "details": err.Error()})
+		return
+	}
+
+	// Students are listed in the order of their oldest pending submission
+	students := []StudentPendingGrading{}
+	index := make(map[uuid.UUID]int)
+	for _, row := range rows {
+		i, ok := index[row.StudentID]
+		if !ok {
+			i = len(students)
+			index[row.StudentID] = i
+			students = append(students, StudentPendingGrading{
+				StudentID: row.StudentID,
+				FirstName: row.FirstName,
+				LastName:  row.LastName,
+			})
+		}
+		students[i].Submissions = append(students[i].Submissions, row.PendingSubmission)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quiz_id":       quizID,
+		"pending_count": len(rows),
+		"students":      students,
+	})
+}
+
+// GradeSubmission records a teacher's grade for a short-answer or essay
+// submission and recomputes the score of the quiz session it belongs to.
+// Regrading an already graded submission overwrites the grade. Only the
+// teacher of the quiz's classroom and admins of its school may grade it.
+func (h *ReportingHandler) GradeSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission_id"})
+		return
+	}
+
+	var req gradeSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format", "details": err.Error()})
+		return
+	}
+
+	var submission struct {
+		QuizID        uuid.UUID
+		StudentID     uuid.UUID
+		AttemptNumber int
+		QuestionType  string
+		MaxPoints     int
+		TeacherID     *uuid.UUID
+		SchoolID      uuid.UUID
+	}
+	err = h.db.Table("quiz_submissions qsub").
+		Select("qsub.quiz_id, qsub.student_id, qsub.attempt_number, qq.question_type, COALESCE(qq.points, 1) as max_points, cl.teacher_id, cl.school_id").
+		Joins("JOIN quiz_questions qq ON qq.id = qsub.question_id").
+		Joins("JOIN quizzes q ON q.id = qsub.quiz_id").
+		Joins("JOIN classrooms cl ON cl.id = q.classroom_id").
+		Where("qsub.id = ?", submissionID).
+		Take(&submission).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch submission", "details": err.Error()})
+		return
+	}
+	if !mayGrade(c, submission.TeacherID, submission.SchoolID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the classroom's teacher or an admin of its school may grade this submission"})
+		return
+	}
+
+	manual := false
+	for _, questionType := range manuallyGradedTypes {
+		manual = manual || submission.QuestionType == questionType
+	}
+	if !manual {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s questions are graded automatically", submission.QuestionType)})
+		return
+	}
+	if *req.PointsEarned < 0 || *req.PointsEarned > submission.MaxPoints {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("points_earned must be between 0 and %d", submission.MaxPoints)})
+		return
+	}
+
+	viewer := requestViewer(c)
+	gradedAt := time.Now()
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("quiz_submissions").Where("id = ?", submissionID).Updates(map[string]interface{}{
+			"points_earned": *req.PointsEarned,
+			"is_correct":    *req.IsCorrect,
+			"graded_at":     gradedAt,
+			"graded_by":     viewer.UserID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// The session score includes every answer of the attempt
+		return tx.Exec(`
+			UPDATE quiz_sessions qs SET
+				total_score = t.total,
+				percentage_score = CASE WHEN qs.max_possible_score > 0
+					THEN ROUND(t.total * 100.0 / qs.max_possible_score, 2) END
+			FROM (
+				SELECT COALESCE(SUM(points_earned), 0) as total
+				FROM quiz_submissions
+				WHERE quiz_id = @quiz AND student_id = @student AND attempt_number = @attempt
+			) t
+			WHERE qs.quiz_id = @quiz AND qs.student_id = @student AND qs.attempt_number = @attempt
+		`, map[string]interface{}{"quiz": submission.QuizID, "student": submission.StudentID, "attempt": submission.AttemptNumber}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grade submission", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"submission_id": submissionID,
+		"points_earned": *req.PointsEarned,
+		"is_correct":    *req.IsCorrect,
+		"graded_at":     gradedAt,
+		"graded_by":     viewer.UserID,
+	})
+}
+
+// mayGrade reports whether the requester may see and grade the submissions
+// of a quiz in a classroom with the given teacher and school: API-key
+// requests, which carry no user, the classroom's teacher and admins whose
+// token names the same school
+func mayGrade(c *gin.Context, teacherID *uuid.UUID, schoolID uuid.UUID) bool {
+	viewer := requestViewer(c)
+	switch {
+	case viewer.UserID == nil && viewer.Role == "":
+		return true
+	case viewer.Role == "teacher":
+		return viewer.UserID != nil && teacherID != nil && *viewer.UserID == *teacherID
+	case viewer.Role == "admin":
+		viewerSchool, ok := c.Get("school_id")
+		return ok && viewerSchool == schoolID
+	}
+	return false
+}
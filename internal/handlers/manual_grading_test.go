@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGradeSubmissionOwnership(t *testing.T) {
+	teacherID := uuid.New()
+	schoolID := uuid.New()
+	submissionID := uuid.New()
+	body := `{"points_earned": 3, "is_correct": true}`
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   int
+	}{
+		{"classroom teacher", map[string]interface{}{"user_id": teacherID, "user_role": "teacher"}, http.StatusOK},
+		{"other teacher", map[string]interface{}{"user_id": uuid.New(), "user_role": "teacher"}, http.StatusForbidden},
+		{"admin of the school", map[string]interface{}{"user_id": uuid.New(), "user_role": "admin", "school_id": schoolID}, http.StatusOK},
+		{"admin of another school", map[string]interface{}{"user_id": uuid.New(), "user_role": "admin", "school_id": uuid.New()}, http.StatusForbidden},
+		{"student", map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}, http.StatusForbidden},
+		{"token without role", map[string]interface{}{"user_id": teacherID}, http.StatusForbidden},
+		{"api key", nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			fake.rows([]string{"FROM quiz_submissions qsub", "qsub.id ="},
+				[]string{"quiz_id", "student_id", "attempt_number", "question_type", "max_points", "teacher_id", "school_id"},
+				[]driver.Value{uuid.NewString(), uuid.NewString(), int64(1), "essay", int64(5), teacherID.String(), schoolID.String()})
+			h := NewReportingHandler(db)
+
+			w := testRequest(h.GradeSubmission, "/submissions/:id/grade", http.MethodPost, "/submissions/"+submissionID.String()+"/grade", body, tt.values)
+			expectStatus(t, w, tt.want)
+
+			graded := len(fake.ran(`UPDATE "quiz_submissions"`)) > 0
+			if graded != (tt.want == http.StatusOK) {
+				t.Errorf("submission updated = %v with status %d", graded, w.Code)
+			}
+		})
+	}
+}
+
+func TestGetQuizPendingGradingForbidsOtherTeachers(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM quizzes q", "JOIN classrooms cl"},
+		[]string{"teacher_id", "school_id"},
+		[]driver.Value{uuid.NewString(), uuid.NewString()})
+	h := NewReportingHandler(db)
+
+	quizID := uuid.NewString()
+	w := testRequest(h.GetQuizPendingGrading, "/quizzes/:id/pending-grading", http.MethodGet, "/quizzes/"+quizID+"/pending-grading", "",
+		map[string]interface{}{"user_id": uuid.New(), "user_role": "teacher"})
+	expectStatus(t, w, http.StatusForbidden)
+	if len(fake.ran("FROM quiz_submissions")) > 0 {
+		t.Error("pending submissions were read for a forbidden viewer")
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// measureBound is an inclusive range a percentage measure must fall in
+type measureBound struct {
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+// measureBounds maps measure names to their bounds
+type measureBounds map[string]measureBound
+
+// parseMeasureBounds reads min_<measure> and max_<measure> query parameters for
+// the given percentage measures. Bounds must lie in [0, 100] and min may not exceed max.
+func parseMeasureBounds(c *gin.Context, measures ...string) (measureBounds, error) {
+	bounds := measureBounds{}
+	for _, measure := range measures {
+		var bound measureBound
+		for _, side := range []struct {
+			param  string
+			target **float64
+		}{
+			{"min_" + measure, &bound.Min},
+			{"max_" + measure, &bound.Max},
+		} {
+			raw := c.Query(side.param)
+			if raw == "" {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", side.param)
+			}
+			if value < 0 || value > 100 {
+				return nil, fmt.Errorf("%s must be between 0 and 100", side.param)
+			}
+			*side.target = &value
+		}
+		if bound.Min != nil && bound.Max != nil && *bound.Min > *bound.Max {
+			return nil, fmt.Errorf("min_%s must not exceed max_%s", measure, measure)
+		}
+		if bound.Min != nil || bound.Max != nil {
+			bounds[measure] = bound
+		}
+	}
+	return bounds, nil
+}
+
+// allows reports whether value satisfies the measure's bounds. A missing value
+// never satisfies a bound, since withheld averages can't be compared.
+func (b measureBounds) allows(measure string, value *float64) bool {
+	bound, ok := b[measure]
+	if !ok {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	if bound.Min != nil && *value < *bound.Min {
+		return false
+	}
+	if bound.Max != nil && *value > *bound.Max {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// studentOnboarding is a student's enrollment and first activity after it
+type studentOnboarding struct {
+	UserID          uuid.UUID
+	EnrolledAt      time.Time
+	FirstActivityAt *time.Time
+}
+
+// GetOnboardingLatency reports how long students take between enrollment and
+// their first session or event. A student enrolled in several classrooms in
+// scope is counted once, from their earliest enrollment. Students with no
+// activity since enrolling are counted as never activated and left out of the
+// distribution.
+func (h *ReportingHandler) GetOnboardingLatency(c *gin.Context) {
+	schoolIDStr := c.Query("school_id")
+	classroomIDStr := c.Query("classroom_id")
+	if schoolIDStr == "" && classroomIDStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id or classroom_id is required"})
+		return
+	}
+
+	query := h.db.Table("user_classrooms uc").
+		Select("DISTINCT ON (uc.user_id) uc.user_id, uc.enrolled_at").
+		Joins("JOIN classrooms cl ON cl.id = uc.classroom_id").
+		Where("uc.role = 'student' AND uc.enrolled_at IS NOT NULL")
+
+	if schoolIDStr != "" {
+		schoolID, err := uuid.Parse(schoolIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid school_id format"})
+			return
+		}
+		query = query.Where("cl.school_id = ?", schoolID)
+	}
+	if classroomIDStr != "" {
+		classroomID, err := uuid.Parse(classroomIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		query = query.Where("cl.id = ?", classroomID)
+	}
+	enrollments := query.Order("uc.user_id, uc.enrolled_at ASC")
+
+	var students []studentOnboarding
+	err := h.db.Table("(?) en", enrollments).
+		Select(`
+			en.user_id, en.enrolled_at,
+			LEAST(
+				(SELECT MIN(s.start_time) FROM sessions s WHERE s.user_id = en.user_id AND s.start_time >= en.enrolled_at),
+				(SELECT MIN(e.timestamp) FROM events e WHERE e.user_id = en.user_id AND e.timestamp >= en.enrolled_at)
+			) as first_activity_at
+		`).
+		Scan(&students).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollments", "details": err.Error()})
+		return
+	}
+
+	hours := make([]float64, 0, len(students))
+	for _, s := range students {
+		if s.FirstActivityAt != nil {
+			hours = append(hours, math.Max(0, s.FirstActivityAt.Sub(s.EnrolledAt).Hours()))
+		}
+	}
+
+	neverActivated := len(students) - len(hours)
+	neverActivatedRate := 0.0
+	if len(students) > 0 {
+		neverActivatedRate = roundTo(float64(neverActivated)*100/float64(len(students)), 2)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrolled_students":    len(students),
+		"activated_students":   len(hours),
+		"never_activated":      neverActivated,
+		"never_activated_rate": neverActivatedRate,
+		"latency":              newLatencyDistribution(hours),
+	})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestOnboardingLatency(t *testing.T) {
+	fake, db := newFakeDB(t)
+	enrolled := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	fake.rows([]string{"first_activity_at", "DISTINCT ON (uc.user_id)"}, []string{"user_id", "enrolled_at", "first_activity_at"},
+		[]driver.Value{uuid.NewString(), enrolled, enrolled.Add(2 * time.Hour)},
+		[]driver.Value{uuid.NewString(), enrolled, enrolled.Add(24 * time.Hour)},
+		[]driver.Value{uuid.NewString(), enrolled, nil},
+		[]driver.Value{uuid.NewString(), enrolled, enrolled.Add(10 * time.Hour)})
+	h := NewReportingHandler(db)
+	classroomID := uuid.New()
+
+	w := testRequest(h.GetOnboardingLatency, "/reports/onboarding-latency", http.MethodGet, "/reports/onboarding-latency?classroom_id="+classroomID.String(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	if body["enrolled_students"] != 4.0 || body["activated_students"] != 3.0 || body["never_activated"] != 1.0 || body["never_activated_rate"] != 25.0 {
+		t.Errorf("counts = %v, want 4 enrolled with 1 never activated", body)
+	}
+	// Latencies of 2, 10 and 24 hours; p90 interpolates 80% of the way from 10 to 24
+	want := map[string]interface{}{"avg_hours": 12.0, "median_hours": 10.0, "p90_hours": 21.2, "min_hours": 2.0, "max_hours": 24.0}
+	if latency := body["latency"]; !reflect.DeepEqual(latency, want) {
+		t.Errorf("latency = %v, want %v", latency, want)
+	}
+
+	ran := fake.ran("first_activity_at")
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{"cl.id = $1", "ORDER BY uc.user_id, uc.enrolled_at ASC"}) || ran[0].Args[0] != classroomID {
+		t.Errorf("query = %v, want each student's earliest enrollment in the classroom", ran)
+	}
+
+	expectStatus(t, testRequest(h.GetOnboardingLatency, "/reports/onboarding-latency", http.MethodGet, "/reports/onboarding-latency", "", nil), http.StatusBadRequest)
+}
+
+func TestOnboardingLatencyWithoutActivity(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	w := testRequest(h.GetOnboardingLatency, "/reports/onboarding-latency", http.MethodGet, "/reports/onboarding-latency?school_id="+uuid.NewString(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if body := decodeBody(t, w); body["latency"] != nil || body["never_activated_rate"] != 0.0 {
+		t.Errorf("body = %v, want no distribution and a zero rate", body)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes for list endpoints that don't set their own
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// page is a limit/offset window over a list endpoint's rows
+type page struct {
+	Limit  int
+	Offset int
+}
+
+// parsePage reads limit and offset from the query string. limit falls back
+// to defaultLimit and is capped at maxLimit; offset defaults to 0.
+func parsePage(c *gin.Context, defaultLimit, maxLimit int) (page, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit < 1 {
+		return page{}, errors.New("limit must be a positive integer")
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		return page{}, errors.New("offset must be a non-negative integer")
+	}
+	return page{Limit: min(limit, maxLimit), Offset: offset}, nil
+}
+
+// envelope describes the page for the response; next_offset is null on the
+// last page
+func (p page) envelope(total int64) gin.H {
+	var nextOffset *int
+	if next := p.Offset + p.Limit; int64(next) < total {
+		nextOffset = &next
+	}
+	return gin.H{
+		"limit":       p.Limit,
+		"offset":      p.Offset,
+		"total":       total,
+		"next_offset": nextOffset,
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// equityActivitySQL selects each student's total activity in the period for a
+// participation equity metric; the arguments are classroom id, from and to
+var equityActivitySQL = map[string]string{
+	"events": `SELECT user_id, COUNT(*)::float as activity FROM events
+		WHERE classroom_id = ? AND timestamp BETWEEN ? AND ? GROUP BY user_id`,
+	"minutes": `SELECT user_id, COALESCE(SUM(duration_seconds), 0) / 60.0 as activity FROM sessions
+		WHERE classroom_id = ? AND start_time BETWEEN ? AND ? GROUP BY user_id`,
+}
+
+// GetClassroomParticipationEquity measures how evenly activity is spread across
+// a classroom's actively enrolled students with the Gini coefficient of their
+// totals (events by default, or session minutes with ?metric=minutes) and the
+// Lorenz curve behind it. Students with no activity count as zero. The
+// coefficient is 0 when everyone is equally active and null when nobody was.
+func (h *ReportingHandler) GetClassroomParticipationEquity(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+		return
+	}
+
+	metric := c.DefaultQuery("metric", "events")
+	activitySQL, ok := equityActivitySQL[metric]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metric must be events or minutes"})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var rows []struct {
+		UserID   uuid.UUID
+		Activity float64
+	}
+	err = h.db.Table("user_classrooms uc").
+		Select("uc.user_id, COALESCE(a.activity, 0) as activity").
+		Joins("JOIN users u ON u.id = uc.user_id").
+		Joins("LEFT JOIN ("+activitySQL+") a ON a.user_id = uc.user_id", classroomID, dateFrom, dateTo).
+		Where("uc.classroom_id = ? AND uc.is_active = true AND u.role = 'student'", classroomID).
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch student activity", "details": err.Error()})
+		return
+	}
+
+	activity := make([]float64, len(rows))
+	total, active := 0.0, 0
+	for i, row := range rows {
+		activity[i] = row.Activity
+		total += row.Activity
+		if row.Activity > 0 {
+			active++
+		}
+	}
+
+	response := gin.H{
+		"classroom_id":    classroomID,
+		"period":          gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"metric":          metric,
+		"students":        len(rows),
+		"active_students": active,
+		"total_activity":  roundTo(total, 2),
+		"gini":            nil,
+		"lorenz_curve":    lorenzCurve(activity),
+	}
+	if gini, ok := giniCoefficient(activity); ok {
+		response["gini"] = roundTo(gini, 4)
+	} else if len(rows) > 0 {
+		response["gini_unavailable_reason"] = "No student activity in the period"
+	} else {
+		response["gini_unavailable_reason"] = "Classroom has no actively enrolled students"
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PendingQuiz is a published quiz the student has not yet completed
+type PendingQuiz struct {
+	QuizID           uuid.UUID  `json:"quiz_id"`
+	Title            string     `json:"title"`
+	ClassroomID      uuid.UUID  `json:"classroom_id"`
+	ClassroomName    string     `json:"classroom_name"`
+	TotalQuestions   int        `json:"total_questions"`
+	TimeLimitMinutes *int       `json:"time_limit_minutes"`
+	DueAt            *time.Time `json:"due_at"`
+	DaysRemaining    *int       `json:"days_remaining"` // whole days until due; negative when overdue, null without a due date
+	Overdue          bool       `json:"overdue"`
+	InProgress       bool       `json:"in_progress"` // the student has started but not completed an attempt
+}
+
+// GetStudentPendingQuizzes lists published quizzes in the student's active
+// classrooms without a completed quiz session, most urgent first. Quizzes
+// whose end_time has passed are left out unless include_overdue=true.
+func (h *ReportingHandler) GetStudentPendingQuizzes(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's pending quizzes") {
+		return
+	}
+
+	includeOverdue := c.Query("include_overdue") == "true"
+	now := time.Now()
+
+	query := h.db.Table("quizzes q").
+		Select(`
+			q.id as quiz_id, q.title, q.classroom_id, cl.name as classroom_name,
+			q.total_questions, q.time_limit_minutes, q.end_time as due_at,
+			EXISTS (
+				SELECT 1 FROM quiz_sessions qs
+				WHERE qs.quiz_id = q.id AND qs.student_id = ? AND qs.is_completed = false
+			) as in_progress
+		`, studentID).
+		Joins("JOIN user_classrooms uc ON uc.classroom_id = q.classroom_id AND uc.user_id = ? AND uc.is_active = true", studentID).
+		Joins("JOIN classrooms cl ON cl.id = q.classroom_id").
+		Where("q.is_active = true AND q.deleted_at IS NULL").
+		Where("q.start_time IS NULL OR q.start_time <= ?", now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM quiz_sessions qs
+			WHERE qs.quiz_id = q.id AND qs.student_id = ? AND qs.is_completed = true
+		)`, studentID)
+
+	if !includeOverdue {
+		query = query.Where("q.end_time IS NULL OR q.end_time >= ?", now)
+	}
+
+	pending := []PendingQuiz{}
+	if err := query.Order("q.end_time ASC NULLS LAST, q.created_at ASC").Scan(&pending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pending quizzes", "details": err.Error()})
+		return
+	}
+
+	overdueCount := 0
+	for i := range pending {
+		if pending[i].DueAt == nil {
+			continue
+		}
+		days := int(math.Floor(pending[i].DueAt.Sub(now).Hours() / 24))
+		pending[i].DaysRemaining = &days
+		pending[i].Overdue = pending[i].DueAt.Before(now)
+		if pending[i].Overdue {
+			overdueCount++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":      studentID,
+		"as_of":           now,
+		"pending_quizzes": pending,
+		"total_pending":   len(pending),
+		"overdue_count":   overdueCount,
+	})
+}
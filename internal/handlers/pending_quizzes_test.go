@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetStudentPendingQuizzes(t *testing.T) {
+	studentID := uuid.New()
+	classroomID := uuid.New()
+	pendingQuery := []string{"FROM quizzes q", "NOT EXISTS"}
+	columns := []string{"quiz_id", "title", "classroom_id", "classroom_name", "total_questions", "time_limit_minutes", "due_at", "in_progress"}
+	route := "/students/:id/pending-quizzes"
+	target := "/students/" + studentID.String() + "/pending-quizzes"
+	quiz := func(title string, due interface{}, inProgress bool) []driver.Value {
+		return []driver.Value{uuid.New().String(), title, classroomID.String(), "Algebra", int64(10), nil, due, inProgress}
+	}
+
+	t.Run("lists pending quizzes most urgent first", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		now := time.Now()
+		// The database leaves out completed quizzes and orders by due date
+		fake.rows(pendingQuery, columns,
+			quiz("Due soon", now.Add(36*time.Hour), true),
+			quiz("Due later", now.Add(10*24*time.Hour+time.Hour), false),
+			quiz("No due date", nil, false))
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentPendingQuizzes, route, http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		if body["total_pending"] != 3.0 || body["overdue_count"] != 0.0 {
+			t.Errorf("totals = %v pending, %v overdue, want 3 and 0", body["total_pending"], body["overdue_count"])
+		}
+		pending := body["pending_quizzes"].([]interface{})
+		first, last := pending[0].(map[string]interface{}), pending[2].(map[string]interface{})
+		if first["title"] != "Due soon" || first["days_remaining"] != 1.0 || first["in_progress"] != true {
+			t.Errorf("first = %v, want the quiz due soonest, 1 day left, in progress", first)
+		}
+		if pending[1].(map[string]interface{})["days_remaining"] != 10.0 {
+			t.Errorf("second days_remaining = %v, want 10", pending[1].(map[string]interface{})["days_remaining"])
+		}
+		if last["days_remaining"] != nil || last["overdue"] != false {
+			t.Errorf("undated quiz = %v, want no days remaining", last)
+		}
+
+		ran := fake.ran(pendingQuery...)
+		if len(ran) != 1 {
+			t.Fatalf("ran %d queries, want 1", len(ran))
+		}
+		wantSQL := []string{
+			"qs.is_completed = true",
+			"q.end_time IS NULL OR q.end_time >= $",
+			"ORDER BY q.end_time ASC NULLS LAST, q.created_at ASC",
+		}
+		if !containsAll(ran[0].SQL, wantSQL) {
+			t.Errorf("query = %s, want completed quizzes and past windows excluded, ordered by due date", ran[0].SQL)
+		}
+	})
+
+	t.Run("include_overdue keeps past windows", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(pendingQuery, columns,
+			quiz("Missed", time.Now().Add(-49*time.Hour), false),
+			quiz("Upcoming", time.Now().Add(49*time.Hour), false))
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentPendingQuizzes, route, http.MethodGet, target+"?include_overdue=true", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		if body["overdue_count"] != 1.0 {
+			t.Errorf("overdue_count = %v, want 1", body["overdue_count"])
+		}
+		missed := body["pending_quizzes"].([]interface{})[0].(map[string]interface{})
+		if missed["overdue"] != true || missed["days_remaining"] != -3.0 {
+			t.Errorf("missed quiz = %v, want overdue with -3 days remaining", missed)
+		}
+		if ran := fake.ran(pendingQuery...); len(ran) != 1 || containsAll(ran[0].SQL, []string{"q.end_time >= $"}) {
+			t.Errorf("query = %v, want no due date cut-off", ran)
+		}
+	})
+
+	t.Run("another student's token", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		values := map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}
+		w := testRequest(h.GetStudentPendingQuizzes, route, http.MethodGet, target, "", values)
+		expectStatus(t, w, http.StatusForbidden)
+		if len(fake.ran()) != 0 {
+			t.Error("a forbidden request reached the database")
+		}
+	})
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -10,12 +11,13 @@ import (
 
 // GenericQueryBuilder handles cube.dev style queries
 type GenericQueryBuilder struct {
-	db *gorm.DB
+	db              *gorm.DB
+	fiscalYearStart time.Month // first month of time.fiscal_year
 }
 
 // NewGenericQueryBuilder creates a new query builder
 func NewGenericQueryBuilder(db *gorm.DB) *GenericQueryBuilder {
-	return &GenericQueryBuilder{db: db}
+	return &GenericQueryBuilder{db: db, fiscalYearStart: time.January}
 }
 
 
@@ -23,13 +25,19 @@ func NewGenericQueryBuilder(db *gorm.DB) *GenericQueryBuilder {
 type CubeSchema struct {
 	Measures   map[string]MeasureDefinition   `json:"measures"`
 	Dimensions map[string]DimensionDefinition `json:"dimensions"`
+
+	// Filter operators and time granularities the schema's query builder
+	// supports; ValidateQuery assumes GenericQueryBuilder's when unset
+	filterOperators map[string]filterOperator
+	granularities   map[string]bool
 }
 
 type MeasureDefinition struct {
-	Type        string `json:"type"`        // count, sum, avg, min, max
-	SQL         string `json:"sql"`         // SQL expression
-	Table       string `json:"table"`       // Source table
-	Description string `json:"description"` // Human readable description
+	Type        string `json:"type"`                  // count, sum, avg, min, max, rolling_count_distinct
+	SQL         string `json:"sql"`                   // SQL expression
+	Table       string `json:"table"`                 // Source table
+	Description string `json:"description"`           // Human readable description
+	WindowDays  int    `json:"window_days,omitempty"` // Trailing window size for rolling measures
 }
 
 type DimensionDefinition struct {
@@ -56,6 +64,13 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 				Table:       "events",
 				Description: "Number of unique users generating events",
 			},
+			"events.rolling_7d_active_users": {
+				Type:        MeasureTypeRollingCountDistinct,
+				SQL:         "user_id",
+				Table:       "events",
+				Description: "Distinct users with events in the trailing 7 days, per day",
+				WindowDays:  7,
+			},
 
 			// Session measures
 			"sessions.count": {
@@ -110,6 +125,12 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 				Table:       "quiz_sessions",
 				Description: "Quiz completion rate percentage",
 			},
+			"quiz_sessions.avg_questions_answered": {
+				Type:        "avg",
+				SQL:         "AVG(" + answeredPerSessionSQL + ")",
+				Table:       "quiz_sessions",
+				Description: "Average questions answered per quiz session, including sessions with no answers",
+			},
 
 			// Content measures
 			"content.count": {
@@ -151,17 +172,29 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 				Table:       "events",
 				Description: "Month of the event",
 			},
+			"time.iso_week": {
+				Type:        "string",
+				SQL:         isoWeekSQL,
+				Table:       "events",
+				Description: "ISO 8601 week of the event (e.g. 2025-W01)",
+			},
+			"time.fiscal_year": {
+				Type:        "number",
+				SQL:         fiscalYearSQL(q.fiscalYearStart),
+				Table:       "events",
+				Description: fmt.Sprintf("Fiscal year of the event, starting in %s and named by the year it ends in", q.fiscalYearStart),
+			},
 
 			// User dimensions
 			"users.role": {
 				Type:        "string",
-				SQL:         "role",
+				SQL:         "u.role",
 				Table:       "users",
 				Description: "User role (teacher, student, admin)",
 			},
 			"users.school_id": {
 				Type:        "string",
-				SQL:         "school_id::text",
+				SQL:         "u.school_id::text",
 				Table:       "users",
 				Description: "School identifier",
 			},
@@ -187,6 +220,12 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 				Table:       "sessions",
 				Description: "Session application type",
 			},
+			"sessions.network_bucket": {
+				Type:        "string",
+				SQL:         "network_bucket",
+				Table:       "sessions",
+				Description: "Where the session was started from (on_network, off_network, unknown)",
+			},
 
 			// Content dimensions
 			"content.type": {
@@ -199,25 +238,25 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 			// School/Classroom dimensions
 			"schools.name": {
 				Type:        "string",
-				SQL:         "s.name",
+				SQL:         "sch.name",
 				Table:       "schools",
 				Description: "School name",
 			},
 			"classrooms.name": {
 				Type:        "string",
-				SQL:         "c.name",
+				SQL:         "cl.name",
 				Table:       "classrooms",
 				Description: "Classroom name",
 			},
 			"classrooms.grade_level": {
 				Type:        "number",
-				SQL:         "c.grade_level",
+				SQL:         "cl.grade_level",
 				Table:       "classrooms",
 				Description: "Classroom grade level",
 			},
 			"classrooms.subject": {
 				Type:        "string",
-				SQL:         "c.subject",
+				SQL:         "cl.subject",
 				Table:       "classrooms",
 				Description: "Classroom subject",
 			},
@@ -225,63 +264,86 @@ func (q *GenericQueryBuilder) GetSchema() CubeSchema {
 	}
 }
 
-// ExecuteQuery executes a cube.dev style query
-func (q *GenericQueryBuilder) ExecuteQuery(queryReq interface{}) ([]map[string]interface{}, error) {
+// ExecuteQuery executes a cube.dev style query and returns typed rows with column metadata
+func (q *GenericQueryBuilder) ExecuteQuery(queryReq interface{}) (*CubeQueryResult, error) {
+	query, args, columns, err := q.prepareQuery(queryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execute query
+	var results []map[string]interface{}
+	if err := q.db.Raw(query, args...).Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	return newQueryResult(columns, results)
+}
+
+// prepareQuery builds the SQL, bind arguments and result columns of a
+// cube.dev style query
+func (q *GenericQueryBuilder) prepareQuery(queryReq interface{}) (string, []interface{}, []QueryColumn, error) {
 	// Cast to proper type
 	req, ok := queryReq.(struct {
 		Measures       []string `json:"measures"`
 		Dimensions     []string `json:"dimensions"`
 		TimeDimensions []struct {
-			Dimension   string   `json:"dimension"`
-			Granularity string   `json:"granularity"`
-			DateRange   []string `json:"dateRange"`
+			Dimension   string        `json:"dimension"`
+			Granularity string        `json:"granularity"`
+			DateRange   cubeDateRange `json:"dateRange"`
 		} `json:"timeDimensions"`
 		Filters []struct {
 			Member   string   `json:"member"`
 			Operator string   `json:"operator"`
 			Values   []string `json:"values"`
 		} `json:"filters"`
-		Order [][]string `json:"order"`
-		Limit int        `json:"limit"`
+		Having []cubeFilter `json:"having"`
+		Order  [][]string   `json:"order"`
+		Limit  int          `json:"limit"`
 	})
 	if !ok {
-		return nil, fmt.Errorf("invalid query request type")
+		return "", nil, nil, fmt.Errorf("invalid query request type")
 	}
 
 	schema := q.GetSchema()
 
 	// Build SQL query
-	query, err := q.buildSQL(req, schema)
+	query, args, err := q.buildSQL(req, schema)
 	if err != nil {
-		return nil, err
-	}
-
-	// Execute query
-	var results []map[string]interface{}
-	if err := q.db.Raw(query).Scan(&results).Error; err != nil {
-		return nil, fmt.Errorf("query execution failed: %w", err)
+		return "", nil, nil, err
 	}
 
-	return results, nil
+	return query, args, resultColumns(req.Measures, req.Dimensions, req.TimeDimensions, schema), nil
 }
 
-// buildSQL constructs the SQL query from the cube request
+// buildSQL constructs the SQL query from the cube request. Schema SQL is
+// trusted and inlined; request values are returned as bind arguments for the
+// query's ? placeholders.
 func (q *GenericQueryBuilder) buildSQL(req struct {
 	Measures       []string `json:"measures"`
 	Dimensions     []string `json:"dimensions"`
 	TimeDimensions []struct {
-		Dimension   string   `json:"dimension"`
-		Granularity string   `json:"granularity"`
-		DateRange   []string `json:"dateRange"`
+		Dimension   string        `json:"dimension"`
+		Granularity string        `json:"granularity"`
+		DateRange   cubeDateRange `json:"dateRange"`
 	} `json:"timeDimensions"`
 	Filters []struct {
 		Member   string   `json:"member"`
 		Operator string   `json:"operator"`
 		Values   []string `json:"values"`
 	} `json:"filters"`
-	Order [][]string `json:"order"`
-	Limit int        `json:"limit"`
-}, schema CubeSchema) (string, error) {
+	Having []cubeFilter `json:"having"`
+	Order  [][]string   `json:"order"`
+	Limit  int          `json:"limit"`
+}, schema CubeSchema) (string, []interface{}, error) {
+
+	// Windowed measures need a different query shape over a day series
+	if hasRollingMeasure(req.Measures, schema) {
+		if len(req.Having) > 0 {
+			return "", nil, fmt.Errorf("having is not supported with rolling measures")
+		}
+		return q.buildRollingSQL(req.Measures, req.Dimensions, req.TimeDimensions, req.Filters, req.Order, req.Limit, schema)
+	}
 
 	// Determine primary table and joins needed
 	tables := q.determineTables(req.Measures, req.Dimensions, schema)
@@ -328,18 +390,28 @@ func (q *GenericQueryBuilder) buildSQL(req struct {
 	}
 
 	if len(selectClauses) == 0 {
-		return "", fmt.Errorf("no valid measures or dimensions specified")
+		return "", nil, fmt.Errorf("no valid measures or dimensions specified")
 	}
 
 	// Build FROM clause with JOINs
 	fromClause := q.buildFromClause(primaryTable, tables)
 
 	// Build WHERE clause
-	whereClause := q.buildWhereClause(req.Filters, req.TimeDimensions, schema)
+	whereClause, args, err := q.buildWhereClause(req.Filters, req.TimeDimensions, schema)
+	if err != nil {
+		return "", nil, err
+	}
 
 	// Build GROUP BY clause
 	groupByClause := q.buildGroupByClause(req.Dimensions, req.TimeDimensions, schema)
 
+	// Build HAVING clause; its arguments follow the WHERE clause's
+	havingClause, havingArgs, err := q.buildHavingClause(req.Having, schema)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, havingArgs...)
+
 	// Build ORDER BY clause
 	orderByClause := q.buildOrderByClause(req.Order)
 
@@ -360,6 +432,10 @@ func (q *GenericQueryBuilder) buildSQL(req struct {
 		query += " GROUP BY " + groupByClause
 	}
 
+	if havingClause != "" {
+		query += " HAVING " + havingClause
+	}
+
 	if orderByClause != "" {
 		query += " ORDER BY " + orderByClause
 	}
@@ -368,7 +444,7 @@ func (q *GenericQueryBuilder) buildSQL(req struct {
 		query += " " + limitClause
 	}
 
-	return query, nil
+	return query, args, nil
 }
 
 // Helper methods for SQL building
@@ -393,7 +469,7 @@ func (q *GenericQueryBuilder) determineTables(measures, dimensions []string, sch
 
 func (q *GenericQueryBuilder) determinePrimaryTable(tables map[string]bool) string {
 	// Priority order for primary table selection
-	priority := []string{"events", "sessions", "users", "quizzes", "content", "schools", "classrooms"}
+	priority := []string{"events", "sessions", "users", "quizzes", "quiz_sessions", "content", "schools", "classrooms"}
 
 	for _, table := range priority {
 		if tables[table] {
@@ -477,80 +553,110 @@ func (q *GenericQueryBuilder) buildWhereClause(filters []struct {
 	Operator string   `json:"operator"`
 	Values   []string `json:"values"`
 }, timeDimensions []struct {
-	Dimension   string   `json:"dimension"`
-	Granularity string   `json:"granularity"`
-	DateRange   []string `json:"dateRange"`
-}, schema CubeSchema) string {
+	Dimension   string        `json:"dimension"`
+	Granularity string        `json:"granularity"`
+	DateRange   cubeDateRange `json:"dateRange"`
+}, schema CubeSchema) (string, []interface{}, error) {
 
 	conditions := []string{}
+	args := []interface{}{}
 
 	// Add filter conditions
 	for _, filter := range filters {
 		if def, exists := schema.Dimensions[filter.Member]; exists {
-			condition := q.buildFilterCondition(def.SQL, filter.Operator, filter.Values)
+			condition, conditionArgs := q.buildFilterCondition(def.SQL, filter.Operator, filter.Values)
 			if condition != "" {
 				conditions = append(conditions, condition)
+				args = append(args, conditionArgs...)
 			}
 		}
 	}
 
-	// Add time range conditions
+	// Add time range conditions; relative ranges resolve against the current date
+	now := time.Now().UTC()
 	for _, timeDim := range timeDimensions {
-		if def, exists := schema.Dimensions[timeDim.Dimension]; exists && len(timeDim.DateRange) == 2 {
-			condition := fmt.Sprintf("%s BETWEEN '%s' AND '%s'", def.SQL, timeDim.DateRange[0], timeDim.DateRange[1])
-			conditions = append(conditions, condition)
+		def, exists := schema.Dimensions[timeDim.Dimension]
+		if !exists {
+			continue
+		}
+		from, to, ok, err := resolveDateRange(timeDim.DateRange, now)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", timeDim.Dimension, err)
+		}
+		if ok {
+			conditions = append(conditions, fmt.Sprintf("%s BETWEEN ? AND ?", def.SQL))
+			args = append(args, from, to)
 		}
 	}
 
 	if len(conditions) == 0 {
-		return ""
+		return "", nil, nil
 	}
 
-	return strings.Join(conditions, " AND ")
+	return strings.Join(conditions, " AND "), args, nil
 }
 
-func (q *GenericQueryBuilder) buildFilterCondition(sql, operator string, values []string) string {
+// buildHavingClause turns measure filters into HAVING conditions on the
+// measures' aggregate SQL. Unlike dimension filters, an unknown measure or an
+// unusable operator is an error rather than silently ignored.
+func (q *GenericQueryBuilder) buildHavingClause(having []cubeFilter, schema CubeSchema) (string, []interface{}, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	for _, filter := range having {
+		def, exists := schema.Measures[filter.Member]
+		if !exists {
+			return "", nil, fmt.Errorf("unknown having measure %s", filter.Member)
+		}
+		condition, conditionArgs := q.buildFilterCondition(def.SQL, filter.Operator, filter.Values)
+		if condition == "" {
+			return "", nil, fmt.Errorf("invalid having operator %q or values for %s", filter.Operator, filter.Member)
+		}
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// buildFilterCondition returns the condition for one filter with its values
+// as bind arguments, or "" for an unsupported operator or value count
+func (q *GenericQueryBuilder) buildFilterCondition(sql, operator string, values []string) (string, []interface{}) {
 	switch operator {
 	case "equals":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s = '%s'", sql, values[0])
+			return fmt.Sprintf("%s = ?", sql), []interface{}{values[0]}
 		}
 	case "in":
 		if len(values) > 0 {
-			quotedValues := make([]string, len(values))
-			for i, v := range values {
-				quotedValues[i] = fmt.Sprintf("'%s'", v)
-			}
-			return fmt.Sprintf("%s IN (%s)", sql, strings.Join(quotedValues, ", "))
+			return fmt.Sprintf("%s IN ?", sql), []interface{}{values}
 		}
 	case "gt":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s > '%s'", sql, values[0])
+			return fmt.Sprintf("%s > ?", sql), []interface{}{values[0]}
 		}
 	case "gte":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s >= '%s'", sql, values[0])
+			return fmt.Sprintf("%s >= ?", sql), []interface{}{values[0]}
 		}
 	case "lt":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s < '%s'", sql, values[0])
+			return fmt.Sprintf("%s < ?", sql), []interface{}{values[0]}
 		}
 	case "lte":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s <= '%s'", sql, values[0])
+			return fmt.Sprintf("%s <= ?", sql), []interface{}{values[0]}
 		}
 	case "contains":
 		if len(values) == 1 {
-			return fmt.Sprintf("%s ILIKE '%%%s%%'", sql, values[0])
+			return fmt.Sprintf("%s ILIKE ?", sql), []interface{}{"%" + likeEscaper.Replace(values[0]) + "%"}
 		}
 	}
-	return ""
+	return "", nil
 }
 
 func (q *GenericQueryBuilder) buildGroupByClause(dimensions []string, timeDimensions []struct {
-	Dimension   string   `json:"dimension"`
-	Granularity string   `json:"granularity"`
-	DateRange   []string `json:"dateRange"`
+	Dimension   string        `json:"dimension"`
+	Granularity string        `json:"granularity"`
+	DateRange   cubeDateRange `json:"dateRange"`
 }, schema CubeSchema) string {
 
 	groupByClauses := []string{}
@@ -599,6 +705,10 @@ func (q *GenericQueryBuilder) buildOrderByClause(order [][]string) string {
 		if len(orderItem) >= 2 {
 			column := strings.ReplaceAll(orderItem[0], ".", "_")
 			direction := strings.ToUpper(orderItem[1])
+			// Columns can't be bound, so only plain result aliases are accepted
+			if !isSQLIdentifier(column) {
+				continue
+			}
 			if direction == "ASC" || direction == "DESC" {
 				orderClauses = append(orderClauses, fmt.Sprintf("%s %s", column, direction))
 			}
@@ -608,18 +718,36 @@ func (q *GenericQueryBuilder) buildOrderByClause(order [][]string) string {
 	return strings.Join(orderClauses, ", ")
 }
 
+// isSQLIdentifier reports whether s is a plain unquoted identifier
+func isSQLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
 // GetAvailableMetrics returns all available measures and dimensions
 func (q *GenericQueryBuilder) GetAvailableMetrics() gin.H {
 	schema := q.GetSchema()
 
 	measures := make([]gin.H, 0, len(schema.Measures))
 	for name, def := range schema.Measures {
-		measures = append(measures, gin.H{
+		measure := gin.H{
 			"name":        name,
 			"type":        def.Type,
 			"description": def.Description,
 			"table":       def.Table,
-		})
+		}
+		if def.WindowDays > 0 {
+			measure["window_days"] = def.WindowDays
+		}
+		measures = append(measures, measure)
 	}
 
 	dimensions := make([]gin.H, 0, len(schema.Dimensions))
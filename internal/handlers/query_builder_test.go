@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+const injection = "'; DROP TABLE users; --"
+
+func TestBuildFilterConditionBindsValues(t *testing.T) {
+	q := NewGenericQueryBuilder(nil)
+	tests := []struct {
+		operator string
+		values   []string
+		wantArgs []interface{}
+	}{
+		{"equals", []string{injection}, []interface{}{injection}},
+		{"in", []string{"teacher", injection}, []interface{}{[]string{"teacher", injection}}},
+		{"gt", []string{injection}, []interface{}{injection}},
+		{"gte", []string{injection}, []interface{}{injection}},
+		{"lt", []string{injection}, []interface{}{injection}},
+		{"lte", []string{injection}, []interface{}{injection}},
+		{"contains", []string{injection}, []interface{}{"%" + injection + "%"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			condition, args := q.buildFilterCondition("u.role", tt.operator, tt.values)
+			if condition == "" || strings.Contains(condition, "DROP") || strings.Contains(condition, "'") {
+				t.Errorf("condition %q does not bind the value", condition)
+			}
+			if !strings.Contains(condition, "?") {
+				t.Errorf("condition %q has no placeholder", condition)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildFilterConditionEscapesLikeWildcards(t *testing.T) {
+	_, args := NewGenericQueryBuilder(nil).buildFilterCondition("c.title", "contains", []string{`50%_off\`})
+	if want := `%50\%\_off\\%`; args[0] != want {
+		t.Errorf("contains argument = %q, want %q", args[0], want)
+	}
+}
+
+func TestExecuteQueryBindsFilterValues(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{
+		Measures: []string{"users.count"},
+		Filters:  []cubeFilter{{Member: "users.role", Operator: "equals", Values: []string{injection}}},
+	}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	statements := fake.ran("SELECT")
+	if len(statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(statements))
+	}
+	if strings.Contains(statements[0].SQL, "DROP TABLE") {
+		t.Errorf("filter value was inlined: %s", statements[0].SQL)
+	}
+	if !reflect.DeepEqual(statements[0].Args, []interface{}{injection}) {
+		t.Errorf("args = %#v, want the filter value bound", statements[0].Args)
+	}
+}
+
+func TestExecuteQueryHaving(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{
+		Measures:   []string{"events.count"},
+		Dimensions: []string{"events.type"},
+		Filters:    []cubeFilter{{Member: "events.application", Operator: "equals", Values: []string{"whiteboard"}}},
+		Having:     []cubeFilter{{Member: "events.count", Operator: "gte", Values: []string{"10"}}},
+	}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	statements := fake.ran("SELECT")
+	if len(statements) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(statements))
+	}
+	if !strings.Contains(statements[0].SQL, "GROUP BY event_type HAVING COUNT(*) >= $2") {
+		t.Errorf("SQL %q does not filter the aggregate after grouping", statements[0].SQL)
+	}
+	if want := []interface{}{"whiteboard", "10"}; !reflect.DeepEqual(statements[0].Args, want) {
+		t.Errorf("args = %#v, want %#v", statements[0].Args, want)
+	}
+}
+
+func TestBuildHavingClauseErrors(t *testing.T) {
+	q := NewGenericQueryBuilder(nil)
+	schema := q.GetSchema()
+	for _, having := range [][]cubeFilter{
+		{{Member: "events.type", Operator: "equals", Values: []string{"login"}}},
+		{{Member: "events.count", Operator: "between", Values: []string{"1"}}},
+		{{Member: "events.count", Operator: "gt"}},
+	} {
+		if _, _, err := q.buildHavingClause(having, schema); err == nil {
+			t.Errorf("buildHavingClause(%+v) succeeded, want an error", having)
+		}
+	}
+}
+
+func TestValidateQueryHaving(t *testing.T) {
+	schema := NewGenericQueryBuilder(nil).GetSchema()
+	problems := ValidateQuery(cubeQuery{
+		Measures: []string{"events.count"},
+		Having: []cubeFilter{
+			{Member: "events.type", Operator: "equals", Values: []string{"login"}},
+			{Member: "events.count", Operator: "gt", Values: []string{"10"}},
+			{Member: "events.count", Operator: "lt", Values: []string{"5"}},
+		},
+	}, schema)
+	want := []QueryValidationError{
+		{"events.type", "unknown having measure"},
+		{"events.count", "lower and upper bound filters leave no values"},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("ValidateQuery = %v, want %v", problems, want)
+	}
+
+	rolling := ValidateQuery(cubeQuery{
+		Measures:       []string{"events.rolling_7d_active_users"},
+		TimeDimensions: []cubeTimeDimension{{Dimension: "time.date", Granularity: "day"}},
+		Having:         []cubeFilter{{Member: "events.rolling_7d_active_users", Operator: "gt", Values: []string{"1"}}},
+	}, schema)
+	if len(rolling) != 1 || rolling[0].Member != "having" {
+		t.Errorf("ValidateQuery with a rolling measure = %v, want having rejected", rolling)
+	}
+}
+
+func TestGenericQueryEndpoint(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM events e LEFT JOIN users u ON e.user_id = u.id"}, []string{"events_count", "users_role"},
+		[]driver.Value{int64(120), "student"},
+		[]driver.Value{int64(8), "teacher"})
+	h := NewReportingHandler(db)
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api"))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(`{"measures":["events.count"],"dimensions":["users.role"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	expectStatus(t, w, http.StatusOK)
+
+	ran := fake.ran("FROM events e")
+	if len(ran) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(ran))
+	}
+	want := []string{"SELECT COUNT(*) AS events_count, u.role AS users_role", "LEFT JOIN users u ON e.user_id = u.id", "GROUP BY u.role"}
+	if !containsAll(ran[0].SQL, want) {
+		t.Errorf("query = %s, want events joined to users and grouped by role", ran[0].SQL)
+	}
+
+	body := decodeBody(t, w)
+	data := body["data"].([]interface{})
+	if len(data) != 2 || !reflect.DeepEqual(data[0], map[string]interface{}{"events_count": 120.0, "users_role": "student"}) {
+		t.Errorf("data = %v, want a row per role", data)
+	}
+	columns := body["columns"].([]interface{})
+	if len(columns) != 2 || columns[0].(map[string]interface{})["member"] != "events.count" || columns[1].(map[string]interface{})["type"] != ColumnTypeString {
+		t.Errorf("columns = %v, want events.count then users.role", columns)
+	}
+	query := body["query"].(map[string]interface{})
+	if !reflect.DeepEqual(query["dimensions"], []interface{}{"users.role"}) {
+		t.Errorf("query = %v, want the request echoed", query)
+	}
+	if _, ok := body["executedAt"].(string); !ok {
+		t.Errorf("executedAt = %v, want a timestamp", body["executedAt"])
+	}
+}
+
+func TestExecuteQueryJoinAliases(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{
+		Measures:   []string{"sessions.count"},
+		Dimensions: []string{"schools.name", "classrooms.name", "classrooms.subject", "users.school_id"},
+	}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	ran := fake.ran("FROM sessions s")
+	if len(ran) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(ran))
+	}
+	// Every dimension is qualified with the alias its table is joined under
+	want := []string{
+		"sch.name AS schools_name", "cl.name AS classrooms_name", "cl.subject AS classrooms_subject", "u.school_id::text AS users_school_id",
+		"LEFT JOIN users u ON s.user_id = u.id", "LEFT JOIN classrooms cl ON s.classroom_id = cl.id", "LEFT JOIN schools sch ON cl.school_id = sch.id",
+	}
+	if !containsAll(ran[0].SQL, want) {
+		t.Errorf("query = %s, want dimensions matching the join aliases", ran[0].SQL)
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Default generic query cache: results kept per instance, and how long each
+// is served before the query runs again
+const (
+	DefaultQueryCacheSize = 256
+	DefaultQueryCacheTTL  = 30 * time.Second
+)
+
+// queryCacheEntry is a cached generic query result
+type queryCacheEntry struct {
+	key      string
+	result   *CubeQueryResult
+	storedAt time.Time
+}
+
+// queryResultCache is a least-recently-used cache of generic query results
+// whose entries expire after ttl. It is safe for concurrent use; cached
+// results are shared between responses and must not be modified.
+type queryResultCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // most recently used at the front
+}
+
+func newQueryResultCache(size int, ttl time.Duration) *queryResultCache {
+	return &queryResultCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// SetQueryCache configures the generic query result cache. A size below 1
+// or a non-positive ttl disables caching.
+func (h *ReportingHandler) SetQueryCache(size int, ttl time.Duration) {
+	if size < 1 || ttl <= 0 {
+		h.queryCache = nil
+		return
+	}
+	h.queryCache = newQueryResultCache(size, ttl)
+}
+
+// get returns a fresh cached result and when it was stored
+func (qc *queryResultCache) get(key string) (*CubeQueryResult, time.Time, bool) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	element, ok := qc.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	entry := element.Value.(*queryCacheEntry)
+	if time.Since(entry.storedAt) > qc.ttl {
+		qc.order.Remove(element)
+		delete(qc.entries, key)
+		return nil, time.Time{}, false
+	}
+	qc.order.MoveToFront(element)
+	return entry.result, entry.storedAt, true
+}
+
+// put stores a result, evicting the least recently used entry when full
+func (qc *queryResultCache) put(key string, result *CubeQueryResult) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	if element, ok := qc.entries[key]; ok {
+		entry := element.Value.(*queryCacheEntry)
+		entry.result, entry.storedAt = result, time.Now()
+		qc.order.MoveToFront(element)
+		return
+	}
+
+	qc.entries[key] = qc.order.PushFront(&queryCacheEntry{key: key, result: result, storedAt: time.Now()})
+	for qc.order.Len() > qc.size {
+		oldest := qc.order.Back()
+		qc.order.Remove(oldest)
+		delete(qc.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// queryCacheKey hashes the parsed query, so requests differing only in JSON
+// whitespace or key order share an entry. The fiscal year start is part of
+// the key because it changes what time.fiscal_year groups by.
+func queryCacheKey(queryReq cubeQuery, fiscalYearStart time.Month) (string, error) {
+	data, err := json.Marshal(struct {
+		Query           cubeQuery  `json:"query"`
+		FiscalYearStart time.Month `json:"fiscal_year_start"`
+	}{queryReq, fiscalYearStart})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
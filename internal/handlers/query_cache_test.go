@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQueryResultCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newQueryResultCache(2, time.Minute)
+	a, b, c := &CubeQueryResult{}, &CubeQueryResult{}, &CubeQueryResult{}
+	cache.put("a", a)
+	cache.put("b", b)
+	if _, _, ok := cache.get("a"); !ok {
+		t.Fatal("a missing before eviction")
+	}
+	cache.put("c", c)
+
+	if _, _, ok := cache.get("b"); ok {
+		t.Error("b survived, want the least recently used entry evicted")
+	}
+	for key, want := range map[string]*CubeQueryResult{"a": a, "c": c} {
+		if got, _, ok := cache.get(key); !ok || got != want {
+			t.Errorf("get(%q) = %p, %v, want %p", key, got, ok, want)
+		}
+	}
+}
+
+func TestQueryResultCachePutReplaces(t *testing.T) {
+	cache := newQueryResultCache(1, time.Minute)
+	first, second := &CubeQueryResult{}, &CubeQueryResult{}
+	cache.put("a", first)
+	cache.put("a", second)
+	if got, _, ok := cache.get("a"); !ok || got != second {
+		t.Errorf("get = %p, %v, want the replacement %p", got, ok, second)
+	}
+	if cache.order.Len() != 1 {
+		t.Errorf("cache holds %d entries, want 1", cache.order.Len())
+	}
+}
+
+func TestQueryResultCacheExpires(t *testing.T) {
+	cache := newQueryResultCache(2, time.Minute)
+	cache.put("a", &CubeQueryResult{})
+	cache.entries["a"].Value.(*queryCacheEntry).storedAt = time.Now().Add(-2 * time.Minute)
+
+	if _, _, ok := cache.get("a"); ok {
+		t.Error("expired entry was served")
+	}
+	if _, ok := cache.entries["a"]; ok || cache.order.Len() != 0 {
+		t.Error("expired entry was not dropped")
+	}
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	query := cubeQuery{Measures: []string{"events.count"}, Dimensions: []string{"events.type"}}
+	key, err := queryCacheKey(query, time.January)
+	if err != nil {
+		t.Fatalf("queryCacheKey: %v", err)
+	}
+
+	same, _ := queryCacheKey(cubeQuery{Measures: []string{"events.count"}, Dimensions: []string{"events.type"}}, time.January)
+	if same != key {
+		t.Error("identical queries got different keys")
+	}
+	if other, _ := queryCacheKey(query, time.September); other == key {
+		t.Error("fiscal year start does not change the key")
+	}
+	query.Limit = 10
+	if other, _ := queryCacheKey(query, time.January); other == key {
+		t.Error("limit does not change the key")
+	}
+}
+
+func TestExecuteGenericQueryCachesResults(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	run := func(target, body string) map[string]interface{} {
+		w := testRequest(h.ExecuteGenericQuery, "/query", http.MethodPost, target, body, nil)
+		expectStatus(t, w, http.StatusOK)
+		return decodeBody(t, w)
+	}
+	cached := func(body map[string]interface{}) bool {
+		return body["meta"].(map[string]interface{})["cached"].(bool)
+	}
+
+	// Whitespace and key order do not split the cache
+	if cached(run("/query", `{"measures":["events.count"],"dimensions":["events.type"]}`)) {
+		t.Error("first run served from cache")
+	}
+	if !cached(run("/query", `{ "dimensions": ["events.type"], "measures": ["events.count"] }`)) {
+		t.Error("repeat run was not served from cache")
+	}
+	if len(fake.ran("SELECT")) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(fake.ran("SELECT")))
+	}
+
+	if cached(run("/query?no_cache=true", `{"measures":["events.count"],"dimensions":["events.type"]}`)) {
+		t.Error("no_cache=true served from cache")
+	}
+	if len(fake.ran("SELECT")) != 2 {
+		t.Errorf("ran %d queries, want no_cache to run again", len(fake.ran("SELECT")))
+	}
+
+	h.SetQueryCache(0, time.Minute)
+	run("/query", `{"measures":["events.count"],"dimensions":["events.type"]}`)
+	if cached(run("/query", `{"measures":["events.count"],"dimensions":["events.type"]}`)) {
+		t.Error("served from cache with caching disabled")
+	}
+	if len(fake.ran("SELECT")) != 4 {
+		t.Errorf("ran %d queries, want every run to reach the database", len(fake.ran("SELECT")))
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isoWeekSQL labels a timestamp with its ISO 8601 week, e.g. 2025-W01. The
+// year is the ISO week-numbering year, so the last days of December can
+// belong to week 1 of the next year.
+const isoWeekSQL = `TO_CHAR(created_at, 'IYYY-"W"IW')`
+
+// fiscalYearSQL numbers a timestamp's fiscal year by the calendar year it
+// ends in: with a September start, 2024-09-01 through 2025-08-31 is 2025. A
+// January start is the calendar year.
+func fiscalYearSQL(start time.Month) string {
+	shift := (13 - int(start)) % 12
+	if shift == 0 {
+		return "EXTRACT(YEAR FROM created_at)::int"
+	}
+	return fmt.Sprintf("EXTRACT(YEAR FROM created_at + INTERVAL '%d months')::int", shift)
+}
+
+// SetFiscalYearStart sets the month the time.fiscal_year dimension's years
+// start in
+func (q *GenericQueryBuilder) SetFiscalYearStart(month time.Month) error {
+	if err := validateFiscalYearStart(month); err != nil {
+		return err
+	}
+	q.fiscalYearStart = month
+	return nil
+}
+
+// SetFiscalYearStart sets the fiscal year start month for generic queries
+func (h *ReportingHandler) SetFiscalYearStart(month time.Month) error {
+	if err := validateFiscalYearStart(month); err != nil {
+		return err
+	}
+	h.fiscalYearStart = month
+	return nil
+}
+
+// queryBuilder returns a GenericQueryBuilder with the handler's calendar settings
+func (h *ReportingHandler) queryBuilder() *GenericQueryBuilder {
+	q := NewGenericQueryBuilder(h.db)
+	q.fiscalYearStart = h.fiscalYearStart
+	return q
+}
+
+// ParseFiscalYearStart parses a month number (1-12) or English month name
+// such as "september"; empty means January
+func ParseFiscalYearStart(s string) (time.Month, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return time.January, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if err := validateFiscalYearStart(time.Month(n)); err != nil {
+			return 0, err
+		}
+		return time.Month(n), nil
+	}
+	for month := time.January; month <= time.December; month++ {
+		if name := strings.ToLower(month.String()); s == name || s == name[:3] {
+			return month, nil
+		}
+	}
+	return 0, fmt.Errorf("fiscal year start must be a month number or name, got %q", s)
+}
+
+func validateFiscalYearStart(month time.Month) error {
+	if month < time.January || month > time.December {
+		return fmt.Errorf("fiscal year start month must be between 1 and 12, got %d", int(month))
+	}
+	return nil
+}
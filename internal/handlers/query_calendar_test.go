@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFiscalYearSQL(t *testing.T) {
+	tests := []struct {
+		start time.Month
+		want  string
+	}{
+		{time.January, "EXTRACT(YEAR FROM created_at)::int"},
+		{time.February, "EXTRACT(YEAR FROM created_at + INTERVAL '11 months')::int"},
+		{time.July, "EXTRACT(YEAR FROM created_at + INTERVAL '6 months')::int"},
+		{time.September, "EXTRACT(YEAR FROM created_at + INTERVAL '4 months')::int"},
+		{time.December, "EXTRACT(YEAR FROM created_at + INTERVAL '1 months')::int"},
+	}
+	for _, tt := range tests {
+		if got := fiscalYearSQL(tt.start); got != tt.want {
+			t.Errorf("fiscalYearSQL(%s) = %s, want %s", tt.start, got, tt.want)
+		}
+	}
+}
+
+// fiscalYearOf evaluates fiscalYearSQL's expression for one timestamp the way
+// Postgres does: shift by the interval, then take the calendar year
+func fiscalYearOf(t *testing.T, start time.Month, at time.Time) int {
+	t.Helper()
+	sql := fiscalYearSQL(start)
+	if start == time.January {
+		return at.Year()
+	}
+	var months int
+	if _, err := fmt.Sscanf(sql, "EXTRACT(YEAR FROM created_at + INTERVAL '%d months')::int", &months); err != nil {
+		t.Fatalf("unexpected fiscal year SQL %q: %v", sql, err)
+	}
+	return at.AddDate(0, months, 0).Year()
+}
+
+func TestFiscalYearBoundaries(t *testing.T) {
+	tests := []struct {
+		start time.Month
+		at    time.Time
+		want  int
+	}{
+		// A September start names 2024-09-01 through 2025-08-31 fiscal 2025
+		{time.September, time.Date(2024, 8, 31, 23, 59, 59, 0, time.UTC), 2024},
+		{time.September, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), 2025},
+		{time.September, time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC), 2025},
+		{time.September, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), 2025},
+		{time.September, time.Date(2025, 8, 31, 0, 0, 0, 0, time.UTC), 2025},
+		{time.July, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), 2025},
+		{time.July, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), 2026},
+		{time.December, time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC), 2025},
+		{time.December, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), 2026},
+		{time.January, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), 2025},
+		{time.January, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 2026},
+	}
+	for _, tt := range tests {
+		if got := fiscalYearOf(t, tt.start, tt.at); got != tt.want {
+			t.Errorf("%s start, %s: fiscal year %d, want %d", tt.start, tt.at.Format(DateFormat), got, tt.want)
+		}
+	}
+}
+
+func TestISOWeekBoundaries(t *testing.T) {
+	// IYYY and IW are the ISO week-numbering year and week; a plain YYYY would
+	// label the days around New Year with the wrong year
+	if !strings.Contains(isoWeekSQL, `'IYYY-"W"IW'`) {
+		t.Fatalf("isoWeekSQL = %s, want the ISO year and week", isoWeekSQL)
+	}
+	tests := map[string]string{
+		"2026-12-31": "2026-W53",
+		"2027-01-03": "2026-W53",
+		"2027-01-04": "2027-W01",
+		"2024-12-30": "2025-W01",
+		"2021-01-01": "2020-W53",
+	}
+	for date, want := range tests {
+		at, _ := time.Parse(DateFormat, date)
+		year, week := at.ISOWeek()
+		if got := fmt.Sprintf("%04d-W%02d", year, week); got != want {
+			t.Errorf("%s is in %s, want %s", date, got, want)
+		}
+	}
+}
+
+func TestExecuteQueryCalendarDimensions(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	if err := h.SetFiscalYearStart(time.September); err != nil {
+		t.Fatalf("SetFiscalYearStart: %v", err)
+	}
+	query := cubeQuery{
+		Measures:   []string{"events.count"},
+		Dimensions: []string{"time.fiscal_year", "time.iso_week"},
+		Filters:    []cubeFilter{{Member: "time.fiscal_year", Operator: "equals", Values: []string{"2025"}}},
+	}
+	if _, err := h.queryBuilder().ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	ran := fake.ran("FROM events e")
+	if len(ran) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(ran))
+	}
+	want := []string{
+		"EXTRACT(YEAR FROM created_at + INTERVAL '4 months')::int AS time_fiscal_year",
+		`TO_CHAR(created_at, 'IYYY-"W"IW') AS time_iso_week`,
+		"WHERE EXTRACT(YEAR FROM created_at + INTERVAL '4 months')::int = $1",
+	}
+	if !containsAll(ran[0].SQL, want) {
+		t.Errorf("query = %s, want fiscal years starting in September", ran[0].SQL)
+	}
+	if len(ran[0].Args) != 1 || ran[0].Args[0] != "2025" {
+		t.Errorf("args = %#v, want the fiscal year bound", ran[0].Args)
+	}
+}
+
+func TestParseFiscalYearStart(t *testing.T) {
+	for input, want := range map[string]time.Month{"": time.January, "9": time.September, " September ": time.September, "sep": time.September, "12": time.December} {
+		if got, err := ParseFiscalYearStart(input); err != nil || got != want {
+			t.Errorf("ParseFiscalYearStart(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"0", "13", "-1", "autumn"} {
+		if _, err := ParseFiscalYearStart(input); err == nil {
+			t.Errorf("ParseFiscalYearStart(%q) succeeded", input)
+		}
+	}
+	if err := NewReportingHandler(nil).SetFiscalYearStart(13); err == nil {
+		t.Error("SetFiscalYearStart(13) succeeded")
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cubeDateRange is a time dimension's dateRange: either a [from, to] pair of
+// dates or, as in cube.dev, a single relative token such as "last 7 days"
+// that is resolved when the query runs. A bare JSON string is accepted as a
+// one-element range.
+type cubeDateRange []string
+
+func (r *cubeDateRange) UnmarshalJSON(data []byte) error {
+	var token string
+	if err := json.Unmarshal(data, &token); err == nil {
+		*r = cubeDateRange{token}
+		return nil
+	}
+	var pair []string
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return fmt.Errorf("dateRange must be a [from, to] pair or a relative range such as \"last 7 days\"")
+	}
+	*r = pair
+	return nil
+}
+
+// Times appended to a resolved range's dates, so the range covers the whole
+// last day
+const (
+	dateRangeStartTime = "T00:00:00.000"
+	dateRangeEndTime   = "T23:59:59.999"
+)
+
+var lastPeriodsPattern = regexp.MustCompile(`^last (\d+) (day|week|month|year)s?$`)
+
+// resolveDateRange returns the bounds to filter a time dimension on. ok is
+// false when the range is empty and no filter applies. Date-only bounds of an
+// explicit pair are widened to whole days like relative ranges; bounds with
+// a time are kept as given. Relative tokens are
+// resolved against now in whole calendar days: "today", "yesterday",
+// "this week|month|year", "last week|month|year" (the previous calendar
+// period) and "last N days|weeks|months|years" (the N periods ending today).
+// Weeks start on Monday.
+func resolveDateRange(dateRange cubeDateRange, now time.Time) (from, to string, ok bool, err error) {
+	switch len(dateRange) {
+	case 0:
+		return "", "", false, nil
+	case 2:
+		return widenDate(dateRange[0], dateRangeStartTime), widenDate(dateRange[1], dateRangeEndTime), true, nil
+	case 1:
+	default:
+		return "", "", false, fmt.Errorf("dateRange must be a [from, to] pair or a relative range")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	yearStart := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+
+	var start, end time.Time
+	token := strings.Join(strings.Fields(strings.ToLower(dateRange[0])), " ")
+	switch token {
+	case "today":
+		start, end = today, today
+	case "yesterday":
+		start = today.AddDate(0, 0, -1)
+		end = start
+	case "this week":
+		start, end = weekStart, today
+	case "this month":
+		start, end = monthStart, today
+	case "this year":
+		start, end = yearStart, today
+	case "last week":
+		start, end = weekStart.AddDate(0, 0, -7), weekStart.AddDate(0, 0, -1)
+	case "last month":
+		start, end = monthStart.AddDate(0, -1, 0), monthStart.AddDate(0, 0, -1)
+	case "last year":
+		start, end = yearStart.AddDate(-1, 0, 0), yearStart.AddDate(0, 0, -1)
+	default:
+		match := lastPeriodsPattern.FindStringSubmatch(token)
+		if match == nil {
+			return "", "", false, fmt.Errorf("unrecognized relative dateRange %q", dateRange[0])
+		}
+		n, convErr := strconv.Atoi(match[1])
+		if convErr != nil || n < 1 {
+			return "", "", false, fmt.Errorf("unrecognized relative dateRange %q", dateRange[0])
+		}
+		end = today
+		switch match[2] {
+		case "day":
+			start = today.AddDate(0, 0, -(n - 1))
+		case "week":
+			start = today.AddDate(0, 0, -(7*n - 1))
+		case "month":
+			start = today.AddDate(0, -n, 1)
+		case "year":
+			start = today.AddDate(-n, 0, 1)
+		}
+	}
+	return start.Format(DateFormat) + dateRangeStartTime, end.Format(DateFormat) + dateRangeEndTime, true, nil
+}
+
+// widenDate appends clock to a bare YYYY-MM-DD date, so BETWEEN includes the
+// whole end day; anything else is returned unchanged
+func widenDate(bound, clock string) string {
+	if _, err := time.Parse(DateFormat, bound); err != nil {
+		return bound
+	}
+	return bound + clock
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCubeDateRangeUnmarshal(t *testing.T) {
+	tests := []struct {
+		input string
+		want  cubeDateRange
+	}{
+		{`"last 7 days"`, cubeDateRange{"last 7 days"}},
+		{`["2024-01-01","2024-01-31"]`, cubeDateRange{"2024-01-01", "2024-01-31"}},
+	}
+	for _, tt := range tests {
+		var got cubeDateRange
+		if err := json.Unmarshal([]byte(tt.input), &got); err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("unmarshal %s = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+
+	var got cubeDateRange
+	if err := json.Unmarshal([]byte(`7`), &got); err == nil {
+		t.Error("expected an error for a number")
+	}
+}
+
+func TestResolveDateRange(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2024, 3, 13, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		token    string
+		from, to string
+	}{
+		{"today", "2024-03-13", "2024-03-13"},
+		{"Yesterday", "2024-03-12", "2024-03-12"},
+		{"this week", "2024-03-11", "2024-03-13"},
+		{"this month", "2024-03-01", "2024-03-13"},
+		{"this year", "2024-01-01", "2024-03-13"},
+		{"last week", "2024-03-04", "2024-03-10"},
+		{"last month", "2024-02-01", "2024-02-29"},
+		{"last year", "2023-01-01", "2023-12-31"},
+		{"last 7 days", "2024-03-07", "2024-03-13"},
+		{"last  1 day", "2024-03-13", "2024-03-13"},
+		{"last 2 weeks", "2024-02-29", "2024-03-13"},
+		{"last 3 months", "2023-12-14", "2024-03-13"},
+		{"last 1 year", "2023-03-14", "2024-03-13"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			from, to, ok, err := resolveDateRange(cubeDateRange{tt.token}, now)
+			if err != nil || !ok {
+				t.Fatalf("resolveDateRange = %v, %v", ok, err)
+			}
+			if from != tt.from+dateRangeStartTime || to != tt.to+dateRangeEndTime {
+				t.Errorf("range = %s to %s, want %s to %s", from, to, tt.from, tt.to)
+			}
+		})
+	}
+
+	t.Run("explicit pair", func(t *testing.T) {
+		// Date-only bounds cover the whole last day, as relative ranges do
+		from, to, ok, err := resolveDateRange(cubeDateRange{"2024-01-01", "2024-01-31"}, now)
+		if from != "2024-01-01T00:00:00.000" || to != "2024-01-31T23:59:59.999" || !ok || err != nil {
+			t.Errorf("resolveDateRange = %s, %s, %v, %v", from, to, ok, err)
+		}
+	})
+
+	t.Run("explicit pair with times", func(t *testing.T) {
+		from, to, ok, err := resolveDateRange(cubeDateRange{"2024-01-01T08:00:00Z", "2024-01-31"}, now)
+		if from != "2024-01-01T08:00:00Z" || to != "2024-01-31T23:59:59.999" || !ok || err != nil {
+			t.Errorf("resolveDateRange = %s, %s, %v, %v", from, to, ok, err)
+		}
+	})
+
+	t.Run("no range", func(t *testing.T) {
+		if _, _, ok, err := resolveDateRange(nil, now); ok || err != nil {
+			t.Errorf("resolveDateRange(nil) = %v, %v, want no filter", ok, err)
+		}
+	})
+
+	for _, bad := range []cubeDateRange{{"next week"}, {"last 0 days"}, {"a", "b", "c"}} {
+		if _, _, _, err := resolveDateRange(bad, now); err == nil {
+			t.Errorf("resolveDateRange(%v) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestExecuteQueryResolvesRelativeRanges(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{
+		Measures:       []string{"events.count"},
+		TimeDimensions: []cubeTimeDimension{{Dimension: "time.date", Granularity: "day", DateRange: cubeDateRange{"today"}}},
+	}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	statements := fake.ran("SELECT")
+	today := time.Now().UTC().Format(DateFormat)
+	want := []interface{}{today + dateRangeStartTime, today + dateRangeEndTime}
+	if len(statements) != 1 || !reflect.DeepEqual(statements[0].Args, want) {
+		t.Errorf("statements = %v, want the range bound as %v", statements, want)
+	}
+}
+
+func TestExecuteQueryWidensExplicitDates(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{
+		Measures:       []string{"events.count"},
+		TimeDimensions: []cubeTimeDimension{{Dimension: "time.date", Granularity: "day", DateRange: cubeDateRange{"2024-01-01", "2024-01-31"}}},
+	}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	// Events after midnight on the 31st fall inside the range
+	statements := fake.ran("BETWEEN")
+	want := []interface{}{"2024-01-01T00:00:00.000", "2024-01-31T23:59:59.999"}
+	if len(statements) != 1 || !reflect.DeepEqual(statements[0].Args, want) {
+		t.Errorf("statements = %v, want the range bound as %v", statements, want)
+	}
+}
+
+func TestDateRangeReversed(t *testing.T) {
+	tests := []struct {
+		dateRange cubeDateRange
+		want      bool
+	}{
+		{cubeDateRange{"2024-01-31", "2024-01-01"}, true},
+		{cubeDateRange{"2024-01-05", "2024-01-05"}, false},
+		{cubeDateRange{"2024-01-01", "2024-01-31"}, false},
+		{cubeDateRange{"2024-01-05T12:00:00Z", "2024-01-05"}, false},
+		{cubeDateRange{"2024-01-06T00:00:00Z", "2024-01-05"}, true},
+	}
+	for _, tt := range tests {
+		from, to, _, err := resolveDateRange(tt.dateRange, time.Now())
+		if err != nil {
+			t.Fatalf("resolveDateRange(%v): %v", tt.dateRange, err)
+		}
+		if got := dateRangeReversed(from, to); got != tt.want {
+			t.Errorf("dateRangeReversed(%s, %s) = %v, want %v", from, to, got, tt.want)
+		}
+	}
+}
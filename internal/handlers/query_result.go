@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column types reported in query results
+const (
+	ColumnTypeNumber = "number"
+	ColumnTypeString = "string"
+	ColumnTypeTime   = "time"
+)
+
+// QueryColumn describes one column of a query result
+type QueryColumn struct {
+	Name   string `json:"name"`   // key used in each row
+	Member string `json:"member"` // schema member, e.g. events.count
+	Type   string `json:"type"`   // number, string or time
+}
+
+// CubeQueryResult carries column metadata alongside rows whose values have been
+// coerced to consistent Go types: float64 for numbers, string for strings and
+// time.Time (serialized as ISO-8601) for times. NULLs stay nil.
+type CubeQueryResult struct {
+	Columns []QueryColumn            `json:"columns"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+// resultColumns derives the result columns for a request from the schema,
+// using the same aliases the SQL builders assign
+func resultColumns(measures, dimensions []string, timeDims []cubeTimeDimension, schema CubeSchema) []QueryColumn {
+	var columns []QueryColumn
+	for _, measure := range measures {
+		if _, exists := schema.Measures[measure]; exists {
+			columns = append(columns, QueryColumn{Name: strings.ReplaceAll(measure, ".", "_"), Member: measure, Type: ColumnTypeNumber})
+		}
+	}
+	for _, dimension := range dimensions {
+		if def, exists := schema.Dimensions[dimension]; exists {
+			columns = append(columns, QueryColumn{Name: strings.ReplaceAll(dimension, ".", "_"), Member: dimension, Type: columnType(def.Type)})
+		}
+	}
+	for _, timeDim := range timeDims {
+		if _, exists := schema.Dimensions[timeDim.Dimension]; exists {
+			name := fmt.Sprintf("%s_%s", strings.ReplaceAll(timeDim.Dimension, ".", "_"), timeDim.Granularity)
+			columns = append(columns, QueryColumn{Name: name, Member: timeDim.Dimension, Type: ColumnTypeTime})
+		}
+	}
+	return columns
+}
+
+// columnType maps a schema dimension type onto a result column type
+func columnType(schemaType string) string {
+	switch schemaType {
+	case "number":
+		return ColumnTypeNumber
+	case "time":
+		return ColumnTypeTime
+	default:
+		return ColumnTypeString
+	}
+}
+
+// newQueryResult coerces raw driver rows into a typed result. Values that
+// cannot be converted produce an error rather than being silently passed through.
+func newQueryResult(columns []QueryColumn, rows []map[string]interface{}) (*CubeQueryResult, error) {
+	typed := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		out, err := coerceRow(columns, row)
+		if err != nil {
+			return nil, err
+		}
+		typed[i] = out
+	}
+	return &CubeQueryResult{Columns: columns, Rows: typed}, nil
+}
+
+// coerceRow converts one raw driver row to the result columns' Go types
+func coerceRow(columns []QueryColumn, row map[string]interface{}) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		value, err := coerceValue(row[column.Name], column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.Name, err)
+		}
+		out[column.Name] = value
+	}
+	return out, nil
+}
+
+// coerceValue converts a driver value to the Go type for the column type
+func coerceValue(value interface{}, columnType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	// DECIMAL and some text columns arrive as raw bytes
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	switch columnType {
+	case ColumnTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case float32:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case int32:
+			return float64(v), nil
+		case int:
+			return float64(v), nil
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", v)
+			}
+			return f, nil
+		}
+	case ColumnTypeTime:
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case string:
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", DateFormat} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("invalid time %q", v)
+		}
+	default:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(value), nil
+	}
+	return nil, fmt.Errorf("unsupported %s value of type %T", columnType, value)
+}
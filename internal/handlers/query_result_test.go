@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResultColumns(t *testing.T) {
+	schema := NewGenericQueryBuilder(nil).GetSchema()
+	columns := resultColumns(
+		[]string{"events.count", "unknown.measure"},
+		[]string{"classrooms.grade_level", "events.type"},
+		[]cubeTimeDimension{{Dimension: "time.date", Granularity: "day"}},
+		schema,
+	)
+	want := []QueryColumn{
+		{Name: "events_count", Member: "events.count", Type: ColumnTypeNumber},
+		{Name: "classrooms_grade_level", Member: "classrooms.grade_level", Type: ColumnTypeNumber},
+		{Name: "events_type", Member: "events.type", Type: ColumnTypeString},
+		{Name: "time_date_day", Member: "time.date", Type: ColumnTypeTime},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("resultColumns = %+v, want %+v", columns, want)
+	}
+}
+
+func TestCoerceValue(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		value      interface{}
+		columnType string
+		want       interface{}
+	}{
+		{"null", nil, ColumnTypeNumber, nil},
+		{"int64", int64(7), ColumnTypeNumber, 7.0},
+		{"decimal bytes", []byte("12.50"), ColumnTypeNumber, 12.5},
+		{"bool", true, ColumnTypeNumber, 1.0},
+		{"time", day, ColumnTypeTime, day},
+		{"date string", "2024-03-04", ColumnTypeTime, day},
+		{"timestamp string", "2024-03-04 00:00:00", ColumnTypeTime, day},
+		{"text bytes", []byte("video"), ColumnTypeString, "video"},
+		{"number as string", int64(3), ColumnTypeString, "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := coerceValue(tt.value, tt.columnType)
+			if err != nil {
+				t.Fatalf("coerceValue: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceValue = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []struct {
+		value      interface{}
+		columnType string
+	}{
+		{"many", ColumnTypeNumber},
+		{"last tuesday", ColumnTypeTime},
+		{int64(1), ColumnTypeTime},
+	} {
+		if _, err := coerceValue(bad.value, bad.columnType); err == nil {
+			t.Errorf("coerceValue(%#v, %s) succeeded, want an error", bad.value, bad.columnType)
+		}
+	}
+}
+
+func TestNewQueryResultReportsTheColumn(t *testing.T) {
+	columns := []QueryColumn{{Name: "events_count", Member: "events.count", Type: ColumnTypeNumber}}
+	_, err := newQueryResult(columns, []map[string]interface{}{{"events_count": int64(1)}, {"events_count": "n/a"}})
+	if err == nil || err.Error() != `column events_count: invalid number "n/a"` {
+		t.Errorf("newQueryResult error = %v", err)
+	}
+
+	result, err := newQueryResult(columns, []map[string]interface{}{{"events_count": int64(4), "extra": "dropped"}})
+	if err != nil || !reflect.DeepEqual(result.Rows, []map[string]interface{}{{"events_count": 4.0}}) {
+		t.Errorf("newQueryResult = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MeasureTypeRollingCountDistinct counts distinct members over a trailing window
+// of day buckets, e.g. users active in the prior 7 days
+const MeasureTypeRollingCountDistinct = "rolling_count_distinct"
+
+// cubeQuery, cubeTimeDimension and cubeFilter name the anonymous request
+// structs used by ExecuteQuery so helpers can accept them without repeating
+// the definitions
+type cubeQuery = struct {
+	Measures       []string            `json:"measures"`
+	Dimensions     []string            `json:"dimensions"`
+	TimeDimensions []cubeTimeDimension `json:"timeDimensions"`
+	Filters        []cubeFilter        `json:"filters"`
+	Having         []cubeFilter        `json:"having"`
+	Order          [][]string          `json:"order"`
+	Limit          int                 `json:"limit"`
+}
+
+type cubeTimeDimension = struct {
+	Dimension   string        `json:"dimension"`
+	Granularity string        `json:"granularity"`
+	DateRange   cubeDateRange `json:"dateRange"`
+}
+
+type cubeFilter = struct {
+	Member   string   `json:"member"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// hasRollingMeasure reports whether any requested measure is windowed
+func hasRollingMeasure(measures []string, schema CubeSchema) bool {
+	for _, measure := range measures {
+		if def, exists := schema.Measures[measure]; exists && def.WindowDays > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRollingSQL builds a query for windowed measures bucketed by day.
+//
+// Postgres cannot use COUNT(DISTINCT ...) as a window aggregate, so each member's
+// active days are turned into non-overlapping coverage spans with LEAD: a day d
+// covers [d, min(next active day, d + window)). A running SUM() OVER the +1/-1
+// span boundaries then yields the distinct count for every day. When a date range
+// is given, activity is scanned window-1 days before its start so the leading
+// buckets see a full window rather than a partial one.
+//
+// Bind arguments are collected in the order their placeholders appear in the
+// final query text.
+func (q *GenericQueryBuilder) buildRollingSQL(measures, dimensions []string, timeDims []cubeTimeDimension, filters []cubeFilter, order [][]string, limit int, schema CubeSchema) (string, []interface{}, error) {
+	if len(timeDims) != 1 || timeDims[0].Granularity != "day" {
+		return "", nil, fmt.Errorf("rolling measures require exactly one time dimension with day granularity")
+	}
+	timeDim := timeDims[0]
+	timeDef, exists := schema.Dimensions[timeDim.Dimension]
+	if !exists {
+		return "", nil, fmt.Errorf("unknown time dimension %s", timeDim.Dimension)
+	}
+
+	var rolling []MeasureDefinition
+	var measureAliases []string
+	lookback := 0
+	for _, measure := range measures {
+		def, exists := schema.Measures[measure]
+		if !exists {
+			continue
+		}
+		if def.WindowDays <= 0 {
+			return "", nil, fmt.Errorf("rolling measures cannot be combined with non-rolling measure %s", measure)
+		}
+		rolling = append(rolling, def)
+		measureAliases = append(measureAliases, strings.ReplaceAll(measure, ".", "_"))
+		if def.WindowDays-1 > lookback {
+			lookback = def.WindowDays - 1
+		}
+	}
+
+	// Dimensions become partition keys so each group gets its own rolling series
+	var dimSelects, dimAliases []string
+	for _, dimension := range dimensions {
+		if def, exists := schema.Dimensions[dimension]; exists {
+			alias := strings.ReplaceAll(dimension, ".", "_")
+			dimSelects = append(dimSelects, fmt.Sprintf("%s AS %s", def.SQL, alias))
+			dimAliases = append(dimAliases, alias)
+		}
+	}
+
+	tables := q.determineTables(measures, dimensions, schema)
+	fromClause := q.buildFromClause(q.determinePrimaryTable(tables), tables)
+
+	conditions := []string{}
+	where, args, err := q.buildWhereClause(filters, nil, schema)
+	if err != nil {
+		return "", nil, err
+	}
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+
+	// With a date range, the range bounds are bound once per use
+	bucketFrom := "(SELECT MIN(bucket) FROM activity)"
+	bucketTo := "(SELECT MAX(bucket) FROM activity)"
+	seriesFrom := bucketFrom
+	var bucketFromArgs, seriesArgs []interface{}
+	rangeFrom, rangeTo, hasRange, err := resolveDateRange(timeDim.DateRange, time.Now().UTC())
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", timeDim.Dimension, err)
+	}
+	if hasRange {
+		bucketFrom = "?::date"
+		bucketTo = "?::date"
+		seriesFrom = fmt.Sprintf("(%s - %d)", bucketFrom, lookback)
+		bucketFromArgs = []interface{}{rangeFrom}
+		seriesArgs = []interface{}{rangeFrom, rangeTo}
+		conditions = append(conditions, fmt.Sprintf("DATE(%s) BETWEEN %s AND %s", timeDef.SQL, seriesFrom, bucketTo))
+		args = append(args, seriesArgs...)
+	}
+
+	// activity: one row per source record with its day bucket and measure members
+	activityCols := []string{fmt.Sprintf("DATE(%s) AS bucket", timeDef.SQL)}
+	for i, def := range rolling {
+		activityCols = append(activityCols, fmt.Sprintf("%s AS member_%d", def.SQL, i))
+	}
+	activityCols = append(activityCols, dimSelects...)
+	activity := fmt.Sprintf("activity AS (SELECT %s FROM %s", strings.Join(activityCols, ", "), fromClause)
+	if len(conditions) > 0 {
+		activity += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	activity += ")"
+
+	withDims := func(prefix string) string {
+		if len(dimAliases) == 0 {
+			return ""
+		}
+		cols := make([]string, len(dimAliases))
+		for i, alias := range dimAliases {
+			cols[i] = prefix + alias
+		}
+		return ", " + strings.Join(cols, ", ")
+	}
+	matchDims := func(left, right string) string {
+		var clauses []string
+		for _, alias := range dimAliases {
+			clauses = append(clauses, fmt.Sprintf(" AND %s.%s IS NOT DISTINCT FROM %s.%s", left, alias, right, alias))
+		}
+		return strings.Join(clauses, "")
+	}
+
+	ctes := []string{activity}
+	for i, def := range rolling {
+		ctes = append(ctes,
+			fmt.Sprintf("days_%d AS (SELECT DISTINCT bucket, member_%d AS member%s FROM activity WHERE member_%d IS NOT NULL)",
+				i, i, withDims(""), i),
+			fmt.Sprintf("deltas_%d AS (SELECT bucket AS day%s, 1 AS delta FROM days_%d UNION ALL "+
+				"SELECT LEAST(LEAD(bucket) OVER (PARTITION BY member%s ORDER BY bucket), bucket + %d) AS day%s, -1 AS delta FROM days_%d)",
+				i, withDims(""), i, withDims(""), def.WindowDays, withDims(""), i),
+			fmt.Sprintf("daily_%d AS (SELECT day%s, SUM(delta) AS delta FROM deltas_%d GROUP BY day%s)",
+				i, withDims(""), i, withDims("")),
+		)
+	}
+
+	buckets := fmt.Sprintf("buckets AS (SELECT series.bucket::date AS bucket%s FROM generate_series(%s, %s, INTERVAL '1 day') AS series(bucket)",
+		withDims("g."), seriesFrom, bucketTo)
+	if len(dimAliases) > 0 {
+		buckets += fmt.Sprintf(" CROSS JOIN (SELECT DISTINCT %s FROM activity) g", strings.Join(dimAliases, ", "))
+	}
+	buckets += ")"
+	ctes = append(ctes, buckets)
+	args = append(args, seriesArgs...)
+
+	partition := ""
+	if len(dimAliases) > 0 {
+		partition = "PARTITION BY " + strings.Join(prefixAll("b.", dimAliases), ", ") + " "
+	}
+	timeAlias := fmt.Sprintf("%s_%s", strings.ReplaceAll(timeDim.Dimension, ".", "_"), timeDim.Granularity)
+
+	selectCols := []string{"b.bucket AS " + timeAlias}
+	selectCols = append(selectCols, prefixAll("b.", dimAliases)...)
+	joins := []string{}
+	for i, alias := range measureAliases {
+		selectCols = append(selectCols, fmt.Sprintf("SUM(COALESCE(d%d.delta, 0)) OVER (%sORDER BY b.bucket) AS %s", i, partition, alias))
+		joins = append(joins, fmt.Sprintf("LEFT JOIN daily_%d d%d ON d%d.day = b.bucket%s", i, i, i, matchDims(fmt.Sprintf("d%d", i), "b")))
+	}
+
+	// The running sum must see the lookback days, so trim to the range afterwards
+	query := fmt.Sprintf("WITH %s SELECT * FROM (SELECT %s FROM buckets b %s) rolling WHERE %s >= %s",
+		strings.Join(ctes, ", "), strings.Join(selectCols, ", "), strings.Join(joins, " "), timeAlias, bucketFrom)
+	args = append(args, bucketFromArgs...)
+
+	if orderByClause := q.buildOrderByClause(order); orderByClause != "" {
+		query += " ORDER BY " + orderByClause
+	} else {
+		query += " ORDER BY " + timeAlias + " ASC"
+	}
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	return query, args, nil
+}
+
+func prefixAll(prefix string, values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = prefix + v
+	}
+	return out
+}
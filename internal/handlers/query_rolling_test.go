@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHasRollingMeasure(t *testing.T) {
+	schema := NewGenericQueryBuilder(nil).GetSchema()
+	if !hasRollingMeasure([]string{"events.count", "events.rolling_7d_active_users"}, schema) {
+		t.Error("expected the rolling measure to be detected")
+	}
+	if hasRollingMeasure([]string{"events.count", "unknown.measure"}, schema) {
+		t.Error("expected no rolling measure")
+	}
+}
+
+func TestBuildRollingSQL(t *testing.T) {
+	q := NewGenericQueryBuilder(nil)
+	schema := q.GetSchema()
+	measures := []string{"events.rolling_7d_active_users"}
+
+	t.Run("binds the range with a lookback", func(t *testing.T) {
+		timeDims := []cubeTimeDimension{{Dimension: "time.date", Granularity: "day", DateRange: cubeDateRange{"2024-03-01", "2024-03-31"}}}
+		filters := []cubeFilter{{Member: "events.type", Operator: "equals", Values: []string{"login"}}}
+
+		query, args, err := q.buildRollingSQL(measures, []string{"events.application"}, timeDims, filters, nil, 0, schema)
+		if err != nil {
+			t.Fatalf("buildRollingSQL: %v", err)
+		}
+		if placeholders := strings.Count(query, "?"); placeholders != len(args) {
+			t.Fatalf("query has %d placeholders for %d args: %s", placeholders, len(args), query)
+		}
+		// The ::date casts drop the whole-day times the range is widened with
+		from, to := "2024-03-01T00:00:00.000", "2024-03-31T23:59:59.999"
+		wantArgs := []interface{}{"login", from, to, from, to, from}
+		if !reflect.DeepEqual(args, wantArgs) {
+			t.Errorf("args = %v, want %v", args, wantArgs)
+		}
+		for _, fragment := range []string{
+			"(?::date - 6)",
+			"LEAST(LEAD(bucket) OVER (PARTITION BY member, events_application ORDER BY bucket), bucket + 7)",
+			"PARTITION BY b.events_application ORDER BY b.bucket) AS events_rolling_7d_active_users",
+			"WHERE time_date_day >= ?::date ORDER BY time_date_day ASC",
+		} {
+			if !strings.Contains(query, fragment) {
+				t.Errorf("query is missing %q: %s", fragment, query)
+			}
+		}
+	})
+
+	t.Run("without a range spans the activity", func(t *testing.T) {
+		timeDims := []cubeTimeDimension{{Dimension: "time.date", Granularity: "day"}}
+		query, args, err := q.buildRollingSQL(measures, nil, timeDims, nil, nil, 10, schema)
+		if err != nil {
+			t.Fatalf("buildRollingSQL: %v", err)
+		}
+		if len(args) != 0 || strings.Contains(query, "?") {
+			t.Errorf("query binds %v: %s", args, query)
+		}
+		if !strings.HasSuffix(query, "LIMIT 10") || !strings.Contains(query, "(SELECT MIN(bucket) FROM activity)") {
+			t.Errorf("unexpected query: %s", query)
+		}
+	})
+
+	errorCases := []struct {
+		name     string
+		measures []string
+		timeDims []cubeTimeDimension
+	}{
+		{"no time dimension", measures, nil},
+		{"weekly granularity", measures, []cubeTimeDimension{{Dimension: "time.date", Granularity: "week"}}},
+		{"unknown time dimension", measures, []cubeTimeDimension{{Dimension: "time.nope", Granularity: "day"}}},
+		{"mixed with a plain measure", []string{"events.rolling_7d_active_users", "events.count"}, []cubeTimeDimension{{Dimension: "time.date", Granularity: "day"}}},
+	}
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := q.buildRollingSQL(tt.measures, nil, tt.timeDims, nil, nil, 0, schema); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"reporting-framework/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// NDJSONContentType is the Accept value that streams generic query results
+// as newline-delimited JSON
+const NDJSONContentType = "application/x-ndjson"
+
+// CubeRowStream reads a query's typed rows one at a time instead of loading
+// the whole result
+type CubeRowStream struct {
+	Columns []QueryColumn
+	db      *gorm.DB
+	rows    *sql.Rows
+}
+
+// StreamQuery starts a cube.dev style query and returns a stream over its
+// rows, coerced as by ExecuteQuery. Cancelling ctx stops the query. The
+// stream must be closed.
+func (q *GenericQueryBuilder) StreamQuery(ctx context.Context, queryReq interface{}) (*CubeRowStream, error) {
+	query, args, columns, err := q.prepareQuery(queryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	db := q.db.WithContext(ctx)
+	rows, err := db.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+	return &CubeRowStream{Columns: columns, db: db, rows: rows}, nil
+}
+
+// Next returns the next row, or false once the rows are exhausted or fail
+func (s *CubeRowStream) Next() (map[string]interface{}, bool, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return nil, false, fmt.Errorf("query execution failed: %w", err)
+		}
+		return nil, false, nil
+	}
+	raw := make(map[string]interface{}, len(s.Columns))
+	if err := s.db.ScanRows(s.rows, &raw); err != nil {
+		return nil, false, fmt.Errorf("query execution failed: %w", err)
+	}
+	row, err := coerceRow(s.Columns, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return row, true, nil
+}
+
+// Close releases the stream's database connection
+func (s *CubeRowStream) Close() error {
+	return s.rows.Close()
+}
+
+// wantsNDJSON reports whether the client asked for a streamed result
+func wantsNDJSON(c *gin.Context) bool {
+	return strings.Contains(c.GetHeader("Accept"), NDJSONContentType)
+}
+
+// streamGenericQuery writes a query's rows as one JSON object per line while
+// they are read. Errors before the first row get a normal JSON error
+// response; an error mid-stream ends the body with an {"error": ...} line.
+func (h *ReportingHandler) streamGenericQuery(c *gin.Context, queryReq cubeQuery) {
+	start := time.Now()
+	stream, err := h.queryBuilder().StreamQuery(c.Request.Context(), queryReq)
+	if err != nil {
+		metrics.ObserveQuery(start, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to execute query", "details": err.Error()})
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", NDJSONContentType)
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	var streamErr error
+	c.Stream(func(w io.Writer) bool {
+		row, ok, err := stream.Next()
+		if err != nil {
+			streamErr = err
+			encoder.Encode(gin.H{"error": err.Error()})
+			return false
+		}
+		if !ok {
+			return false
+		}
+		if err := encoder.Encode(row); err != nil {
+			streamErr = err
+			return false
+		}
+		return true
+	})
+	metrics.ObserveQuery(start, streamErr)
+}
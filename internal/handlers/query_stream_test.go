@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamedQueriesShareExportLimits(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	h.SetExportLimits(1, 0)
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api"))
+
+	// Hold the only export slot
+	if err := h.exportLimiter.acquire(context.Background(), func(int) {}); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer h.exportLimiter.release()
+
+	query := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(`{"measures": ["events.count"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := query(NDJSONContentType); w.Code != http.StatusTooManyRequests {
+		t.Errorf("streamed query status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if w := query(""); w.Code == http.StatusTooManyRequests {
+		t.Errorf("buffered query was held to the export limits: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryValidationError is one problem with a generic query request. Member
+// names the measure, dimension or request field at fault.
+type QueryValidationError struct {
+	Member string `json:"member"`
+	Reason string `json:"reason"`
+}
+
+// filterKind groups filter operators by what they constrain, so conflicting
+// filters can be found across operator spellings
+type filterKind int
+
+const (
+	filterOther filterKind = iota
+	filterEquals
+	filterIn
+	filterLower // gt, gte
+	filterUpper // lt, lte
+)
+
+// filterOperator describes a filter operator an endpoint supports
+type filterOperator struct {
+	kind      filterKind
+	multi     bool // takes one or more values rather than exactly one
+	inclusive bool // bound includes its value
+}
+
+// cubeFilterOperators are the operators GenericQueryBuilder turns into SQL
+var cubeFilterOperators = map[string]filterOperator{
+	"equals":   {kind: filterEquals},
+	"in":       {kind: filterIn, multi: true},
+	"gt":       {kind: filterLower},
+	"gte":      {kind: filterLower, inclusive: true},
+	"lt":       {kind: filterUpper},
+	"lte":      {kind: filterUpper, inclusive: true},
+	"contains": {kind: filterOther},
+}
+
+// cubeGranularities are the time dimension granularities GenericQueryBuilder
+// supports; no granularity selects the dimension as it is
+var cubeGranularities = map[string]bool{"": true, "hour": true, "day": true, "week": true, "month": true}
+
+// ValidateQuery checks a generic query request against a schema before any
+// SQL is built and returns every problem found, or nil for a valid request:
+// a query without measures or dimensions, unknown members, time dimensions
+// that aren't time typed or have an unsupported granularity or date range,
+// filters with unknown operators, the wrong number of values or conditions
+// that can never hold together, order entries for members not in the query,
+// a negative limit, and rolling measures used in a shape they don't support.
+func ValidateQuery(req cubeQuery, schema CubeSchema) []QueryValidationError {
+	var problems []QueryValidationError
+	add := func(member, format string, args ...interface{}) {
+		problems = append(problems, QueryValidationError{Member: member, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	operators := schema.filterOperators
+	if operators == nil {
+		operators = cubeFilterOperators
+	}
+	granularities := schema.granularities
+	if granularities == nil {
+		granularities = cubeGranularities
+	}
+
+	if len(req.Measures) == 0 && len(req.Dimensions) == 0 {
+		add("measures", "query needs at least one measure or dimension")
+	}
+
+	selected := make(map[string]bool)
+	rolling, plain := 0, 0
+	for _, measure := range req.Measures {
+		def, exists := schema.Measures[measure]
+		if !exists {
+			add(measure, "unknown measure")
+			continue
+		}
+		selected[measure] = true
+		if def.WindowDays > 0 {
+			rolling++
+		} else {
+			plain++
+		}
+	}
+	for _, dimension := range req.Dimensions {
+		if _, exists := schema.Dimensions[dimension]; !exists {
+			add(dimension, "unknown dimension")
+			continue
+		}
+		selected[dimension] = true
+	}
+
+	now := time.Now().UTC()
+	for _, timeDim := range req.TimeDimensions {
+		def, exists := schema.Dimensions[timeDim.Dimension]
+		switch {
+		case !exists:
+			add(timeDim.Dimension, "unknown time dimension")
+			continue
+		case def.Type != "time":
+			add(timeDim.Dimension, "%s dimension can't be used as a time dimension", def.Type)
+			continue
+		}
+		if !granularities[timeDim.Granularity] {
+			add(timeDim.Dimension, "unknown granularity %q, expected one of %s", timeDim.Granularity, strings.Join(sortedKeys(granularities), ", "))
+		} else {
+			selected[timeDim.Dimension+"."+timeDim.Granularity] = true
+		}
+		from, to, ok, err := resolveDateRange(timeDim.DateRange, now)
+		if err != nil {
+			add(timeDim.Dimension, "%v", err)
+		} else if ok && dateRangeReversed(from, to) {
+			add(timeDim.Dimension, "dateRange starts after it ends")
+		}
+	}
+
+	if rolling > 0 {
+		if plain > 0 {
+			add("measures", "rolling measures can't be combined with other measures")
+		}
+		if len(req.TimeDimensions) != 1 || req.TimeDimensions[0].Granularity != "day" {
+			add("timeDimensions", "rolling measures require exactly one time dimension with day granularity")
+		}
+		if len(req.Having) > 0 {
+			add("having", "having is not supported with rolling measures")
+		}
+	}
+
+	problems = append(problems, validateFilters(req.Filters, operators, func(member string) string {
+		if _, exists := schema.Dimensions[member]; !exists {
+			return "unknown filter dimension"
+		}
+		return ""
+	})...)
+	problems = append(problems, validateFilters(req.Having, operators, func(member string) string {
+		if _, exists := schema.Measures[member]; !exists {
+			return "unknown having measure"
+		}
+		return ""
+	})...)
+
+	for i, item := range req.Order {
+		switch {
+		case len(item) != 2:
+			add(fmt.Sprintf("order[%d]", i), "order entries must be [member, direction]")
+		case !selected[item[0]]:
+			add(item[0], "can only order by a measure or dimension in the query")
+		case !strings.EqualFold(item[1], "asc") && !strings.EqualFold(item[1], "desc"):
+			add(item[0], "order direction must be asc or desc, got %q", item[1])
+		}
+	}
+
+	if req.Limit < 0 {
+		add("limit", "limit must not be negative")
+	}
+	return problems
+}
+
+// validateFilters checks each filter's member with unknown, which returns a
+// reason for members the schema lacks, then its operator and value count, and
+// finally looks for filters on one member that no row can satisfy together
+func validateFilters(filters []cubeFilter, operators map[string]filterOperator, unknown func(member string) string) []QueryValidationError {
+	var problems []QueryValidationError
+	byMember := make(map[string][]cubeFilter)
+	var members []string
+
+	for _, filter := range filters {
+		if reason := unknown(filter.Member); reason != "" {
+			problems = append(problems, QueryValidationError{Member: filter.Member, Reason: reason})
+			continue
+		}
+		op, supported := operators[filter.Operator]
+		switch {
+		case !supported:
+			problems = append(problems, QueryValidationError{Member: filter.Member,
+				Reason: fmt.Sprintf("unknown operator %q, expected one of %s", filter.Operator, strings.Join(sortedKeys(operators), ", "))})
+			continue
+		case op.multi && len(filter.Values) == 0:
+			problems = append(problems, QueryValidationError{Member: filter.Member,
+				Reason: fmt.Sprintf("%s needs at least one value", filter.Operator)})
+			continue
+		case !op.multi && len(filter.Values) != 1:
+			problems = append(problems, QueryValidationError{Member: filter.Member,
+				Reason: fmt.Sprintf("%s needs exactly one value, got %d", filter.Operator, len(filter.Values))})
+			continue
+		}
+		if _, seen := byMember[filter.Member]; !seen {
+			members = append(members, filter.Member)
+		}
+		byMember[filter.Member] = append(byMember[filter.Member], filter)
+	}
+
+	for _, member := range members {
+		if reason := filterConflict(byMember[member], operators); reason != "" {
+			problems = append(problems, QueryValidationError{Member: member, Reason: reason})
+		}
+	}
+	return problems
+}
+
+// filterConflict describes why filters on one member exclude every row, or
+// returns "". Equality and in filters must share a value; numeric bounds must
+// leave a range that contains any required value.
+func filterConflict(filters []cubeFilter, operators map[string]filterOperator) string {
+	var allowed map[string]bool // values every equals/in filter accepts, nil when unconstrained
+	var lower, upper *float64
+	lowerInclusive, upperInclusive := false, false
+
+	for _, filter := range filters {
+		op := operators[filter.Operator]
+		switch op.kind {
+		case filterEquals, filterIn:
+			values := make(map[string]bool, len(filter.Values))
+			for _, v := range filter.Values {
+				if allowed == nil || allowed[v] {
+					values[v] = true
+				}
+			}
+			allowed = values
+			if len(allowed) == 0 {
+				return "equals and in filters have no value in common"
+			}
+		case filterLower, filterUpper:
+			bound, err := strconv.ParseFloat(filter.Values[0], 64)
+			if err != nil {
+				continue
+			}
+			if op.kind == filterLower && (lower == nil || bound > *lower || (bound == *lower && !op.inclusive)) {
+				lower, lowerInclusive = &bound, op.inclusive
+			}
+			if op.kind == filterUpper && (upper == nil || bound < *upper || (bound == *upper && !op.inclusive)) {
+				upper, upperInclusive = &bound, op.inclusive
+			}
+		}
+	}
+
+	if lower != nil && upper != nil && (*lower > *upper || (*lower == *upper && !(lowerInclusive && upperInclusive))) {
+		return "lower and upper bound filters leave no values"
+	}
+	if allowed != nil && (lower != nil || upper != nil) {
+		for v := range allowed {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return ""
+			}
+			aboveLower := lower == nil || n > *lower || (n == *lower && lowerInclusive)
+			belowUpper := upper == nil || n < *upper || (n == *upper && upperInclusive)
+			if aboveLower && belowUpper {
+				return ""
+			}
+		}
+		return "equals and in values fall outside the bound filters"
+	}
+	return ""
+}
+
+// dateRangeReversed reports whether a [from, to] pair of dates or RFC 3339
+// timestamps starts after it ends; unparseable bounds are left to the database
+func dateRangeReversed(from, to string) bool {
+	parse := func(s string) (time.Time, bool) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.000", DateFormat} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		return time.Time{}, false
+	}
+	start, okFrom := parse(from)
+	end, okTo := parse(to)
+	return okFrom && okTo && start.After(end)
+}
+
+// sortedKeys lists a map's keys in order, for stable error messages
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
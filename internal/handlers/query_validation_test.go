@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	schema := NewGenericQueryBuilder(nil).GetSchema()
+	day := func(dateRange ...string) []cubeTimeDimension {
+		return []cubeTimeDimension{{Dimension: "time.date", Granularity: "day", DateRange: dateRange}}
+	}
+	tests := []struct {
+		name  string
+		query cubeQuery
+		want  []QueryValidationError
+	}{
+		{
+			name: "valid",
+			query: cubeQuery{
+				Measures:       []string{"events.count"},
+				Dimensions:     []string{"events.type"},
+				TimeDimensions: day("2024-01-01", "2024-01-31"),
+				Filters:        []cubeFilter{{Member: "events.type", Operator: "in", Values: []string{"login", "logout"}}},
+				Order:          [][]string{{"events.count", "DESC"}, {"time.date.day", "asc"}},
+				Limit:          10,
+			},
+		},
+		{
+			name:  "empty",
+			query: cubeQuery{},
+			want:  []QueryValidationError{{"measures", "query needs at least one measure or dimension"}},
+		},
+		{
+			name:  "unknown members",
+			query: cubeQuery{Measures: []string{"events.nope"}, Dimensions: []string{"events.type", "events.nope"}},
+			want: []QueryValidationError{
+				{"events.nope", "unknown measure"},
+				{"events.nope", "unknown dimension"},
+			},
+		},
+		{
+			name: "bad time dimensions",
+			query: cubeQuery{Measures: []string{"events.count"}, TimeDimensions: []cubeTimeDimension{
+				{Dimension: "events.type", Granularity: "day"},
+				{Dimension: "time.date", Granularity: "fortnight"},
+				{Dimension: "time.date", Granularity: "day", DateRange: cubeDateRange{"2024-02-01", "2024-01-01"}},
+				{Dimension: "time.date", Granularity: "day", DateRange: cubeDateRange{"next tuesday"}},
+			}},
+			want: []QueryValidationError{
+				{"events.type", "string dimension can't be used as a time dimension"},
+				{"time.date", `unknown granularity "fortnight", expected one of day, hour, month, week`},
+				{"time.date", "dateRange starts after it ends"},
+				{"time.date", `unrecognized relative dateRange "next tuesday"`},
+			},
+		},
+		{
+			name: "bad filters",
+			query: cubeQuery{Measures: []string{"events.count"}, Filters: []cubeFilter{
+				{Member: "events.nope", Operator: "equals", Values: []string{"x"}},
+				{Member: "events.type", Operator: "like", Values: []string{"x"}},
+				{Member: "events.application", Operator: "in"},
+				{Member: "classrooms.subject", Operator: "equals", Values: []string{"a", "b"}},
+			}},
+			want: []QueryValidationError{
+				{"events.nope", "unknown filter dimension"},
+				{"events.type", `unknown operator "like", expected one of contains, equals, gt, gte, in, lt, lte`},
+				{"events.application", "in needs at least one value"},
+				{"classrooms.subject", "equals needs exactly one value, got 2"},
+			},
+		},
+		{
+			name: "conflicting filters",
+			query: cubeQuery{Measures: []string{"events.count"}, Filters: []cubeFilter{
+				{Member: "events.type", Operator: "equals", Values: []string{"login"}},
+				{Member: "events.type", Operator: "in", Values: []string{"logout"}},
+				{Member: "classrooms.grade_level", Operator: "gt", Values: []string{"5"}},
+				{Member: "classrooms.grade_level", Operator: "lte", Values: []string{"5"}},
+			}},
+			want: []QueryValidationError{
+				{"events.type", "equals and in filters have no value in common"},
+				{"classrooms.grade_level", "lower and upper bound filters leave no values"},
+			},
+		},
+		{
+			name: "order and limit",
+			query: cubeQuery{Measures: []string{"events.count"}, Limit: -1, Order: [][]string{
+				{"events.count"},
+				{"sessions.count", "asc"},
+				{"events.count", "up"},
+			}},
+			want: []QueryValidationError{
+				{"order[0]", "order entries must be [member, direction]"},
+				{"sessions.count", "can only order by a measure or dimension in the query"},
+				{"events.count", `order direction must be asc or desc, got "up"`},
+				{"limit", "limit must not be negative"},
+			},
+		},
+		{
+			name: "rolling measure shape",
+			query: cubeQuery{
+				Measures:       []string{"events.rolling_7d_active_users", "events.count"},
+				TimeDimensions: []cubeTimeDimension{{Dimension: "time.date", Granularity: "week"}},
+			},
+			want: []QueryValidationError{
+				{"measures", "rolling measures can't be combined with other measures"},
+				{"timeDimensions", "rolling measures require exactly one time dimension with day granularity"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateQuery(tt.query, schema); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateQuery =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []cubeFilter
+		want    string
+	}{
+		{"shared value", []cubeFilter{
+			{Operator: "in", Values: []string{"1", "2"}},
+			{Operator: "in", Values: []string{"2", "3"}},
+		}, ""},
+		{"touching inclusive bounds", []cubeFilter{
+			{Operator: "gte", Values: []string{"5"}},
+			{Operator: "lte", Values: []string{"5"}},
+		}, ""},
+		{"value outside the bounds", []cubeFilter{
+			{Operator: "equals", Values: []string{"12"}},
+			{Operator: "lt", Values: []string{"10"}},
+		}, "equals and in values fall outside the bound filters"},
+		{"non-numeric values are left alone", []cubeFilter{
+			{Operator: "equals", Values: []string{"k"}},
+			{Operator: "lt", Values: []string{"10"}},
+		}, ""},
+	}
+	for _, tt := range tests {
+		if got := filterConflict(tt.filters, cubeFilterOperators); got != tt.want {
+			t.Errorf("%s: filterConflict = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteGenericQueryRejectsInvalidQueries(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.ExecuteGenericQuery, "/query", http.MethodPost, "/query", `{"measures":["events.nope"],"limit":-1}`, nil)
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	if details := decodeBody(t, w)["details"].([]interface{}); len(details) != 2 {
+		t.Errorf("details = %v, want both problems", details)
+	}
+	if len(fake.ran()) != 0 {
+		t.Error("an invalid query reached the database")
+	}
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"reporting-framework/internal/models"
@@ -12,7 +14,8 @@ import (
 )
 
 type QuizHandler struct {
-	db *gorm.DB
+	db          *gorm.DB
+	shuffleSeed string
 }
 
 type CreateQuizRequest struct {
@@ -20,6 +23,7 @@ type CreateQuizRequest struct {
 	ClassroomID      string    `json:"classroom_id" binding:"required"`
 	TeacherID        string    `json:"teacher_id" binding:"required"`
 	TimeLimitMinutes *int      `json:"time_limit_minutes"`
+	Tags             []string  `json:"tags"`
 	Questions        []Question `json:"questions"`
 }
 
@@ -37,6 +41,7 @@ type SubmitResponseRequest struct {
 	StudentID        string `json:"student_id" binding:"required"`
 	Answer           string `json:"answer" binding:"required"`
 	TimeTakenSeconds int    `json:"time_taken_seconds"`
+	Shuffled         bool   `json:"shuffled"` // answer is a label from the student's shuffled view
 }
 
 func NewQuizHandler(db *gorm.DB) *QuizHandler {
@@ -89,6 +94,7 @@ func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 		QuestionCount:    len(req.Questions),
 		TimeLimitMinutes: req.TimeLimitMinutes,
 		Status:           "draft",
+		Tags:             normalizeTags(req.Tags),
 	}
 
 	if err := tx.Create(&quiz).Error; err != nil {
@@ -150,9 +156,102 @@ func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 		"quiz_id":      quiz.ID,
 		"question_count": quiz.QuestionCount,
 		"total_points": totalPoints,
+		"tags":         quiz.Tags,
 	})
 }
 
+// ListQuizzes returns quizzes filtered by classroom, status and tags.
+// Tags are passed as a comma-separated list; tag_match=all requires every tag,
+// otherwise a quiz matching any of the tags is returned. Archived quizzes are
+// left out unless include_archived=true or status=archived is given.
+func (h *QuizHandler) ListQuizzes(c *gin.Context) {
+	query := h.db.Model(&models.Quiz{})
+
+	if classroomID := c.Query("classroom_id"); classroomID != "" {
+		id, err := uuid.Parse(classroomID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "VALIDATION_ERROR",
+					"message": "Invalid classroom_id format",
+				},
+			})
+			return
+		}
+		query = query.Where("classroom_id = ?", id)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	} else if c.Query("include_archived") != "true" {
+		query = query.Where("status <> ?", QuizStatusArchived)
+	}
+
+	tags, matchAll := parseTagFilter(c)
+	query = applyTagFilter(query, "quizzes.tags", tags, matchAll)
+
+	var quizzes []models.Quiz
+	if err := query.Order("created_at DESC").Find(&quizzes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quizzes",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, quizzes)
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags so containment
+// queries match regardless of how teachers typed them
+func normalizeTags(tags []string) models.StringList {
+	seen := make(map[string]bool, len(tags))
+	normalized := make(models.StringList, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// parseTagFilter reads the tags and tag_match query parameters
+func parseTagFilter(c *gin.Context) ([]string, bool) {
+	raw := c.Query("tags")
+	if raw == "" {
+		return nil, false
+	}
+	return normalizeTags(strings.Split(raw, ",")), c.Query("tag_match") == "all"
+}
+
+// applyTagFilter restricts a query to rows whose JSONB tag column contains the
+// given tags. Both modes use the @> operator so the GIN index on tags is used.
+func applyTagFilter(query *gorm.DB, column string, tags []string, matchAll bool) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+
+	if matchAll {
+		all, _ := json.Marshal(tags)
+		return query.Where(column+" @> ?::jsonb", string(all))
+	}
+
+	conditions := make([]string, len(tags))
+	args := make([]interface{}, len(tags))
+	for i, tag := range tags {
+		single, _ := json.Marshal([]string{tag})
+		conditions[i] = column + " @> ?::jsonb"
+		args[i] = string(single)
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
 func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 	quizID := c.Param("id")
 	id, err := uuid.Parse(quizID)
@@ -180,6 +279,17 @@ func (h *QuizHandler) UpdateQuiz(c *gin.Context) {
 		return
 	}
 
+	// Archiving must go through ArchiveQuiz so the analytics get frozen
+	if req.Status == QuizStatusArchived {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Use POST /quizzes/:id/archive to archive a quiz",
+			},
+		})
+		return
+	}
+
 	updates := map[string]interface{}{
 		"status": req.Status,
 	}
@@ -251,9 +361,29 @@ func (h *QuizHandler) SubmitResponse(c *gin.Context) {
 		return
 	}
 
+	var quiz models.Quiz
+	if err := h.db.Select("id", "status").First(&quiz, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": map[string]interface{}{
+				"code":    "NOT_FOUND",
+				"message": "Quiz not found",
+			},
+		})
+		return
+	}
+	if quiz.Status == QuizStatusArchived {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": map[string]interface{}{
+				"code":    "QUIZ_ARCHIVED",
+				"message": "Quiz is archived and no longer accepts responses",
+			},
+		})
+		return
+	}
+
 	// Get question to check correct answer
 	var question models.QuizQuestion
-	if err := h.db.First(&question, "id = ?", questionID).Error; err != nil {
+	if err := h.db.First(&question, "id = ? AND quiz_id = ?", questionID, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": map[string]interface{}{
 				"code":    "NOT_FOUND",
@@ -263,8 +393,14 @@ func (h *QuizHandler) SubmitResponse(c *gin.Context) {
 		return
 	}
 
+	// Shuffled answers are mapped back to the canonical option before grading
+	answer := req.Answer
+	if req.Shuffled {
+		answer = h.canonicalAnswer(question, studentID, req.Answer)
+	}
+
 	// Check if answer is correct
-	isCorrect := req.Answer == question.CorrectAnswer
+	isCorrect := answer == question.CorrectAnswer
 	var pointsEarned float64
 	if isCorrect {
 		pointsEarned = question.Points
@@ -274,7 +410,7 @@ func (h *QuizHandler) SubmitResponse(c *gin.Context) {
 		QuizID:           id,
 		QuestionID:       questionID,
 		StudentID:        studentID,
-		Answer:           req.Answer,
+		Answer:           answer,
 		IsCorrect:        &isCorrect,
 		PointsEarned:     &pointsEarned,
 		TimeTakenSeconds: &req.TimeTakenSeconds,
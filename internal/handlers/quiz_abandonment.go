@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// answeredPerSessionSQL counts the submissions belonging to a quiz session's
+// attempt; sessions without submissions count as zero
+const answeredPerSessionSQL = `(SELECT COUNT(*) FROM quiz_submissions qsub
+	WHERE qsub.quiz_id = quiz_sessions.quiz_id
+	AND qsub.student_id = quiz_sessions.student_id
+	AND qsub.attempt_number = quiz_sessions.attempt_number)`
+
+// QuizAbandonment describes where students stop in a quiz they don't finish
+type QuizAbandonment struct {
+	QuizID                 uuid.UUID `json:"quiz_id"`
+	TotalQuestions         int       `json:"total_questions"`
+	TotalSessions          int       `json:"total_sessions"`
+	IncompleteSessions     int       `json:"incomplete_sessions"`
+	AvgQuestionsAnswered   *float64  `json:"avg_questions_answered"`
+	AvgAbandonmentPoint    *float64  `json:"avg_abandonment_point"`    // mean 1-based position of the last question answered in incomplete sessions
+	AbandonedBeforeAnswers int       `json:"abandoned_before_answers"` // incomplete sessions with no submissions, counted as position 0
+}
+
+// GetQuizAbandonment reports the average number of questions answered per
+// session and, for incomplete sessions, the average position where students stopped
+func (h *ReportingHandler) GetQuizAbandonment(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quiz_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz_id"})
+		return
+	}
+
+	var totalQuestions int64
+	if err := h.db.Table("quiz_questions").Where("quiz_id = ?", quizID).Count(&totalQuestions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count quiz questions", "details": err.Error()})
+		return
+	}
+
+	// Position is the question's rank by order_index, so gaps in the ordering don't skew the result
+	var result QuizAbandonment
+	err = h.db.Raw(`
+		WITH positions AS (
+			SELECT id, ROW_NUMBER() OVER (ORDER BY order_index, id) AS position
+			FROM quiz_questions
+			WHERE quiz_id = ?
+		),
+		session_progress AS (
+			SELECT quiz_sessions.is_completed,
+				`+answeredPerSessionSQL+` AS answered,
+				(SELECT MAX(p.position) FROM quiz_submissions qsub
+					JOIN positions p ON p.id = qsub.question_id
+					WHERE qsub.quiz_id = quiz_sessions.quiz_id
+					AND qsub.student_id = quiz_sessions.student_id
+					AND qsub.attempt_number = quiz_sessions.attempt_number) AS stop_position
+			FROM quiz_sessions
+			WHERE quiz_sessions.quiz_id = ?
+		)
+		SELECT
+			COUNT(*) AS total_sessions,
+			COUNT(*) FILTER (WHERE NOT is_completed) AS incomplete_sessions,
+			AVG(answered) AS avg_questions_answered,
+			AVG(COALESCE(stop_position, 0)) FILTER (WHERE NOT is_completed) AS avg_abandonment_point,
+			COUNT(*) FILTER (WHERE NOT is_completed AND stop_position IS NULL) AS abandoned_before_answers
+		FROM session_progress
+	`, quizID, quizID).Scan(&result).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate quiz abandonment", "details": err.Error()})
+		return
+	}
+
+	result.QuizID = quizID
+	result.TotalQuestions = int(totalQuestions)
+	if result.AvgQuestionsAnswered != nil {
+		v := roundTo(*result.AvgQuestionsAnswered, 2)
+		result.AvgQuestionsAnswered = &v
+	}
+	if result.AvgAbandonmentPoint != nil {
+		v := roundTo(*result.AvgAbandonmentPoint, 2)
+		result.AvgAbandonmentPoint = &v
+	}
+
+	c.JSON(http.StatusOK, result)
+}
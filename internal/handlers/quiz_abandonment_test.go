@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestQuizAbandonment(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "quiz_questions"`, "count(*)"}, []string{"count"}, []driver.Value{int64(10)})
+	fake.rows([]string{"WITH positions AS", "session_progress"},
+		[]string{"total_sessions", "incomplete_sessions", "avg_questions_answered", "avg_abandonment_point", "abandoned_before_answers"},
+		[]driver.Value{int64(6), int64(3), 6.833333, 3.666667, int64(1)})
+	h := NewReportingHandler(db)
+	quizID := uuid.New()
+
+	w := testRequest(h.GetQuizAbandonment, "/analytics/quiz-analytics/:quiz_id/abandonment", http.MethodGet,
+		"/analytics/quiz-analytics/"+quizID.String()+"/abandonment", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	want := map[string]interface{}{
+		"quiz_id":                  quizID.String(),
+		"total_questions":          10.0,
+		"total_sessions":           6.0,
+		"incomplete_sessions":      3.0,
+		"avg_questions_answered":   6.83,
+		"avg_abandonment_point":    3.67,
+		"abandoned_before_answers": 1.0,
+	}
+	for key, value := range want {
+		if body[key] != value {
+			t.Errorf("%s = %v, want %v", key, body[key], value)
+		}
+	}
+
+	ran := fake.ran("WITH positions AS")
+	if len(ran) != 1 || len(ran[0].Args) != 2 || ran[0].Args[0] != quizID || ran[0].Args[1] != quizID {
+		t.Errorf("abandonment query args = %v, want the quiz id for both CTEs", ran)
+	}
+
+	expectStatus(t, testRequest(h.GetQuizAbandonment, "/abandonment/:quiz_id", http.MethodGet, "/abandonment/nope", "", nil), http.StatusBadRequest)
+}
+
+func TestAvgQuestionsAnsweredMeasure(t *testing.T) {
+	fake, db := newFakeDB(t)
+	query := cubeQuery{Measures: []string{"quiz_sessions.avg_questions_answered"}}
+	if _, err := NewGenericQueryBuilder(db).ExecuteQuery(query); err != nil {
+		t.Fatalf("ExecuteQuery: %v", err)
+	}
+
+	ran := fake.ran("SELECT")
+	if len(ran) != 1 {
+		t.Fatalf("ran %d queries, want 1", len(ran))
+	}
+	// The correlated subquery refers to quiz_sessions by name, so the table
+	// must be the unaliased primary table
+	sql := ran[0].SQL
+	if !strings.Contains(sql, "FROM quiz_sessions") || strings.Contains(sql, "FROM quiz_sessions qs") ||
+		!strings.Contains(sql, "AVG((SELECT COUNT(*) FROM quiz_submissions qsub") {
+		t.Errorf("query = %s, want the answered count averaged over quiz_sessions", sql)
+	}
+}
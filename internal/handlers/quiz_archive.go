@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"reporting-framework/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QuizStatusArchived marks a quiz that is hidden from default listings and
+// closed to submissions; its analytics live on in a QuizAnalyticsSnapshot
+const QuizStatusArchived = "archived"
+
+// ArchiveQuiz archives a quiz, freezing its analytics into a snapshot.
+// Archiving an already archived quiz returns the existing snapshot.
+func (h *QuizHandler) ArchiveQuiz(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid quiz_id format",
+			},
+		})
+		return
+	}
+
+	var quiz models.Quiz
+	var snapshot models.QuizAnalyticsSnapshot
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the quiz so the snapshot and status change happen together
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quiz, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		if quiz.Status == QuizStatusArchived {
+			return tx.First(&snapshot, "quiz_id = ?", id).Error
+		}
+
+		snap, err := snapshotQuizAnalytics(tx, id)
+		if err != nil {
+			return err
+		}
+		snapshot = *snap
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return err
+		}
+
+		quiz.Status = QuizStatusArchived
+		return tx.Model(&quiz).Update("status", QuizStatusArchived).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Quiz not found",
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to archive quiz",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quiz_id":   quiz.ID,
+		"status":    quiz.Status,
+		"analytics": snapshot,
+	})
+}
+
+// GetQuizArchive returns the analytics frozen when the quiz was archived
+func (h *QuizHandler) GetQuizArchive(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid quiz_id format",
+			},
+		})
+		return
+	}
+
+	var snapshot models.QuizAnalyticsSnapshot
+	if err := h.db.First(&snapshot, "quiz_id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Quiz is not archived",
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve archived analytics",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// snapshotQuizAnalytics computes the quiz's current analytics as an unsaved snapshot
+func snapshotQuizAnalytics(tx *gorm.DB, quizID uuid.UUID) (*models.QuizAnalyticsSnapshot, error) {
+	var totals struct {
+		ParticipantCount   int
+		ResponseCount      int
+		AverageScore       *float64
+		AverageTimeSeconds *float64
+	}
+	err := tx.Table("quiz_responses").
+		Select(`
+			COUNT(DISTINCT quiz_responses.student_id) as participant_count,
+			COUNT(*) as response_count,
+			AVG(CASE WHEN quiz_responses.points_earned IS NOT NULL AND quiz_questions.points > 0 THEN
+				(quiz_responses.points_earned / quiz_questions.points) * 100
+			END) as average_score,
+			AVG(quiz_responses.time_taken_seconds) as average_time_seconds
+		`).
+		Joins("LEFT JOIN quiz_questions ON quiz_responses.question_id = quiz_questions.id").
+		Where("quiz_responses.quiz_id = ?", quizID).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var questionStats []struct {
+		QuestionID    uuid.UUID
+		OrderIndex    int
+		ResponseCount int
+		CorrectRate   *float64
+	}
+	err = tx.Table("quiz_questions").
+		Select(`
+			quiz_questions.id as question_id,
+			quiz_questions.order_index,
+			COUNT(quiz_responses.id) as response_count,
+			AVG(CASE WHEN quiz_responses.is_correct THEN 100.0 WHEN quiz_responses.is_correct = false THEN 0 END) as correct_rate
+		`).
+		Joins("LEFT JOIN quiz_responses ON quiz_responses.question_id = quiz_questions.id").
+		Where("quiz_questions.quiz_id = ?", quizID).
+		Group("quiz_questions.id, quiz_questions.order_index").
+		Scan(&questionStats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make(models.JSONB, len(questionStats))
+	for _, q := range questionStats {
+		questions[q.QuestionID.String()] = map[string]interface{}{
+			"order_index":    q.OrderIndex,
+			"response_count": q.ResponseCount,
+			"correct_rate":   q.CorrectRate,
+		}
+	}
+
+	return &models.QuizAnalyticsSnapshot{
+		QuizID:             quizID,
+		ParticipantCount:   totals.ParticipantCount,
+		ResponseCount:      totals.ResponseCount,
+		AverageScore:       totals.AverageScore,
+		AverageTimeSeconds: totals.AverageTimeSeconds,
+		Questions:          questions,
+		ArchivedAt:         time.Now(),
+	}, nil
+}
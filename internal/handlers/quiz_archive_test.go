@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestArchiveQuiz(t *testing.T) {
+	quizID := uuid.New()
+	questionID := uuid.New()
+	target := "/quizzes/" + quizID.String() + "/archive"
+	lockQuiz := []string{`FROM "quizzes"`, "FOR UPDATE"}
+
+	t.Run("freezes analytics and archives", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(lockQuiz, []string{"id", "status"}, []driver.Value{quizID.String(), "active"})
+		fake.rows([]string{`FROM "quiz_responses"`, "participant_count"},
+			[]string{"participant_count", "response_count", "average_score", "average_time_seconds"},
+			[]driver.Value{int64(12), int64(48), 76.5, 41.0})
+		fake.rows([]string{`FROM "quiz_questions"`, "correct_rate"},
+			[]string{"question_id", "order_index", "response_count", "correct_rate"},
+			[]driver.Value{questionID.String(), int64(0), int64(12), 75.0})
+		h := NewQuizHandler(db)
+
+		w := testRequest(h.ArchiveQuiz, "/quizzes/:id/archive", http.MethodPost, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+
+		if body["status"] != QuizStatusArchived {
+			t.Errorf("status = %v, want archived", body["status"])
+		}
+		analytics := body["analytics"].(map[string]interface{})
+		if analytics["participant_count"] != 12.0 || analytics["average_score"] != 76.5 {
+			t.Errorf("analytics = %v", analytics)
+		}
+		question, _ := analytics["questions"].(map[string]interface{})[questionID.String()].(map[string]interface{})
+		if question["correct_rate"] != 75.0 {
+			t.Errorf("question stats = %v", question)
+		}
+
+		if len(fake.ran(`INSERT INTO "quiz_analytics_snapshots"`)) != 1 {
+			t.Error("snapshot was not stored")
+		}
+		updates := fake.ran(`UPDATE "quizzes" SET "status"=$1`)
+		if len(updates) != 1 || updates[0].Args[0] != QuizStatusArchived {
+			t.Errorf("status updates = %v", updates)
+		}
+	})
+
+	t.Run("already archived returns the snapshot", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(lockQuiz, []string{"id", "status"}, []driver.Value{quizID.String(), QuizStatusArchived})
+		fake.rows([]string{`FROM "quiz_analytics_snapshots"`}, []string{"quiz_id", "participant_count"},
+			[]driver.Value{quizID.String(), int64(9)})
+		h := NewQuizHandler(db)
+
+		w := testRequest(h.ArchiveQuiz, "/quizzes/:id/archive", http.MethodPost, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		if analytics := decodeBody(t, w)["analytics"].(map[string]interface{}); analytics["participant_count"] != 9.0 {
+			t.Errorf("analytics = %v, want the existing snapshot", analytics)
+		}
+		if len(fake.ran("INSERT INTO")) != 0 || len(fake.ran(`UPDATE "quizzes"`)) != 0 {
+			t.Error("re-archiving wrote to the database")
+		}
+	})
+
+	t.Run("unknown quiz", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		w := testRequest(NewQuizHandler(db).ArchiveQuiz, "/quizzes/:id/archive", http.MethodPost, target, "", nil)
+		expectStatus(t, w, http.StatusNotFound)
+	})
+}
+
+func TestGetQuizArchiveNotArchived(t *testing.T) {
+	_, db := newFakeDB(t)
+	target := "/quizzes/" + uuid.NewString() + "/archive"
+
+	w := testRequest(NewQuizHandler(db).GetQuizArchive, "/quizzes/:id/archive", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AssignmentHourCompletion summarizes the quizzes published in one local hour
+// of the day
+type AssignmentHourCompletion struct {
+	Hour               int      `json:"hour"` // 0-23 in the requested timezone
+	Quizzes            int      `json:"quizzes"`
+	AvgCompletionRate  *float64 `json:"avg_completion_rate"` // percent of enrolled students who completed, averaged over quizzes
+	InsufficientSample []string `json:"insufficient_sample"`
+}
+
+// GetQuizCompletionByAssignmentHour groups the quizzes published in the period
+// by the local hour of their published_at and averages, per hour, the share of
+// each quiz's enrolled students who completed it. Quizzes without
+// published_at were never assigned; they are left out and counted separately.
+// All 24 hours are returned; hours without quizzes have a null average.
+func (h *ReportingHandler) GetQuizCompletionByAssignmentHour(c *gin.Context) {
+	var classroomID *uuid.UUID
+	if classroomIDStr := c.Query("classroom_id"); classroomIDStr != "" {
+		id, err := uuid.Parse(classroomIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		classroomID = &id
+	}
+
+	var schoolTimezone string
+	if classroomID != nil {
+		schoolTimezone = h.classroomTimezone(*classroomID)
+	}
+	loc, err := h.requestLocation(c, schoolTimezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -90, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	start := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day()+1, 0, 0, 0, 0, loc)
+	if !start.Before(end) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+
+	// Quiz timestamps are stored in UTC
+	query := h.db.Table("quizzes q").
+		Select(`
+			EXTRACT(HOUR FROM q.published_at AT TIME ZONE 'UTC' AT TIME ZONE ?)::int as hour,
+			COUNT(*) as quizzes,
+			AVG(LEAST(completed.students * 100.0 / NULLIF(enrolled.total, 0), 100)) as avg_completion_rate
+		`, loc.String()).
+		Joins(`CROSS JOIN LATERAL (
+			SELECT COUNT(*) as total FROM user_classrooms uc
+			WHERE uc.classroom_id = q.classroom_id AND uc.role = 'student' AND uc.is_active = true
+		) enrolled`).
+		Joins(`CROSS JOIN LATERAL (
+			SELECT COUNT(DISTINCT qs.student_id) as students FROM quiz_sessions qs
+			WHERE qs.quiz_id = q.id AND qs.is_completed = true
+		) completed`).
+		Where("q.deleted_at IS NULL AND q.published_at >= ? AND q.published_at < ?", start.UTC(), end.UTC())
+	unpublished := h.db.Table("quizzes q").
+		Where("q.deleted_at IS NULL AND q.published_at IS NULL").
+		Where("q.created_at >= ? AND q.created_at < ?", start.UTC(), end.UTC())
+	if classroomID != nil {
+		query = query.Where("q.classroom_id = ?", *classroomID)
+		unpublished = unpublished.Where("q.classroom_id = ?", *classroomID)
+	}
+
+	var rows []struct {
+		Hour              int
+		Quizzes           int
+		AvgCompletionRate *float64
+	}
+	if err := query.Group("hour").Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to group quizzes by assignment hour", "details": err.Error()})
+		return
+	}
+
+	var unpublishedCount int64
+	if err := unpublished.Count(&unpublishedCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count unpublished quizzes", "details": err.Error()})
+		return
+	}
+
+	hours := make([]AssignmentHourCompletion, 24)
+	for i := range hours {
+		hours[i] = AssignmentHourCompletion{Hour: i, InsufficientSample: []string{}}
+	}
+	for _, row := range rows {
+		if row.Hour < 0 || row.Hour > 23 {
+			continue
+		}
+		hourGuard := h.newSampleGuard()
+		avg := hourGuard.average("avg_completion_rate", row.AvgCompletionRate, row.Quizzes)
+		if avg != nil {
+			*avg = roundTo(*avg, 2)
+		}
+		hours[row.Hour] = AssignmentHourCompletion{
+			Hour:               row.Hour,
+			Quizzes:            row.Quizzes,
+			AvgCompletionRate:  avg,
+			InsufficientSample: hourGuard.flagged(),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":        classroomID,
+		"period":              gin.H{"from": start.Format(DateFormat), "to": end.AddDate(0, 0, -1).Format(DateFormat)},
+		"timezone":            loc.String(),
+		"hours":               hours,
+		"unpublished_quizzes": unpublishedCount,
+		"thresholds_applied":  h.thresholdsApplied(),
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestQuizCompletionByAssignmentHour(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM quizzes q", "EXTRACT(HOUR"}, []string{"hour", "quizzes", "avg_completion_rate"},
+		[]driver.Value{int64(8), int64(5), 72.456},
+		[]driver.Value{int64(19), int64(1), 40.0})
+	fake.rows([]string{"FROM quizzes q", "q.published_at IS NULL", "count(*)"}, []string{"count"}, []driver.Value{int64(2)})
+	h := NewReportingHandler(db)
+	classroomID := uuid.New()
+
+	target := "/analytics/quiz-completion-by-hour?classroom_id=" + classroomID.String() + "&tz=Europe/Berlin&date_from=2026-03-02&date_to=2026-03-08"
+	w := testRequest(h.GetQuizCompletionByAssignmentHour, "/analytics/quiz-completion-by-hour", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	hours := body["hours"].([]interface{})
+	if len(hours) != 24 || body["unpublished_quizzes"] != 2.0 {
+		t.Fatalf("body = %v, want 24 hours and 2 unpublished quizzes", body)
+	}
+	if morning := hours[8].(map[string]interface{}); morning["quizzes"] != 5.0 || morning["avg_completion_rate"] != 72.46 {
+		t.Errorf("hour 8 = %v, want 5 quizzes at 72.46%%", morning)
+	}
+	// A single quiz is below the default minimum sample
+	if evening := hours[19].(map[string]interface{}); evening["avg_completion_rate"] != nil || len(evening["insufficient_sample"].([]interface{})) != 1 {
+		t.Errorf("hour 19 = %v, want the average withheld", evening)
+	}
+	if quiet := hours[0].(map[string]interface{}); quiet["quizzes"] != 0.0 || quiet["avg_completion_rate"] != nil {
+		t.Errorf("hour 0 = %v, want no quizzes", quiet)
+	}
+
+	// Berlin is an hour ahead of UTC in early March
+	start := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	ran := fake.ran("EXTRACT(HOUR")
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{`GROUP BY "hour"`, "q.classroom_id = $"}) ||
+		!argsContain(ran[0].Args, "Europe/Berlin") || !argsContain(ran[0].Args, start) || !argsContain(ran[0].Args, classroomID) {
+		t.Errorf("quiz statements = %v, want local hours grouped by their alias", ran)
+	}
+	if counted := fake.ran("q.published_at IS NULL"); len(counted) != 1 || !argsContain(counted[0].Args, classroomID) {
+		t.Errorf("unpublished statements = %v, want one scoped to the classroom", counted)
+	}
+}
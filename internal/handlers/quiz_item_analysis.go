@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"reporting-framework/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// minItemAnalysisRespondents is the fewest scored respondents an item needs
+// before its discrimination index is reported
+const minItemAnalysisRespondents = 5
+
+// poorDiscrimination is the conventional cutoff below which an item barely
+// separates strong from weak students
+const poorDiscrimination = 0.2
+
+// Item analysis flags
+const (
+	ItemFlagTooFewRespondents      = "too_few_respondents"
+	ItemFlagAllCorrect             = "all_correct"
+	ItemFlagAllIncorrect           = "all_incorrect"
+	ItemFlagPoorDiscrimination     = "poor_discrimination"
+	ItemFlagNegativeDiscrimination = "negative_discrimination"
+)
+
+// ItemStatistics holds classic test theory statistics for one question
+type ItemStatistics struct {
+	Rank                int       `json:"rank"`
+	QuestionID          uuid.UUID `json:"question_id"`
+	OrderIndex          int       `json:"order_index"`
+	Respondents         int       `json:"respondents"`
+	CorrectCount        int       `json:"correct_count"`
+	DifficultyIndex     *float64  `json:"difficulty_index"`     // proportion correct
+	DiscriminationIndex *float64  `json:"discrimination_index"` // item-rest point-biserial correlation
+	Flags               []string  `json:"flags"`
+}
+
+// GetQuizItemAnalysis computes each question's difficulty index (proportion of
+// respondents answering correctly) and discrimination index (correlation of
+// item correctness with the student's score on the rest of the quiz). Items
+// are ranked by discrimination, weakest first, so poorly discriminating items
+// surface at the top; items whose discrimination is undefined come last.
+func (h *QuizHandler) GetQuizItemAnalysis(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid quiz_id format",
+			},
+		})
+		return
+	}
+
+	var quiz models.Quiz
+	if err := h.db.First(&quiz, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Quiz not found",
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	var questions []models.QuizQuestion
+	if err := h.db.Where("quiz_id = ?", id).Order("order_index ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz questions",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	// A student's item is correct if any of their graded answers was; ungraded
+	// answers (is_correct still null) are left out
+	var responses []itemResponse
+	err = h.db.Model(&models.QuizResponse{}).
+		Select("student_id, question_id, BOOL_OR(is_correct) as correct").
+		Where("quiz_id = ? AND is_correct IS NOT NULL", id).
+		Group("student_id, question_id").
+		Scan(&responses).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz responses",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quiz_id":         id,
+		"item_count":      len(questions),
+		"min_respondents": minItemAnalysisRespondents,
+		"items":           analyzeItems(questions, responses),
+	})
+}
+
+// itemResponse is whether a student answered a question correctly
+type itemResponse struct {
+	StudentID  uuid.UUID
+	QuestionID uuid.UUID
+	Correct    bool
+}
+
+// analyzeItems computes difficulty and discrimination per question and ranks
+// the items. A student's rest score for an item is their number of correct
+// answers on the other questions, so the item doesn't correlate with itself.
+func analyzeItems(questions []models.QuizQuestion, responses []itemResponse) []ItemStatistics {
+	totals := make(map[uuid.UUID]float64)
+	byQuestion := make(map[uuid.UUID][]itemResponse)
+	for _, r := range responses {
+		if r.Correct {
+			totals[r.StudentID]++
+		}
+		byQuestion[r.QuestionID] = append(byQuestion[r.QuestionID], r)
+	}
+
+	items := make([]ItemStatistics, len(questions))
+	for i, q := range questions {
+		item := ItemStatistics{
+			QuestionID: q.ID,
+			OrderIndex: q.OrderIndex,
+			Flags:      []string{},
+		}
+
+		answers := byQuestion[q.ID]
+		correctness := make([]float64, len(answers))
+		rest := make([]float64, len(answers))
+		for j, r := range answers {
+			rest[j] = totals[r.StudentID]
+			if r.Correct {
+				item.CorrectCount++
+				correctness[j] = 1
+				rest[j]--
+			}
+		}
+		item.Respondents = len(answers)
+
+		if item.Respondents > 0 {
+			difficulty := roundTo(float64(item.CorrectCount)/float64(item.Respondents), 4)
+			item.DifficultyIndex = &difficulty
+		}
+
+		switch {
+		case item.Respondents < minItemAnalysisRespondents:
+			item.Flags = append(item.Flags, ItemFlagTooFewRespondents)
+		case item.CorrectCount == item.Respondents:
+			item.Flags = append(item.Flags, ItemFlagAllCorrect)
+		case item.CorrectCount == 0:
+			item.Flags = append(item.Flags, ItemFlagAllIncorrect)
+		default:
+			if r, ok := pearsonCorrelation(correctness, rest); ok {
+				discrimination := roundTo(r, 4)
+				item.DiscriminationIndex = &discrimination
+				if discrimination < 0 {
+					item.Flags = append(item.Flags, ItemFlagNegativeDiscrimination)
+				} else if discrimination < poorDiscrimination {
+					item.Flags = append(item.Flags, ItemFlagPoorDiscrimination)
+				}
+			}
+		}
+
+		items[i] = item
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].DiscriminationIndex, items[j].DiscriminationIndex
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+	for i := range items {
+		items[i].Rank = i + 1
+	}
+	return items
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/models"
+)
+
+func TestAnalyzeItems(t *testing.T) {
+	questions := make([]models.QuizQuestion, 5)
+	for i := range questions {
+		questions[i] = models.QuizQuestion{ID: uuid.New(), OrderIndex: i + 1}
+	}
+	students := make([]uuid.UUID, 7)
+	for i := range students {
+		students[i] = uuid.New()
+	}
+
+	// Rows are questions 1-3, columns are students 1-5. Everyone answers
+	// question 5 correctly, and question 4 has only two other respondents.
+	matrix := [][]bool{
+		{true, true, true, false, false},
+		{true, true, false, false, false},
+		{true, false, true, false, true},
+	}
+	var responses []itemResponse
+	for q, row := range matrix {
+		for s, correct := range row {
+			responses = append(responses, itemResponse{StudentID: students[s], QuestionID: questions[q].ID, Correct: correct})
+		}
+	}
+	for s := 0; s < 5; s++ {
+		responses = append(responses, itemResponse{StudentID: students[s], QuestionID: questions[4].ID, Correct: true})
+	}
+	responses = append(responses,
+		itemResponse{StudentID: students[5], QuestionID: questions[3].ID, Correct: true},
+		itemResponse{StudentID: students[6], QuestionID: questions[3].ID, Correct: false})
+
+	// Question 1's rest scores are 2, 1, 1, 0, 1 against correctness 1, 1, 1, 0, 0:
+	// covariance sum 1 over sqrt(1.2 * 2). Question 2's are 2, 1, 2, 0, 1 against
+	// 1, 1, 0, 0, 0: 0.6 over sqrt(1.2 * 2.8). Question 3 is uncorrelated.
+	type want struct {
+		order          int
+		respondents    int
+		difficulty     float64
+		discrimination *float64
+		flags          []string
+	}
+	d := func(v float64) *float64 { return &v }
+	wants := []want{
+		{3, 5, 0.6, d(0), []string{ItemFlagPoorDiscrimination}},
+		{2, 5, 0.4, d(0.3273), []string{}},
+		{1, 5, 0.6, d(0.6455), []string{}},
+		{4, 2, 0.5, nil, []string{ItemFlagTooFewRespondents}},
+		{5, 5, 1, nil, []string{ItemFlagAllCorrect}},
+	}
+
+	items := analyzeItems(questions, responses)
+	if len(items) != len(wants) {
+		t.Fatalf("got %d items, want %d", len(items), len(wants))
+	}
+	for i, w := range wants {
+		item := items[i]
+		if item.Rank != i+1 || item.OrderIndex != w.order {
+			t.Errorf("rank %d is question %d, want question %d", item.Rank, item.OrderIndex, w.order)
+			continue
+		}
+		if item.Respondents != w.respondents || item.DifficultyIndex == nil || *item.DifficultyIndex != w.difficulty {
+			t.Errorf("question %d respondents %d, difficulty %v, want %d, %v", w.order, item.Respondents, item.DifficultyIndex, w.respondents, w.difficulty)
+		}
+		if (item.DiscriminationIndex == nil) != (w.discrimination == nil) ||
+			(w.discrimination != nil && *item.DiscriminationIndex != *w.discrimination) {
+			t.Errorf("question %d discrimination = %v, want %v", w.order, item.DiscriminationIndex, w.discrimination)
+		}
+		if !reflect.DeepEqual(item.Flags, w.flags) {
+			t.Errorf("question %d flags = %v, want %v", w.order, item.Flags, w.flags)
+		}
+	}
+}
+
+func TestAnalyzeItemsWithoutResponses(t *testing.T) {
+	items := analyzeItems([]models.QuizQuestion{{ID: uuid.New()}}, nil)
+	if len(items) != 1 || items[0].DifficultyIndex != nil || items[0].Respondents != 0 {
+		t.Errorf("items = %+v, want an unanswered item without a difficulty", items)
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"reporting-framework/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ObjectiveCoverage tallies the quizzes and questions that assess one
+// learning objective. Quiz tags are the objectives; every question of a
+// tagged quiz counts toward each of its tags.
+type ObjectiveCoverage struct {
+	Objective string `json:"objective"`
+	Quizzes   int    `json:"quizzes"`
+	Questions int    `json:"questions"`
+	Uncovered bool   `json:"uncovered"` // no quiz with at least one question addresses it
+}
+
+// UncategorizedCoverage tallies the quizzes without any tags
+type UncategorizedCoverage struct {
+	Quizzes   int `json:"quizzes"`
+	Questions int `json:"questions"`
+}
+
+// objectiveQuiz is a classroom quiz with its tags and question count
+type objectiveQuiz struct {
+	ID            uuid.UUID
+	Tags          models.StringList
+	QuestionCount int
+}
+
+// GetObjectiveCoverage tallies how many of a classroom's quizzes and
+// questions address each objective. Objectives are the tags on the
+// classroom's quizzes plus any listed in ?objectives= (comma-separated), so
+// curriculum objectives nobody has assessed yet show up as uncovered.
+// Untagged quizzes are counted in an uncategorized bucket. Archived quizzes
+// are left out unless include_archived=true.
+func (h *QuizHandler) GetObjectiveCoverage(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid classroom_id format",
+			},
+		})
+		return
+	}
+
+	var classroom models.Classroom
+	if err := h.db.First(&classroom, "id = ?", classroomID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Classroom not found",
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve classroom",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	query := h.db.Table("quizzes q").
+		Select("q.id, q.tags, (SELECT COUNT(*) FROM quiz_questions qq WHERE qq.quiz_id = q.id) as question_count").
+		Where("q.classroom_id = ?", classroomID)
+	if c.Query("include_archived") != "true" {
+		query = query.Where("q.status <> ?", QuizStatusArchived)
+	}
+
+	var quizzes []objectiveQuiz
+	if err := query.Scan(&quizzes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quizzes",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	var expected []string
+	if raw := c.Query("objectives"); raw != "" {
+		expected = normalizeTags(strings.Split(raw, ","))
+	}
+	objectives, uncategorized := tallyObjectiveCoverage(quizzes, expected)
+
+	uncovered := []string{}
+	for _, objective := range objectives {
+		if objective.Uncovered {
+			uncovered = append(uncovered, objective.Objective)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":  classroomID,
+		"total_quizzes": len(quizzes),
+		"objectives":    objectives,
+		"uncovered":     uncovered,
+		"uncategorized": uncategorized,
+	})
+}
+
+// tallyObjectiveCoverage counts the quizzes and questions per objective, in
+// objective order. Expected objectives that no quiz is tagged with are
+// included with zero counts.
+func tallyObjectiveCoverage(quizzes []objectiveQuiz, expected []string) ([]ObjectiveCoverage, UncategorizedCoverage) {
+	byObjective := make(map[string]*ObjectiveCoverage, len(expected))
+	for _, objective := range expected {
+		byObjective[objective] = &ObjectiveCoverage{Objective: objective}
+	}
+
+	var uncategorized UncategorizedCoverage
+	for _, quiz := range quizzes {
+		tags := normalizeTags(quiz.Tags)
+		if len(tags) == 0 {
+			uncategorized.Quizzes++
+			uncategorized.Questions += quiz.QuestionCount
+			continue
+		}
+		for _, tag := range tags {
+			coverage, ok := byObjective[tag]
+			if !ok {
+				coverage = &ObjectiveCoverage{Objective: tag}
+				byObjective[tag] = coverage
+			}
+			coverage.Quizzes++
+			coverage.Questions += quiz.QuestionCount
+		}
+	}
+
+	objectives := make([]ObjectiveCoverage, 0, len(byObjective))
+	for _, coverage := range byObjective {
+		coverage.Uncovered = coverage.Questions == 0
+		objectives = append(objectives, *coverage)
+	}
+	sort.Slice(objectives, func(i, j int) bool {
+		return objectives[i].Objective < objectives[j].Objective
+	})
+	return objectives, uncategorized
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/models"
+)
+
+func TestTallyObjectiveCoverage(t *testing.T) {
+	quizzes := []objectiveQuiz{
+		{ID: uuid.New(), Tags: models.StringList{"Fractions", "decimals"}, QuestionCount: 4},
+		{ID: uuid.New(), Tags: models.StringList{"fractions ", "FRACTIONS"}, QuestionCount: 3},
+		{ID: uuid.New(), Tags: models.StringList{"geometry"}, QuestionCount: 0},
+		{ID: uuid.New(), QuestionCount: 5},
+	}
+	objectives, uncategorized := tallyObjectiveCoverage(quizzes, []string{"algebra", "fractions"})
+
+	want := []ObjectiveCoverage{
+		{Objective: "algebra", Uncovered: true},
+		{Objective: "decimals", Quizzes: 1, Questions: 4},
+		{Objective: "fractions", Quizzes: 2, Questions: 7},
+		{Objective: "geometry", Quizzes: 1, Uncovered: true},
+	}
+	if !slices.Equal(objectives, want) {
+		t.Errorf("objectives = %+v, want %+v", objectives, want)
+	}
+	if uncategorized != (UncategorizedCoverage{Quizzes: 1, Questions: 5}) {
+		t.Errorf("uncategorized = %+v, want the untagged quiz", uncategorized)
+	}
+}
+
+func TestGetObjectiveCoverage(t *testing.T) {
+	fake, db := newFakeDB(t)
+	classroomID := uuid.New()
+	fake.rows([]string{`FROM "classrooms"`}, []string{"id", "name"}, []driver.Value{classroomID.String(), "Maths"})
+	fake.rows([]string{"FROM quizzes q", "question_count"}, []string{"id", "tags", "question_count"},
+		[]driver.Value{uuid.NewString(), []byte(`["fractions"]`), int64(6)},
+		[]driver.Value{uuid.NewString(), []byte(`[]`), int64(2)})
+	h := NewQuizHandler(db)
+
+	target := "/classrooms/" + classroomID.String() + "/objective-coverage?objectives=Fractions,%20ratios"
+	w := testRequest(h.GetObjectiveCoverage, "/classrooms/:id/objective-coverage", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	uncovered := body["uncovered"].([]interface{})
+	if body["total_quizzes"] != 2.0 || len(uncovered) != 1 || uncovered[0] != "ratios" {
+		t.Errorf("total, uncovered = %v, %v, want 2 quizzes with ratios uncovered", body["total_quizzes"], uncovered)
+	}
+	if uncategorized := body["uncategorized"].(map[string]interface{}); uncategorized["quizzes"] != 1.0 || uncategorized["questions"] != 2.0 {
+		t.Errorf("uncategorized = %v", uncategorized)
+	}
+	if ran := fake.ran("FROM quizzes q"); len(ran) != 1 || !argsContain(ran[0].Args, QuizStatusArchived) {
+		t.Errorf("quiz statements = %v, want archived quizzes left out", ran)
+	}
+
+	w = testRequest(h.GetObjectiveCoverage, "/classrooms/:id/objective-coverage", http.MethodGet,
+		"/classrooms/"+classroomID.String()+"/objective-coverage?include_archived=true", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if ran := fake.ran("FROM quizzes q"); len(ran) != 2 || argsContain(ran[1].Args, QuizStatusArchived) {
+		t.Errorf("quiz statements = %v, want archived quizzes included", ran)
+	}
+}
+
+func TestGetObjectiveCoverageUnknownClassroom(t *testing.T) {
+	_, db := newFakeDB(t)
+	w := testRequest(NewQuizHandler(db).GetObjectiveCoverage, "/classrooms/:id/objective-coverage", http.MethodGet,
+		"/classrooms/"+uuid.NewString()+"/objective-coverage", "", nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
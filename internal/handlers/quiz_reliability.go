@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+
+	"reporting-framework/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// minReliabilityRespondents is the fewest complete respondents needed for a
+// meaningful reliability estimate
+const minReliabilityRespondents = 3
+
+// ItemVariance describes one question's contribution to quiz reliability
+type ItemVariance struct {
+	QuestionID    uuid.UUID `json:"question_id"`
+	OrderIndex    int       `json:"order_index"`
+	Variance      float64   `json:"variance"`
+	VarianceShare float64   `json:"variance_share"`
+}
+
+// GetQuizReliability computes Cronbach's alpha over per-question scores for
+// every student who answered all questions of the quiz
+func (h *QuizHandler) GetQuizReliability(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid quiz_id format",
+			},
+		})
+		return
+	}
+
+	var quiz models.Quiz
+	if err := h.db.First(&quiz, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": map[string]interface{}{
+					"code":    "NOT_FOUND",
+					"message": "Quiz not found",
+				},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	var questions []models.QuizQuestion
+	if err := h.db.Where("quiz_id = ?", id).Order("order_index ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz questions",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	// Best score per student and question; repeated answers don't inflate the item
+	var itemScores []struct {
+		StudentID  uuid.UUID
+		QuestionID uuid.UUID
+		Score      float64
+	}
+	err = h.db.Model(&models.QuizResponse{}).
+		Select("student_id, question_id, MAX(COALESCE(points_earned, 0)) as score").
+		Where("quiz_id = ?", id).
+		Group("student_id, question_id").
+		Scan(&itemScores).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve quiz responses",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	// Build the student x item matrix, keeping only students who answered every item
+	itemIndex := make(map[uuid.UUID]int, len(questions))
+	for i, q := range questions {
+		itemIndex[q.ID] = i
+	}
+	byStudent := make(map[uuid.UUID][]*float64)
+	for _, s := range itemScores {
+		idx, ok := itemIndex[s.QuestionID]
+		if !ok {
+			continue
+		}
+		row, ok := byStudent[s.StudentID]
+		if !ok {
+			row = make([]*float64, len(questions))
+			byStudent[s.StudentID] = row
+		}
+		score := s.Score
+		row[idx] = &score
+	}
+
+	var matrix [][]float64
+	for _, row := range byStudent {
+		complete := make([]float64, 0, len(row))
+		for _, v := range row {
+			if v == nil {
+				break
+			}
+			complete = append(complete, *v)
+		}
+		if len(complete) == len(questions) {
+			matrix = append(matrix, complete)
+		}
+	}
+
+	response := gin.H{
+		"quiz_id":     id,
+		"item_count":  len(questions),
+		"respondents": len(matrix),
+	}
+
+	if len(questions) < 2 {
+		response["status"] = "insufficient_data"
+		response["reason"] = "Reliability requires at least two questions"
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	if len(matrix) < minReliabilityRespondents {
+		response["status"] = "insufficient_data"
+		response["reason"] = "Not enough students have answered every question"
+		response["min_respondents"] = minReliabilityRespondents
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	alpha, variances, ok := cronbachAlpha(matrix)
+	if !ok {
+		response["status"] = "insufficient_data"
+		response["reason"] = "Total scores have no variance across students"
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	sumVariance := 0.0
+	for _, v := range variances {
+		sumVariance += v
+	}
+	items := make([]ItemVariance, len(questions))
+	for i, q := range questions {
+		items[i] = ItemVariance{
+			QuestionID: q.ID,
+			OrderIndex: q.OrderIndex,
+			Variance:   variances[i],
+		}
+		if sumVariance > 0 {
+			items[i].VarianceShare = variances[i] / sumVariance
+		}
+	}
+
+	response["status"] = "ok"
+	response["cronbach_alpha"] = alpha
+	response["items"] = items
+	c.JSON(http.StatusOK, response)
+}
+
+// cronbachAlpha computes alpha = k/(k-1) * (1 - sum(item variances) / variance(totals))
+// over a respondents x items matrix. It returns false when the statistic is
+// undefined (fewer than two items or respondents, or zero total variance).
+func cronbachAlpha(scores [][]float64) (float64, []float64, bool) {
+	if len(scores) < 2 || len(scores[0]) < 2 {
+		return 0, nil, false
+	}
+	k := len(scores[0])
+
+	itemVariances := make([]float64, k)
+	column := make([]float64, len(scores))
+	for j := 0; j < k; j++ {
+		for i, row := range scores {
+			column[i] = row[j]
+		}
+		itemVariances[j] = sampleVariance(column)
+	}
+
+	totals := make([]float64, len(scores))
+	for i, row := range scores {
+		for _, v := range row {
+			totals[i] += v
+		}
+	}
+	totalVariance := sampleVariance(totals)
+	if totalVariance == 0 {
+		return 0, itemVariances, false
+	}
+
+	sumItemVariance := 0.0
+	for _, v := range itemVariances {
+		sumItemVariance += v
+	}
+
+	kf := float64(k)
+	return kf / (kf - 1) * (1 - sumItemVariance/totalVariance), itemVariances, true
+}
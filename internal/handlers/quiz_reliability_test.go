@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCronbachAlpha(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores [][]float64
+		want   float64
+		ok     bool
+	}{
+		{"perfectly consistent items", [][]float64{{1, 1}, {2, 2}, {3, 3}}, 1, true},
+		{"partly consistent items", [][]float64{{1, 2}, {2, 1}, {3, 3}}, 2.0 / 3, true},
+		{"no variance in totals", [][]float64{{1, 2}, {2, 1}}, 0, false},
+		{"single item", [][]float64{{1}, {2}, {3}}, 0, false},
+		{"single respondent", [][]float64{{1, 2}}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alpha, _, ok := cronbachAlpha(tt.scores)
+			if ok != tt.ok || math.Abs(alpha-tt.want) > 1e-9 {
+				t.Errorf("cronbachAlpha = %v, %v, want %v, %v", alpha, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGetQuizReliability(t *testing.T) {
+	quizID := uuid.New()
+	q1, q2 := uuid.New(), uuid.New()
+	target := "/quizzes/" + quizID.String() + "/reliability"
+
+	setup := func(t *testing.T, scores ...[]driver.Value) *QuizHandler {
+		fake, db := newFakeDB(t)
+		fake.rows([]string{`FROM "quizzes"`}, []string{"id"}, []driver.Value{quizID.String()})
+		fake.rows([]string{`FROM "quiz_questions"`}, []string{"id", "order_index"},
+			[]driver.Value{q1.String(), int64(0)},
+			[]driver.Value{q2.String(), int64(1)})
+		fake.rows([]string{`FROM "quiz_responses"`}, []string{"student_id", "question_id", "score"}, scores...)
+		return NewQuizHandler(db)
+	}
+
+	t.Run("alpha over complete respondents", func(t *testing.T) {
+		s1, s2, s3, partial := uuid.New().String(), uuid.New().String(), uuid.New().String(), uuid.New().String()
+		h := setup(t,
+			[]driver.Value{s1, q1.String(), 1.0}, []driver.Value{s1, q2.String(), 1.0},
+			[]driver.Value{s2, q1.String(), 2.0}, []driver.Value{s2, q2.String(), 2.0},
+			[]driver.Value{s3, q1.String(), 3.0}, []driver.Value{s3, q2.String(), 3.0},
+			[]driver.Value{partial, q1.String(), 0.0})
+
+		w := testRequest(h.GetQuizReliability, "/quizzes/:id/reliability", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+
+		if body["status"] != "ok" || body["cronbach_alpha"] != 1.0 || body["respondents"] != 3.0 {
+			t.Errorf("body = %v, want alpha 1 over 3 complete respondents", body)
+		}
+		items := body["items"].([]interface{})
+		if share := items[0].(map[string]interface{})["variance_share"]; share != 0.5 {
+			t.Errorf("variance_share = %v, want 0.5", share)
+		}
+	})
+
+	t.Run("too few respondents", func(t *testing.T) {
+		s1 := uuid.New().String()
+		h := setup(t, []driver.Value{s1, q1.String(), 1.0}, []driver.Value{s1, q2.String(), 1.0})
+
+		w := testRequest(h.GetQuizReliability, "/quizzes/:id/reliability", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		if body := decodeBody(t, w); body["status"] != "insufficient_data" || body["min_respondents"] != float64(minReliabilityRespondents) {
+			t.Errorf("body = %v, want insufficient_data", body)
+		}
+	})
+}
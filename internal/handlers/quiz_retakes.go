@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AttemptCount is how many students completed a quiz a given number of times
+type AttemptCount struct {
+	Attempts int `json:"attempts"`
+	Students int `json:"students"`
+}
+
+// QuizRetakes describes how students retake a quiz
+type QuizRetakes struct {
+	QuizID                    uuid.UUID      `json:"quiz_id"`
+	Students                  int            `json:"students"`
+	RetookStudents            int            `json:"retook_students"`
+	RetakeRate                *float64       `json:"retake_rate"` // share of students with more than one attempt, null without students
+	AttemptDistribution       []AttemptCount `json:"attempt_distribution"`
+	AvgImprovementPerRetake   *float64       `json:"avg_improvement_per_retake"` // mean percentage point change from one attempt to the next
+	AvgFirstToBestImprovement *float64       `json:"avg_first_to_best_improvement"`
+}
+
+// GetQuizRetakes reports how many times students completed a quiz, the share
+// who retook it, and how scores change with each additional attempt.
+// Attempts are ordered by attempt_number within each student; the improvement
+// fields are null when nobody retook the quiz.
+func (h *ReportingHandler) GetQuizRetakes(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz_id"})
+		return
+	}
+
+	var quiz struct{ ID uuid.UUID }
+	err = h.db.Table("quizzes").Select("id").Where("id = ?", quizID).Take(&quiz).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz", "details": err.Error()})
+		return
+	}
+
+	var attempts []struct {
+		StudentID       uuid.UUID
+		AttemptNumber   int
+		PercentageScore float64
+	}
+	err = h.db.Table("quiz_sessions").
+		Select("student_id, attempt_number, percentage_score").
+		Where("quiz_id = ? AND is_completed = true AND percentage_score IS NOT NULL", quizID).
+		Order("student_id, attempt_number, completed_at").
+		Scan(&attempts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quiz attempts", "details": err.Error()})
+		return
+	}
+
+	// Rows are grouped by student, so each student's scores are contiguous and in attempt order
+	var perStudent [][]float64
+	for i, a := range attempts {
+		if i == 0 || a.StudentID != attempts[i-1].StudentID {
+			perStudent = append(perStudent, nil)
+		}
+		perStudent[len(perStudent)-1] = append(perStudent[len(perStudent)-1], a.PercentageScore)
+	}
+
+	result := QuizRetakes{QuizID: quizID, Students: len(perStudent), AttemptDistribution: []AttemptCount{}}
+	byAttempts := make(map[int]int)
+	var stepChanges, firstToBest []float64
+	for _, scores := range perStudent {
+		byAttempts[len(scores)]++
+		if len(scores) < 2 {
+			continue
+		}
+		result.RetookStudents++
+		best := scores[0]
+		for i := 1; i < len(scores); i++ {
+			stepChanges = append(stepChanges, scores[i]-scores[i-1])
+			best = max(best, scores[i])
+		}
+		firstToBest = append(firstToBest, best-scores[0])
+	}
+
+	for count, students := range byAttempts {
+		result.AttemptDistribution = append(result.AttemptDistribution, AttemptCount{Attempts: count, Students: students})
+	}
+	sort.Slice(result.AttemptDistribution, func(i, j int) bool {
+		return result.AttemptDistribution[i].Attempts < result.AttemptDistribution[j].Attempts
+	})
+
+	if result.Students > 0 {
+		rate := roundTo(float64(result.RetookStudents)/float64(result.Students)*100, 2)
+		result.RetakeRate = &rate
+	}
+	if len(stepChanges) > 0 {
+		avg := roundTo(meanOf(stepChanges), 2)
+		result.AvgImprovementPerRetake = &avg
+		avgBest := roundTo(meanOf(firstToBest), 2)
+		result.AvgFirstToBestImprovement = &avgBest
+	}
+
+	c.JSON(http.StatusOK, result)
+}
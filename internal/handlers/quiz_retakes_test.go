@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestQuizRetakes(t *testing.T) {
+	fake, db := newFakeDB(t)
+	quizID := uuid.New()
+	fake.rows([]string{`FROM "quizzes"`}, []string{"id"}, []driver.Value{quizID.String()})
+	once, twice, thrice := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	fake.rows([]string{`FROM "quiz_sessions"`, "is_completed = true"}, []string{"student_id", "attempt_number", "percentage_score"},
+		[]driver.Value{once, int64(1), 70.0},
+		[]driver.Value{thrice, int64(1), 50.0},
+		[]driver.Value{thrice, int64(2), 70.0},
+		[]driver.Value{thrice, int64(3), 60.0},
+		[]driver.Value{twice, int64(1), 40.0},
+		[]driver.Value{twice, int64(2), 80.0})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetQuizRetakes, "/analytics/quizzes/:id/retakes", http.MethodGet, "/analytics/quizzes/"+quizID.String()+"/retakes", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	if body["students"] != 3.0 || body["retook_students"] != 2.0 || body["retake_rate"] != 66.67 {
+		t.Errorf("students, retook, rate = %v, %v, %v, want 3, 2, 66.67", body["students"], body["retook_students"], body["retake_rate"])
+	}
+	// Step changes are +20, -10 and +40; first to best is 20 and 40
+	if body["avg_improvement_per_retake"] != 16.67 || body["avg_first_to_best_improvement"] != 30.0 {
+		t.Errorf("improvement = %v per retake, %v first to best, want 16.67 and 30",
+			body["avg_improvement_per_retake"], body["avg_first_to_best_improvement"])
+	}
+	distribution := body["attempt_distribution"].([]interface{})
+	for i, want := range []float64{1, 2, 3} {
+		if got := distribution[i].(map[string]interface{}); got["attempts"] != want || got["students"] != 1.0 {
+			t.Errorf("attempt_distribution[%d] = %v, want one student with %v attempts", i, got, want)
+		}
+	}
+}
+
+func TestQuizRetakesWithoutRetakes(t *testing.T) {
+	fake, db := newFakeDB(t)
+	quizID := uuid.New()
+	fake.rows([]string{`FROM "quizzes"`}, []string{"id"}, []driver.Value{quizID.String()})
+	fake.rows([]string{`FROM "quiz_sessions"`}, []string{"student_id", "attempt_number", "percentage_score"},
+		[]driver.Value{uuid.NewString(), int64(1), 70.0})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetQuizRetakes, "/analytics/quizzes/:id/retakes", http.MethodGet, "/analytics/quizzes/"+quizID.String()+"/retakes", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	if body["retake_rate"] != 0.0 || body["avg_improvement_per_retake"] != nil || body["avg_first_to_best_improvement"] != nil {
+		t.Errorf("body = %v, want a zero rate and null improvements", body)
+	}
+}
+
+func TestQuizRetakesUnknownQuiz(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	w := testRequest(h.GetQuizRetakes, "/analytics/quizzes/:id/retakes", http.MethodGet, "/analytics/quizzes/"+uuid.NewString()+"/retakes", "", nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
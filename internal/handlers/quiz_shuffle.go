@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"net/http"
+	"sort"
+
+	"reporting-framework/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PresentedOption is an answer option as shown to a student. Labels are the
+// question's own option labels in order; in a shuffled view the text behind
+// each label comes from a different canonical option.
+type PresentedOption struct {
+	Label string      `json:"label"`
+	Text  interface{} `json:"text"`
+}
+
+// StudentQuestion is a question as served to a student, without the answer
+type StudentQuestion struct {
+	ID           uuid.UUID         `json:"id"`
+	QuestionText string            `json:"question_text"`
+	QuestionType string            `json:"question_type"`
+	Options      []PresentedOption `json:"options"`
+	Points       float64           `json:"points"`
+	OrderIndex   int               `json:"order_index"`
+}
+
+// SetShuffleSeed sets the secret mixed into every option shuffle, so students
+// can't reproduce each other's option order from their ids alone
+func (h *QuizHandler) SetShuffleSeed(seed string) {
+	h.shuffleSeed = seed
+}
+
+// GetQuizQuestions lists a quiz's questions in order. With for=student and a
+// student_id, correct answers are left out and each question's options are
+// shuffled deterministically per student and question; submit the shown
+// label with "shuffled": true to have it mapped back for grading.
+func (h *QuizHandler) GetQuizQuestions(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"code":    "VALIDATION_ERROR",
+				"message": "Invalid quiz_id format",
+			},
+		})
+		return
+	}
+
+	forStudent := c.Query("for") == "student"
+	var studentID uuid.UUID
+	if forStudent {
+		studentID, err = uuid.Parse(c.Query("student_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": map[string]interface{}{
+					"code":    "VALIDATION_ERROR",
+					"message": "A valid student_id is required with for=student",
+				},
+			})
+			return
+		}
+	}
+
+	var quiz models.Quiz
+	if err := h.db.Select("id").First(&quiz, "id = ?", quizID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": map[string]interface{}{
+				"code":    "NOT_FOUND",
+				"message": "Quiz not found",
+			},
+		})
+		return
+	}
+
+	var questions []models.QuizQuestion
+	if err := h.db.Where("quiz_id = ?", quizID).Order("order_index ASC, id ASC").Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": map[string]interface{}{
+				"code":    "DATABASE_ERROR",
+				"message": "Failed to retrieve questions",
+				"details": err.Error(),
+			},
+		})
+		return
+	}
+
+	if !forStudent {
+		c.JSON(http.StatusOK, gin.H{"quiz_id": quizID, "questions": questions})
+		return
+	}
+
+	served := make([]StudentQuestion, len(questions))
+	for i, question := range questions {
+		labels := optionLabels(question.Options)
+		order := h.shuffledLabels(studentID, question.ID, labels)
+		options := make([]PresentedOption, len(labels))
+		for j, label := range labels {
+			options[j] = PresentedOption{Label: label, Text: question.Options[order[j]]}
+		}
+		served[i] = StudentQuestion{
+			ID:           question.ID,
+			QuestionText: question.QuestionText,
+			QuestionType: question.QuestionType,
+			Options:      options,
+			Points:       question.Points,
+			OrderIndex:   question.OrderIndex,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quiz_id": quizID, "student_id": studentID, "questions": served})
+}
+
+// canonicalAnswer maps a label from a student's shuffled view back to the
+// question's own option label. Answers that aren't a shown label are
+// returned unchanged, so free-text answers grade as before.
+func (h *QuizHandler) canonicalAnswer(question models.QuizQuestion, studentID uuid.UUID, shown string) string {
+	labels := optionLabels(question.Options)
+	order := h.shuffledLabels(studentID, question.ID, labels)
+	for i, label := range labels {
+		if label == shown {
+			return order[i]
+		}
+	}
+	return shown
+}
+
+// optionLabels returns a question's option labels sorted, so A, B, C, D
+func optionLabels(options models.JSONB) []string {
+	labels := make([]string, 0, len(options))
+	for label := range options {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// shuffledLabels permutes labels with a generator seeded by the shuffle seed,
+// the student and the question, so a student always sees the same order
+func (h *QuizHandler) shuffledLabels(studentID, questionID uuid.UUID, labels []string) []string {
+	hash := sha256.New()
+	hash.Write([]byte(h.shuffleSeed))
+	hash.Write(studentID[:])
+	hash.Write(questionID[:])
+	seed := int64(binary.BigEndian.Uint64(hash.Sum(nil)))
+
+	shuffled := append([]string(nil), labels...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/models"
+)
+
+func TestShuffledLabels(t *testing.T) {
+	question := models.QuizQuestion{
+		ID:      uuid.MustParse("6f1c2a4e-0b8d-4c1e-9a57-3d2f8e6b4a10"),
+		Options: models.JSONB{"A": "2", "B": "3", "C": "5", "D": "7", "E": "11", "F": "13"},
+	}
+	studentID := uuid.MustParse("c3a9d1f2-5e47-4b8a-8f06-1b2c3d4e5f60")
+	labels := optionLabels(question.Options)
+	if want := []string{"A", "B", "C", "D", "E", "F"}; !reflect.DeepEqual(labels, want) {
+		t.Fatalf("optionLabels = %v, want %v", labels, want)
+	}
+
+	h := NewQuizHandler(nil)
+	h.SetShuffleSeed("first")
+	order := h.shuffledLabels(studentID, question.ID, labels)
+	if again := h.shuffledLabels(studentID, question.ID, labels); !reflect.DeepEqual(again, order) {
+		t.Errorf("same seed gave %v then %v", order, again)
+	}
+	sorted := slices.Clone(order)
+	slices.Sort(sorted)
+	if !reflect.DeepEqual(sorted, labels) {
+		t.Fatalf("shuffled labels %v are not a permutation of %v", order, labels)
+	}
+	if reflect.DeepEqual(order, labels) {
+		t.Errorf("shuffle left the labels in order")
+	}
+
+	// The student sees label j showing the text of option order[j], so
+	// answering with label j must grade as order[j]
+	for j, shown := range labels {
+		if got := h.canonicalAnswer(question, studentID, shown); got != order[j] {
+			t.Errorf("canonicalAnswer(%q) = %q, want %q", shown, got, order[j])
+		}
+	}
+	if got := h.canonicalAnswer(question, studentID, "free text"); got != "free text" {
+		t.Errorf("canonicalAnswer kept %q as %q", "free text", got)
+	}
+
+	other := NewQuizHandler(nil)
+	other.SetShuffleSeed("second")
+	if reflect.DeepEqual(other.shuffledLabels(studentID, question.ID, labels), order) {
+		t.Error("a different seed gave the same order")
+	}
+	if reflect.DeepEqual(h.shuffledLabels(uuid.MustParse("0d4e8f2a-7c61-4b39-a5e0-9f8e7d6c5b4a"), question.ID, labels), order) {
+		t.Error("a different student got the same order")
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"reporting-framework/internal/models"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got := normalizeTags([]string{" Fractions", "fractions", "", "Week 3 ", "  "})
+	if want := (models.StringList{"fractions", "week 3"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeTags = %v, want %v", got, want)
+	}
+}
+
+func TestListQuizzesTagFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"any tag", "/quizzes?tags=Fractions,week%203", `(quizzes.tags @> $2::jsonb OR quizzes.tags @> $3::jsonb)`, []interface{}{"archived", `["fractions"]`, `["week 3"]`}},
+		{"all tags", "/quizzes?tags=Fractions,week%203&tag_match=all", `quizzes.tags @> $2::jsonb ORDER BY`, []interface{}{"archived", `["fractions","week 3"]`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			w := testRequest(NewQuizHandler(db).ListQuizzes, "/quizzes", http.MethodGet, tt.target, "", nil)
+			expectStatus(t, w, http.StatusOK)
+
+			statements := fake.ran(`FROM "quizzes"`)
+			if len(statements) != 1 {
+				t.Fatalf("ran %d quiz queries, want 1", len(statements))
+			}
+			if !strings.Contains(statements[0].SQL, tt.wantSQL) {
+				t.Errorf("SQL %q does not contain %q", statements[0].SQL, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(statements[0].Args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", statements[0].Args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// highTimingVariationCV is the coefficient of variation (stddev / mean) of
+// time spent at or above which a question is flagged as potentially confusing:
+// students either answer quickly or get stuck
+const highTimingVariationCV = 1.0
+
+// TimePercentiles is the distribution of time spent per question, in seconds
+type TimePercentiles struct {
+	Responses    int      `json:"responses"`
+	AvgSeconds   *float64 `json:"avg_seconds"`
+	P25Seconds   *float64 `json:"p25_seconds"`
+	P50Seconds   *float64 `json:"p50_seconds"`
+	P75Seconds   *float64 `json:"p75_seconds"`
+	P90Seconds   *float64 `json:"p90_seconds"`
+	StddevSecs   *float64 `json:"stddev_seconds"`
+	Variation    *float64 `json:"coefficient_of_variation"`
+	Insufficient bool     `json:"insufficient_sample"`
+}
+
+// QuestionTiming is the time spent distribution for one question
+type QuestionTiming struct {
+	QuestionID    uuid.UUID `json:"question_id"`
+	QuestionText  string    `json:"question_text"`
+	OrderIndex    int       `json:"order_index"`
+	HighVariation bool      `json:"high_variation"`
+	TimePercentiles
+}
+
+// GetQuizTiming reports p25/p50/p75/p90 of time spent per question, across
+// the whole quiz and for each question. Distributions backed by fewer
+// responses than the minimum sample size are withheld; questions whose
+// timing varies widely are flagged as potentially confusing.
+func (h *ReportingHandler) GetQuizTiming(c *gin.Context) {
+	quizID, err := uuid.Parse(c.Param("quiz_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz_id"})
+		return
+	}
+
+	const distributionSQL = `
+		COUNT(qsub.time_spent_seconds) as responses,
+		AVG(qsub.time_spent_seconds) as avg_seconds,
+		percentile_cont(0.25) WITHIN GROUP (ORDER BY qsub.time_spent_seconds) as p25_seconds,
+		percentile_cont(0.50) WITHIN GROUP (ORDER BY qsub.time_spent_seconds) as p50_seconds,
+		percentile_cont(0.75) WITHIN GROUP (ORDER BY qsub.time_spent_seconds) as p75_seconds,
+		percentile_cont(0.90) WITHIN GROUP (ORDER BY qsub.time_spent_seconds) as p90_seconds,
+		STDDEV_SAMP(qsub.time_spent_seconds) as stddev_secs
+	`
+
+	// percentile_cont ignores NULLs, so untimed submissions don't count
+	var overall TimePercentiles
+	err = h.db.Table("quiz_submissions qsub").
+		Select(distributionSQL).
+		Where("qsub.quiz_id = ?", quizID).
+		Scan(&overall).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate quiz timing", "details": err.Error()})
+		return
+	}
+
+	questions := []QuestionTiming{}
+	err = h.db.Table("quiz_questions qq").
+		Select("qq.id as question_id, qq.question_text, qq.order_index,"+distributionSQL).
+		Joins("LEFT JOIN quiz_submissions qsub ON qsub.question_id = qq.id").
+		Where("qq.quiz_id = ?", quizID).
+		Group("qq.id, qq.question_text, qq.order_index").
+		Order("qq.order_index, qq.id").
+		Scan(&questions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate question timing", "details": err.Error()})
+		return
+	}
+	if len(questions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found or has no questions"})
+		return
+	}
+
+	h.finishTimePercentiles(&overall)
+	var flagged []uuid.UUID
+	for i := range questions {
+		q := &questions[i]
+		h.finishTimePercentiles(&q.TimePercentiles)
+		if q.Variation != nil && *q.Variation >= highTimingVariationCV {
+			q.HighVariation = true
+			flagged = append(flagged, q.QuestionID)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quiz_id":              quizID,
+		"overall":              overall,
+		"questions":            questions,
+		"high_variation_count": len(flagged),
+		"high_variation_cv":    highTimingVariationCV,
+		"thresholds_applied":   h.thresholdsApplied(),
+	})
+}
+
+// finishTimePercentiles withholds a distribution with too few responses,
+// otherwise rounds it and derives the coefficient of variation
+func (h *ReportingHandler) finishTimePercentiles(t *TimePercentiles) {
+	if t.Responses < h.minSampleSize {
+		*t = TimePercentiles{Responses: t.Responses, Insufficient: true}
+		return
+	}
+	for _, v := range []*float64{t.AvgSeconds, t.P25Seconds, t.P50Seconds, t.P75Seconds, t.P90Seconds, t.StddevSecs} {
+		if v != nil {
+			*v = roundTo(*v, 2)
+		}
+	}
+	if t.AvgSeconds != nil && t.StddevSecs != nil && *t.AvgSeconds > 0 {
+		cv := roundTo(*t.StddevSecs / *t.AvgSeconds, 2)
+		t.Variation = &cv
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestQuizTiming(t *testing.T) {
+	fake, db := newFakeDB(t)
+	distribution := []string{"responses", "avg_seconds", "p25_seconds", "p50_seconds", "p75_seconds", "p90_seconds", "stddev_secs"}
+	fake.rows([]string{"FROM quiz_submissions qsub", "percentile_cont(0.90)"}, distribution,
+		[]driver.Value{int64(40), 30.0, 12.0, 25.456, 40.0, 60.0, 15.0})
+	steady, confusing, rare := uuid.New(), uuid.New(), uuid.New()
+	fake.rows([]string{"FROM quiz_questions qq", "LEFT JOIN quiz_submissions qsub"}, append([]string{"question_id", "question_text", "order_index"}, distribution...),
+		[]driver.Value{steady.String(), "Steady", int64(1), int64(20), 20.0, 15.0, 20.0, 25.0, 28.0, 5.0},
+		[]driver.Value{confusing.String(), "Confusing", int64(2), int64(18), 40.0, 5.0, 30.0, 70.0, 100.0, 44.0},
+		[]driver.Value{rare.String(), "Rare", int64(3), int64(2), 10.0, 1.0, 2.0, 30.0, 40.0, 20.0})
+	h := NewReportingHandler(db)
+	h.SetMinSampleSize(5)
+
+	quizID := uuid.New()
+	w := testRequest(h.GetQuizTiming, "/analytics/quizzes/:quiz_id/timing", http.MethodGet, "/analytics/quizzes/"+quizID.String()+"/timing", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	overall := body["overall"].(map[string]interface{})
+	if overall["p50_seconds"] != 25.46 || overall["coefficient_of_variation"] != 0.5 {
+		t.Errorf("overall = %v, want rounded p50 25.46 and variation 0.5", overall)
+	}
+	questions := body["questions"].([]interface{})
+	if len(questions) != 3 {
+		t.Fatalf("got %d questions, want 3", len(questions))
+	}
+	if q := questions[0].(map[string]interface{}); q["high_variation"] != false || q["coefficient_of_variation"] != 0.25 {
+		t.Errorf("steady question = %v, want variation 0.25 unflagged", q)
+	}
+	if q := questions[1].(map[string]interface{}); q["high_variation"] != true || q["coefficient_of_variation"] != 1.1 {
+		t.Errorf("confusing question = %v, want variation 1.1 flagged", q)
+	}
+	// Two responses fall under the minimum sample, so only the count survives
+	if q := questions[2].(map[string]interface{}); q["insufficient_sample"] != true || q["responses"] != 2.0 || q["p50_seconds"] != nil || q["high_variation"] != false {
+		t.Errorf("rare question = %v, want the distribution withheld", q)
+	}
+	if body["high_variation_count"] != 1.0 {
+		t.Errorf("high_variation_count = %v, want 1", body["high_variation_count"])
+	}
+	if ran := fake.ran("FROM quiz_submissions qsub"); len(ran) != 1 || !argsContain(ran[0].Args, quizID) {
+		t.Errorf("overall statements = %v, want one scoped to the quiz", ran)
+	}
+}
+
+func TestQuizTimingUnknownQuiz(t *testing.T) {
+	_, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	w := testRequest(h.GetQuizTiming, "/analytics/quizzes/:quiz_id/timing", http.MethodGet, "/analytics/quizzes/"+uuid.NewString()+"/timing", "", nil)
+	expectStatus(t, w, http.StatusNotFound)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Supported sort options for classroom rankings
+const (
+	RankByEngagement    = "engagement"
+	RankByParticipation = "participation"
+	RankByAvgScore      = "avg_score"
+)
+
+// ClassroomRanking represents a single classroom's position in a school ranking
+type ClassroomRanking struct {
+	Rank             int       `json:"rank"`
+	ClassroomID      uuid.UUID `json:"classroom_id"`
+	ClassroomName    string    `json:"classroom_name"`
+	MetricValue      *float64  `json:"metric_value"`
+	AvgEngagement    *float64  `json:"avg_engagement"`
+	AvgParticipation *float64  `json:"avg_participation"`
+	AvgScore         *float64  `json:"avg_score"`
+	DaysWithData     int       `json:"days_with_data"`
+	QuizSessions     int       `json:"-"`
+
+	InsufficientSample []string `json:"insufficient_sample"`
+}
+
+// GetClassroomRankings ranks every classroom in a school by the requested metric.
+// min_/max_ parameters for participation, avg_score and engagement keep only
+// classrooms within those percentages; ranks still reflect the whole school.
+func (h *ReportingHandler) GetClassroomRankings(c *gin.Context) {
+	schoolID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid school_id format"})
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort_by", RankByEngagement)
+	if sortBy != RankByEngagement && sortBy != RankByParticipation && sortBy != RankByAvgScore {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort_by must be one of engagement, participation, avg_score"})
+		return
+	}
+
+	bounds, err := parseMeasureBounds(c, "participation", "avg_score", "engagement")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Single pass over daily_classroom_metrics; the LEFT JOIN keeps classrooms without data
+	var rankings []ClassroomRanking
+	err = h.db.Table("classrooms c").
+		Select(`
+			c.id as classroom_id,
+			c.name as classroom_name,
+			AVG(dcm.engagement_score) as avg_engagement,
+			AVG(dcm.participation_rate) as avg_participation,
+			AVG(dcm.avg_class_quiz_score) as avg_score,
+			COUNT(dcm.id) as days_with_data,
+			COALESCE(SUM(dcm.total_quiz_sessions), 0) as quiz_sessions
+		`).
+		Joins("LEFT JOIN daily_classroom_metrics dcm ON dcm.classroom_id = c.id AND dcm.date BETWEEN ? AND ?", dateFrom, dateTo).
+		Where("c.school_id = ?", schoolID).
+		Group("c.id, c.name").
+		Scan(&rankings).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute classroom rankings", "details": err.Error()})
+		return
+	}
+
+	// Classrooms with too little data are ranked last rather than on a noisy average
+	for i := range rankings {
+		guard := h.newSampleGuard()
+		rankings[i].AvgEngagement = guard.average("avg_engagement", rankings[i].AvgEngagement, rankings[i].DaysWithData)
+		rankings[i].AvgParticipation = guard.average("avg_participation", rankings[i].AvgParticipation, rankings[i].DaysWithData)
+		rankings[i].AvgScore = guard.average("avg_score", rankings[i].AvgScore, rankings[i].QuizSessions)
+		rankings[i].InsufficientSample = guard.flagged()
+	}
+
+	rankClassrooms(rankings, sortBy)
+
+	totalBeforeFilter := len(rankings)
+	filtered := []ClassroomRanking{}
+	for _, ranking := range rankings {
+		if bounds.allows("participation", ranking.AvgParticipation) &&
+			bounds.allows("avg_score", ranking.AvgScore) &&
+			bounds.allows("engagement", ranking.AvgEngagement) {
+			filtered = append(filtered, ranking)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"school_id": schoolID,
+		"period":    gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"sort_by":   sortBy,
+		"rankings":  filtered,
+		"meta": gin.H{
+			"total_before_filter": totalBeforeFilter,
+			"returned":            len(filtered),
+			"filters":             bounds,
+		},
+	})
+}
+
+// rankClassrooms sorts rankings by the chosen metric (descending) and assigns ranks.
+// Classrooms without data for the metric are placed last and share the final rank.
+// Ties share a rank, using standard competition ranking (1, 2, 2, 4).
+func rankClassrooms(rankings []ClassroomRanking, sortBy string) {
+	for i := range rankings {
+		switch sortBy {
+		case RankByParticipation:
+			rankings[i].MetricValue = rankings[i].AvgParticipation
+		case RankByAvgScore:
+			rankings[i].MetricValue = rankings[i].AvgScore
+		default:
+			rankings[i].MetricValue = rankings[i].AvgEngagement
+		}
+	}
+
+	sort.SliceStable(rankings, func(i, j int) bool {
+		a, b := rankings[i].MetricValue, rankings[j].MetricValue
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if *a != *b {
+			return *a > *b
+		}
+		return rankings[i].ClassroomName < rankings[j].ClassroomName
+	})
+
+	for i := range rankings {
+		switch {
+		case i == 0:
+			rankings[i].Rank = 1
+		case sameMetric(rankings[i].MetricValue, rankings[i-1].MetricValue):
+			rankings[i].Rank = rankings[i-1].Rank
+		default:
+			rankings[i].Rank = i + 1
+		}
+	}
+}
+
+func sameMetric(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestRankClassroomsOrderAndTies(t *testing.T) {
+	rankings := []ClassroomRanking{
+		{ClassroomName: "E", AvgEngagement: nil},
+		{ClassroomName: "C", AvgEngagement: ptr(70)},
+		{ClassroomName: "B", AvgEngagement: ptr(85)},
+		{ClassroomName: "A", AvgEngagement: ptr(70)},
+		{ClassroomName: "F", AvgEngagement: nil},
+		{ClassroomName: "D", AvgEngagement: ptr(60)},
+	}
+	rankClassrooms(rankings, RankByEngagement)
+
+	want := []struct {
+		name string
+		rank int
+	}{
+		{"B", 1},
+		{"A", 2}, // ties share a rank and are ordered by name
+		{"C", 2},
+		{"D", 4}, // competition ranking skips the tied place
+		{"E", 5}, // classrooms without data come last and share the final rank
+		{"F", 5},
+	}
+	for i, w := range want {
+		if rankings[i].ClassroomName != w.name || rankings[i].Rank != w.rank {
+			t.Errorf("position %d = %s ranked %d, want %s ranked %d",
+				i, rankings[i].ClassroomName, rankings[i].Rank, w.name, w.rank)
+		}
+	}
+}
+
+func TestRankClassroomsBySortMetric(t *testing.T) {
+	rankings := []ClassroomRanking{
+		{ClassroomName: "A", AvgEngagement: ptr(90), AvgParticipation: ptr(40), AvgScore: ptr(75)},
+		{ClassroomName: "B", AvgEngagement: ptr(50), AvgParticipation: ptr(95), AvgScore: ptr(80)},
+	}
+	for sortBy, first := range map[string]string{RankByEngagement: "A", RankByParticipation: "B", RankByAvgScore: "B"} {
+		ranked := append([]ClassroomRanking(nil), rankings...)
+		rankClassrooms(ranked, sortBy)
+		if ranked[0].ClassroomName != first {
+			t.Errorf("sort_by=%s ranks %s first, want %s", sortBy, ranked[0].ClassroomName, first)
+		}
+		if ranked[0].MetricValue == nil {
+			t.Errorf("sort_by=%s left metric_value unset", sortBy)
+		}
+	}
+}
+
+func TestGetClassroomRankings(t *testing.T) {
+	schoolID := uuid.NewString()
+	columns := []string{"classroom_id", "classroom_name", "avg_engagement", "avg_participation", "avg_score", "days_with_data", "quiz_sessions"}
+
+	t.Run("filters keep whole-school ranks", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows([]string{"FROM classrooms c", "daily_classroom_metrics dcm"}, columns,
+			[]driver.Value{uuid.NewString(), "Room 1", 90.0, 80.0, 70.0, int64(10), int64(10)},
+			[]driver.Value{uuid.NewString(), "Room 2", 60.0, 50.0, 65.0, int64(10), int64(10)},
+			// Two days of data is below the minimum sample, so the averages are withheld
+			[]driver.Value{uuid.NewString(), "Room 3", 99.0, 99.0, 99.0, int64(2), int64(2)})
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetClassroomRankings, "/schools/:id/classroom-rankings", http.MethodGet,
+			"/schools/"+schoolID+"/classroom-rankings?max_engagement=70", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+
+		rankings := body["rankings"].([]interface{})
+		if len(rankings) != 1 {
+			t.Fatalf("rankings = %v, want only Room 2", rankings)
+		}
+		room := rankings[0].(map[string]interface{})
+		if room["classroom_name"] != "Room 2" || room["rank"] != float64(2) {
+			t.Errorf("ranking = %v, want Room 2 ranked 2", room)
+		}
+		if meta := body["meta"].(map[string]interface{}); meta["total_before_filter"] != float64(3) {
+			t.Errorf("meta = %v", meta)
+		}
+	})
+
+	t.Run("unknown sort", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		w := testRequest(NewReportingHandler(db).GetClassroomRankings, "/schools/:id/classroom-rankings", http.MethodGet,
+			"/schools/"+schoolID+"/classroom-rankings?sort_by=name", "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+}
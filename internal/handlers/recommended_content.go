@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultRecommendationLimit is how many items are suggested when no limit is given
+	defaultRecommendationLimit = 10
+	// maxRecommendationLimit caps the number of suggestions
+	maxRecommendationLimit = 50
+)
+
+// RecommendedContent is content a student has not viewed yet, with the
+// metrics it is ranked by
+type RecommendedContent struct {
+	ContentID          uuid.UUID `json:"content_id"`
+	Title              *string   `json:"title"`
+	ContentType        string    `json:"content_type"`
+	ClassroomID        uuid.UUID `json:"classroom_id"`
+	ClassroomName      string    `json:"classroom_name"`
+	EffectivenessScore float64   `json:"effectiveness_score"`
+	UniqueViewers      int       `json:"unique_viewers"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// GetStudentRecommendedContent suggests the most effective content in the
+// student's active classrooms (or just ?classroom_id=) that the student has
+// no content_viewed event for. The student's own content and content without
+// an effectiveness score are left out. Students may only see their own
+// recommendations.
+func (h *ReportingHandler) GetStudentRecommendedContent(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's recommendations") {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRecommendationLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	limit = min(limit, maxRecommendationLimit)
+
+	query := h.db.Table("content c").
+		Select(`
+			c.id as content_id, c.title, c.content_type, c.classroom_id, cl.name as classroom_name,
+			cm.effectiveness_score, cm.unique_viewers, c.created_at
+		`).
+		Joins("JOIN content_metrics cm ON cm.content_id = c.id").
+		Joins("JOIN user_classrooms uc ON uc.classroom_id = c.classroom_id AND uc.user_id = ? AND uc.is_active = true", studentID).
+		Joins("JOIN classrooms cl ON cl.id = c.classroom_id").
+		Where("c.deleted_at IS NULL AND c.creator_id <> ? AND cm.effectiveness_score IS NOT NULL", studentID).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM events e
+			WHERE e.event_type = 'content_viewed' AND e.user_id = ? AND e.metadata->>'content_id' = c.id::text
+		)`, studentID)
+
+	var classroomID *uuid.UUID
+	if raw := c.Query("classroom_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid classroom_id format"})
+			return
+		}
+		classroomID = &id
+		query = query.Where("c.classroom_id = ?", id)
+	}
+
+	recommendations := []RecommendedContent{}
+	err = query.Order("cm.effectiveness_score DESC, cm.unique_viewers DESC, c.created_at DESC").
+		Limit(limit).
+		Scan(&recommendations).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recommended content", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":      studentID,
+		"classroom_id":    classroomID,
+		"limit":           limit,
+		"recommendations": recommendations,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStudentRecommendedContent(t *testing.T) {
+	fake, db := newFakeDB(t)
+	created := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	fake.rows([]string{"FROM content c", "JOIN content_metrics cm"},
+		[]string{"content_id", "title", "content_type", "classroom_id", "classroom_name", "effectiveness_score", "unique_viewers", "created_at"},
+		[]driver.Value{uuid.NewString(), "Fractions", "notebook", uuid.NewString(), "Maths", 0.92, int64(18), created},
+		[]driver.Value{uuid.NewString(), nil, "whiteboard", uuid.NewString(), "Maths", 0.8, int64(25), created})
+	h := NewReportingHandler(db)
+	studentID, classroomID := uuid.New(), uuid.New()
+
+	target := "/students/" + studentID.String() + "/recommended-content?classroom_id=" + classroomID.String() + "&limit=500"
+	w := testRequest(h.GetStudentRecommendedContent, "/students/:id/recommended-content", http.MethodGet, target, "",
+		map[string]interface{}{"user_id": studentID, "user_role": "student"})
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	recommendations := body["recommendations"].([]interface{})
+	if body["limit"] != float64(maxRecommendationLimit) || len(recommendations) != 2 {
+		t.Fatalf("body = %v, want two recommendations with the limit capped", body)
+	}
+	if first := recommendations[0].(map[string]interface{}); first["title"] != "Fractions" || first["effectiveness_score"] != 0.92 {
+		t.Errorf("first recommendation = %v", first)
+	}
+
+	// The student's own content and content they've viewed are excluded
+	ran := fake.ran("FROM content c")
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{"c.creator_id <> $", "NOT EXISTS", "'content_viewed'", "c.classroom_id = $",
+		"ORDER BY cm.effectiveness_score DESC", "LIMIT 50"}) {
+		t.Fatalf("recommendation statements = %v", ran)
+	}
+	if !argsContain(ran[0].Args, studentID) || !argsContain(ran[0].Args, classroomID) {
+		t.Errorf("args = %v, want the student and classroom bound", ran[0].Args)
+	}
+}
+
+func TestStudentRecommendedContentRejects(t *testing.T) {
+	studentID := uuid.New()
+	tests := []struct {
+		name   string
+		query  string
+		values map[string]interface{}
+		want   int
+	}{
+		{"another student", "", map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}, http.StatusForbidden},
+		{"bad limit", "?limit=0", nil, http.StatusBadRequest},
+		{"bad classroom", "?classroom_id=nope", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			target := "/students/" + studentID.String() + "/recommended-content" + tt.query
+			w := testRequest(NewReportingHandler(db).GetStudentRecommendedContent, "/students/:id/recommended-content", http.MethodGet, target, "", tt.values)
+			expectStatus(t, w, tt.want)
+			if len(fake.ran()) != 0 {
+				t.Error("a rejected request reached the database")
+			}
+		})
+	}
+}
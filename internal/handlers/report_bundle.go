@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportFormatZip selects a ZIP bundle of per-section CSVs instead of JSON
+const ReportFormatZip = "zip"
+
+// bundleSection is one report section written as <Name>.csv. Rows may be a
+// single object or a slice of objects; columns follow their JSON field names.
+type bundleSection struct {
+	Name string
+	Rows interface{}
+}
+
+// bundleFile describes one CSV in the bundle manifest
+type bundleFile struct {
+	Name    string   `json:"name"`
+	Section string   `json:"section"`
+	Columns []string `json:"columns"`
+	Rows    int      `json:"rows"`
+}
+
+// bundleManifest is written to manifest.json at the root of the bundle
+type bundleManifest struct {
+	Report      string                 `json:"report"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Period      gin.H                  `json:"period"`
+	Files       []bundleFile           `json:"files"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+// wantsReportBundle reports whether the request asked for format=zip
+func wantsReportBundle(c *gin.Context) bool {
+	return c.Query("format") == ReportFormatZip
+}
+
+// writeReportBundle streams the sections as <report>_<from>_<to>.zip holding
+// manifest.json plus one CSV per section. Report-level values that aren't
+// tabular, such as flags or recommendations, travel in the manifest's extra field.
+func writeReportBundle(c *gin.Context, report string, dateFrom, dateTo time.Time, sections []bundleSection, extra map[string]interface{}) {
+	manifest := bundleManifest{
+		Report:      report,
+		GeneratedAt: time.Now().UTC(),
+		Period:      gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		Extra:       extra,
+	}
+
+	// Render every CSV up front so a failure can still be reported as JSON
+	contents := make([][]byte, len(sections))
+	for i, section := range sections {
+		columns, rows, err := sectionRecords(section.Rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report bundle", "details": err.Error()})
+			return
+		}
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write(columns)
+		w.WriteAll(rows)
+		if err := w.Error(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report bundle", "details": err.Error()})
+			return
+		}
+
+		contents[i] = buf.Bytes()
+		manifest.Files = append(manifest.Files, bundleFile{
+			Name:    section.Name + ".csv",
+			Section: section.Name,
+			Columns: columns,
+			Rows:    len(rows),
+		})
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.zip", report, dateFrom.Format(DateFormat), dateTo.Format(DateFormat))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	manifestFile, err := zw.Create("manifest.json")
+	if err == nil {
+		enc := json.NewEncoder(manifestFile)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(manifest)
+	}
+	for i := 0; err == nil && i < len(sections); i++ {
+		var f io.Writer
+		if f, err = zw.Create(manifest.Files[i].Name); err == nil {
+			_, err = f.Write(contents[i])
+		}
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		// Headers are already sent; all we can do is log and cut the stream short
+		log.Printf("Failed to stream report bundle %s: %v", filename, err)
+	}
+}
+
+// sectionRecords flattens rows into a header and CSV records. Columns are
+// taken in JSON field order, in the order they are first seen.
+func sectionRecords(rows interface{}) ([]string, [][]string, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return []string{}, nil, nil
+	}
+	if len(data) > 0 && data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // [
+		return nil, nil, err
+	}
+
+	var columns []string
+	index := map[string]int{}
+	var objects []map[string]string
+	for dec.More() {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return nil, nil, fmt.Errorf("section rows must be objects")
+		}
+		object := map[string]string{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key := tok.(string)
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, nil, err
+			}
+			if _, seen := index[key]; !seen {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+			object[key] = csvCell(raw)
+		}
+		if _, err := dec.Token(); err != nil { // }
+			return nil, nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	records := make([][]string, len(objects))
+	for i, object := range objects {
+		record := make([]string, len(columns))
+		for key, value := range object {
+			record[index[key]] = value
+		}
+		records[i] = record
+	}
+	return columns, records, nil
+}
+
+// csvCell renders a JSON value as cell text: null is empty, strings are
+// unquoted and lists of scalars are joined with ";"
+func csvCell(raw json.RawMessage) string {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return string(raw)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			b, _ := json.Marshal(item)
+			parts = append(parts, csvCell(b))
+		}
+		return strings.Join(parts, ";")
+	default:
+		return string(raw)
+	}
+}
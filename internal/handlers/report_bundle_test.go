@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClassroomEngagementBundle(t *testing.T) {
+	fake, db := newFakeDB(t)
+	classroomID := uuid.New()
+	fake.rows([]string{"STDDEV_SAMP(participation_rate)"},
+		[]string{"active_participation_rate", "avg_session_duration", "total_quiz_sessions", "participation_samples"},
+		[]driver.Value{72.5, 18.0, int64(12), int64(5)})
+	fake.rows([]string{"FROM users u", "JOIN user_classrooms uc"},
+		[]string{"id", "first_name", "last_name", "avg_quiz_score", "avg_daily_minutes", "active_days", "quiz_completions"},
+		[]driver.Value{uuid.New().String(), "Ada", "Lovelace", 91.0, 30.0, int64(5), int64(4)},
+		[]driver.Value{uuid.New().String(), "Grace", "Hopper", 84.0, 25.0, int64(4), int64(3)},
+		[]driver.Value{uuid.New().String(), "Alan", nil, nil, nil, int64(0), int64(0)})
+	fake.rows([]string{"active_students_count"},
+		[]string{"date", "active_students_count", "participation_rate", "avg_session_duration_minutes", "engagement_score"},
+		[]driver.Value{time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), int64(3), 75.0, 20.0, 61.5},
+		[]driver.Value{time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), int64(2), 70.0, 16.0, 55.0})
+	h := NewReportingHandler(db)
+
+	target := "/reports/classroom-engagement?classroom_id=" + classroomID.String() + "&date_from=2024-03-01&date_to=2024-03-07&format=zip"
+	w := testRequest(h.GetClassroomEngagementReport, "/reports/classroom-engagement", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", got)
+	}
+	if got, want := w.Header().Get("Content-Disposition"), `attachment; filename="classroom-engagement_2024-03-01_2024-03-07.zip"`; got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+
+	files, names := readBundle(t, w)
+	if want := []string{"manifest.json", "metrics.csv", "student_breakdown.csv", "timeline.csv"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("bundle holds %v, want %v", names, want)
+	}
+
+	var manifest bundleManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.Report != "classroom-engagement" || manifest.Period["from"] != "2024-03-01" || manifest.Period["to"] != "2024-03-07" {
+		t.Errorf("manifest = %+v, want the report and its period", manifest)
+	}
+	if manifest.Extra["classroom_id"] != classroomID.String() {
+		t.Errorf("manifest extra = %v, want the classroom id", manifest.Extra)
+	}
+
+	checkBundleRows(t, files, manifest, map[string]int{"metrics.csv": 1, "student_breakdown.csv": 3, "timeline.csv": 2})
+
+	breakdown, _ := csv.NewReader(bytes.NewReader(files["student_breakdown.csv"])).ReadAll()
+	if want := []string{"id", "first_name", "last_name", "avg_quiz_score", "avg_daily_minutes", "active_days", "insufficient_sample"}; !reflect.DeepEqual(breakdown[0], want) {
+		t.Errorf("student_breakdown header = %v, want %v", breakdown[0], want)
+	}
+	if last := breakdown[3]; last[1] != "Alan" || last[2] != "" || last[3] != "" {
+		t.Errorf("student without data = %v, want empty cells for nulls", last)
+	}
+}
+
+func TestContentEffectivenessBundle(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM content c", `GROUP BY "c"."content_type"`}, []string{"content_type", "total_content", "avg_views", "metric_samples"},
+		[]driver.Value{"video", int64(4), 12.5, int64(4)},
+		[]driver.Value{"worksheet", int64(2), 3.0, int64(2)})
+	fake.rows([]string{"FROM content c", "LIMIT 10"}, []string{"title", "content_type", "view_count", "effectiveness_score", "created_at"},
+		[]driver.Value{"Fractions", "video", int64(30), 88.0, time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)},
+		[]driver.Value{"Decimals", "video", int64(21), 75.0, time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)},
+		[]driver.Value{"Ratios", "worksheet", int64(9), nil, time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)})
+	h := NewReportingHandler(db)
+
+	target := "/reports/content-effectiveness?date_from=2024-03-01&date_to=2024-03-07&format=zip"
+	w := testRequest(h.GetContentEffectivenessReport, "/reports/content-effectiveness", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+
+	files, names := readBundle(t, w)
+	if want := []string{"manifest.json", "content_type_breakdown.csv", "most_engaging_content.csv"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("bundle holds %v, want %v", names, want)
+	}
+	var manifest bundleManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	checkBundleRows(t, files, manifest, map[string]int{"content_type_breakdown.csv": 2, "most_engaging_content.csv": 3})
+}
+
+// readBundle unzips a report bundle response, returning each file's contents
+// and the file names in archive order
+func readBundle(t *testing.T, w *httptest.ResponseRecorder) (map[string][]byte, []string) {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	files := map[string][]byte{}
+	var names []string
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = data
+		names = append(names, f.Name)
+	}
+	return files, names
+}
+
+// checkBundleRows checks that every CSV in the manifest has the wanted number
+// of data rows, agreeing with the manifest's row count and columns
+func checkBundleRows(t *testing.T, files map[string][]byte, manifest bundleManifest, want map[string]int) {
+	t.Helper()
+	if len(manifest.Files) != len(want) {
+		t.Errorf("manifest lists %d files, want %d", len(manifest.Files), len(want))
+	}
+	for _, file := range manifest.Files {
+		records, err := csv.NewReader(bytes.NewReader(files[file.Name])).ReadAll()
+		if err != nil {
+			t.Fatalf("parse %s: %v", file.Name, err)
+		}
+		if len(records)-1 != want[file.Name] || file.Rows != want[file.Name] {
+			t.Errorf("%s has %d rows (manifest says %d), want %d", file.Name, len(records)-1, file.Rows, want[file.Name])
+		}
+		if !reflect.DeepEqual(records[0], file.Columns) {
+			t.Errorf("%s header = %v, manifest columns %v", file.Name, records[0], file.Columns)
+		}
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	tests := map[string]string{
+		`null`:                   "",
+		`"Algebra"`:              "Algebra",
+		`12.50`:                  "12.50",
+		`true`:                   "true",
+		`["a", 2, null]`:         "a;2;",
+		`{"nested": true}`:       `{"nested": true}`,
+		`"2024-03-04T00:00:00Z"`: "2024-03-04T00:00:00Z",
+	}
+	for raw, want := range tests {
+		if got := csvCell(json.RawMessage(raw)); got != want {
+			t.Errorf("csvCell(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultReportCacheMaxAge is how long clients may reuse a report before revalidating
+const DefaultReportCacheMaxAge = 60 * time.Second
+
+// reportSource is a table a report reads, with the expression giving when
+// each of its rows last changed
+type reportSource struct {
+	table     string
+	changedAt string
+}
+
+var (
+	dailyUserMetricsSource      = reportSource{"daily_user_metrics", "updated_at"}
+	dailyClassroomMetricsSource = reportSource{"daily_classroom_metrics", "updated_at"}
+	weeklySchoolMetricsSource   = reportSource{"weekly_school_metrics", "updated_at"}
+	contentMetricsSource        = reportSource{"content_metrics", "updated_at"}
+	contentSource               = reportSource{"content", "updated_at"}
+	quizzesSource               = reportSource{"quizzes", "updated_at"}
+	classroomsSource            = reportSource{"classrooms", "updated_at"}
+	usersSource                 = reportSource{"users", "updated_at"}
+	// Raw tables without updated_at change when rows are added or completed
+	quizSessionsSource = reportSource{"quiz_sessions", "GREATEST(started_at, completed_at)"}
+	enrollmentsSource  = reportSource{"user_classrooms", "enrolled_at"}
+)
+
+// reportSourceTables lists the aggregate and raw tables each report reads
+// from, including those only read for include_details and confidence
+// intervals. Their latest change determines the report's Last-Modified time.
+var reportSourceTables = map[string][]reportSource{
+	"student-performance":           {dailyUserMetricsSource, quizSessionsSource, quizzesSource, enrollmentsSource},
+	"classroom-engagement":          {dailyClassroomMetricsSource, dailyUserMetricsSource, quizSessionsSource, quizzesSource, enrollmentsSource, usersSource},
+	"content-effectiveness":         {contentMetricsSource, contentSource, classroomsSource},
+	"school-overview":               {weeklySchoolMetricsSource, dailyClassroomMetricsSource},
+	"creator-content-effectiveness": {contentMetricsSource, contentSource, usersSource},
+	"subject-breakdown":             {dailyClassroomMetricsSource, classroomsSource, quizzesSource},
+}
+
+// SetReportCacheMaxAge configures the Cache-Control max-age sent with reports
+func (h *ReportingHandler) SetReportCacheMaxAge(maxAge time.Duration) {
+	h.reportCacheMaxAge = maxAge
+}
+
+// reportFreshness adds Cache-Control, Last-Modified and ETag headers to report
+// responses and answers conditional requests with 304 when nothing has changed
+func (h *ReportingHandler) reportFreshness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sources, ok := reportSourceTables[path.Base(c.FullPath())]
+		if !ok || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		lastModified, err := h.latestAggregateUpdate(sources)
+		if err != nil {
+			// Freshness is best effort; serve the report without validators
+			c.Next()
+			return
+		}
+
+		etag := reportETag(c, lastModified)
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d, must-revalidate", int(h.reportCacheMaxAge.Seconds())))
+		c.Header("ETag", etag)
+		c.Header("Vary", "Authorization")
+		if !lastModified.IsZero() {
+			c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+
+		if notModified(c.Request, etag, lastModified) {
+			c.AbortWithStatus(http.StatusNotModified)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// latestAggregateUpdate returns the most recent change across the given
+// tables, or the last metrics refresh if that is newer
+func (h *ReportingHandler) latestAggregateUpdate(sources []reportSource) (time.Time, error) {
+	parts := make([]string, len(sources))
+	for i, source := range sources {
+		parts[i] = fmt.Sprintf("SELECT MAX(%s) AS updated_at FROM %s", source.changedAt, source.table)
+	}
+	query := fmt.Sprintf("SELECT MAX(updated_at) FROM (%s) latest", strings.Join(parts, " UNION ALL "))
+
+	var updatedAt sql.NullTime
+	if err := h.db.Raw(query).Row().Scan(&updatedAt); err != nil {
+		return time.Time{}, err
+	}
+
+	latest := updatedAt.Time
+	if refreshed := h.metricsRefreshedAt.Load(); refreshed > 0 {
+		if t := time.Unix(0, refreshed); t.After(latest) {
+			latest = t
+		}
+	}
+	// HTTP dates have second precision
+	return latest.Truncate(time.Second), nil
+}
+
+// reportETag derives a weak ETag from the report path, its query parameters and
+// the data's last modification time, so any filter or data change yields a new tag
+func reportETag(c *gin.Context, lastModified time.Time) string {
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha1.New()
+	hash.Write([]byte(c.Request.URL.Path))
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		fmt.Fprintf(hash, "|%s=%s", k, strings.Join(values, ","))
+	}
+	fmt.Fprintf(hash, "|%d", lastModified.Unix())
+	// Responses may be redacted per viewer, so validators must not be shared across them
+	viewer := requestViewer(c)
+	if viewer.Role != "" {
+		fmt.Fprintf(hash, "|%s", viewer.Role)
+	}
+	if viewer.UserID != nil {
+		fmt.Fprintf(hash, "|%s", viewer.UserID)
+	}
+
+	return `W/"` + hex.EncodeToString(hash.Sum(nil))[:16] + `"`
+}
+
+// notModified reports whether the request's validators match the current report.
+// If-None-Match takes precedence over If-Modified-Since, as in RFC 9110.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !lastModified.After(since)
+	}
+	return false
+}
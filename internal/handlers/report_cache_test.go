@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var latestUpdate = []string{"SELECT MAX(updated_at) FROM ("}
+
+// freshnessRouter serves a stand-in student performance report behind
+// reportFreshness, counting how often the report itself runs
+func freshnessRouter(h *ReportingHandler, served *int) *gin.Engine {
+	router := gin.New()
+	router.GET("/reports/student-performance", h.reportFreshness(), func(c *gin.Context) {
+		*served++
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router
+}
+
+func getReport(router *gin.Engine, header, value string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/reports/student-performance?student_id=s1", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestReportFreshnessNotModified(t *testing.T) {
+	updated := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	fake, db := newFakeDB(t)
+	fake.rows(latestUpdate, []string{"max"}, []driver.Value{updated})
+	h := NewReportingHandler(db)
+	served := 0
+	router := freshnessRouter(h, &served)
+
+	first := getReport(router, "", "")
+	expectStatus(t, first, http.StatusOK)
+	etag := first.Header().Get("ETag")
+	if etag == "" || first.Header().Get("Last-Modified") != updated.Format(http.TimeFormat) {
+		t.Fatalf("validators = %q, %q", etag, first.Header().Get("Last-Modified"))
+	}
+
+	expectStatus(t, getReport(router, "If-None-Match", etag), http.StatusNotModified)
+	expectStatus(t, getReport(router, "If-Modified-Since", updated.Format(http.TimeFormat)), http.StatusNotModified)
+	expectStatus(t, getReport(router, "If-None-Match", `W/"stale"`), http.StatusOK)
+	if served != 2 {
+		t.Errorf("report ran %d times, want 2", served)
+	}
+
+	// The raw tables the report reads count toward its freshness
+	statements := fake.ran(latestUpdate...)
+	if len(statements) == 0 {
+		t.Fatal("no freshness query ran")
+	}
+	for _, table := range []string{"FROM daily_user_metrics", "FROM quiz_sessions", "FROM user_classrooms"} {
+		if !containsAll(statements[0].SQL, []string{table}) {
+			t.Errorf("freshness query does not read %s: %s", table, statements[0].SQL)
+		}
+	}
+}
+
+func TestReportETagChangesAfterRefresh(t *testing.T) {
+	updated := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	fake, db := newFakeDB(t)
+	fake.rows(latestUpdate, []string{"max"}, []driver.Value{updated}).times(2)
+	fake.rows(latestUpdate, []string{"max"}, []driver.Value{updated.Add(time.Minute)})
+	h := NewReportingHandler(db)
+	served := 0
+	router := freshnessRouter(h, &served)
+
+	etag := getReport(router, "", "").Header().Get("ETag")
+
+	// A metrics refresh after the data last changed invalidates the tag
+	h.metricsRefreshedAt.Store(updated.Add(30 * time.Second).UnixNano())
+	refreshed := getReport(router, "If-None-Match", etag)
+	expectStatus(t, refreshed, http.StatusOK)
+	if refreshed.Header().Get("ETag") == etag {
+		t.Error("ETag unchanged after a metrics refresh")
+	}
+
+	// So does a later change to a source table
+	changed := getReport(router, "If-None-Match", refreshed.Header().Get("ETag"))
+	expectStatus(t, changed, http.StatusOK)
+	if changed.Header().Get("ETag") == refreshed.Header().Get("ETag") {
+		t.Error("ETag unchanged after a source table changed")
+	}
+}
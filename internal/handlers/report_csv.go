@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportFormatCSV selects a single flat CSV instead of JSON
+const ReportFormatCSV = "csv"
+
+// CSV column orders per report. Summary columns come first and are repeated
+// on every detail row; a report without detail rows is a single summary row.
+// Columns are JSON field names of the report's sections. Append new columns
+// at the end so spreadsheets built on these exports keep working.
+var (
+	// Detail rows are quiz_performance, one per completed quiz
+	studentPerformanceCSVSummary = []string{
+		"student_id", "period_from", "period_to",
+		"avg_quiz_score", "total_quiz_attempts", "total_quiz_completions",
+		"avg_daily_minutes", "total_events", "active_days", "engagement_score",
+	}
+	studentPerformanceCSVDetail = []string{"title", "percentage_score", "completed_at", "time_spent_seconds"}
+
+	// Detail rows are student_breakdown, one per student
+	classroomEngagementCSVSummary = []string{
+		"classroom_id", "period_from", "period_to",
+		"active_participation_rate", "avg_session_duration", "collaboration_events",
+		"content_sharing_frequency", "total_quiz_sessions", "avg_quiz_completion_rate",
+		"avg_class_quiz_score",
+	}
+	classroomEngagementCSVDetail = []string{
+		"id", "first_name", "last_name", "avg_quiz_score", "avg_daily_minutes",
+		"active_days", "insufficient_sample",
+	}
+
+	// Detail rows are content_type_breakdown, one per content type. The
+	// most_engaging_content list is only exported in the format=zip bundle.
+	contentEffectivenessCSVSummary = []string{"period_from", "period_to", "school_id", "classroom_id", "content_type_filter"}
+	contentEffectivenessCSVDetail  = []string{
+		"content_type", "total_content", "avg_views", "avg_unique_viewers",
+		"avg_view_duration", "avg_effectiveness_score", "insufficient_sample",
+	}
+)
+
+// wantsReportCSV reports whether the request asked for format=csv
+func wantsReportCSV(c *gin.Context) bool {
+	return c.Query("format") == ReportFormatCSV
+}
+
+// wantsReportExport reports whether the request asked for any file export
+func wantsReportExport(c *gin.Context) bool {
+	return wantsReportBundle(c) || wantsReportCSV(c) || wantsReportPDF(c)
+}
+
+// writeReportCSV writes <report>_<from>_<to>.csv with the summary columns
+// followed by the detail columns. summary is an object and details a slice
+// of objects; cells are rendered as in report bundles.
+func writeReportCSV(c *gin.Context, report string, dateFrom, dateTo time.Time, summaryColumns []string, summary interface{}, detailColumns []string, details interface{}) {
+	summaryFields, err := csvFields(summary)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build CSV export", "details": err.Error()})
+		return
+	}
+	var detailRows []map[string]json.RawMessage
+	if data, err := json.Marshal(details); err == nil {
+		err = json.Unmarshal(data, &detailRows)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build CSV export", "details": err.Error()})
+		return
+	}
+
+	summaryRecord := make([]string, len(summaryColumns))
+	for i, column := range summaryColumns {
+		summaryRecord[i] = csvCell(summaryFields[column])
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(append(append([]string{}, summaryColumns...), detailColumns...))
+	if len(detailRows) == 0 {
+		w.Write(append(summaryRecord, make([]string, len(detailColumns))...))
+	}
+	for _, row := range detailRows {
+		record := append([]string{}, summaryRecord...)
+		for _, column := range detailColumns {
+			record = append(record, csvCell(row[column]))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build CSV export", "details": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.csv", report, dateFrom.Format(DateFormat), dateTo.Format(DateFormat))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvFields decodes an object into its raw JSON fields
+func csvFields(v interface{}) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
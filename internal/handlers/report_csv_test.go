@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStudentPerformanceDetailRows(t *testing.T) {
+	fake, db := newFakeDB(t)
+	studentID := uuid.New()
+	completed := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	fake.rows([]string{"q.title", "JOIN quizzes q"},
+		[]string{"title", "percentage_score", "completed_at", "time_spent_seconds"},
+		[]driver.Value{"Fractions", 90.0, completed, int64(300)},
+		[]driver.Value{"Decimals", 75.5, completed.Add(24 * time.Hour), int64(420)})
+	fake.rows([]string{`FROM "daily_user_metrics"`, "daily_minutes"},
+		[]string{"date", "avg_quiz_score", "daily_minutes", "events_count"},
+		[]driver.Value{time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), 90.0, int64(25), int64(14)})
+	h := NewReportingHandler(db)
+	target := "/reports/student-performance?student_id=" + studentID.String() + "&date_from=2024-03-01&date_to=2024-03-07"
+
+	t.Run("csv", func(t *testing.T) {
+		w := testRequest(h.GetStudentPerformanceReport, "/reports/student-performance", http.MethodGet, target+"&format=csv", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(w.Body.String(), "\ufeff"))).ReadAll()
+		if err != nil {
+			t.Fatalf("read CSV: %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("CSV has %d records, want a header and 2 quiz rows: %v", len(records), records)
+		}
+		header := records[0]
+		title := slices.Index(header, "title")
+		if title < 0 || records[1][title] != "Fractions" || records[2][title] != "Decimals" {
+			t.Errorf("title column = %v, want the quiz titles", records)
+		}
+		if score := slices.Index(header, "percentage_score"); records[2][score] != "75.5" {
+			t.Errorf("percentage_score = %q, want 75.5", records[2][score])
+		}
+		if id := slices.Index(header, "student_id"); records[1][id] != studentID.String() {
+			t.Errorf("student_id = %q, want it repeated on each row", records[1][id])
+		}
+	})
+
+	t.Run("include_details", func(t *testing.T) {
+		w := testRequest(h.GetStudentPerformanceReport, "/reports/student-performance", http.MethodGet, target+"&include_details=true", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		quizzes, _ := body["quiz_performance"].([]interface{})
+		if len(quizzes) != 2 || quizzes[0].(map[string]interface{})["title"] != "Fractions" {
+			t.Errorf("quiz_performance = %v, want both quizzes", body["quiz_performance"])
+		}
+		progression, _ := body["learning_progression"].([]interface{})
+		if len(progression) != 1 || progression[0].(map[string]interface{})["events_count"] != 14.0 {
+			t.Errorf("learning_progression = %v, want the daily row", body["learning_progression"])
+		}
+	})
+}
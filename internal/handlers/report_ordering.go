@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reportSection lists the fields a report list section can be ordered by,
+// mapped to their SQL expressions, and the tiebreaker that keeps output stable
+type reportSection struct {
+	columns  map[string]string
+	tiebreak string
+}
+
+var reportSections = map[string]reportSection{
+	"quiz_performance": {
+		columns: map[string]string{
+			"completed_at":       "qs.completed_at",
+			"percentage_score":   "qs.percentage_score",
+			"time_spent_seconds": "qs.time_spent_seconds",
+			"title":              "q.title",
+		},
+		tiebreak: "qs.id",
+	},
+	"student_breakdown": {
+		columns: map[string]string{
+			"last_name":         "u.last_name",
+			"first_name":        "u.first_name",
+			"avg_quiz_score":    "avg_quiz_score",
+			"avg_daily_minutes": "avg_daily_minutes",
+			"active_days":       "active_days",
+		},
+		tiebreak: "u.last_name, u.first_name, u.id",
+	},
+	"content_type_breakdown": {
+		columns: map[string]string{
+			"content_type":            "c.content_type",
+			"total_content":           "total_content",
+			"avg_views":               "avg_views",
+			"avg_effectiveness_score": "avg_effectiveness_score",
+		},
+		tiebreak: "c.content_type",
+	},
+	"most_engaging_content": {
+		columns: map[string]string{
+			"effectiveness_score": "cm.effectiveness_score",
+			"view_count":          "cm.view_count",
+			"created_at":          "c.created_at",
+			"title":               "c.title",
+		},
+		tiebreak: "c.id",
+	},
+}
+
+// DefaultSectionOrders is the ordering of each report list section when the
+// request has no order_by. Date series (timelines, learning progression) are
+// always oldest first and classroom rankings follow their sort_by.
+var DefaultSectionOrders = map[string]string{
+	"quiz_performance":       "completed_at:desc",
+	"student_breakdown":      "last_name:asc",
+	"content_type_breakdown": "content_type:asc",
+	"most_engaging_content":  "effectiveness_score:desc",
+}
+
+// SetDefaultSectionOrder overrides the default ordering of a report list
+// section, given as field[:asc|desc]
+func (h *ReportingHandler) SetDefaultSectionOrder(section, orderBy string) error {
+	if _, err := sectionOrderClause(section, orderBy); err != nil {
+		return err
+	}
+	if h.sectionOrders == nil {
+		h.sectionOrders = map[string]string{}
+	}
+	h.sectionOrders[section] = orderBy
+	return nil
+}
+
+// sectionOrder returns the ORDER BY clause for a section, using orderBy when
+// the client supplied one and the configured default otherwise
+func (h *ReportingHandler) sectionOrder(section, orderBy string) (string, error) {
+	if orderBy == "" {
+		orderBy = h.sectionOrders[section]
+		if orderBy == "" {
+			orderBy = DefaultSectionOrders[section]
+		}
+	}
+	return sectionOrderClause(section, orderBy)
+}
+
+// sectionOrderClause validates field[:asc|desc] against the section's
+// orderable fields. Nulls sort last in either direction.
+func sectionOrderClause(section, orderBy string) (string, error) {
+	spec, ok := reportSections[section]
+	if !ok {
+		return "", fmt.Errorf("unknown report section %q", section)
+	}
+
+	field, direction, _ := strings.Cut(strings.TrimSpace(orderBy), ":")
+	column, ok := spec.columns[field]
+	if !ok {
+		fields := make([]string, 0, len(spec.columns))
+		for name := range spec.columns {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+		return "", fmt.Errorf("order_by for %s must be one of %s", section, strings.Join(fields, ", "))
+	}
+
+	switch strings.ToLower(direction) {
+	case "", "asc":
+		direction = "ASC"
+	case "desc":
+		direction = "DESC"
+	default:
+		return "", fmt.Errorf("order_by direction must be asc or desc")
+	}
+
+	return fmt.Sprintf("%s %s NULLS LAST, %s", column, direction, spec.tiebreak), nil
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSectionOrderClause(t *testing.T) {
+	tests := []struct {
+		section, orderBy, want string
+	}{
+		{"quiz_performance", "percentage_score", "qs.percentage_score ASC NULLS LAST, qs.id"},
+		{"quiz_performance", "title:DESC", "q.title DESC NULLS LAST, qs.id"},
+		{"student_breakdown", " active_days:asc ", "active_days ASC NULLS LAST, u.last_name, u.first_name, u.id"},
+	}
+	for _, tt := range tests {
+		if got, err := sectionOrderClause(tt.section, tt.orderBy); err != nil || got != tt.want {
+			t.Errorf("sectionOrderClause(%q, %q) = %q, %v, want %q", tt.section, tt.orderBy, got, err, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ section, orderBy string }{
+		{"timeline_data", "date"},
+		{"quiz_performance", "qs.id"},
+		{"quiz_performance", "title:sideways"},
+		{"quiz_performance", "title; DROP TABLE quizzes"},
+	} {
+		if _, err := sectionOrderClause(tt.section, tt.orderBy); err == nil {
+			t.Errorf("sectionOrderClause(%q, %q) succeeded", tt.section, tt.orderBy)
+		}
+	}
+}
+
+func TestSectionOrderDefaults(t *testing.T) {
+	h := NewReportingHandler(nil)
+	if got, _ := h.sectionOrder("most_engaging_content", ""); !strings.HasPrefix(got, "cm.effectiveness_score DESC") {
+		t.Errorf("built-in default = %q, want effectiveness_score descending", got)
+	}
+
+	if err := h.SetDefaultSectionOrder("most_engaging_content", "view_count:desc"); err != nil {
+		t.Fatalf("SetDefaultSectionOrder: %v", err)
+	}
+	if got, _ := h.sectionOrder("most_engaging_content", ""); !strings.HasPrefix(got, "cm.view_count DESC") {
+		t.Errorf("configured default = %q, want view_count descending", got)
+	}
+	if got, _ := h.sectionOrder("most_engaging_content", "title"); !strings.HasPrefix(got, "c.title ASC") {
+		t.Errorf("request order = %q, want it to override the configured default", got)
+	}
+	if err := h.SetDefaultSectionOrder("most_engaging_content", "popularity"); err == nil {
+		t.Error("SetDefaultSectionOrder accepted an unknown field")
+	}
+}
+
+func TestClassroomEngagementOrderBy(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	target := "/reports/classroom-engagement?classroom_id=" + uuid.NewString() + "&date_from=2024-03-01&date_to=2024-03-07"
+	run := func(query string) int {
+		w := testRequest(h.GetClassroomEngagementReport, "/reports/classroom-engagement", http.MethodGet, target+query, "", nil)
+		return w.Code
+	}
+	breakdownOrder := func() string {
+		ran := fake.ran("JOIN user_classrooms uc")
+		sql := ran[len(ran)-1].SQL
+		return sql[strings.Index(sql, "ORDER BY"):]
+	}
+
+	if code := run(""); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if got := breakdownOrder(); got != "ORDER BY u.last_name ASC NULLS LAST, u.last_name, u.first_name, u.id" {
+		t.Errorf("default order = %q, want by last name", got)
+	}
+
+	if code := run("&order_by=avg_quiz_score:desc"); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if got := breakdownOrder(); got != "ORDER BY avg_quiz_score DESC NULLS LAST, u.last_name, u.first_name, u.id" {
+		t.Errorf("order_by order = %q, want by quiz score descending", got)
+	}
+
+	if code := run("&order_by=password"); code != http.StatusBadRequest {
+		t.Errorf("unknown order_by status = %d, want 400", code)
+	}
+}
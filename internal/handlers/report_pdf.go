@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"reporting-framework/internal/export"
+	"reporting-framework/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReportFormatPDF selects a printable PDF instead of JSON
+const ReportFormatPDF = "pdf"
+
+// wantsReportPDF reports whether the request asked for format=pdf
+func wantsReportPDF(c *gin.Context) bool {
+	return c.Query("format") == ReportFormatPDF
+}
+
+// writeReportPDF renders the report as <report>_<from>_<to>.pdf
+func writeReportPDF(c *gin.Context, report string, dateFrom, dateTo time.Time, doc export.Report) {
+	// Render up front so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := export.RenderPDF(&buf, doc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build PDF export", "details": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("%s_%s_%s.pdf", report, dateFrom.Format(DateFormat), dateTo.Format(DateFormat))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, export.PDFContentType, buf.Bytes())
+}
+
+// classroomReportHeader names the classroom, its teacher and school for
+// printed reports
+type classroomReportHeader struct {
+	ClassroomName string
+	TeacherName   *string
+	SchoolName    string
+}
+
+// classroomHeader looks up the names shown at the top of a printed classroom
+// report; a missing classroom yields empty names
+func (h *ReportingHandler) classroomHeader(classroomID uuid.UUID) classroomReportHeader {
+	var header classroomReportHeader
+	h.db.Table("classrooms c").
+		Select("c.name as classroom_name, NULLIF(TRIM(CONCAT(t.first_name, ' ', t.last_name)), '') as teacher_name, s.name as school_name").
+		Joins("JOIN schools s ON s.id = c.school_id").
+		Joins("LEFT JOIN users t ON t.id = c.teacher_id").
+		Where("c.id = ?", classroomID).
+		Scan(&header)
+	return header
+}
+
+// classroomEngagementInsights states what stands out in the classroom metrics,
+// using the same cutoffs as the classroom report service
+func classroomEngagementInsights(participationRate, classScore *float64, insufficient []string, redacted bool) []string {
+	thresholds := services.DefaultReportThresholds().Classroom
+	var insights []string
+	if participationRate != nil && *participationRate > thresholds.ExcellentParticipationRate {
+		insights = append(insights, "Excellent classroom participation rate indicates high student engagement")
+	}
+	if classScore != nil && *classScore > thresholds.StrongClassScore {
+		insights = append(insights, "Strong academic performance across the classroom")
+	}
+	if len(insufficient) > 0 {
+		insights = append(insights, "Not enough data to report: "+strings.Join(insufficient, ", "))
+	}
+	if redacted {
+		insights = append(insights, "The student breakdown is limited to the viewer's own row")
+	}
+	return insights
+}
+
+// classroomBreakdownTable lays out the student breakdown for printing
+func classroomBreakdownTable(students []StudentBreakdownRow) export.Table {
+	table := export.Table{
+		Title:   "Students",
+		Columns: []string{"Student", "Avg quiz score", "Avg daily minutes", "Active days"},
+		Widths:  []float64{3, 1.5, 1.5, 1},
+		Rows:    make([][]string, 0, len(students)),
+	}
+	for _, student := range students {
+		var name []string
+		for _, part := range []*string{student.FirstName, student.LastName} {
+			if part != nil && *part != "" {
+				name = append(name, *part)
+			}
+		}
+		if len(name) == 0 {
+			name = append(name, "(no name)")
+		}
+		table.Rows = append(table.Rows, []string{
+			strings.Join(name, " "),
+			pdfValue(student.AvgQuizScore, ""),
+			pdfValue(student.AvgDailyMinutes, ""),
+			strconv.Itoa(student.ActiveDays),
+		})
+	}
+	return table
+}
+
+// pdfValue formats an optional metric for print, "n/a" when it is missing
+// or withheld
+func pdfValue(v *float64, unit string) string {
+	if v == nil {
+		return "n/a"
+	}
+	return strconv.FormatFloat(*v, 'f', 1, 64) + unit
+}
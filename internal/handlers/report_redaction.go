@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DefaultRedactedRoles only see aggregate metrics and their own row in student breakdowns
+var DefaultRedactedRoles = []string{"student"}
+
+// StudentBreakdownRow is one student's line in the classroom engagement report
+type StudentBreakdownRow struct {
+	ID                 uuid.UUID `json:"id"`
+	FirstName          *string   `json:"first_name"`
+	LastName           *string   `json:"last_name"`
+	AvgQuizScore       *float64  `json:"avg_quiz_score"`
+	AvgDailyMinutes    *float64  `json:"avg_daily_minutes"`
+	ActiveDays         int       `json:"active_days"`
+	QuizCompletions    int       `json:"-"`
+	InsufficientSample bool      `json:"insufficient_sample"`
+}
+
+// reportViewer is the authenticated requester as set in the context by the auth
+// middleware; both fields are empty for API-key and unauthenticated callers
+type reportViewer struct {
+	Role   string
+	UserID *uuid.UUID
+}
+
+// SetRedactedRoles configures which JWT roles get redacted student breakdowns
+func (h *ReportingHandler) SetRedactedRoles(roles []string) {
+	h.redactedRoles = make(map[string]bool, len(roles))
+	for _, role := range roles {
+		if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+			h.redactedRoles[role] = true
+		}
+	}
+}
+
+// requestViewer reads the requester's role and user id from the gin context
+func requestViewer(c *gin.Context) reportViewer {
+	var viewer reportViewer
+	if role, ok := c.Get("user_role"); ok && role != nil {
+		viewer.Role = strings.ToLower(fmt.Sprint(role))
+	}
+	if userID, ok := c.Get("user_id"); ok {
+		switch v := userID.(type) {
+		case uuid.UUID:
+			viewer.UserID = &v
+		case string:
+			if id, err := uuid.Parse(v); err == nil {
+				viewer.UserID = &id
+			}
+		}
+	}
+	return viewer
+}
+
+// redacts reports whether the viewer's role is limited to their own row.
+// User tokens without a role claim are limited too.
+func (h *ReportingHandler) redacts(viewer reportViewer) bool {
+	if viewer.UserID != nil && viewer.Role == "" {
+		return true
+	}
+	return viewer.Role != "" && h.redactedRoles[viewer.Role]
+}
+
+// adminRole accepts administrators
+func adminRole(role string) bool {
+	return role == "admin"
+}
+
+// seesAllStudents accepts roles whose reports aren't redacted to their own row
+func (h *ReportingHandler) seesAllStudents(role string) bool {
+	return !h.redactedRoles[role]
+}
+
+// authorizeViewer answers 403 with denied and returns false unless the
+// requester may read data belonging to ownerID. API-key requests carry no
+// user and may; a user may read their own data, or anyone's when allowed
+// accepts their role. User tokens without a role claim are always denied.
+func authorizeViewer(c *gin.Context, ownerID uuid.UUID, allowed func(role string) bool, denied string) bool {
+	viewer := requestViewer(c)
+	switch {
+	case viewer.UserID == nil && viewer.Role == "":
+		return true
+	case viewer.Role == "":
+	case viewer.UserID != nil && *viewer.UserID == ownerID:
+		return true
+	case allowed(viewer.Role):
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": denied})
+	return false
+}
+
+// redactStudentBreakdown keeps only the viewer's own row, so classmates' names
+// and scores never leave the server. Viewers without a user id see no rows.
+func redactStudentBreakdown(rows []StudentBreakdownRow, viewer reportViewer) []StudentBreakdownRow {
+	redacted := []StudentBreakdownRow{}
+	if viewer.UserID == nil {
+		return redacted
+	}
+	for _, row := range rows {
+		if row.ID == *viewer.UserID {
+			redacted = append(redacted, row)
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRedacts(t *testing.T) {
+	h := NewReportingHandler(nil)
+	h.SetRedactedRoles([]string{" Student ", "parent", ""})
+	userID := uuid.New()
+
+	tests := []struct {
+		name   string
+		viewer reportViewer
+		want   bool
+	}{
+		{"api key", reportViewer{}, false},
+		{"teacher", reportViewer{Role: "teacher", UserID: &userID}, false},
+		{"student", reportViewer{Role: "student", UserID: &userID}, true},
+		{"parent", reportViewer{Role: "parent", UserID: &userID}, true},
+		{"token without a role", reportViewer{UserID: &userID}, true},
+	}
+	for _, tt := range tests {
+		if got := h.redacts(tt.viewer); got != tt.want {
+			t.Errorf("%s: redacts = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeViewer(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   int
+	}{
+		{"api key", nil, http.StatusOK},
+		{"owner", map[string]interface{}{"user_id": owner, "user_role": "student"}, http.StatusOK},
+		{"owner as a string", map[string]interface{}{"user_id": owner.String(), "user_role": "student"}, http.StatusOK},
+		{"admin", map[string]interface{}{"user_id": other, "user_role": "Admin"}, http.StatusOK},
+		{"another student", map[string]interface{}{"user_id": other, "user_role": "student"}, http.StatusForbidden},
+		{"owner without a role", map[string]interface{}{"user_id": owner}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testRequest(func(c *gin.Context) {
+				if authorizeViewer(c, owner, adminRole, "Not your report") {
+					c.Status(http.StatusOK)
+				}
+			}, "/report", http.MethodGet, "/report", "", tt.values)
+			expectStatus(t, w, tt.want)
+		})
+	}
+}
+
+func TestClassroomEngagementRedactsBreakdown(t *testing.T) {
+	classroomID := uuid.New()
+	self, classmate := uuid.New(), uuid.New()
+	target := "/reports/classroom-engagement?classroom_id=" + classroomID.String()
+
+	run := func(t *testing.T, values map[string]interface{}) map[string]interface{} {
+		fake, db := newFakeDB(t)
+		fake.rows([]string{"FROM users u", "quiz_completions"}, []string{"id", "first_name", "active_days"},
+			[]driver.Value{self.String(), "Ada", int64(4)},
+			[]driver.Value{classmate.String(), "Grace", int64(6)})
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetClassroomEngagementReport, "/reports/classroom-engagement", http.MethodGet, target, "", values)
+		expectStatus(t, w, http.StatusOK)
+		return decodeBody(t, w)
+	}
+
+	t.Run("student sees only their own row", func(t *testing.T) {
+		body := run(t, map[string]interface{}{"user_id": self, "user_role": "student"})
+		rows := body["student_breakdown"].([]interface{})
+		if body["breakdown_redacted"] != true || len(rows) != 1 || rows[0].(map[string]interface{})["id"] != self.String() {
+			t.Errorf("breakdown = %v, redacted %v, want only the student's row", rows, body["breakdown_redacted"])
+		}
+	})
+
+	t.Run("teacher sees every row", func(t *testing.T) {
+		body := run(t, map[string]interface{}{"user_id": uuid.New(), "user_role": "teacher"})
+		if rows := body["student_breakdown"].([]interface{}); body["breakdown_redacted"] != false || len(rows) != 2 {
+			t.Errorf("breakdown = %v, redacted %v, want both rows", rows, body["breakdown_redacted"])
+		}
+	})
+}
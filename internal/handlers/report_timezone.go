@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TimezoneQueryParam overrides the time zone a report's days are counted in
+const TimezoneQueryParam = "tz"
+
+// schoolTimezoneSQL is the time zone of the school joined as sc, falling back
+// to the report location passed as its one argument
+const schoolTimezoneSQL = "COALESCE(NULLIF(sc.timezone, ''), ?)"
+
+// localDateSQL is the local calendar day of a UTC timestamp column in the
+// time zone given by tzSQL
+func localDateSQL(column, tzSQL string) string {
+	return fmt.Sprintf("(%s AT TIME ZONE 'UTC' AT TIME ZONE %s)::date", column, tzSQL)
+}
+
+// requestLocation resolves the time zone a report's days are counted in: the
+// tz query parameter, else the school's time zone, else the report location
+// (UTC unless configured). An unknown tz parameter is an error; an unknown
+// stored school time zone is logged and skipped.
+func (h *ReportingHandler) requestLocation(c *gin.Context, schoolTimezone string) (*time.Location, error) {
+	if tz := c.Query(TimezoneQueryParam); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: unknown time zone %q", TimezoneQueryParam, tz)
+		}
+		return loc, nil
+	}
+	if schoolTimezone != "" {
+		loc, err := time.LoadLocation(schoolTimezone)
+		if err == nil {
+			return loc, nil
+		}
+		log.Printf("Ignoring unknown school time zone %q: %v", schoolTimezone, err)
+	}
+	return h.reportLocation, nil
+}
+
+// schoolTimezone returns the stored time zone of a school, or "" if it has none
+func (h *ReportingHandler) schoolTimezone(schoolID uuid.UUID) string {
+	var tz string
+	h.db.Table("schools").Select("COALESCE(timezone, '')").Where("id = ?", schoolID).Scan(&tz)
+	return tz
+}
+
+// classroomTimezone returns the stored time zone of a classroom's school
+func (h *ReportingHandler) classroomTimezone(classroomID uuid.UUID) string {
+	var tz string
+	h.db.Table("classrooms c").
+		Select("COALESCE(sc.timezone, '')").
+		Joins("JOIN schools sc ON sc.id = c.school_id").
+		Where("c.id = ?", classroomID).
+		Scan(&tz)
+	return tz
+}
+
+// userTimezone returns the stored time zone of a user's school
+func (h *ReportingHandler) userTimezone(userID uuid.UUID) string {
+	var tz string
+	h.db.Table("users u").
+		Select("COALESCE(sc.timezone, '')").
+		Joins("JOIN schools sc ON sc.id = u.school_id").
+		Where("u.id = ?", userID).
+		Scan(&tz)
+	return tz
+}
+
+// userLocations returns the school time zone of each user, with the report
+// location for users whose school has none or an unknown one
+func (h *ReportingHandler) userLocations(userIDs []uuid.UUID) (map[uuid.UUID]*time.Location, error) {
+	var rows []struct {
+		ID       uuid.UUID
+		Timezone string
+	}
+	err := h.db.Table("users u").
+		Select("u.id, COALESCE(sc.timezone, '') as timezone").
+		Joins("LEFT JOIN schools sc ON sc.id = u.school_id").
+		Where("u.id IN ?", userIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make(map[uuid.UUID]*time.Location, len(rows))
+	loaded := make(map[string]*time.Location)
+	for _, row := range rows {
+		loc, ok := loaded[row.Timezone]
+		if !ok {
+			loc = h.reportLocation
+			if row.Timezone != "" {
+				if l, err := time.LoadLocation(row.Timezone); err == nil {
+					loc = l
+				} else {
+					log.Printf("Ignoring unknown school time zone %q: %v", row.Timezone, err)
+				}
+			}
+			loaded[row.Timezone] = loc
+		}
+		locations[row.ID] = loc
+	}
+	return locations, nil
+}
+
+// validateTimezone checks that an optional IANA time zone name is known
+func validateTimezone(tz *string) error {
+	if tz == nil || *tz == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(*tz); err != nil {
+		return fmt.Errorf("unknown time zone %q", *tz)
+	}
+	return nil
+}
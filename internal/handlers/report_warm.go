@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Days of history a warm recomputes unless the request passes its own
+const (
+	defaultWarmDays = 365
+	maxWarmDays     = 3650
+)
+
+// warmJobQuizAnalytics names the quiz analytics refresh, which only runs on warm
+const warmJobQuizAnalytics = "quiz_analytics"
+
+// Warm job statuses
+const (
+	WarmJobOK      = "ok"
+	WarmJobFailed  = "failed"
+	WarmJobSkipped = "skipped"
+)
+
+// WarmJobResult is the outcome of one aggregation run by a report warm
+type WarmJobResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMs int64   `json:"duration_ms"`
+	Error      *string `json:"error,omitempty"`
+}
+
+// warmJob is an aggregation run by a report warm; it is skipped when a job
+// it reads from failed
+type warmJob struct {
+	name      string
+	dependsOn []string
+	run       func(ctx context.Context, since time.Time) error
+}
+
+// warmJobs lists the aggregations behind the reports in dependency order:
+// weekly school metrics read the daily tables
+func (h *ReportingHandler) warmJobs() []warmJob {
+	return []warmJob{
+		{name: AggregationJobDaily, run: h.recomputeDailyMetrics},
+		{name: AggregationJobWeekly, dependsOn: []string{AggregationJobDaily}, run: h.recomputeWeeklySchoolMetrics},
+		{name: AggregationJobContent, run: func(ctx context.Context, since time.Time) error {
+			_, err := h.refreshContentMetrics(ctx, since, time.Now().UTC())
+			return err
+		}},
+		{name: warmJobQuizAnalytics, run: h.refreshQuizAnalytics},
+	}
+}
+
+// WarmReports - Admin endpoint that runs every aggregation the reports read
+// from over the last ?days= of history (default 365), so reports return real
+// data after a fresh seed. Each job's outcome is reported; the response is a
+// 500 when any job failed or was skipped.
+func (h *ReportingHandler) WarmReports(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(defaultWarmDays)))
+	if err != nil || days < 1 || days > maxWarmDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxWarmDays)})
+		return
+	}
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	ctx := c.Request.Context()
+	jobs := h.warmJobs()
+	failed := map[string]bool{}
+	results := make([]WarmJobResult, 0, len(jobs))
+	for _, job := range jobs {
+		result := WarmJobResult{Name: job.name, Status: WarmJobOK}
+		for _, dependency := range job.dependsOn {
+			if failed[dependency] {
+				message := dependency + " job did not complete"
+				result.Status = WarmJobSkipped
+				result.Error = &message
+			}
+		}
+		if result.Status == WarmJobOK {
+			started := time.Now()
+			err := job.run(ctx, since)
+			result.DurationMs = time.Since(started).Milliseconds()
+			if err != nil {
+				message := err.Error()
+				result.Status = WarmJobFailed
+				result.Error = &message
+			}
+		}
+		if result.Status != WarmJobOK {
+			failed[job.name] = true
+		}
+		results = append(results, result)
+	}
+
+	status := http.StatusOK
+	if len(failed) > 0 {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, gin.H{
+		"success": len(failed) == 0,
+		"since":   since.Format(DateFormat),
+		"jobs":    results,
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// warmStatements are fragments of each statement a full warm runs, in order
+var warmStatements = []string{
+	"INSERT INTO daily_user_metrics",
+	"INSERT INTO daily_classroom_metrics",
+	"refresh_classroom_performance_mv",
+	"INSERT INTO weekly_school_metrics",
+	"INSERT INTO content_metrics",
+	"INSERT INTO quiz_analytics",
+}
+
+func TestWarmReportsFillsClassroomReport(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	classroomID := uuid.New()
+	report := func() map[string]interface{} {
+		target := "/reports/classroom-engagement?classroom_id=" + classroomID.String() + "&date_from=2026-02-01&date_to=2026-02-28"
+		w := testRequest(h.GetClassroomEngagementReport, "/reports/classroom-engagement", http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		return decodeBody(t, w)
+	}
+
+	// Before warming the aggregate tables are empty and the report is a stub
+	stub := report()
+	if metrics := stub["engagement_metrics"].(map[string]interface{}); metrics["active_participation_rate"] != nil || metrics["total_quiz_sessions"] != 0.0 {
+		t.Fatalf("engagement_metrics = %v, want a stub before warming", metrics)
+	}
+
+	w := testRequest(h.WarmReports, "/admin/reports/warm", http.MethodPost, "/admin/reports/warm?days=7", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -6)
+	if body["success"] != true || body["since"] != since.Format(DateFormat) {
+		t.Errorf("warm = %v, want success since %s", body, since.Format(DateFormat))
+	}
+	for i, job := range body["jobs"].([]interface{}) {
+		if job := job.(map[string]interface{}); job["status"] != WarmJobOK {
+			t.Errorf("jobs[%d] = %v, want ok", i, job)
+		}
+	}
+
+	// Every job ran once, in dependency order, from the start of the window
+	statements := fake.ran()
+	next := 0
+	for _, statement := range statements {
+		if next < len(warmStatements) && containsAll(statement.SQL, []string{warmStatements[next]}) {
+			next++
+			// Daily metrics bind the first local day rather than the instant
+			windowStart := argsHaveTime(statement.Args, since) || argsContain(statement.Args, since.Format(DateFormat))
+			if statement.SQL != "SELECT refresh_classroom_performance_mv()" && !windowStart {
+				t.Errorf("%s args = %v, want the window start %v", warmStatements[next-1], statement.Args, since)
+			}
+		}
+	}
+	if next != len(warmStatements) {
+		t.Fatalf("warm stopped matching at %q, ran %v", warmStatements[next], statements)
+	}
+
+	// Stand in for the rows the warm wrote to the daily tables
+	fake.rows([]string{`FROM "daily_classroom_metrics"`, "participation_samples"},
+		[]string{"active_participation_rate", "avg_session_duration", "collaboration_events", "content_sharing_frequency", "total_quiz_sessions",
+			"avg_quiz_completion_rate", "avg_class_quiz_score", "participation_samples", "class_score_samples"},
+		[]driver.Value{62.5, 24.0, int64(10), 1.5, int64(12), 80.0, 74.0, int64(20), int64(12)})
+	fake.rows([]string{`FROM "daily_classroom_metrics"`, "active_students_count"},
+		[]string{"date", "active_students_count", "participation_rate", "avg_session_duration_minutes", "engagement_score"},
+		[]driver.Value{time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC), int64(15), 62.5, 24.0, 71.0})
+
+	warmed := report()
+	metrics := warmed["engagement_metrics"].(map[string]interface{})
+	if metrics["active_participation_rate"] != 62.5 || metrics["total_quiz_sessions"] != 12.0 || metrics["avg_class_quiz_score"] != 74.0 {
+		t.Errorf("engagement_metrics = %v, want the warmed aggregates", metrics)
+	}
+	if timeline := warmed["timeline_data"].([]interface{}); len(timeline) != 1 {
+		t.Errorf("timeline_data = %v, want the warmed day", timeline)
+	}
+}
+
+func TestWarmReportsSkipsDependentJobs(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.fail([]string{"INSERT INTO daily_user_metrics"}, errors.New("disk full"))
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.WarmReports, "/admin/reports/warm", http.MethodPost, "/admin/reports/warm", "", nil)
+	expectStatus(t, w, http.StatusInternalServerError)
+	body := decodeBody(t, w)
+
+	// Weekly metrics read the daily tables; content and quiz analytics don't
+	want := map[string]string{
+		AggregationJobDaily:   WarmJobFailed,
+		AggregationJobWeekly:  WarmJobSkipped,
+		AggregationJobContent: WarmJobOK,
+		warmJobQuizAnalytics:  WarmJobOK,
+	}
+	jobs := body["jobs"].([]interface{})
+	if body["success"] != false || len(jobs) != len(want) {
+		t.Fatalf("warm = %v, want %d jobs and no success", body, len(want))
+	}
+	for _, raw := range jobs {
+		job := raw.(map[string]interface{})
+		if job["status"] != want[job["name"].(string)] {
+			t.Errorf("job %v status = %v, want %v", job["name"], job["status"], want[job["name"].(string)])
+		}
+	}
+	if len(fake.ran("INSERT INTO weekly_school_metrics")) != 0 {
+		t.Error("weekly metrics ran after the daily job failed")
+	}
+}
+
+func TestWarmReportsRejectsDays(t *testing.T) {
+	for _, days := range []string{"0", "3651", "x"} {
+		fake, db := newFakeDB(t)
+		w := testRequest(NewReportingHandler(db).WarmReports, "/admin/reports/warm", http.MethodPost, "/admin/reports/warm?days="+days, "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+		if len(fake.ran()) != 0 {
+			t.Errorf("days=%s reached the database", days)
+		}
+	}
+}
+
+// argsHaveTime reports whether a bound argument is the instant want
+func argsHaveTime(args []interface{}, want time.Time) bool {
+	for _, arg := range args {
+		if at, ok := arg.(time.Time); ok && at.Equal(want) {
+			return true
+		}
+	}
+	return false
+}
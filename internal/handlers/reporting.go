@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,18 +15,60 @@ import (
 	"gorm.io/gorm"
 
 	"reporting-framework/internal/domain/reporting"
+	"reporting-framework/internal/export"
+	"reporting-framework/internal/metrics"
+	"reporting-framework/internal/services"
 )
 
 // ReportingHandler handles reporting-related HTTP requests
 type ReportingHandler struct {
 	db *gorm.DB
+
+	reportCacheMaxAge    time.Duration
+	metricsRefreshedAt   atomic.Int64 // unix nanoseconds of the last successful refresh
+	minSampleSize        int
+	eventDedupWindow     time.Duration // zero disables deduplication
+	redactedRoles        map[string]bool
+	onNetworkRanges      []*net.IPNet      // client ranges bucketed as on-network
+	storeClientIP        bool              // false keeps only the network bucket
+	sectionOrders        map[string]string // report section -> default order_by
+	engagementScoring    EngagementScoring
+	engagementScore      services.EngagementScoreConfig // student engagement score weighting
+	aggregationScheduler *AggregationScheduler          // nil when scheduling is not configured
+	reportLocation       *time.Location                 // timezone that decides local days
+	weekStart            time.Weekday                   // first day in weekday breakdowns
+	fiscalYearStart      time.Month                     // first month of time.fiscal_year in generic queries
+	disabledFeatures     map[string]bool
+	exportLimiter        *exportLimiter // nil when exports are unlimited
+	appUsageThresholds   AppUsageThresholds
+	aggregationRetries   *aggregationRetryQueue // retries failed post-ingestion aggregation
+	cannedQueries        *services.CannedQueryRegistry
+	queryCache           *queryResultCache // nil when generic query caching is off
+	clockSkew            EventClockSkewPolicy
 }
 
 // NewReportingHandler creates a new reporting handler
 func NewReportingHandler(db *gorm.DB) *ReportingHandler {
-	return &ReportingHandler{
-		db: db,
+	h := &ReportingHandler{
+		db:                 db,
+		reportCacheMaxAge:  DefaultReportCacheMaxAge,
+		minSampleSize:      DefaultMinSampleSize,
+		storeClientIP:      true,
+		reportLocation:     time.UTC,
+		weekStart:          time.Monday,
+		fiscalYearStart:    time.January,
+		engagementScore:    services.DefaultEngagementScoreConfig(),
+		disabledFeatures:   make(map[string]bool),
+		exportLimiter:      newExportLimiter(DefaultExportConcurrency, DefaultExportQueueSize),
+		appUsageThresholds: DefaultAppUsageThresholds,
+		aggregationRetries: newAggregationRetryQueue(aggregationRetryQueueSize, maxAggregationAttempts, aggregationRetryBaseDelay),
+		cannedQueries:      services.DefaultCannedQueryRegistry(),
+		queryCache:         newQueryResultCache(DefaultQueryCacheSize, DefaultQueryCacheTTL),
+		clockSkew:          DefaultEventClockSkewPolicy,
 	}
+	h.SetRedactedRoles(DefaultRedactedRoles)
+	h.SetEngagementScoring(DefaultEngagementScoring)
+	return h
 }
 
 // RegisterRoutes registers all reporting routes
@@ -30,27 +77,95 @@ func (h *ReportingHandler) RegisterRoutes(router *gin.RouterGroup) {
 	{
 		// Event ingestion endpoints
 		v1.POST("/events", h.IngestEvents)
+		v1.POST("/events/import", h.ImportEvents)
 		v1.POST("/sessions/batch", h.IngestSessionBatch)
 
 		// Report generation endpoints
 		reports := v1.Group("/reports")
+		reports.Use(h.requireFeature(FeatureReportExport, wantsReportExport), h.timeFormat(), h.reportFreshness(), h.limitExports(wantsReportExport))
 		{
 			reports.GET("/student-performance", h.GetStudentPerformanceReport)
 			reports.GET("/classroom-engagement", h.GetClassroomEngagementReport)
 			reports.GET("/content-effectiveness", h.GetContentEffectivenessReport)
 			reports.GET("/school-overview", h.GetSchoolOverviewReport)
+			reports.GET("/creator-content-effectiveness", h.GetCreatorContentEffectiveness)
+			reports.GET("/onboarding-latency", h.GetOnboardingLatency)
+			reports.GET("/class-size-engagement", h.GetClassSizeEngagement)
+			reports.GET("/school-comparison", h.GetSchoolComparison)
+			reports.GET("/content-type-trend", h.GetContentTypeTrend)
+			reports.GET("/teacher/:id/subject-breakdown", h.GetTeacherSubjectBreakdown)
 		}
 
 		// Analytics endpoints
 		analytics := v1.Group("/analytics")
+		analytics.Use(h.timeFormat())
 		{
 			analytics.GET("/real-time/active-sessions", h.GetActiveSessions)
 			analytics.GET("/trends/engagement", h.GetEngagementTrends)
 			analytics.GET("/quiz-analytics/:quiz_id", h.GetQuizAnalytics)
+			analytics.GET("/quiz-analytics/:quiz_id/abandonment", h.GetQuizAbandonment)
+			analytics.GET("/quiz-analytics/:quiz_id/timing", h.GetQuizTiming)
+			analytics.GET("/engagement-anomalies", h.requireFeature(FeatureAnomalyDetection, nil), h.GetEngagementAnomalies)
+			analytics.GET("/grading-latency", h.GetGradingLatency)
+			analytics.GET("/stickiness", h.GetStickiness)
+			analytics.GET("/content-freshness", h.GetContentFreshness)
+			analytics.GET("/engagement-by-weekday", h.GetEngagementByWeekday)
+			analytics.GET("/content-by-creation-hour", h.GetContentByCreationHour)
+			analytics.GET("/quiz-completion-by-assignment-hour", h.GetQuizCompletionByAssignmentHour)
+			analytics.GET("/session-duration-histogram", h.GetSessionDurationHistogram)
+		}
+
+		// School-level endpoints
+		schools := v1.Group("/schools")
+		schools.Use(h.timeFormat())
+		{
+			schools.GET("/:id/classroom-rankings", h.GetClassroomRankings)
+		}
+
+		// Classroom-level endpoints
+		classrooms := v1.Group("/classrooms")
+		classrooms.Use(h.timeFormat())
+		{
+			classrooms.GET("/:id/engagement-explain", h.GetClassroomEngagementExplain)
+			classrooms.GET("/:id/equity", h.GetClassroomParticipationEquity)
+			classrooms.GET("/:id/presence", h.GetClassroomPresence)
+		}
+
+		// Student-level endpoints
+		students := v1.Group("/students")
+		students.Use(h.timeFormat())
+		{
+			students.GET("/:id/grade-comparison", h.GetStudentGradeComparison)
+			students.GET("/:id/pending-quizzes", h.GetStudentPendingQuizzes)
+			students.GET("/:id/growth", h.GetStudentGrowth)
+			students.GET("/:id/activity", h.GetStudentActivity)
+			students.GET("/:id/recommended-content", h.GetStudentRecommendedContent)
+			students.GET("/:id/app-usage", h.GetStudentAppUsage)
+		}
+
+		// Quiz-level endpoints
+		quizzes := v1.Group("/quizzes")
+		{
+			quizzes.GET("/:id/retakes", h.GetQuizRetakes)
+			quizzes.GET("/:id/pending-grading", h.GetQuizPendingGrading)
+		}
+
+		// Manual grading of short-answer and essay submissions
+		v1.POST("/submissions/:id/grade", h.GradeSubmission)
+
+		// Teacher-level endpoints
+		teachers := v1.Group("/teachers")
+		{
+			teachers.GET("/:id/load", h.GetTeacherLoad)
+			teachers.GET("/:id/content-cadence", h.GetTeacherContentCadence)
 		}
 
 		// Generic query endpoint (cube.dev style)
-		v1.POST("/query", h.ExecuteGenericQuery)
+		v1.POST("/query", h.requireFeature(FeatureGenericQuery, nil), h.limitExports(wantsNDJSON), h.ExecuteGenericQuery)
+		v1.GET("/query/dimension-values", h.requireFeature(FeatureGenericQuery, nil), h.GetDimensionValues)
+		v1.POST("/query/saved", h.requireFeature(FeatureGenericQuery, nil), h.SaveQuery)
+		v1.GET("/query/saved", h.requireFeature(FeatureGenericQuery, nil), h.ListSavedQueries)
+		v1.POST("/query/saved/:name/run", h.requireFeature(FeatureGenericQuery, nil), h.RunSavedQuery)
 
 		// Administrative endpoints
 		admin := v1.Group("/admin")
@@ -59,6 +174,12 @@ func (h *ReportingHandler) RegisterRoutes(router *gin.RouterGroup) {
 			admin.POST("/classrooms", h.CreateClassroom)
 			admin.POST("/users", h.CreateUser)
 			admin.POST("/refresh-metrics", h.RefreshAggregatedMetrics)
+			admin.POST("/refresh-content-metrics", h.RefreshContentMetrics)
+			admin.POST("/reports/warm", h.WarmReports)
+			admin.GET("/ingestion-stats", h.GetIngestionStats)
+			admin.GET("/aggregation-schedule", h.GetAggregationSchedule)
+			admin.GET("/canned-reports", h.ListCannedReports)
+			admin.GET("/canned-reports/:name", h.RunCannedReport)
 		}
 	}
 }
@@ -79,20 +200,28 @@ func (h *ReportingHandler) IngestEvents(c *gin.Context) {
 	}
 
 	schoolID, _ := c.Get("school_id")
+	batchKey := c.GetHeader(IdempotencyKeyHeader)
 
 	var events []reporting.Event
 	var eventIDs []uuid.UUID
 
-	for _, eventData := range req.Events {
+	for i, eventData := range req.Events {
+		clientEventID, err := clientEventID(eventData, batchKey, i)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid idempotency key", "details": err.Error()})
+			return
+		}
+
 		event := reporting.Event{
-			ID:          uuid.New(),
-			EventType:   eventData.EventType,
-			UserID:      eventData.UserID,
-			SessionID:   eventData.SessionID,
-			ClassroomID: eventData.ClassroomID,
-			Application: eventData.Application,
-			Timestamp:   eventData.Timestamp,
-			CreatedAt:   time.Now(),
+			ID:            uuid.New(),
+			EventType:     eventData.EventType,
+			UserID:        eventData.UserID,
+			SessionID:     eventData.SessionID,
+			ClassroomID:   eventData.ClassroomID,
+			Application:   eventData.Application,
+			Timestamp:     eventData.Timestamp,
+			ClientEventID: clientEventID,
+			CreatedAt:     time.Now(),
 		}
 
 		// Set user and school context if not provided
@@ -116,39 +245,125 @@ func (h *ReportingHandler) IngestEvents(c *gin.Context) {
 		}
 
 		events = append(events, event)
-		eventIDs = append(eventIDs, event.ID)
 	}
 
-	// Batch insert events
-	if err := h.db.CreateInBatches(events, 100).Error; err != nil {
+	// Events from clients with wrong clocks would land in the wrong daily buckets
+	clockSkewAdjusted, skewProblems := h.applyClockSkewPolicy(events)
+	if len(skewProblems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event timestamps are too far from server time", "details": skewProblems})
+		return
+	}
+
+	// Retried events already stored under their idempotency key answer with the original ids
+	events, storedIDs, err := h.dropReplayedEvents(events)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for replayed events", "details": err.Error()})
+		return
+	}
+	replayed := len(storedIDs) - len(events)
+
+	// Drop near-duplicate events when deduplication is enabled
+	candidates := events
+	events, deduplicated, err := h.dropDuplicateEvents(events)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicate events", "details": err.Error()})
+		return
+	}
+	dropped := make(map[uuid.UUID]bool, deduplicated)
+	for _, event := range candidates {
+		dropped[event.ID] = true
+	}
+	for _, event := range events {
+		delete(dropped, event.ID)
+	}
+	for _, id := range storedIDs {
+		if !dropped[id] {
+			eventIDs = append(eventIDs, id)
+		}
+	}
+
+	if len(events) == 0 {
+		c.JSON(http.StatusOK, reporting.EventResponse{
+			Success:                true,
+			Message:                "All events were duplicates",
+			EventIDs:               eventIDs,
+			DeduplicatedCount:      deduplicated,
+			ReplayedCount:          replayed,
+			ClockSkewAdjustedCount: clockSkewAdjusted,
+		})
+		return
+	}
+
+	// Batch insert events; a concurrent retry that stored the same key first wins
+	inserted, err := h.insertEvents(events)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store events", "details": err.Error()})
 		return
 	}
+	if len(inserted) < len(events) {
+		// Events that lost the race answer with the ids the winner stored
+		lost := make([]reporting.Event, 0, len(events)-len(inserted))
+		kept := make(map[uuid.UUID]bool, len(inserted))
+		for _, event := range inserted {
+			kept[event.ID] = true
+		}
+		for _, event := range events {
+			if !kept[event.ID] {
+				lost = append(lost, event)
+			}
+		}
+		_, winnerIDs, err := h.dropReplayedEvents(lost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for replayed events", "details": err.Error()})
+			return
+		}
+		winners := make(map[uuid.UUID]uuid.UUID, len(lost))
+		for i, event := range lost {
+			winners[event.ID] = winnerIDs[i]
+		}
+		for i, id := range eventIDs {
+			if winner, ok := winners[id]; ok {
+				eventIDs[i] = winner
+			}
+		}
+		replayed += len(lost)
+		events = inserted
+		if len(events) == 0 {
+			c.JSON(http.StatusOK, reporting.EventResponse{
+				Success:                true,
+				Message:                "All events were duplicates",
+				EventIDs:               eventIDs,
+				DeduplicatedCount:      deduplicated,
+				ReplayedCount:          replayed,
+				ClockSkewAdjustedCount: clockSkewAdjusted,
+			})
+			return
+		}
+	}
+
+	metrics.EventsIngested.Add(float64(len(events)))
 
 	// Trigger async aggregation update (in a real system, this would be done via message queue)
-	go h.updateAggregatedMetrics(events)
+	go h.updateAggregatedMetrics(c.GetString("request_id"), events)
 
 	response := reporting.EventResponse{
-		Success:        true,
-		ProcessedCount: len(events),
-		Message:        "Events ingested successfully",
-		EventIDs:       eventIDs,
+		Success:                true,
+		ProcessedCount:         len(events),
+		Message:                "Events ingested successfully",
+		EventIDs:               eventIDs,
+		DeduplicatedCount:      deduplicated,
+		ReplayedCount:          replayed,
+		ClockSkewAdjustedCount: clockSkewAdjusted,
 	}
 
 	c.JSON(http.StatusCreated, response)
 }
 
-// IngestSessionBatch handles batch session data ingestion
+// IngestSessionBatch handles batch session data ingestion. The whole batch is
+// validated before the transaction opens; any problem rejects it with a 400.
 func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 	var req struct {
-		Sessions []struct {
-			Application string               `json:"application"`
-			StartTime   time.Time            `json:"start_time"`
-			EndTime     *time.Time           `json:"end_time"`
-			ClassroomID *uuid.UUID           `json:"classroom_id"`
-			DeviceInfo  map[string]interface{} `json:"device_info"`
-			Events      []reporting.EventData `json:"events"`
-		} `json:"sessions"`
+		Sessions []sessionBatchItem `json:"sessions"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -156,6 +371,11 @@ func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 		return
 	}
 
+	if problems := validateSessionBatch(req.Sessions); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session batch", "details": problems})
+		return
+	}
+
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -164,6 +384,16 @@ func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 
 	uid := userID.(uuid.UUID)
 	var processedSessions []uuid.UUID
+	var processedEvents int
+
+	// Every session in the batch comes from the same client
+	clientIP := net.ParseIP(c.ClientIP())
+	networkBucket := h.networkBucket(clientIP)
+	var ipAddress *string
+	if h.storeClientIP && clientIP != nil {
+		ip := clientIP.String()
+		ipAddress = &ip
+	}
 
 	tx := h.db.Begin()
 	defer func() {
@@ -174,13 +404,15 @@ func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 
 	for _, sessionData := range req.Sessions {
 		session := reporting.Session{
-			ID:          uuid.New(),
-			UserID:      uid,
-			ClassroomID: sessionData.ClassroomID,
-			Application: sessionData.Application,
-			StartTime:   sessionData.StartTime,
-			EndTime:     sessionData.EndTime,
-			CreatedAt:   time.Now(),
+			ID:            uuid.New(),
+			UserID:        uid,
+			ClassroomID:   sessionData.ClassroomID,
+			Application:   sessionData.Application,
+			StartTime:     sessionData.StartTime,
+			EndTime:       sessionData.EndTime,
+			IPAddress:     ipAddress,
+			NetworkBucket: &networkBucket,
+			CreatedAt:     time.Now(),
 		}
 
 		if sessionData.EndTime != nil {
@@ -220,6 +452,7 @@ func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event", "details": err.Error()})
 				return
 			}
+			processedEvents++
 		}
 
 		processedSessions = append(processedSessions, session.ID)
@@ -229,6 +462,8 @@ func (h *ReportingHandler) IngestSessionBatch(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction", "details": err.Error()})
 		return
 	}
+	metrics.SessionsCreated.Add(float64(len(processedSessions)))
+	metrics.EventsIngested.Add(float64(processedEvents))
 
 	c.JSON(http.StatusCreated, gin.H{
 		"success":           true,
@@ -246,6 +481,7 @@ const (
 
 // GetStudentPerformanceReport generates student performance analytics
 func (h *ReportingHandler) GetStudentPerformanceReport(c *gin.Context) {
+	defer metrics.ObserveReport("student_performance", time.Now())
 	studentIDStr := c.Query("student_id")
 	dateFromStr := c.Query("date_from")
 	dateToStr := c.Query("date_to")
@@ -262,73 +498,120 @@ func (h *ReportingHandler) GetStudentPerformanceReport(c *gin.Context) {
 		return
 	}
 
-	dateFrom, dateTo, err := h.parseDateRange(dateFromStr, dateToStr)
+	loc, err := h.requestLocation(c, h.userTimezone(studentID))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Build base query
-	query := h.db.Table("daily_user_metrics dum").
-		Select(`
-			dum.user_id,
-			AVG(dum.avg_quiz_score) as avg_quiz_score,
-			SUM(dum.quiz_attempts) as total_quiz_attempts,
-			SUM(dum.quiz_completions) as total_quiz_completions,
-			AVG(dum.total_session_duration_seconds / 60.0) as avg_daily_minutes,
-			SUM(dum.events_count) as total_events,
-			COUNT(dum.date) as active_days
-		`).
-		Where("dum.user_id = ? AND dum.date BETWEEN ? AND ?", studentID, dateFrom, dateTo).
-		Group("dum.user_id")
+	dateFrom, dateTo, err := h.parseDateRange(dateFromStr, dateToStr, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	var result struct {
-		UserID               uuid.UUID `json:"user_id"`
-		AvgQuizScore         *float64  `json:"avg_quiz_score"`
-		TotalQuizAttempts    int       `json:"total_quiz_attempts"`
-		TotalQuizCompletions int       `json:"total_quiz_completions"`
-		AvgDailyMinutes      float64   `json:"avg_daily_minutes"`
-		TotalEvents          int       `json:"total_events"`
-		ActiveDays           int       `json:"active_days"`
+	quizOrder, err := h.sectionOrder("quiz_performance", c.Query("order_by"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := query.Scan(&result).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch performance data", "details": err.Error()})
+	var comparison *services.ReportPeriod
+	switch compareTo := c.Query("compare_to"); compareTo {
+	case "":
+	case services.CompareToPrevious:
+		previous := services.PreviousPeriod(dateFrom, dateTo)
+		comparison = &previous
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid compare_to %q, expected %q", compareTo, services.CompareToPrevious)})
 		return
 	}
 
-	// Calculate engagement score
-	engagementScore := calculateEngagementScore(result.AvgDailyMinutes, result.ActiveDays, float64(dateTo.Sub(dateFrom).Hours()/24))
+	// Aggregate the period, and the comparison period when requested, in one pass
+	reports := h.reportsService()
+	result, previous, err := reports.GetStudentWindowStats(studentID, dateFrom, dateTo, comparison)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch performance data", "details": err.Error()})
+		return
+	}
 
+	// Calculate engagement score over the days the student was enrolled
+	enrolledAt, err := reports.StudentEnrolledAt(studentID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch enrollment", "details": err.Error()})
+		return
+	}
+	engagementScore := h.calculateEngagementScore(result.AvgDailyMinutes, result.ActiveDays, services.EnrolledPeriodDays(dateFrom, dateTo, enrolledAt))
+
+	// A quiz average over one or two completions is not representative
+	guard := h.newSampleGuard()
+	avgQuizScore := guard.average("avg_quiz_score", result.AvgQuizScore, result.TotalQuizCompletions)
+
+	overallStats := gin.H{
+		"avg_quiz_score":       avgQuizScore,
+		"total_quiz_attempts":  result.TotalQuizAttempts,
+		"total_quiz_completions": result.TotalQuizCompletions,
+		"avg_daily_minutes":    result.AvgDailyMinutes,
+		"total_events":         result.TotalEvents,
+		"active_days":          result.ActiveDays,
+		"engagement_score":     engagementScore,
+		"insufficient_sample":  guard.flagged(),
+	}
 	response := gin.H{
-		"student_id": studentID,
-		"period":     gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
-		"overall_stats": gin.H{
-			"avg_quiz_score":       result.AvgQuizScore,
-			"total_quiz_attempts":  result.TotalQuizAttempts,
-			"total_quiz_completions": result.TotalQuizCompletions,
-			"avg_daily_minutes":    result.AvgDailyMinutes,
-			"total_events":         result.TotalEvents,
-			"active_days":          result.ActiveDays,
-			"engagement_score":     engagementScore,
-		},
+		"student_id":         studentID,
+		"period":             gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"overall_stats":      overallStats,
+		"thresholds_applied": h.thresholdsApplied(),
+	}
+
+	if comparison != nil {
+		previousGuard := h.newSampleGuard()
+		previousQuizScore := previousGuard.average("avg_quiz_score", previous.AvgQuizScore, previous.TotalQuizCompletions)
+		change := reports.CompareStudentWindows(result, previous, engagementScore, *comparison, enrolledAt)
+		response["comparison"] = gin.H{
+			"period":              gin.H{"from": comparison.From.Format(DateFormat), "to": comparison.To.Format(DateFormat)},
+			"avg_quiz_score":      services.NewMetricChange(avgQuizScore, previousQuizScore),
+			"avg_daily_minutes":   change.AvgDailyMinutes,
+			"active_days":         change.ActiveDays,
+			"engagement_score":    change.EngagementScore,
+			"insufficient_sample": previousGuard.flagged(),
+		}
+	}
+
+	if wantsReportCSV(c) {
+		var quizPerformance []map[string]interface{}
+		h.db.Table("quiz_sessions qs").
+			Select("q.title, qs.percentage_score, qs.completed_at, qs.time_spent_seconds").
+			Joins("JOIN quizzes q ON qs.quiz_id = q.id").
+			Where("qs.student_id = ? AND qs.completed_at BETWEEN ? AND ? AND qs.is_completed = true",
+				studentID, dateFrom, dateTo).
+			Order(quizOrder).
+			Scan(&quizPerformance)
+
+		summary := gin.H{"student_id": studentID, "period_from": dateFrom.Format(DateFormat), "period_to": dateTo.Format(DateFormat)}
+		for k, v := range overallStats {
+			summary[k] = v
+		}
+		writeReportCSV(c, "student-performance", dateFrom, dateTo,
+			studentPerformanceCSVSummary, summary, studentPerformanceCSVDetail, quizPerformance)
+		return
 	}
 
 	if includeDetails {
 		// Add detailed quiz performance
-		var quizPerformance []gin.H
+		var quizPerformance []map[string]interface{}
 		h.db.Table("quiz_sessions qs").
 			Select("q.title, qs.percentage_score, qs.completed_at, qs.time_spent_seconds").
 			Joins("JOIN quizzes q ON qs.quiz_id = q.id").
 			Where("qs.student_id = ? AND qs.completed_at BETWEEN ? AND ? AND qs.is_completed = true",
 				studentID, dateFrom, dateTo).
-			Order("qs.completed_at DESC").
+			Order(quizOrder).
 			Scan(&quizPerformance)
 
 		response["quiz_performance"] = quizPerformance
 
 		// Add learning progression (daily metrics over time)
-		var learningProgression []gin.H
+		var learningProgression []map[string]interface{}
 		h.db.Table("daily_user_metrics").
 			Select("date, avg_quiz_score, total_session_duration_seconds / 60 as daily_minutes, events_count").
 			Where("user_id = ? AND date BETWEEN ? AND ?", studentID, dateFrom, dateTo).
@@ -343,6 +626,7 @@ func (h *ReportingHandler) GetStudentPerformanceReport(c *gin.Context) {
 
 // GetClassroomEngagementReport generates classroom engagement analytics
 func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
+	defer metrics.ObserveReport("classroom_engagement", time.Now())
 	classroomIDStr := c.Query("classroom_id")
 	dateFromStr := c.Query("date_from")
 	dateToStr := c.Query("date_to")
@@ -358,8 +642,20 @@ func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
 		return
 	}
 
+	loc, err := h.requestLocation(c, h.classroomTimezone(classroomID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Parse date range (default to last 30 days)
-	dateFrom, dateTo, err := h.parseDateRangeWithDefault(dateFromStr, dateToStr, -30)
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(dateFromStr, dateToStr, -30, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	breakdownOrder, err := h.sectionOrder("student_breakdown", c.Query("order_by"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -373,6 +669,14 @@ func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
 		ContentSharingFrequency  float64  `json:"content_sharing_frequency"`
 		TotalQuizSessions        int      `json:"total_quiz_sessions"`
 		AvgQuizCompletionRate    *float64 `json:"avg_quiz_completion_rate"`
+		AvgClassQuizScore        *float64 `json:"avg_class_quiz_score"`
+		ParticipationSamples     int      `json:"-"`
+		ClassScoreSamples        int      `json:"-"`
+		ParticipationStddev      *float64 `json:"-"`
+		SessionDurationStddev    *float64 `json:"-"`
+		SessionDurationSamples   int      `json:"-"`
+		CompletionRateStddev     *float64 `json:"-"`
+		CompletionRateSamples    int      `json:"-"`
 	}
 
 	h.db.Table("daily_classroom_metrics").
@@ -382,28 +686,59 @@ func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
 			SUM(sync_events_count) as collaboration_events,
 			AVG(content_shared_count) as content_sharing_frequency,
 			SUM(total_quiz_sessions) as total_quiz_sessions,
-			AVG(avg_quiz_completion_rate) as avg_quiz_completion_rate
+			AVG(avg_quiz_completion_rate) as avg_quiz_completion_rate,
+			SUM(avg_class_quiz_score * total_quiz_sessions) /
+				NULLIF(SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END), 0) as avg_class_quiz_score,
+			COUNT(participation_rate) as participation_samples,
+			SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END) as class_score_samples,
+			STDDEV_SAMP(participation_rate) as participation_stddev,
+			STDDEV_SAMP(avg_session_duration_minutes) as session_duration_stddev,
+			COUNT(avg_session_duration_minutes) as session_duration_samples,
+			STDDEV_SAMP(avg_quiz_completion_rate) as completion_rate_stddev,
+			COUNT(avg_quiz_completion_rate) as completion_rate_samples
 		`).
 		Where("classroom_id = ? AND date BETWEEN ? AND ?", classroomID, dateFrom, dateTo).
 		Scan(&engagementMetrics)
 
+	// Withhold averages backed by too few days or quiz sessions
+	guard := h.newSampleGuard()
+	engagementMetrics.ActiveParticipationRate = guard.average("active_participation_rate", engagementMetrics.ActiveParticipationRate, engagementMetrics.ParticipationSamples)
+	engagementMetrics.AvgQuizCompletionRate = guard.average("avg_quiz_completion_rate", engagementMetrics.AvgQuizCompletionRate, engagementMetrics.TotalQuizSessions)
+	engagementMetrics.AvgClassQuizScore = guard.average("avg_class_quiz_score", engagementMetrics.AvgClassQuizScore, engagementMetrics.ClassScoreSamples)
+
 	// Get student breakdown
-	var studentBreakdown []gin.H
+	var studentBreakdown []StudentBreakdownRow
 	h.db.Table("users u").
 		Select(`
 			u.id, u.first_name, u.last_name,
 			AVG(dum.avg_quiz_score) as avg_quiz_score,
 			AVG(dum.total_session_duration_seconds / 60.0) as avg_daily_minutes,
-			COUNT(dum.date) as active_days
+			COUNT(dum.date) as active_days,
+			COALESCE(SUM(dum.quiz_completions), 0) as quiz_completions
 		`).
 		Joins("JOIN user_classrooms uc ON u.id = uc.user_id").
 		Joins("LEFT JOIN daily_user_metrics dum ON u.id = dum.user_id AND dum.date BETWEEN ? AND ?", dateFrom, dateTo).
 		Where("uc.classroom_id = ? AND uc.is_active = true AND u.role = 'student'", classroomID).
 		Group("u.id, u.first_name, u.last_name").
+		Order(breakdownOrder).
 		Scan(&studentBreakdown)
 
-	// Get timeline data (daily engagement over time)
-	var timelineData []gin.H
+	for i := range studentBreakdown {
+		studentGuard := h.newSampleGuard()
+		studentBreakdown[i].AvgQuizScore = studentGuard.average("avg_quiz_score", studentBreakdown[i].AvgQuizScore, studentBreakdown[i].QuizCompletions)
+		studentBreakdown[i].InsufficientSample = len(studentGuard.flagged()) > 0
+	}
+
+	// Students see the class aggregates but only their own breakdown row
+	viewer := requestViewer(c)
+	redacted := h.redacts(viewer)
+	if redacted {
+		studentBreakdown = redactStudentBreakdown(studentBreakdown, viewer)
+	}
+
+	// Get timeline data (daily engagement over time). gorm only scans rows
+	// into unnamed maps, so this can't be []gin.H.
+	var timelineData []map[string]interface{}
 	h.db.Table("daily_classroom_metrics").
 		Select("date, active_students_count, participation_rate, avg_session_duration_minutes, engagement_score").
 		Where("classroom_id = ? AND date BETWEEN ? AND ?", classroomID, dateFrom, dateTo).
@@ -412,10 +747,102 @@ func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
 
 	response := gin.H{
 		"classroom_id":        classroomID,
-		"period":             gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"period":              gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
 		"engagement_metrics":  engagementMetrics,
 		"student_breakdown":   studentBreakdown,
+		"breakdown_redacted":  redacted,
 		"timeline_data":       timelineData,
+		"insufficient_sample": guard.flagged(),
+	}
+
+	if wantsConfidenceIntervals(c) {
+		// The class score is weighted by quiz sessions, so its spread comes
+		// from the completed sessions themselves rather than the daily averages
+		var sessionScores struct {
+			Stddev  *float64
+			Samples int
+		}
+		err := h.db.Table("quiz_sessions qs").
+			Select("STDDEV_SAMP(qs.percentage_score) as stddev, COUNT(qs.percentage_score) as samples").
+			Joins("JOIN quizzes q ON q.id = qs.quiz_id").
+			Where("q.classroom_id = ? AND qs.is_completed = true", classroomID).
+			Where("qs.completed_at >= ? AND qs.completed_at < ?", dateFrom, dateTo.AddDate(0, 0, 1)).
+			Scan(&sessionScores).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate confidence intervals", "details": err.Error()})
+			return
+		}
+
+		response["confidence_level"] = ConfidenceLevel
+		response["confidence_intervals"] = gin.H{
+			"active_participation_rate": h.percentageInterval(engagementMetrics.ActiveParticipationRate,
+				engagementMetrics.ParticipationStddev, engagementMetrics.ParticipationSamples),
+			"avg_session_duration": h.confidenceInterval(&engagementMetrics.AvgSessionDuration,
+				engagementMetrics.SessionDurationStddev, engagementMetrics.SessionDurationSamples),
+			"avg_quiz_completion_rate": h.percentageInterval(engagementMetrics.AvgQuizCompletionRate,
+				engagementMetrics.CompletionRateStddev, engagementMetrics.CompletionRateSamples),
+			"avg_class_quiz_score": h.percentageInterval(engagementMetrics.AvgClassQuizScore,
+				sessionScores.Stddev, sessionScores.Samples),
+		}
+	}
+
+	if wantsReportCSV(c) {
+		metricFields, err := csvFields(engagementMetrics)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build CSV export", "details": err.Error()})
+			return
+		}
+		summary := gin.H{"classroom_id": classroomID, "period_from": dateFrom.Format(DateFormat), "period_to": dateTo.Format(DateFormat)}
+		for k, v := range metricFields {
+			summary[k] = v
+		}
+		writeReportCSV(c, "classroom-engagement", dateFrom, dateTo,
+			classroomEngagementCSVSummary, summary, classroomEngagementCSVDetail, studentBreakdown)
+		return
+	}
+
+	if wantsReportBundle(c) {
+		writeReportBundle(c, "classroom-engagement", dateFrom, dateTo, []bundleSection{
+			{Name: "metrics", Rows: engagementMetrics},
+			{Name: "student_breakdown", Rows: studentBreakdown},
+			{Name: "timeline", Rows: timelineData},
+		}, map[string]interface{}{
+			"classroom_id":        classroomID,
+			"insufficient_sample": guard.flagged(),
+			"breakdown_redacted":  redacted,
+		})
+		return
+	}
+
+	if wantsReportPDF(c) {
+		header := h.classroomHeader(classroomID)
+		teacher := "Unassigned"
+		if header.TeacherName != nil {
+			teacher = *header.TeacherName
+		}
+		writeReportPDF(c, "classroom-engagement", dateFrom, dateTo, export.Report{
+			Title: "Classroom Engagement Report",
+			Header: []export.Field{
+				{Label: "Classroom", Value: header.ClassroomName},
+				{Label: "Teacher", Value: teacher},
+				{Label: "School", Value: header.SchoolName},
+				{Label: "Period", Value: dateFrom.Format(DateFormat) + " to " + dateTo.Format(DateFormat)},
+			},
+			Summary: []export.Field{
+				{Label: "Active participation rate", Value: pdfValue(engagementMetrics.ActiveParticipationRate, "%")},
+				{Label: "Avg session duration", Value: pdfValue(&engagementMetrics.AvgSessionDuration, " min")},
+				{Label: "Collaboration events", Value: strconv.Itoa(engagementMetrics.CollaborationEvents)},
+				{Label: "Content sharing frequency", Value: pdfValue(&engagementMetrics.ContentSharingFrequency, " per day")},
+				{Label: "Quiz sessions", Value: strconv.Itoa(engagementMetrics.TotalQuizSessions)},
+				{Label: "Avg quiz completion rate", Value: pdfValue(engagementMetrics.AvgQuizCompletionRate, "%")},
+				{Label: "Avg class quiz score", Value: pdfValue(engagementMetrics.AvgClassQuizScore, "%")},
+			},
+			Tables:     []export.Table{classroomBreakdownTable(studentBreakdown)},
+			NotesTitle: "Insights",
+			Notes: classroomEngagementInsights(engagementMetrics.ActiveParticipationRate, engagementMetrics.AvgClassQuizScore,
+				guard.flagged(), redacted),
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -423,6 +850,7 @@ func (h *ReportingHandler) GetClassroomEngagementReport(c *gin.Context) {
 
 // GetContentEffectivenessReport generates content effectiveness analytics
 func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
+	defer metrics.ObserveReport("content_effectiveness", time.Now())
 	schoolIDStr := c.Query("school_id")
 	classroomIDStr := c.Query("classroom_id")
 	contentType := c.Query("content_type")
@@ -442,13 +870,43 @@ func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
 		}
 	}
 
+	var schoolTimezone string
+	if classroomID != nil {
+		schoolTimezone = h.classroomTimezone(*classroomID)
+	} else if schoolID != nil {
+		schoolTimezone = h.schoolTimezone(*schoolID)
+	}
+	loc, err := h.requestLocation(c, schoolTimezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var dateFrom, dateTo time.Time
-	var err error
-	dateFrom, dateTo, err = h.parseDateRangeWithDefault(dateFromStr, dateToStr, -30)
+	dateFrom, dateTo, err = h.parseDateRangeWithDefault(dateFromStr, dateToStr, -30, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	typeOrder, err := h.sectionOrder("content_type_breakdown", "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	contentOrder, err := h.sectionOrder("most_engaging_content", c.Query("order_by"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// In resilient mode a failing section is reported rather than failing the report
+	mode, err := services.ParseReportMode(c.Query("mode"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	sections := services.NewSectionCollector(mode)
 
 	// Build query for content analytics
 	query := h.db.Table("content c").
@@ -458,7 +916,12 @@ func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
 			AVG(cm.view_count) as avg_views,
 			AVG(cm.unique_viewers) as avg_unique_viewers,
 			AVG(cm.avg_view_duration_seconds) as avg_view_duration,
-			AVG(cm.effectiveness_score) as avg_effectiveness_score
+			AVG(cm.effectiveness_score) as avg_effectiveness_score,
+			COUNT(cm.content_id) as metric_samples,
+			STDDEV_SAMP(cm.view_count) as views_stddev,
+			STDDEV_SAMP(cm.unique_viewers) as unique_viewers_stddev,
+			STDDEV_SAMP(cm.avg_view_duration_seconds) as view_duration_stddev,
+			STDDEV_SAMP(cm.effectiveness_score) as effectiveness_stddev
 		`).
 		Joins("LEFT JOIN content_metrics cm ON c.id = cm.content_id").
 		Where("c.created_at BETWEEN ? AND ?", dateFrom, dateTo)
@@ -474,8 +937,46 @@ func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
 		query = query.Where("c.content_type = ?", contentType)
 	}
 
-	var contentAnalytics []gin.H
-	query.Group("c.content_type").Scan(&contentAnalytics)
+	var contentAnalytics []struct {
+		ContentType           string                         `json:"content_type"`
+		TotalContent          int                            `json:"total_content"`
+		AvgViews              *float64                       `json:"avg_views"`
+		AvgUniqueViewers      *float64                       `json:"avg_unique_viewers"`
+		AvgViewDuration       *float64                       `json:"avg_view_duration"`
+		AvgEffectivenessScore *float64                       `json:"avg_effectiveness_score"`
+		MetricSamples         int                            `json:"-"`
+		InsufficientSample    []string                       `json:"insufficient_sample"`
+		ViewsStddev           *float64                       `json:"-"`
+		UniqueViewersStddev   *float64                       `json:"-"`
+		ViewDurationStddev    *float64                       `json:"-"`
+		EffectivenessStddev   *float64                       `json:"-"`
+		ConfidenceIntervals   map[string]*ConfidenceInterval `json:"confidence_intervals,omitempty"`
+	}
+	err = query.Group("c.content_type").Order(typeOrder).Scan(&contentAnalytics).Error
+	if err := sections.Record("content_type_breakdown", "failed to fetch content analytics", err); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch content analytics", "details": err.Error()})
+		return
+	}
+
+	// Averages over only a couple of content items per type are withheld
+	includeCI := wantsConfidenceIntervals(c)
+	for i := range contentAnalytics {
+		row := &contentAnalytics[i]
+		typeGuard := h.newSampleGuard()
+		row.AvgViews = typeGuard.average("avg_views", row.AvgViews, row.MetricSamples)
+		row.AvgUniqueViewers = typeGuard.average("avg_unique_viewers", row.AvgUniqueViewers, row.MetricSamples)
+		row.AvgViewDuration = typeGuard.average("avg_view_duration", row.AvgViewDuration, row.MetricSamples)
+		row.AvgEffectivenessScore = typeGuard.average("avg_effectiveness_score", row.AvgEffectivenessScore, row.MetricSamples)
+		row.InsufficientSample = typeGuard.flagged()
+		if includeCI {
+			row.ConfidenceIntervals = map[string]*ConfidenceInterval{
+				"avg_views":               h.confidenceInterval(row.AvgViews, row.ViewsStddev, row.MetricSamples),
+				"avg_unique_viewers":      h.confidenceInterval(row.AvgUniqueViewers, row.UniqueViewersStddev, row.MetricSamples),
+				"avg_view_duration":       h.confidenceInterval(row.AvgViewDuration, row.ViewDurationStddev, row.MetricSamples),
+				"avg_effectiveness_score": h.confidenceInterval(row.AvgEffectivenessScore, row.EffectivenessStddev, row.MetricSamples),
+			}
+		}
+	}
 
 	// Get most engaging content
 	mostEngagingQuery := h.db.Table("content c").
@@ -487,8 +988,12 @@ func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
 		mostEngagingQuery = mostEngagingQuery.Where("c.classroom_id = ?", *classroomID)
 	}
 
-	var mostEngagingContent []gin.H
-	mostEngagingQuery.Order("cm.effectiveness_score DESC").Limit(10).Scan(&mostEngagingContent)
+	var mostEngagingContent []map[string]interface{}
+	err = mostEngagingQuery.Order(contentOrder).Limit(10).Scan(&mostEngagingContent).Error
+	if err := sections.Record("most_engaging_content", "failed to fetch most engaging content", err); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch most engaging content", "details": err.Error()})
+		return
+	}
 
 	response := gin.H{
 		"period": gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
@@ -501,6 +1006,43 @@ func (h *ReportingHandler) GetContentEffectivenessReport(c *gin.Context) {
 			"Review content with low view duration for potential improvements",
 			"Encourage content sharing to increase reach and effectiveness",
 		},
+		"thresholds_applied": h.thresholdsApplied(),
+	}
+	if includeCI {
+		response["confidence_level"] = ConfidenceLevel
+	}
+	if mode == services.ReportModeResilient {
+		partial, sectionErrors := sections.Result()
+		response["partial"] = partial
+		if partial {
+			response["errors"] = sectionErrors
+			// A partial report must not be revalidated as current once the failure clears
+			c.Header("Cache-Control", "no-store")
+		}
+	}
+
+	if wantsReportCSV(c) {
+		summary := gin.H{
+			"period_from":         dateFrom.Format(DateFormat),
+			"period_to":           dateTo.Format(DateFormat),
+			"school_id":           schoolID,
+			"classroom_id":        classroomID,
+			"content_type_filter": contentType,
+		}
+		writeReportCSV(c, "content-effectiveness", dateFrom, dateTo,
+			contentEffectivenessCSVSummary, summary, contentEffectivenessCSVDetail, contentAnalytics)
+		return
+	}
+
+	if wantsReportBundle(c) {
+		writeReportBundle(c, "content-effectiveness", dateFrom, dateTo, []bundleSection{
+			{Name: "content_type_breakdown", Rows: contentAnalytics},
+			{Name: "most_engaging_content", Rows: mostEngagingContent},
+		}, map[string]interface{}{
+			"recommendations":    response["recommendations"],
+			"thresholds_applied": response["thresholds_applied"],
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -545,105 +1087,261 @@ func (h *ReportingHandler) GetSchoolOverviewReport(c *gin.Context) {
 	})
 }
 
-// ExecuteGenericQuery handles cube.dev style queries
+// ExecuteGenericQuery runs a cube.dev style query through the GenericQueryBuilder
+// and returns its typed rows as data alongside the column metadata. With
+// Accept: application/x-ndjson the rows are streamed one JSON object per line
+// instead.
 func (h *ReportingHandler) ExecuteGenericQuery(c *gin.Context) {
-	var queryReq struct {
-		Measures       []string `json:"measures"`
-		Dimensions     []string `json:"dimensions"`
-		TimeDimensions []struct {
-			Dimension string   `json:"dimension"`
-			Granularity string `json:"granularity"`
-			DateRange   []string `json:"dateRange"`
-		} `json:"timeDimensions"`
-		Filters []struct {
-			Member   string   `json:"member"`
-			Operator string   `json:"operator"`
-			Values   []string `json:"values"`
-		} `json:"filters"`
-		Order [][]string `json:"order"`
-		Limit int        `json:"limit"`
-	}
-
+	var queryReq cubeQuery
 	if err := c.ShouldBindJSON(&queryReq); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query format", "details": err.Error()})
 		return
 	}
+	if problems := ValidateQuery(queryReq, h.queryBuilder().GetSchema()); len(problems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid query", "details": problems})
+		return
+	}
 
-	// This is a simplified implementation - in a real system, you'd have a proper query builder
-	// For demo purposes, return a sample result
-	result := []map[string]interface{}{
-		{
-			"users_role":        "student",
-			"events_event_type": "quiz_answer_submitted",
-			"events_count":      125,
-		},
-		{
-			"users_role":        "teacher",
-			"events_event_type": "quiz_created",
-			"events_count":      15,
-		},
+	if wantsNDJSON(c) {
+		h.streamGenericQuery(c, queryReq)
+		return
+	}
+
+	// Identical queries within the cache TTL share one execution; no_cache=true
+	// runs the query anyway and refreshes the cached result
+	var cacheKey string
+	if h.queryCache != nil {
+		cacheKey, _ = queryCacheKey(queryReq, h.fiscalYearStart)
+	}
+	if cacheKey != "" && c.Query("no_cache") != "true" {
+		if result, storedAt, ok := h.queryCache.get(cacheKey); ok {
+			c.JSON(http.StatusOK, gin.H{
+				"data":       result.Rows,
+				"columns":    result.Columns,
+				"query":      queryReq,
+				"executedAt": storedAt,
+				"meta":       gin.H{"cached": true},
+			})
+			return
+		}
+	}
+
+	start := time.Now()
+	result, err := h.queryBuilder().ExecuteQuery(queryReq)
+	metrics.ObserveQuery(start, err)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to execute query", "details": err.Error()})
+		return
+	}
+	if cacheKey != "" {
+		h.queryCache.put(cacheKey, result)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data": result,
-		"query": queryReq,
+		"data":       result.Rows,
+		"columns":    result.Columns,
+		"query":      queryReq,
 		"executedAt": time.Now(),
+		"meta":       gin.H{"cached": false},
 	})
 }
 
 // Helper functions
 
-func calculateEngagementScore(avgDailyMinutes float64, activeDays int, totalDays float64) float64 {
-	if totalDays == 0 {
-		return 0
+// SetEngagementScoreConfig overrides the student engagement score weighting
+func (h *ReportingHandler) SetEngagementScoreConfig(cfg services.EngagementScoreConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
+	h.engagementScore = cfg
+	return nil
+}
 
-	// Simple engagement scoring algorithm
-	consistencyScore := float64(activeDays) / totalDays * 100
-	intensityScore := avgDailyMinutes / 60 * 100 // Normalize to hours
+// reportsService returns a reports service over the handler's database that
+// scores engagement with the handler's weighting
+func (h *ReportingHandler) reportsService() *services.ReportsService {
+	rs := services.NewReportsService(h.db)
+	// The weighting was validated when it was set on the handler
+	_ = rs.SetEngagementScoreConfig(h.engagementScore)
+	return rs
+}
+
+func (h *ReportingHandler) calculateEngagementScore(avgDailyMinutes float64, activeDays int, totalDays float64) float64 {
+	return h.engagementScore.Score(avgDailyMinutes, activeDays, totalDays)
+}
 
-	// Weight consistency more heavily than intensity
-	return (consistencyScore * 0.7) + (intensityScore * 0.3)
+// dailyMetricsKey is one user's local day touched by an ingested batch
+type dailyMetricsKey struct {
+	userID uuid.UUID
+	date   string
 }
 
-func (h *ReportingHandler) updateAggregatedMetrics(events []reporting.Event) {
+// dailyMetricsBatch is an ingested batch planned for updateAggregatedMetrics
+type dailyMetricsBatch struct {
+	events      []reporting.Event
+	locations   map[uuid.UUID]*time.Location
+	eventCounts map[dailyMetricsKey]int
+	touched     []dailyMetricsKey
+	quizTouched []dailyMetricsKey
+}
+
+// location returns the school time zone of a user in the batch
+func (b *dailyMetricsBatch) location(userID uuid.UUID, fallback *time.Location) *time.Location {
+	if loc, ok := b.locations[userID]; ok {
+		return loc
+	}
+	return fallback
+}
+
+// updateAggregatedMetrics upserts daily_user_metrics for each (user, date)
+// the events touch: events_count is incremented, session totals are
+// recomputed from the user's sessions that day, and quiz attempts,
+// completions and average score are recomputed from quiz_sessions when any
+// of the events is a quiz event. Dates are local days in the time zone of
+// each user's school. Each batch issues at most three statements; a failed
+// one is retried from that statement on, so no increment is applied twice.
+// Failures are logged with the ingesting request's id.
+func (h *ReportingHandler) updateAggregatedMetrics(requestID string, events []reporting.Event) {
 	// This would typically be handled by a background job or message queue
-	// For demo purposes, we'll do basic aggregation updates
+	batch := &dailyMetricsBatch{events: events}
+	h.aggregationRetries.run(&aggregationTask{
+		requestID: requestID,
+		steps: []aggregationStep{
+			{name: "school time zone lookup", run: func() error { return h.planDailyMetrics(batch) }},
+			{name: "daily user metrics", run: func() error { return h.upsertDailyEventMetrics(batch) }},
+			{name: "daily quiz metrics", run: func() error { return h.updateDailyQuizMetrics(batch) }},
+		},
+	})
+}
 
-	// Group events by user and date
-	userDates := make(map[string]map[string]int)
+// planDailyMetrics looks up the users' school time zones and counts the
+// batch's events per user and local day
+func (h *ReportingHandler) planDailyMetrics(batch *dailyMetricsBatch) error {
+	var userIDs []uuid.UUID
+	userSeen := make(map[uuid.UUID]bool)
+	for _, event := range batch.events {
+		if event.UserID != nil && !userSeen[*event.UserID] {
+			userSeen[*event.UserID] = true
+			userIDs = append(userIDs, *event.UserID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+	locations, err := h.userLocations(userIDs)
+	if err != nil {
+		return err
+	}
 
-	for _, event := range events {
+	batch.locations = locations
+	batch.eventCounts = make(map[dailyMetricsKey]int)
+	quizSeen := make(map[dailyMetricsKey]bool)
+	for _, event := range batch.events {
 		if event.UserID == nil {
 			continue
 		}
+		loc := batch.location(*event.UserID, h.reportLocation)
+		key := dailyMetricsKey{userID: *event.UserID, date: event.Timestamp.In(loc).Format(DateFormat)}
+		if batch.eventCounts[key] == 0 {
+			batch.touched = append(batch.touched, key)
+		}
+		batch.eventCounts[key]++
+		if strings.HasPrefix(event.EventType, "quiz_") && !quizSeen[key] {
+			quizSeen[key] = true
+			batch.quizTouched = append(batch.quizTouched, key)
+		}
+	}
+	return nil
+}
 
-		userKey := event.UserID.String()
-		dateKey := event.Timestamp.Format(DateFormat)
+// upsertDailyEventMetrics adds the batch's event counts to daily_user_metrics
+// and recomputes session totals for the touched days
+func (h *ReportingHandler) upsertDailyEventMetrics(batch *dailyMetricsBatch) error {
+	if len(batch.touched) == 0 {
+		return nil
+	}
 
-		if userDates[userKey] == nil {
-			userDates[userKey] = make(map[string]int)
+	var values strings.Builder
+	args := make([]interface{}, 0, len(batch.touched)*4)
+	for i, key := range batch.touched {
+		if i > 0 {
+			values.WriteString(", ")
 		}
-		userDates[userKey][dateKey]++
+		values.WriteString("(?::uuid, ?::date, ?::int, ?::text)")
+		args = append(args, key.userID, key.date, batch.eventCounts[key], batch.location(key.userID, h.reportLocation).String())
 	}
 
-	// Update daily user metrics
-	for userKey, dates := range userDates {
-		userID, _ := uuid.Parse(userKey)
-		for dateKey, eventCount := range dates {
-			date, _ := time.Parse(DateFormat, dateKey)
+	return h.db.Exec(`
+		WITH touched (user_id, date, events_count, tz) AS (VALUES `+values.String()+`),
+		se AS (
+			SELECT t.user_id, t.date, COUNT(s.id) as session_count, COALESCE(SUM(s.duration_seconds), 0) as total_duration
+			FROM touched t
+			JOIN sessions s ON s.user_id = t.user_id AND `+localDateSQL("s.start_time", "t.tz")+` = t.date
+			GROUP BY t.user_id, t.date
+		)
+		INSERT INTO daily_user_metrics (
+			user_id, school_id, date, events_count, session_count,
+			total_session_duration_seconds, avg_session_duration_seconds, created_at, updated_at
+		)
+		SELECT
+			t.user_id, u.school_id, t.date, t.events_count,
+			COALESCE(se.session_count, 0),
+			COALESCE(se.total_duration, 0),
+			COALESCE(se.total_duration::decimal / NULLIF(se.session_count, 0), 0),
+			NOW(), NOW()
+		FROM touched t
+		JOIN users u ON u.id = t.user_id
+		LEFT JOIN se ON se.user_id = t.user_id AND se.date = t.date
+		ON CONFLICT (user_id, date) DO UPDATE SET
+			events_count = daily_user_metrics.events_count + EXCLUDED.events_count,
+			session_count = EXCLUDED.session_count,
+			total_session_duration_seconds = EXCLUDED.total_session_duration_seconds,
+			avg_session_duration_seconds = EXCLUDED.avg_session_duration_seconds,
+			updated_at = NOW()
+	`, args...).Error
+}
+
+// updateDailyQuizMetrics recomputes quiz attempts, completions and average
+// score for the days the batch's quiz events touch
+func (h *ReportingHandler) updateDailyQuizMetrics(batch *dailyMetricsBatch) error {
+	if len(batch.quizTouched) == 0 {
+		return nil
+	}
 
-			// Upsert daily metrics
-			h.db.Exec(`
-				INSERT INTO daily_user_metrics (user_id, date, events_count, created_at, updated_at)
-				VALUES (?, ?, ?, NOW(), NOW())
-				ON CONFLICT (user_id, date)
-				DO UPDATE SET
-					events_count = daily_user_metrics.events_count + ?,
-					updated_at = NOW()
-			`, userID, date, eventCount, eventCount)
+	var values strings.Builder
+	args := make([]interface{}, 0, len(batch.quizTouched)*3)
+	for i, key := range batch.quizTouched {
+		if i > 0 {
+			values.WriteString(", ")
 		}
+		values.WriteString("(?::uuid, ?::date, ?::text)")
+		args = append(args, key.userID, key.date, batch.location(key.userID, h.reportLocation).String())
 	}
+
+	startedOn := localDateSQL("qs.started_at", "t.tz") + " = t.date"
+	completedOn := localDateSQL("qs.completed_at", "t.tz") + " = t.date"
+
+	// Attempts count on the day they started, completions and scores on the day they finished
+	return h.db.Exec(`
+		WITH touched (user_id, date, tz) AS (VALUES `+values.String()+`),
+		qz AS (
+			SELECT t.user_id, t.date,
+				COUNT(qs.id) FILTER (WHERE `+startedOn+`) as quiz_attempts,
+				COUNT(qs.id) FILTER (WHERE qs.is_completed AND `+completedOn+`) as quiz_completions,
+				AVG(qs.percentage_score) FILTER (WHERE qs.is_completed AND `+completedOn+`) as avg_quiz_score
+			FROM touched t
+			LEFT JOIN quiz_sessions qs ON qs.student_id = t.user_id
+				AND (`+startedOn+` OR `+completedOn+`)
+			GROUP BY t.user_id, t.date
+		)
+		UPDATE daily_user_metrics dum SET
+			quiz_attempts = qz.quiz_attempts,
+			quiz_completions = qz.quiz_completions,
+			avg_quiz_score = qz.avg_quiz_score,
+			updated_at = NOW()
+		FROM qz
+		WHERE dum.user_id = qz.user_id AND dum.date = qz.date
+	`, args...).Error
 }
 
 // Additional helper functions for different report types...
@@ -655,6 +1353,10 @@ func (h *ReportingHandler) CreateSchool(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validateTimezone(school.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	if err := h.db.Create(&school).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create school"})
@@ -672,6 +1374,18 @@ func (h *ReportingHandler) CreateClassroom(c *gin.Context) {
 		return
 	}
 
+	if classroom.TeacherID != nil {
+		if err := validateClassroomTeacher(h.db, classroom.SchoolID, *classroom.TeacherID); err != nil {
+			var assignmentErr *teacherAssignmentError
+			if errors.As(err, &assignmentErr) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": assignmentErr.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify teacher", "details": err.Error()})
+			return
+		}
+	}
+
 	if err := h.db.Create(&classroom).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create classroom"})
 		return
@@ -703,27 +1417,84 @@ func (h *ReportingHandler) RefreshAggregatedMetrics(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh metrics"})
 		return
 	}
+	h.metricsRefreshedAt.Store(time.Now().UnixNano())
 
 	c.JSON(http.StatusOK, gin.H{"message": "Metrics refreshed successfully"})
 }
 
+// maxContentRefreshDays bounds the date range of a manual content metrics refresh
+const maxContentRefreshDays = 366
+
+// RefreshContentMetrics - Admin endpoint to recompute content metrics for
+// content viewed or shared between date_from and date_to (inclusive)
+func (h *ReportingHandler) RefreshContentMetrics(c *gin.Context) {
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -(aggregationLookbackDays - 1), h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from := dateFrom.UTC().Truncate(24 * time.Hour)
+	to := dateTo.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+	if to.Sub(from) > maxContentRefreshDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("date range must be at most %d days", maxContentRefreshDays)})
+		return
+	}
+
+	refreshed, err := h.refreshContentMetrics(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh content metrics", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Content metrics refreshed successfully",
+		"period":            gin.H{"from": from.Format(DateFormat), "to": to.AddDate(0, 0, -1).Format(DateFormat)},
+		"content_refreshed": refreshed,
+	})
+}
+
 // GetActiveSessions - Real-time active sessions
 func (h *ReportingHandler) GetActiveSessions(c *gin.Context) {
 	schoolIDStr := c.Query("school_id")
-	var sessions []gin.H
+	p, err := parsePage(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	query := h.db.Table("active_sessions ass").
-		Select("ass.*, u.first_name, u.last_name, c.name as classroom_name").
-		Joins("JOIN users u ON ass.user_id = u.id").
-		Joins("LEFT JOIN classrooms c ON ass.classroom_id = c.id").
-		Where("ass.last_heartbeat > ?", time.Now().Add(-5*time.Minute))
+	active := func() *gorm.DB {
+		query := h.db.Table("active_sessions ass").
+			Joins("JOIN users u ON ass.user_id = u.id").
+			Where("ass.last_heartbeat > ?", time.Now().Add(-5*time.Minute))
+		if schoolIDStr != "" {
+			query = query.Where("u.school_id = ?", schoolIDStr)
+		}
+		return query
+	}
 
-	if schoolIDStr != "" {
-		query = query.Where("u.school_id = ?", schoolIDStr)
+	var total int64
+	if err := active().Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count active sessions", "details": err.Error()})
+		return
 	}
 
-	query.Scan(&sessions)
-	c.JSON(http.StatusOK, gin.H{"active_sessions": sessions})
+	sessions := []gin.H{}
+	err = active().
+		Select("ass.*, u.first_name, u.last_name, c.name as classroom_name").
+		Joins("LEFT JOIN classrooms c ON ass.classroom_id = c.id").
+		Order("ass.last_heartbeat DESC, ass.id").
+		Limit(p.Limit).
+		Offset(p.Offset).
+		Scan(&sessions).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch active sessions", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"active_sessions": sessions, "pagination": p.envelope(total)})
 }
 
 // GetEngagementTrends - Engagement trends over time
@@ -743,6 +1514,16 @@ func (h *ReportingHandler) GetEngagementTrends(c *gin.Context) {
 		days = 7
 	}
 
+	forecastDays := 0
+	if forecastStr := c.Query("forecast_days"); forecastStr != "" {
+		n, err := strconv.Atoi(forecastStr)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "forecast_days must be a non-negative integer"})
+			return
+		}
+		forecastDays = min(n, maxForecastDays)
+	}
+
 	dateFrom := time.Now().AddDate(0, 0, -days)
 
 	query := h.db.Table("daily_classroom_metrics").
@@ -756,13 +1537,22 @@ func (h *ReportingHandler) GetEngagementTrends(c *gin.Context) {
 			Where("c.school_id = ?", schoolIDStr)
 	}
 
-	var trends []gin.H
+	var trends []EngagementTrendPoint
 	query.Scan(&trends)
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"period": period,
-		"trends": trends,
-	})
+	}
+
+	if forecastDays > 0 {
+		// Too little history yields no projection rather than a wild one
+		projected, forecast := forecastEngagement(trends, forecastDays)
+		trends = append(trends, projected...)
+		response["forecast"] = forecast
+	}
+
+	response["trends"] = trends
+	c.JSON(http.StatusOK, response)
 }
 
 // GetQuizAnalytics - Detailed quiz analytics
@@ -785,53 +1575,53 @@ func (h *ReportingHandler) GetQuizAnalytics(c *gin.Context) {
 	c.JSON(http.StatusOK, analytics)
 }
 
-// parseDateRange parses date range from query parameters
-func (h *ReportingHandler) parseDateRange(dateFromStr, dateToStr string) (time.Time, time.Time, error) {
+// parseDateRange parses date range from query parameters as days in loc
+func (h *ReportingHandler) parseDateRange(dateFromStr, dateToStr string, loc *time.Location) (time.Time, time.Time, error) {
 	var dateFrom, dateTo time.Time
 	var err error
 
 	if dateFromStr != "" {
-		dateFrom, err = time.Parse(DateFormat, dateFromStr)
+		dateFrom, err = time.ParseInLocation(DateFormat, dateFromStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid date_from format (YYYY-MM-DD)")
 		}
 	} else {
-		dateFrom = time.Now().AddDate(0, -1, 0) // Default to last month
+		dateFrom = time.Now().In(loc).AddDate(0, -1, 0) // Default to last month
 	}
 
 	if dateToStr != "" {
-		dateTo, err = time.Parse(DateFormat, dateToStr)
+		dateTo, err = time.ParseInLocation(DateFormat, dateToStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid date_to format (YYYY-MM-DD)")
 		}
 	} else {
-		dateTo = time.Now()
+		dateTo = time.Now().In(loc)
 	}
 
 	return dateFrom, dateTo, nil
 }
 
-// parseDateRangeWithDefault parses date range with a default day offset
-func (h *ReportingHandler) parseDateRangeWithDefault(dateFromStr, dateToStr string, defaultDays int) (time.Time, time.Time, error) {
+// parseDateRangeWithDefault parses date range as days in loc with a default day offset
+func (h *ReportingHandler) parseDateRangeWithDefault(dateFromStr, dateToStr string, defaultDays int, loc *time.Location) (time.Time, time.Time, error) {
 	var dateFrom, dateTo time.Time
 	var err error
 
 	if dateFromStr != "" {
-		dateFrom, err = time.Parse(DateFormat, dateFromStr)
+		dateFrom, err = time.ParseInLocation(DateFormat, dateFromStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid date_from format (YYYY-MM-DD)")
 		}
 	} else {
-		dateFrom = time.Now().AddDate(0, 0, defaultDays)
+		dateFrom = time.Now().In(loc).AddDate(0, 0, defaultDays)
 	}
 
 	if dateToStr != "" {
-		dateTo, err = time.Parse(DateFormat, dateToStr)
+		dateTo, err = time.ParseInLocation(DateFormat, dateToStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid date_to format (YYYY-MM-DD)")
 		}
 	} else {
-		dateTo = time.Now()
+		dateTo = time.Now().In(loc)
 	}
 
 	return dateFrom, dateTo, nil
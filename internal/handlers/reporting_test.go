@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestContentEffectivenessResilientMode(t *testing.T) {
+	breakdown := []string{"FROM content c", `GROUP BY "c"."content_type"`}
+	mostEngaging := []string{"FROM content c", "LIMIT 10"}
+
+	t.Run("strict fails the report", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.fail(mostEngaging, errors.New("relation content_metrics is locked"))
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness", "", nil)
+		expectStatus(t, w, http.StatusInternalServerError)
+	})
+
+	t.Run("resilient returns the sections that succeeded", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		fake.rows(breakdown, []string{"content_type", "total_content", "metric_samples"},
+			[]driver.Value{"video", int64(4), int64(4)})
+		fake.fail(mostEngaging, errors.New("relation content_metrics is locked"))
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness?mode=resilient", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+
+		if body["partial"] != true {
+			t.Errorf("partial = %v, want true", body["partial"])
+		}
+		sectionErrors, _ := body["errors"].(map[string]interface{})
+		if _, ok := sectionErrors["most_engaging_content"]; !ok || len(sectionErrors) != 1 {
+			t.Errorf("errors = %v, want only most_engaging_content", body["errors"])
+		}
+		analytics := body["content_analytics"].(map[string]interface{})
+		if types, _ := analytics["content_type_breakdown"].([]interface{}); len(types) != 1 {
+			t.Errorf("content_type_breakdown = %v, want the one content type", analytics["content_type_breakdown"])
+		}
+		if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+			t.Errorf("Cache-Control = %q, want no-store", cc)
+		}
+	})
+
+	t.Run("resilient without failures is complete", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness?mode=resilient", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		if body["partial"] != false || body["errors"] != nil {
+			t.Errorf("partial = %v, errors = %v, want a complete report", body["partial"], body["errors"])
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness?mode=lenient", "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+}
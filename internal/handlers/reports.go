@@ -442,6 +442,9 @@ func (h *ReportHandler) GetContentEffectiveness(c *gin.Context) {
 		}
 	}
 
+	tags, matchAll := parseTagFilter(c)
+	query = applyTagFilter(query, "quizzes.tags", tags, matchAll)
+
 	var effectiveness []map[string]interface{}
 	if err := query.Scan(&effectiveness).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -457,6 +460,7 @@ func (h *ReportHandler) GetContentEffectiveness(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"content_type": contentType,
 		"time_period":  timePeriod,
+		"tags":         tags,
 		"period": gin.H{
 			"start_date": startDate.Format("2006-01-02"),
 			"end_date":   endDate.Format("2006-01-02"),
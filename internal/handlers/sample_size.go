@@ -0,0 +1,44 @@
+package handlers
+
+import "github.com/gin-gonic/gin"
+
+// DefaultMinSampleSize is the fewest data points an average needs before it is reported
+const DefaultMinSampleSize = 3
+
+// SetMinSampleSize configures the minimum sample behind reported averages.
+// Values below 1 disable the check.
+func (h *ReportingHandler) SetMinSampleSize(n int) {
+	h.minSampleSize = n
+}
+
+// sampleGuard nulls averages computed from too few data points and records
+// which fields were withheld so responses can flag them
+type sampleGuard struct {
+	min          int
+	insufficient []string
+}
+
+// thresholdsApplied lists the cutoffs behind a report response so clients can
+// explain withheld values and reproduce the numbers
+func (h *ReportingHandler) thresholdsApplied() gin.H {
+	return gin.H{"min_sample_size": h.minSampleSize}
+}
+
+func (h *ReportingHandler) newSampleGuard() *sampleGuard {
+	return &sampleGuard{min: h.minSampleSize, insufficient: []string{}}
+}
+
+// average returns avg unchanged when it is backed by at least the minimum
+// number of samples; otherwise it returns nil and flags the field
+func (g *sampleGuard) average(field string, avg *float64, samples int) *float64 {
+	if avg == nil || samples >= g.min {
+		return avg
+	}
+	g.insufficient = append(g.insufficient, field)
+	return nil
+}
+
+// flagged lists the fields withheld for insufficient samples
+func (g *sampleGuard) flagged() []string {
+	return g.insufficient
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSampleGuard(t *testing.T) {
+	h := NewReportingHandler(nil)
+	avg := 72.5
+
+	guard := h.newSampleGuard()
+	if got := guard.average("avg_score", &avg, DefaultMinSampleSize); got != &avg {
+		t.Errorf("average with enough samples = %v, want it unchanged", got)
+	}
+	if got := guard.average("avg_engagement", &avg, DefaultMinSampleSize-1); got != nil {
+		t.Errorf("average with too few samples = %v, want nil", *got)
+	}
+	if got := guard.average("avg_minutes", nil, 0); got != nil {
+		t.Errorf("missing average = %v, want nil", *got)
+	}
+	if flagged := guard.flagged(); !reflect.DeepEqual(flagged, []string{"avg_engagement"}) {
+		t.Errorf("flagged = %v, want only avg_engagement", flagged)
+	}
+
+	h.SetMinSampleSize(0)
+	if got := h.newSampleGuard().average("avg_score", &avg, 0); got != &avg {
+		t.Error("a minimum below 1 should disable the check")
+	}
+}
+
+func TestContentEffectivenessWithholdsSmallSamples(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM content c", `GROUP BY "c"."content_type"`},
+		[]string{"content_type", "total_content", "avg_views", "metric_samples"},
+		[]driver.Value{"video", int64(2), 40.0, int64(2)},
+		[]driver.Value{"quiz", int64(5), 12.0, int64(5)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	if thresholds := body["thresholds_applied"].(map[string]interface{}); thresholds["min_sample_size"] != float64(DefaultMinSampleSize) {
+		t.Errorf("thresholds_applied = %v", thresholds)
+	}
+	types := body["content_analytics"].(map[string]interface{})["content_type_breakdown"].([]interface{})
+	video, quiz := types[0].(map[string]interface{}), types[1].(map[string]interface{})
+	if video["avg_views"] != nil || !reflect.DeepEqual(video["insufficient_sample"], []interface{}{"avg_views"}) {
+		t.Errorf("video = %v, want avg_views withheld", video)
+	}
+	if quiz["avg_views"] != 12.0 || len(quiz["insufficient_sample"].([]interface{})) != 0 {
+		t.Errorf("quiz avg_views = %v, want 12", quiz["avg_views"])
+	}
+}
+
+func TestContentEffectivenessAppliesCustomMinSampleSize(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM content c", `GROUP BY "c"."content_type"`},
+		[]string{"content_type", "total_content", "avg_views", "metric_samples"},
+		[]driver.Value{"video", int64(2), 40.0, int64(2)})
+	h := NewReportingHandler(db)
+	h.SetMinSampleSize(2)
+
+	w := testRequest(h.GetContentEffectivenessReport, "/content-effectiveness", http.MethodGet, "/content-effectiveness", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	if thresholds := body["thresholds_applied"].(map[string]interface{}); thresholds["min_sample_size"] != 2.0 {
+		t.Errorf("thresholds_applied = %v, want the configured min_sample_size 2", thresholds)
+	}
+	video := body["content_analytics"].(map[string]interface{})["content_type_breakdown"].([]interface{})[0].(map[string]interface{})
+	if video["avg_views"] != 40.0 {
+		t.Errorf("video avg_views = %v, want 40 with the lower minimum", video["avg_views"])
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+// maxSavedQueryNameLength bounds saved query names, which appear in URLs
+const maxSavedQueryNameLength = 100
+
+// SaveQueryRequest is the body of POST /query/saved
+type SaveQueryRequest struct {
+	Name        string    `json:"name" binding:"required"`
+	Description *string   `json:"description"`
+	Query       cubeQuery `json:"query"`
+}
+
+// RunSavedQueryRequest optionally overrides a saved query's date range on
+// every time dimension, with a [from, to] pair or a relative range
+type RunSavedQueryRequest struct {
+	DateRange cubeDateRange `json:"dateRange"`
+}
+
+// SaveQuery stores a named query for the requesting user, replacing any
+// query they already saved under that name. The query must build.
+func (h *ReportingHandler) SaveQuery(c *gin.Context) {
+	userID, ok := savedQueryOwner(c)
+	if !ok {
+		return
+	}
+
+	var req SaveQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved query", "details": err.Error()})
+		return
+	}
+	if !validSavedQueryName(req.Name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1-100 letters, digits, '-', '_' or '.'"})
+		return
+	}
+
+	builder := h.queryBuilder()
+	if problems := ValidateQuery(req.Query, builder.GetSchema()); len(problems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid query", "details": problems})
+		return
+	}
+	if _, _, err := builder.buildSQL(req.Query, builder.GetSchema()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query", "details": err.Error()})
+		return
+	}
+
+	definition, err := savedQueryDefinition(req.Query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode query", "details": err.Error()})
+		return
+	}
+
+	saved := reporting.SavedQuery{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Query:       definition,
+	}
+	err = h.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "query", "updated_at"}),
+	}).Create(&saved).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save query", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// ListSavedQueries returns the requesting user's saved queries by name
+func (h *ReportingHandler) ListSavedQueries(c *gin.Context) {
+	userID, ok := savedQueryOwner(c)
+	if !ok {
+		return
+	}
+
+	queries := []reporting.SavedQuery{}
+	if err := h.db.Where("user_id = ?", userID).Order("name ASC").Find(&queries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list saved queries", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"queries": queries})
+}
+
+// RunSavedQuery executes one of the requesting user's saved queries. A
+// dateRange in the body replaces the range on every time dimension.
+func (h *ReportingHandler) RunSavedQuery(c *gin.Context) {
+	userID, ok := savedQueryOwner(c)
+	if !ok {
+		return
+	}
+
+	var overrides RunSavedQueryRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&overrides); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid overrides", "details": err.Error()})
+			return
+		}
+	}
+	if overrides.DateRange != nil {
+		if _, _, ok, err := resolveDateRange(overrides.DateRange, time.Now().UTC()); err != nil || !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dateRange must have a start and an end, or be a relative range such as \"last 7 days\""})
+			return
+		}
+	}
+
+	var saved reporting.SavedQuery
+	err := h.db.Where("user_id = ? AND name = ?", userID, c.Param("name")).First(&saved).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved query not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load saved query", "details": err.Error()})
+		return
+	}
+
+	var query cubeQuery
+	if data, err := json.Marshal(saved.Query); err == nil {
+		err = json.Unmarshal(data, &query)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode saved query", "details": err.Error()})
+		return
+	}
+	if overrides.DateRange != nil {
+		for i := range query.TimeDimensions {
+			query.TimeDimensions[i].DateRange = overrides.DateRange
+		}
+	}
+	// Queries saved before validation, or whose members have since been
+	// removed from the schema, are reported rather than run
+	builder := h.queryBuilder()
+	if problems := ValidateQuery(query, builder.GetSchema()); len(problems) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid saved query", "details": problems})
+		return
+	}
+
+	result, err := builder.ExecuteQuery(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to run saved query", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"name": saved.Name, "query": query, "result": result})
+}
+
+// savedQueryOwner returns the authenticated user, or answers 401 when there is none
+func savedQueryOwner(c *gin.Context) (uuid.UUID, bool) {
+	viewer := requestViewer(c)
+	if viewer.UserID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Saved queries require an authenticated user"})
+		return uuid.Nil, false
+	}
+	return *viewer.UserID, true
+}
+
+func validSavedQueryName(name string) bool {
+	if name == "" || len(name) > maxSavedQueryNameLength {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '-' || r == '_' || r == '.' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// savedQueryDefinition converts a query to its stored JSON form
+func savedQueryDefinition(query cubeQuery) (reporting.JSONB, error) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	var definition reporting.JSONB
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
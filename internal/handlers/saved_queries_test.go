@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var savedQueryColumns = []string{"id", "user_id", "name", "description", "query", "created_at", "updated_at"}
+
+func savedQueryRow(userID uuid.UUID, name, query string) []driver.Value {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	return []driver.Value{uuid.NewString(), userID.String(), name, nil, []byte(query), now, now}
+}
+
+func TestSaveAndListQueries(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	userID := uuid.New()
+	viewer := map[string]interface{}{"user_id": userID}
+
+	w := testRequest(h.SaveQuery, "/query/saved", http.MethodPost, "/query/saved",
+		`{"name":"weekly-views","query":{"measures":["events.count"],"dimensions":["events.type"]}}`, viewer)
+	expectStatus(t, w, http.StatusOK)
+	inserts := fake.ran(`INSERT INTO "saved_queries"`, "ON CONFLICT")
+	if len(inserts) != 1 {
+		t.Fatalf("ran %d upserts, want 1", len(inserts))
+	}
+	if args := inserts[0].Args; !argsContain(args, userID) || !argsContain(args, "weekly-views") {
+		t.Errorf("upsert args = %v, want the owner and name", args)
+	}
+
+	fake.rows([]string{`FROM "saved_queries"`, "user_id = $1", "ORDER BY name ASC"}, savedQueryColumns,
+		savedQueryRow(userID, "weekly-views", `{"measures":["events.count"],"dimensions":["events.type"]}`))
+	w = testRequest(h.ListSavedQueries, "/query/saved", http.MethodGet, "/query/saved", "", viewer)
+	expectStatus(t, w, http.StatusOK)
+	queries := decodeBody(t, w)["queries"].([]interface{})
+	if len(queries) != 1 || queries[0].(map[string]interface{})["name"] != "weekly-views" {
+		t.Fatalf("queries = %v, want weekly-views", queries)
+	}
+	if lists := fake.ran(`FROM "saved_queries"`); len(lists) != 1 || !argsContain(lists[0].Args, userID) {
+		t.Errorf("list statements = %v, want one scoped to the user", lists)
+	}
+}
+
+func TestSaveQueryRejects(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	viewer := map[string]interface{}{"user_id": uuid.New()}
+
+	tests := []struct {
+		name   string
+		body   string
+		values map[string]interface{}
+		want   int
+	}{
+		{"anonymous", `{"name":"a","query":{"measures":["events.count"]}}`, nil, http.StatusUnauthorized},
+		{"bad name", `{"name":"a/b","query":{"measures":["events.count"]}}`, viewer, http.StatusBadRequest},
+		{"unknown measure", `{"name":"a","query":{"measures":["events.bogus"]}}`, viewer, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := testRequest(h.SaveQuery, "/query/saved", http.MethodPost, "/query/saved", tt.body, tt.values)
+			expectStatus(t, w, tt.want)
+		})
+	}
+	if len(fake.ran()) != 0 {
+		t.Error("a rejected query reached the database")
+	}
+}
+
+func TestRunSavedQuery(t *testing.T) {
+	userID := uuid.New()
+	viewer := map[string]interface{}{"user_id": userID}
+	run := func(h *ReportingHandler, name, body string) *httptest.ResponseRecorder {
+		return testRequest(h.RunSavedQuery, "/query/saved/:name/run", http.MethodPost, "/query/saved/"+name+"/run", body, viewer)
+	}
+
+	t.Run("runs with a date range override", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+		fake.rows([]string{`FROM "saved_queries"`, "name = $2"}, savedQueryColumns, savedQueryRow(userID, "weekly-views",
+			`{"measures":["events.count"],"timeDimensions":[{"dimension":"time.date","granularity":"day","dateRange":["2025-01-01","2025-01-31"]}]}`))
+
+		expectStatus(t, run(h, "weekly-views", `{"dateRange":["2026-02-01","2026-02-28"]}`), http.StatusOK)
+		loads := fake.ran(`FROM "saved_queries"`)
+		if len(loads) != 1 || !argsContain(loads[0].Args, userID) || !argsContain(loads[0].Args, "weekly-views") {
+			t.Errorf("load statements = %v, want one by owner and name", loads)
+		}
+		selects := fake.ran("FROM events")
+		if len(selects) != 1 || !argsContain(selects[0].Args, "2026-02-01T00:00:00.000") || argsContain(selects[0].Args, "2025-01-01T00:00:00.000") {
+			t.Errorf("query statements = %v, want the overriding range bound", selects)
+		}
+	})
+
+	t.Run("re-validates the stored query", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+		fake.rows([]string{`FROM "saved_queries"`}, savedQueryColumns, savedQueryRow(userID, "stale", `{"measures":["events.removed"]}`))
+
+		w := run(h, "stale", "")
+		expectStatus(t, w, http.StatusUnprocessableEntity)
+		if !strings.Contains(w.Body.String(), "events.removed") {
+			t.Errorf("body = %s, want the removed measure named", w.Body)
+		}
+		if len(fake.ran("FROM events")) != 0 {
+			t.Error("an invalid saved query was run")
+		}
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		expectStatus(t, run(NewReportingHandler(db), "missing", ""), http.StatusNotFound)
+	})
+}
+
+// argsContain reports whether want is among a statement's bound arguments
+func argsContain(args []interface{}, want interface{}) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxComparedSchools bounds how many schools one comparison may include
+const maxComparedSchools = 20
+
+// SchoolComparison is one school's line in a school comparison
+type SchoolComparison struct {
+	Rank                 int       `json:"rank"`
+	SchoolID             uuid.UUID `json:"school_id"`
+	SchoolName           string    `json:"school_name"`
+	EngagementScore      *float64  `json:"engagement_score"`       // average daily classroom engagement
+	ActiveStudents       *int      `json:"active_students"`        // from the latest week in the period
+	AvgQuizScore         *float64  `json:"avg_quiz_score"`         // weighted by quiz sessions
+	PlatformAdoptionRate *float64  `json:"platform_adoption_rate"` // average over the period's weeks
+	ClassroomDays        int       `json:"classroom_days"`
+	QuizSessions         int       `json:"quiz_sessions"`
+	Weeks                int       `json:"weeks"`
+
+	InsufficientSample []string `json:"insufficient_sample"`
+}
+
+// GetSchoolComparison compares up to maxComparedSchools schools side by side
+// on engagement, active students, quiz scores and platform adoption, ranked by
+// engagement. Schools without enough engagement data are ranked last.
+func (h *ReportingHandler) GetSchoolComparison(c *gin.Context) {
+	var schoolIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, raw := range strings.Split(c.Query("school_ids"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid school_id %q", raw)})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			schoolIDs = append(schoolIDs, id)
+		}
+	}
+	if len(schoolIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_ids is required"})
+		return
+	}
+	if len(schoolIDs) > maxComparedSchools {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d schools can be compared", maxComparedSchools)})
+		return
+	}
+
+	// The schools may be in different time zones, so only ?tz= overrides the default
+	loc, err := h.requestLocation(c, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schools := []SchoolComparison{}
+	err = h.db.Table("schools s").
+		Select(`
+			s.id as school_id,
+			s.name as school_name,
+			d.engagement_score,
+			COALESCE(d.classroom_days, 0) as classroom_days,
+			d.avg_quiz_score,
+			COALESCE(d.quiz_sessions, 0) as quiz_sessions,
+			w.active_students,
+			w.platform_adoption_rate,
+			COALESCE(w.weeks, 0) as weeks
+		`).
+		Joins(`LEFT JOIN (
+			SELECT
+				school_id,
+				AVG(engagement_score) as engagement_score,
+				COUNT(*) as classroom_days,
+				SUM(avg_class_quiz_score * total_quiz_sessions) /
+					NULLIF(SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END), 0) as avg_quiz_score,
+				SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END) as quiz_sessions
+			FROM daily_classroom_metrics
+			WHERE school_id IN ? AND date BETWEEN ? AND ?
+			GROUP BY school_id
+		) d ON d.school_id = s.id`, schoolIDs, dateFrom, dateTo).
+		Joins(`LEFT JOIN (
+			SELECT
+				school_id,
+				(ARRAY_AGG(active_students ORDER BY week_start_date DESC))[1] as active_students,
+				AVG(platform_adoption_rate) as platform_adoption_rate,
+				COUNT(*) as weeks
+			FROM weekly_school_metrics
+			WHERE school_id IN ? AND week_start_date BETWEEN ? AND ?
+			GROUP BY school_id
+		) w ON w.school_id = s.id`, schoolIDs, dateFrom, dateTo).
+		Where("s.id IN ?", schoolIDs).
+		Scan(&schools).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare schools", "details": err.Error()})
+		return
+	}
+
+	found := make(map[uuid.UUID]bool, len(schools))
+	for i := range schools {
+		s := &schools[i]
+		found[s.SchoolID] = true
+		guard := h.newSampleGuard()
+		s.EngagementScore = guard.average("engagement_score", s.EngagementScore, s.ClassroomDays)
+		s.AvgQuizScore = guard.average("avg_quiz_score", s.AvgQuizScore, s.QuizSessions)
+		s.InsufficientSample = guard.flagged()
+		for _, v := range []*float64{s.EngagementScore, s.AvgQuizScore, s.PlatformAdoptionRate} {
+			if v != nil {
+				*v = roundTo(*v, 2)
+			}
+		}
+	}
+	missing := []uuid.UUID{}
+	for _, id := range schoolIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	rankSchools(schools)
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":             gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"schools":            schools,
+		"missing_school_ids": missing,
+		"thresholds_applied": h.thresholdsApplied(),
+	})
+}
+
+// rankSchools sorts schools by engagement (descending) and assigns ranks.
+// Schools without an engagement score are placed last and share the final
+// rank; ties share a rank, as in classroom rankings.
+func rankSchools(schools []SchoolComparison) {
+	sort.SliceStable(schools, func(i, j int) bool {
+		a, b := schools[i].EngagementScore, schools[j].EngagementScore
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if *a != *b {
+			return *a > *b
+		}
+		return schools[i].SchoolName < schools[j].SchoolName
+	})
+
+	for i := range schools {
+		switch {
+		case i == 0:
+			schools[i].Rank = 1
+		case sameMetric(schools[i].EngagementScore, schools[i-1].EngagementScore):
+			schools[i].Rank = schools[i-1].Rank
+		default:
+			schools[i].Rank = i + 1
+		}
+	}
+}
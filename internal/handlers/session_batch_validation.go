@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"reporting-framework/internal/domain/reporting"
+
+	"github.com/google/uuid"
+)
+
+// Session batch limits, checked before any row is written so a malformed
+// client can't hold a huge transaction open
+const (
+	maxSessionsPerBatch   = 100
+	maxEventsPerSession   = 1000
+	maxSessionDuration    = 24 * time.Hour
+	maxSessionBatchErrors = 20 // problems listed in a 400 response
+)
+
+// sessionBatchItem is one session of a session batch with its events
+type sessionBatchItem struct {
+	Application string                 `json:"application"`
+	StartTime   time.Time              `json:"start_time"`
+	EndTime     *time.Time             `json:"end_time"`
+	ClassroomID *uuid.UUID             `json:"classroom_id"`
+	DeviceInfo  map[string]interface{} `json:"device_info"`
+	Events      []reporting.EventData  `json:"events"`
+}
+
+// validateSessionBatch lists what is wrong with a session batch: too many
+// sessions or events, a missing start_time, an end_time before start_time or
+// a duration over maxSessionDuration, and events out of chronological order
+// or outside their session. Only the first maxSessionBatchErrors problems are
+// listed.
+func validateSessionBatch(sessions []sessionBatchItem) []string {
+	if len(sessions) > maxSessionsPerBatch {
+		return []string{fmt.Sprintf("batch has %d sessions, at most %d are allowed", len(sessions), maxSessionsPerBatch)}
+	}
+
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		if len(problems) < maxSessionBatchErrors {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	for i, session := range sessions {
+		if len(session.Events) > maxEventsPerSession {
+			add("sessions[%d]: has %d events, at most %d are allowed", i, len(session.Events), maxEventsPerSession)
+			continue
+		}
+		if session.StartTime.IsZero() {
+			add("sessions[%d]: start_time is required", i)
+			continue
+		}
+		if session.EndTime != nil {
+			duration := session.EndTime.Sub(session.StartTime)
+			if duration < 0 {
+				add("sessions[%d]: end_time %s is before start_time %s", i, session.EndTime.Format(time.RFC3339), session.StartTime.Format(time.RFC3339))
+				continue
+			}
+			if duration > maxSessionDuration {
+				add("sessions[%d]: duration %s exceeds %s", i, duration, maxSessionDuration)
+			}
+		}
+
+		for j, event := range session.Events {
+			switch {
+			case event.Timestamp.Before(session.StartTime):
+				add("sessions[%d].events[%d]: timestamp is before the session's start_time", i, j)
+			case session.EndTime != nil && event.Timestamp.After(*session.EndTime):
+				add("sessions[%d].events[%d]: timestamp is after the session's end_time", i, j)
+			case j > 0 && event.Timestamp.Before(session.Events[j-1].Timestamp):
+				add("sessions[%d].events[%d]: timestamp is earlier than the previous event's", i, j)
+			}
+		}
+	}
+	return problems
+}
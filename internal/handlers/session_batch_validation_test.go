@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+func TestValidateSessionBatch(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	end := func(minutes int) *time.Time { t := at(minutes); return &t }
+	events := func(minutes ...int) []reporting.EventData {
+		out := make([]reporting.EventData, len(minutes))
+		for i, m := range minutes {
+			out[i] = reporting.EventData{EventType: "click", Timestamp: at(m)}
+		}
+		return out
+	}
+
+	valid := []sessionBatchItem{
+		{StartTime: start, EndTime: end(30), Events: events(0, 10, 10, 30)},
+		{StartTime: start, Events: events(5, 600)},
+	}
+	if problems := validateSessionBatch(valid); problems != nil {
+		t.Errorf("valid batch: %v", problems)
+	}
+
+	problems := validateSessionBatch([]sessionBatchItem{
+		{Events: events(0)},
+		{StartTime: start, EndTime: end(-1)},
+		{StartTime: start, EndTime: end(25 * 60)},
+		{StartTime: start, EndTime: end(30), Events: events(-1, 5, 3, 31)},
+		{StartTime: start, Events: make([]reporting.EventData, maxEventsPerSession+1)},
+	})
+	want := []string{
+		"sessions[0]: start_time is required",
+		"sessions[1]: end_time 2024-03-04T08:59:00Z is before start_time 2024-03-04T09:00:00Z",
+		"sessions[2]: duration 25h0m0s exceeds 24h0m0s",
+		"sessions[3].events[0]: timestamp is before the session's start_time",
+		"sessions[3].events[2]: timestamp is earlier than the previous event's",
+		"sessions[3].events[3]: timestamp is after the session's end_time",
+		"sessions[4]: has 1001 events, at most 1000 are allowed",
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems =\n%q\nwant\n%q", problems, want)
+	}
+}
+
+func TestValidateSessionBatchLimits(t *testing.T) {
+	if problems := validateSessionBatch(make([]sessionBatchItem, maxSessionsPerBatch+1)); len(problems) != 1 {
+		t.Errorf("oversized batch problems = %v, want a single one", problems)
+	}
+	if problems := validateSessionBatch(make([]sessionBatchItem, maxSessionsPerBatch)); len(problems) != maxSessionBatchErrors {
+		t.Errorf("listed %d problems, want at most %d", len(problems), maxSessionBatchErrors)
+	}
+}
+
+func TestIngestSessionBatchRejectsBeforeWriting(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	body := `{"sessions":[{"application":"whiteboard","start_time":"2024-03-04T09:00:00Z","end_time":"2024-03-04T08:00:00Z"}]}`
+
+	w := testRequest(h.IngestSessionBatch, "/sessions/batch", http.MethodPost, "/sessions/batch", body, map[string]interface{}{"user_id": uuid.New()})
+	expectStatus(t, w, http.StatusBadRequest)
+	if details := decodeBody(t, w)["details"].([]interface{}); len(details) != 1 {
+		t.Errorf("details = %v, want the one problem", details)
+	}
+	if len(fake.ran()) != 0 {
+		t.Error("an invalid batch reached the database")
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// defaultDurationBuckets are the histogram boundaries, in minutes, used when
+// the request doesn't pass its own
+var defaultDurationBuckets = []int{5, 15, 30, 60}
+
+// maxDurationBuckets bounds the number of boundaries a request may pass
+const maxDurationBuckets = 20
+
+// DurationBucket counts the sessions whose duration falls in
+// [MinMinutes, MaxMinutes); a nil MaxMinutes leaves the bucket open-ended
+type DurationBucket struct {
+	Label      string `json:"label"`
+	MinMinutes int    `json:"min_minutes"`
+	MaxMinutes *int   `json:"max_minutes"`
+	Sessions   int    `json:"sessions"`
+}
+
+// GetSessionDurationHistogram counts sessions per duration bucket, with the
+// mean, median and p90 duration, so many short sessions can be told apart
+// from a few long ones. Sessions without a duration (still open, or closed
+// without one) are excluded and counted separately.
+func (h *ReportingHandler) GetSessionDurationHistogram(c *gin.Context) {
+	var schoolID *uuid.UUID
+	if schoolIDStr := c.Query("school_id"); schoolIDStr != "" {
+		id, err := uuid.Parse(schoolIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid school_id format"})
+			return
+		}
+		schoolID = &id
+	}
+
+	boundaries, err := parseDurationBuckets(c.Query("buckets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions := func() *gorm.DB {
+		query := h.db.Table("sessions s").
+			Where("s.start_time BETWEEN ? AND ?", dateFrom, dateTo)
+		if schoolID != nil {
+			query = query.Joins("JOIN users u ON u.id = s.user_id").Where("u.school_id = ?", *schoolID)
+		}
+		return query
+	}
+
+	var stats struct {
+		Sessions      int
+		Excluded      int
+		MeanMinutes   *float64
+		MedianMinutes *float64
+		P90Minutes    *float64
+	}
+	err = sessions().
+		Select(`
+			COUNT(s.duration_seconds) as sessions,
+			COUNT(*) - COUNT(s.duration_seconds) as excluded,
+			AVG(s.duration_seconds) / 60.0 as mean_minutes,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY s.duration_seconds) / 60.0 as median_minutes,
+			percentile_cont(0.9) WITHIN GROUP (ORDER BY s.duration_seconds) / 60.0 as p90_minutes
+		`).
+		Scan(&stats).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate session durations", "details": err.Error()})
+		return
+	}
+
+	// Bucket index per session: 0 below the first boundary, len(boundaries) at or above the last
+	var bucketExpr strings.Builder
+	args := make([]interface{}, 0, len(boundaries))
+	bucketExpr.WriteString("CASE")
+	for i, minutes := range boundaries {
+		fmt.Fprintf(&bucketExpr, " WHEN s.duration_seconds < ? THEN %d", i)
+		args = append(args, minutes*60)
+	}
+	fmt.Fprintf(&bucketExpr, " ELSE %d END", len(boundaries))
+
+	var counts []struct {
+		Bucket   int
+		Sessions int
+	}
+	err = sessions().
+		Select(bucketExpr.String()+" as bucket, COUNT(*) as sessions", args...).
+		Where("s.duration_seconds IS NOT NULL").
+		Group("bucket").
+		Scan(&counts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to bucket session durations", "details": err.Error()})
+		return
+	}
+
+	buckets := durationBuckets(boundaries)
+	for _, count := range counts {
+		buckets[count.Bucket].Sessions = count.Sessions
+	}
+	for _, v := range []*float64{stats.MeanMinutes, stats.MedianMinutes, stats.P90Minutes} {
+		if v != nil {
+			*v = roundTo(*v, 2)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"school_id":         schoolID,
+		"period":            gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"buckets":           buckets,
+		"sessions":          stats.Sessions,
+		"excluded_sessions": stats.Excluded,
+		"mean_minutes":      stats.MeanMinutes,
+		"median_minutes":    stats.MedianMinutes,
+		"p90_minutes":       stats.P90Minutes,
+	})
+}
+
+// parseDurationBuckets parses comma-separated, strictly increasing bucket
+// boundaries in whole minutes, falling back to defaultDurationBuckets
+func parseDurationBuckets(s string) ([]int, error) {
+	if strings.TrimSpace(s) == "" {
+		return defaultDurationBuckets, nil
+	}
+	var boundaries []int
+	for _, part := range strings.Split(s, ",") {
+		minutes, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || minutes < 1 {
+			return nil, fmt.Errorf("buckets must be positive whole minutes, got %q", part)
+		}
+		if len(boundaries) > 0 && minutes <= boundaries[len(boundaries)-1] {
+			return nil, fmt.Errorf("buckets must be strictly increasing")
+		}
+		boundaries = append(boundaries, minutes)
+	}
+	if len(boundaries) > maxDurationBuckets {
+		return nil, fmt.Errorf("at most %d bucket boundaries are allowed", maxDurationBuckets)
+	}
+	return boundaries, nil
+}
+
+// durationBuckets builds the empty buckets around the boundaries: one below
+// the first, one between each pair and an open-ended one from the last
+func durationBuckets(boundaries []int) []DurationBucket {
+	buckets := make([]DurationBucket, 0, len(boundaries)+1)
+	lower := 0
+	for _, upper := range boundaries {
+		buckets = append(buckets, DurationBucket{
+			Label:      fmt.Sprintf("%d-%d", lower, upper),
+			MinMinutes: lower,
+			MaxMinutes: intPtr(upper),
+		})
+		lower = upper
+	}
+	return append(buckets, DurationBucket{Label: fmt.Sprintf("%d+", lower), MinMinutes: lower})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestParseDurationBuckets(t *testing.T) {
+	if got, err := parseDurationBuckets(" "); err != nil || !slices.Equal(got, defaultDurationBuckets) {
+		t.Errorf("parseDurationBuckets(blank) = %v, %v, want the defaults", got, err)
+	}
+	if got, err := parseDurationBuckets("10, 20,45"); err != nil || !slices.Equal(got, []int{10, 20, 45}) {
+		t.Errorf("parseDurationBuckets = %v, %v, want [10 20 45]", got, err)
+	}
+	for _, input := range []string{"0,10", "10,x", "20,10", "10,10", "1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19,20,21"} {
+		if _, err := parseDurationBuckets(input); err == nil {
+			t.Errorf("parseDurationBuckets(%q) succeeded", input)
+		}
+	}
+}
+
+func TestDurationBuckets(t *testing.T) {
+	buckets := durationBuckets([]int{5, 15})
+	labels := make([]string, len(buckets))
+	for i, b := range buckets {
+		labels[i] = b.Label
+	}
+	if !slices.Equal(labels, []string{"0-5", "5-15", "15+"}) {
+		t.Errorf("labels = %v", labels)
+	}
+	if last := buckets[2]; last.MinMinutes != 15 || last.MaxMinutes != nil {
+		t.Errorf("last bucket = %+v, want open-ended from 15", last)
+	}
+}
+
+func TestSessionDurationHistogram(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{"FROM sessions s", "percentile_cont(0.9)"}, []string{"sessions", "excluded", "mean_minutes", "median_minutes", "p90_minutes"},
+		[]driver.Value{int64(9), int64(2), 18.3333, 12.0, 41.5})
+	fake.rows([]string{"FROM sessions s", "CASE WHEN"}, []string{"bucket", "sessions"},
+		[]driver.Value{int64(0), int64(4)},
+		[]driver.Value{int64(2), int64(5)})
+	h := NewReportingHandler(db)
+
+	w := testRequest(h.GetSessionDurationHistogram, "/analytics/session-durations", http.MethodGet, "/analytics/session-durations?buckets=10,20", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	if body["sessions"] != 9.0 || body["excluded_sessions"] != 2.0 || body["mean_minutes"] != 18.33 {
+		t.Errorf("summary = %v, want 9 sessions, 2 excluded and a rounded mean", body)
+	}
+	// The middle bucket had no sessions, so it stays at zero rather than vanishing
+	var counts []float64
+	for _, b := range body["buckets"].([]interface{}) {
+		counts = append(counts, b.(map[string]interface{})["sessions"].(float64))
+	}
+	if !slices.Equal(counts, []float64{4, 0, 5}) {
+		t.Errorf("bucket counts = %v, want [4 0 5]", counts)
+	}
+
+	ran := fake.ran("CASE WHEN")
+	if len(ran) != 1 || !argsContain(ran[0].Args, 600) || !argsContain(ran[0].Args, 1200) {
+		t.Errorf("bucket statements = %v, want boundaries bound in seconds", ran)
+	}
+}
+
+func TestSessionDurationHistogramRejectsBuckets(t *testing.T) {
+	fake, db := newFakeDB(t)
+	h := NewReportingHandler(db)
+	w := testRequest(h.GetSessionDurationHistogram, "/analytics/session-durations", http.MethodGet, "/analytics/session-durations?buckets=30,15", "", nil)
+	expectStatus(t, w, http.StatusBadRequest)
+	if len(fake.ran()) != 0 {
+		t.Error("invalid buckets reached the database")
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"math"
+	"sort"
+)
+
+// meanOf returns the arithmetic mean of values, or 0 for an empty slice
+func meanOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// medianOf returns the median of values without modifying the input slice
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// percentileRank returns the percentage of values below v, counting ties as half.
+// The result is in the range 0-100.
+func percentileRank(values []float64, v float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	below, equal := 0, 0
+	for _, x := range values {
+		if x < v {
+			below++
+		} else if x == v {
+			equal++
+		}
+	}
+	return (float64(below) + 0.5*float64(equal)) / float64(len(values)) * 100
+}
+
+// sampleVariance returns the unbiased (n-1) variance of values, or 0 when
+// fewer than two values are given
+func sampleVariance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := meanOf(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
+
+// percentileOf returns the p-th percentile (0-100) of values using linear
+// interpolation between closest ranks, or 0 for an empty slice
+func percentileOf(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// linearFit is an ordinary least squares fit of y = Intercept + Slope*x
+type linearFit struct {
+	Intercept float64
+	Slope     float64
+	// ResidualStdErr is sqrt(SSE / (n-2)), the spread of points around the line
+	ResidualStdErr float64
+	N              int
+	MeanX          float64
+	Sxx            float64
+}
+
+// fitLinear fits a least squares line through the points. It returns false when
+// there are fewer than three points or all x values are equal.
+func fitLinear(xs, ys []float64) (linearFit, bool) {
+	n := len(xs)
+	if n < 3 || n != len(ys) {
+		return linearFit{}, false
+	}
+	meanX, meanY := meanOf(xs), meanOf(ys)
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == 0 {
+		return linearFit{}, false
+	}
+
+	fit := linearFit{Slope: sxy / sxx, N: n, MeanX: meanX, Sxx: sxx}
+	fit.Intercept = meanY - fit.Slope*meanX
+
+	var sse float64
+	for i := range xs {
+		r := ys[i] - fit.predict(xs[i])
+		sse += r * r
+	}
+	fit.ResidualStdErr = math.Sqrt(sse / float64(n-2))
+	return fit, true
+}
+
+func (f linearFit) predict(x float64) float64 {
+	return f.Intercept + f.Slope*x
+}
+
+// predictionMargin is the half-width of the prediction interval at x for the
+// given z value (1.96 for ~95%)
+func (f linearFit) predictionMargin(x, z float64) float64 {
+	return z * f.ResidualStdErr * math.Sqrt(1+1/float64(f.N)+(x-f.MeanX)*(x-f.MeanX)/f.Sxx)
+}
+
+// pearsonCorrelation returns the correlation of xs and ys. It returns false
+// when fewer than two pairs are given or either series has no variance.
+func pearsonCorrelation(xs, ys []float64) (float64, bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, false
+	}
+	mx, my := meanOf(xs), meanOf(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0, false
+	}
+	return sxy / math.Sqrt(sxx*syy), true
+}
+
+// giniCoefficient returns the Gini coefficient of non-negative values: 0 when
+// every value is equal, approaching 1 as the total concentrates in one value.
+// It returns false for an empty slice or an all-zero total.
+func giniCoefficient(values []float64) (float64, bool) {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := float64(len(sorted))
+	var total, weighted float64
+	for i, v := range sorted {
+		total += v
+		weighted += (2*float64(i+1) - n - 1) * v
+	}
+	if n == 0 || total == 0 {
+		return 0, false
+	}
+	return weighted / (n * total), true
+}
+
+// LorenzPoint is the share of the total held by the lowest share of the population
+type LorenzPoint struct {
+	PopulationShare float64 `json:"population_share"`
+	ValueShare      float64 `json:"value_share"`
+}
+
+// lorenzCurve returns the Lorenz curve of values, from (0, 0) to (1, 1) with
+// one point per value in ascending order. An all-zero total is drawn as the
+// line of equality.
+func lorenzCurve(values []float64) []LorenzPoint {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, v := range sorted {
+		total += v
+	}
+
+	points := make([]LorenzPoint, 0, len(sorted)+1)
+	points = append(points, LorenzPoint{})
+	cumulative := 0.0
+	for i, v := range sorted {
+		cumulative += v
+		populationShare := float64(i+1) / float64(len(sorted))
+		valueShare := populationShare
+		if total > 0 {
+			valueShare = cumulative / total
+		}
+		points = append(points, LorenzPoint{PopulationShare: roundTo(populationShare, 4), ValueShare: roundTo(valueShare, 4)})
+	}
+	return points
+}
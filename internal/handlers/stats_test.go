@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMeanAndMedian(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	if got := meanOf(values); got != 2.5 {
+		t.Errorf("meanOf = %v, want 2.5", got)
+	}
+	if got := medianOf(values); got != 2.5 {
+		t.Errorf("medianOf = %v, want 2.5", got)
+	}
+	if values[0] != 4 {
+		t.Errorf("medianOf reordered its input: %v", values)
+	}
+	if got := medianOf([]float64{5, 1, 3}); got != 3 {
+		t.Errorf("medianOf odd = %v, want 3", got)
+	}
+	if meanOf(nil) != 0 || medianOf(nil) != 0 {
+		t.Error("empty input should give 0")
+	}
+}
+
+func TestPercentileRank(t *testing.T) {
+	values := []float64{10, 20, 20, 30}
+	tests := []struct {
+		v    float64
+		want float64
+	}{
+		{5, 0},
+		{10, 12.5},
+		{20, 50},
+		{35, 100},
+	}
+	for _, tt := range tests {
+		if got := percentileRank(values, tt.v); got != tt.want {
+			t.Errorf("percentileRank(%v) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestFitLinear(t *testing.T) {
+	fit, ok := fitLinear([]float64{0, 1, 2, 3}, []float64{1, 3, 5, 7})
+	if !ok || fit.Slope != 2 || fit.Intercept != 1 || fit.ResidualStdErr != 0 {
+		t.Errorf("fitLinear = %+v, %v, want y = 2x + 1 with no residual", fit, ok)
+	}
+	if got := fit.predict(10); got != 21 {
+		t.Errorf("predict(10) = %v, want 21", got)
+	}
+
+	if _, ok := fitLinear([]float64{0, 1}, []float64{1, 3}); ok {
+		t.Error("expected no fit through two points")
+	}
+	if _, ok := fitLinear([]float64{2, 2, 2}, []float64{1, 2, 3}); ok {
+		t.Error("expected no fit when every x is equal")
+	}
+}
+
+func TestGiniCoefficient(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"all equal", []float64{5, 5, 5, 5}, 0},
+		{"one has everything", []float64{0, 0, 0, 0, 12}, 0.8},
+		// Mean absolute difference 20/16 over twice the mean 2.5
+		{"mixed", []float64{3, 1, 4, 2}, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := giniCoefficient(tt.values)
+			if !ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("giniCoefficient(%v) = %v, %v, want %v", tt.values, got, ok, tt.want)
+			}
+		})
+	}
+
+	for _, values := range [][]float64{nil, {0, 0, 0}} {
+		if _, ok := giniCoefficient(values); ok {
+			t.Errorf("giniCoefficient(%v) is defined, want undefined with no activity", values)
+		}
+	}
+}
+
+func TestLorenzCurve(t *testing.T) {
+	got := lorenzCurve([]float64{3, 1, 4, 2})
+	want := []LorenzPoint{{0, 0}, {0.25, 0.1}, {0.5, 0.3}, {0.75, 0.6}, {1, 1}}
+	if len(got) != len(want) {
+		t.Fatalf("lorenzCurve = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	for _, point := range lorenzCurve([]float64{0, 0}) {
+		if point.ValueShare != point.PopulationShare {
+			t.Errorf("all-zero curve point %+v is off the line of equality", point)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Trailing windows, in days and including the as-of date, for stickiness metrics
+const (
+	weeklyActiveDays  = 7
+	monthlyActiveDays = 30
+)
+
+// StickinessMetrics reports distinct active users over trailing windows ending on a date
+type StickinessMetrics struct {
+	SchoolID    uuid.UUID `json:"school_id"`
+	Date        string    `json:"date"`
+	DAU         int       `json:"dau"`
+	WAU         int       `json:"wau"`
+	MAU         int       `json:"mau"`
+	DAUMAURatio *float64  `json:"dau_mau_ratio"` // null when there were no monthly active users
+}
+
+// GetStickiness returns DAU, WAU, MAU and the DAU/MAU ratio for a school as of
+// `date` (default today). A user is active on a day when they generated at
+// least one event, as for the events.unique_users measures.
+func (h *ReportingHandler) GetStickiness(c *gin.Context) {
+	schoolID, err := uuid.Parse(c.Query("school_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "school_id is required and must be a valid UUID"})
+		return
+	}
+
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	if dateParam := c.Query("date"); dateParam != "" {
+		asOf, err = time.Parse(DateFormat, dateParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	end := asOf.AddDate(0, 0, 1)
+	weekStart := end.AddDate(0, 0, -weeklyActiveDays)
+	monthStart := end.AddDate(0, 0, -monthlyActiveDays)
+
+	var counts struct {
+		DAU int
+		WAU int
+		MAU int
+	}
+	err = h.db.Table("events").
+		Select(`
+			COUNT(DISTINCT user_id) FILTER (WHERE timestamp >= ?) as dau,
+			COUNT(DISTINCT user_id) FILTER (WHERE timestamp >= ?) as wau,
+			COUNT(DISTINCT user_id) as mau
+		`, asOf, weekStart).
+		Where("school_id = ? AND user_id IS NOT NULL", schoolID).
+		Where("timestamp >= ? AND timestamp < ?", monthStart, end).
+		Scan(&counts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate stickiness", "details": err.Error()})
+		return
+	}
+
+	metrics := StickinessMetrics{
+		SchoolID: schoolID,
+		Date:     asOf.Format(DateFormat),
+		DAU:      counts.DAU,
+		WAU:      counts.WAU,
+		MAU:      counts.MAU,
+	}
+	if counts.MAU > 0 {
+		ratio := roundTo(float64(counts.DAU)/float64(counts.MAU), 4)
+		metrics.DAUMAURatio = &ratio
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStickiness(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "events"`, "as dau"}, []string{"dau", "wau", "mau"},
+		[]driver.Value{int64(12), int64(30), int64(48)}).times(1)
+	h := NewReportingHandler(db)
+	schoolID := uuid.New()
+
+	w := testRequest(h.GetStickiness, "/analytics/stickiness", http.MethodGet, "/analytics/stickiness?school_id="+schoolID.String()+"&date=2024-03-31", "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	if body["dau"] != 12.0 || body["wau"] != 30.0 || body["mau"] != 48.0 || body["dau_mau_ratio"] != 0.25 || body["date"] != "2024-03-31" {
+		t.Errorf("body = %v, want 12/30/48 with ratio 0.25 on 2024-03-31", body)
+	}
+
+	// The day, trailing week and trailing month all end at the close of the as-of date
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	args := fake.ran(`FROM "events"`)[0].Args
+	want := []interface{}{day(31), day(25), schoolID, day(2), day(31).AddDate(0, 0, 1)}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("args[%d] = %v, want %v", i, args[i], want[i])
+		}
+	}
+
+	w = testRequest(h.GetStickiness, "/analytics/stickiness", http.MethodGet, "/analytics/stickiness?school_id="+schoolID.String(), "", nil)
+	expectStatus(t, w, http.StatusOK)
+	if body := decodeBody(t, w); body["mau"] != 0.0 || body["dau_mau_ratio"] != nil {
+		t.Errorf("body = %v, want a null ratio without monthly active users", body)
+	}
+
+	for _, target := range []string{"/analytics/stickiness", "/analytics/stickiness?school_id=" + schoolID.String() + "&date=31-03-2024"} {
+		expectStatus(t, testRequest(h.GetStickiness, "/analytics/stickiness", http.MethodGet, target, "", nil), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"reporting-framework/internal/domain/reporting"
+)
+
+const (
+	// defaultActivityLimit is the page size when no limit is given
+	defaultActivityLimit = 100
+	// maxActivityLimit caps the page size
+	maxActivityLimit = 500
+)
+
+// ActivityEvent is one event in a student's activity timeline with its
+// session and classroom context
+type ActivityEvent struct {
+	ID               uuid.UUID       `json:"id"`
+	EventType        string          `json:"event_type"`
+	Timestamp        time.Time       `json:"timestamp"`
+	Application      *string         `json:"application"`
+	SessionID        *uuid.UUID      `json:"session_id"`
+	SessionStartedAt *time.Time      `json:"session_started_at"`
+	ClassroomID      *uuid.UUID      `json:"classroom_id"`
+	ClassroomName    *string         `json:"classroom_name"`
+	Metadata         reporting.JSONB `json:"metadata"`
+}
+
+// GetStudentActivity returns a student's events in chronological order,
+// optionally filtered by comma-separated event types and an application.
+// Pages are keyset-paginated: pass next_cursor back as cursor to continue.
+// Students may only read their own timeline.
+func (h *ReportingHandler) GetStudentActivity(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's activity") {
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -7, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultActivityLimit)))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+		return
+	}
+	limit = min(limit, maxActivityLimit)
+
+	var types []string
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	application := c.Query("application")
+
+	// Filters shared by the page and the type counts
+	filtered := func() *gorm.DB {
+		query := h.db.Table("events e").
+			Where("e.user_id = ? AND e.timestamp BETWEEN ? AND ?", studentID, dateFrom, dateTo)
+		if len(types) > 0 {
+			query = query.Where("e.event_type IN ?", types)
+		}
+		if application != "" {
+			query = query.Where("e.application = ?", application)
+		}
+		return query
+	}
+
+	page := filtered().
+		Select(`
+			e.id, e.event_type, e.timestamp, e.application, e.session_id,
+			s.start_time as session_started_at, e.classroom_id,
+			cl.name as classroom_name, e.metadata
+		`).
+		Joins("LEFT JOIN sessions s ON s.id = e.session_id").
+		Joins("LEFT JOIN classrooms cl ON cl.id = e.classroom_id")
+	if cursor := c.Query("cursor"); cursor != "" {
+		afterTime, afterID, err := decodeActivityCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		page = page.Where("(e.timestamp, e.id) > (?, ?)", afterTime, afterID)
+	}
+
+	events := []ActivityEvent{}
+	if err := page.Order("e.timestamp ASC, e.id ASC").Limit(limit + 1).Scan(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity", "details": err.Error()})
+		return
+	}
+
+	var nextCursor *string
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[len(events)-1]
+		cursor := encodeActivityCursor(last.Timestamp, last.ID)
+		nextCursor = &cursor
+	}
+
+	var typeCounts []struct {
+		EventType string
+		Count     int
+	}
+	if err := filtered().Select("e.event_type, COUNT(*) as count").Group("e.event_type").Scan(&typeCounts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count activity", "details": err.Error()})
+		return
+	}
+	countsByType := make(map[string]int, len(typeCounts))
+	total := 0
+	for _, tc := range typeCounts {
+		countsByType[tc.EventType] = tc.Count
+		total += tc.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id": studentID,
+		"period":     gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"events":     events,
+		"meta": gin.H{
+			"total_events":      total,
+			"event_type_counts": countsByType,
+			"limit":             limit,
+			"next_cursor":       nextCursor,
+		},
+	})
+}
+
+// encodeActivityCursor encodes the position after an event as an opaque token
+func encodeActivityCursor(timestamp time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(timestamp.UTC().Format(time.RFC3339Nano) + "|" + id.String()))
+}
+
+// decodeActivityCursor reverses encodeActivityCursor
+func decodeActivityCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, fmt.Errorf("malformed cursor")
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	eventID, err := uuid.Parse(id)
+	if err != nil {
+		return time.Time{}, uuid.Nil, err
+	}
+	return timestamp, eventID, nil
+}
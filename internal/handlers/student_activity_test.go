@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestActivityCursorRoundTrip(t *testing.T) {
+	timestamp := time.Date(2024, 3, 4, 10, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	id := uuid.New()
+	gotTime, gotID, err := decodeActivityCursor(encodeActivityCursor(timestamp, id))
+	if err != nil || !gotTime.Equal(timestamp) || gotID != id {
+		t.Errorf("round trip = %v, %v, %v, want %v, %v", gotTime, gotID, err, timestamp, id)
+	}
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", encodeActivityCursor(timestamp, id)[:10]} {
+		if _, _, err := decodeActivityCursor(cursor); err == nil {
+			t.Errorf("decodeActivityCursor(%q) succeeded", cursor)
+		}
+	}
+}
+
+func TestStudentActivity(t *testing.T) {
+	fake, db := newFakeDB(t)
+	studentID := uuid.New()
+	at := func(minute int) time.Time { return time.Date(2024, 3, 4, 9, minute, 0, 0, time.UTC) }
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	columns := []string{"id", "event_type", "timestamp", "application", "session_id", "session_started_at", "classroom_id", "classroom_name", "metadata"}
+	fake.rows([]string{"LEFT JOIN sessions s ON s.id = e.session_id"}, columns,
+		[]driver.Value{ids[0].String(), "page_view", at(0), "notebook", nil, nil, nil, nil, []byte(`{"page":"home"}`)},
+		[]driver.Value{ids[1].String(), "quiz_started", at(5), "notebook", nil, nil, nil, "Algebra", nil},
+		[]driver.Value{ids[2].String(), "page_view", at(9), "notebook", nil, nil, nil, nil, nil}).times(1)
+	fake.rows([]string{`GROUP BY "e"."event_type"`}, []string{"event_type", "count"},
+		[]driver.Value{"page_view", int64(7)},
+		[]driver.Value{"quiz_started", int64(2)})
+	h := NewReportingHandler(db)
+	target := "/students/" + studentID.String() + "/activity?date_from=2024-03-01&date_to=2024-03-07&limit=2&types=page_view,%20quiz_started&application=notebook"
+	owner := map[string]interface{}{"user_id": studentID, "user_role": "student"}
+
+	w := testRequest(h.GetStudentActivity, "/students/:id/activity", http.MethodGet, target, "", owner)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+	events := body["events"].([]interface{})
+	if len(events) != 2 || events[1].(map[string]interface{})["classroom_name"] != "Algebra" {
+		t.Fatalf("events = %v, want the first page of 2", events)
+	}
+	meta := body["meta"].(map[string]interface{})
+	if meta["total_events"] != 9.0 || meta["limit"] != 2.0 {
+		t.Errorf("meta = %v, want 9 events in total and limit 2", meta)
+	}
+	cursor, _ := meta["next_cursor"].(string)
+	if afterTime, afterID, err := decodeActivityCursor(cursor); err != nil || !afterTime.Equal(at(5)) || afterID != ids[1] {
+		t.Errorf("next_cursor decodes to %v, %v, %v, want the last event on the page", afterTime, afterID, err)
+	}
+	page := fake.ran("LEFT JOIN sessions s ON s.id = e.session_id")[0]
+	if !containsAll(page.SQL, []string{"e.event_type IN ($4,$5)", "e.application = $6", "ORDER BY e.timestamp ASC, e.id ASC LIMIT 3"}) {
+		t.Errorf("page query = %s, want the type and application filters and one extra row", page.SQL)
+	}
+
+	// The next page starts after the cursor and, being short, ends the timeline
+	w = testRequest(h.GetStudentActivity, "/students/:id/activity", http.MethodGet, target+"&cursor="+cursor, "", owner)
+	expectStatus(t, w, http.StatusOK)
+	next := fake.ran("LEFT JOIN sessions s ON s.id = e.session_id")[1]
+	if !strings.Contains(next.SQL, "(e.timestamp, e.id) > ($7, $8)") || next.Args[7] != ids[1] {
+		t.Errorf("next page query = %s %v, want it keyed after the cursor", next.SQL, next.Args)
+	}
+	if meta := decodeBody(t, w)["meta"].(map[string]interface{}); meta["next_cursor"] != nil {
+		t.Errorf("next_cursor = %v on the last page, want null", meta["next_cursor"])
+	}
+
+	other := map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}
+	expectStatus(t, testRequest(h.GetStudentActivity, "/students/:id/activity", http.MethodGet, target, "", other), http.StatusForbidden)
+	expectStatus(t, testRequest(h.GetStudentActivity, "/students/:id/activity", http.MethodGet, target+"&cursor=junk", "", owner), http.StatusBadRequest)
+}
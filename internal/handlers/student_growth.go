@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StudentGrowthPoint is one period of a student's longitudinal series
+type StudentGrowthPoint struct {
+	PeriodStart        string      `json:"period_start"`
+	AvgQuizScore       *float64    `json:"avg_quiz_score"`
+	EngagementScore    float64     `json:"engagement_score"`
+	ActiveDays         int         `json:"active_days"`
+	QuizCompletions    int         `json:"quiz_completions"`
+	ClassroomIDs       []uuid.UUID `json:"classroom_ids"` // classrooms with sessions in the period
+	AvgQuizScoreDelta  *float64    `json:"avg_quiz_score_delta"`
+	EngagementDelta    *float64    `json:"engagement_delta"`
+	ActiveDaysDelta    *int        `json:"active_days_delta"`
+	InsufficientSample []string    `json:"insufficient_sample"`
+}
+
+// growthPeriodMetrics is one period of aggregated daily_user_metrics
+type growthPeriodMetrics struct {
+	Period          time.Time
+	AvgQuizScore    *float64
+	QuizCompletions int
+	ActiveDays      int
+	TotalMinutes    float64
+}
+
+// GetStudentGrowth returns a per-period series of a student's quiz average,
+// engagement and active days with period-over-period deltas. Metrics are per
+// student, so the series stays continuous when the student changes classrooms.
+func (h *ReportingHandler) GetStudentGrowth(c *gin.Context) {
+	studentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, studentID, h.seesAllStudents, "Not allowed to view this student's growth") {
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "month")
+	if granularity != "week" && granularity != "month" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be week or month"})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -365, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if dateTo.Before(dateFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to must not be before date_from"})
+		return
+	}
+
+	// Quiz averages are weighted by completions so busy days count for more
+	var metrics []growthPeriodMetrics
+	err = h.db.Table("daily_user_metrics").
+		Select(`
+			DATE_TRUNC(?, date) as period,
+			SUM(avg_quiz_score * quiz_completions) /
+				NULLIF(SUM(CASE WHEN avg_quiz_score IS NOT NULL THEN quiz_completions ELSE 0 END), 0) as avg_quiz_score,
+			COALESCE(SUM(CASE WHEN avg_quiz_score IS NOT NULL THEN quiz_completions ELSE 0 END), 0) as quiz_completions,
+			COUNT(*) as active_days,
+			COALESCE(SUM(total_session_duration_seconds), 0) / 60.0 as total_minutes
+		`, granularity).
+		Where("user_id = ? AND date BETWEEN ? AND ?", studentID, dateFrom, dateTo).
+		Group("period").
+		Scan(&metrics).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch growth data", "details": err.Error()})
+		return
+	}
+
+	var classrooms []struct {
+		Period      time.Time
+		ClassroomID uuid.UUID
+	}
+	err = h.db.Table("sessions").
+		Select("DISTINCT DATE_TRUNC(?, start_time) as period, classroom_id", granularity).
+		Where("user_id = ? AND classroom_id IS NOT NULL", studentID).
+		Where("start_time >= ? AND start_time < ?", dateFrom, dateTo.AddDate(0, 0, 1)).
+		Scan(&classrooms).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch classroom history", "details": err.Error()})
+		return
+	}
+
+	byPeriod := make(map[string]growthPeriodMetrics, len(metrics))
+	for _, m := range metrics {
+		byPeriod[m.Period.Format(DateFormat)] = m
+	}
+	classroomsByPeriod := make(map[string][]uuid.UUID)
+	for _, cl := range classrooms {
+		key := cl.Period.Format(DateFormat)
+		classroomsByPeriod[key] = append(classroomsByPeriod[key], cl.ClassroomID)
+	}
+
+	series := h.buildGrowthSeries(granularity, dateFrom, dateTo, byPeriod, classroomsByPeriod)
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":  studentID,
+		"granularity": granularity,
+		"period":      gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"series":      series,
+	})
+}
+
+// buildGrowthSeries walks every period between dateFrom and dateTo, filling
+// periods without activity with zeros, and computes deltas against the
+// previous period. Engagement uses only the days of each period inside the range.
+func (h *ReportingHandler) buildGrowthSeries(granularity string, dateFrom, dateTo time.Time, byPeriod map[string]growthPeriodMetrics, classroomsByPeriod map[string][]uuid.UUID) []StudentGrowthPoint {
+	series := []StudentGrowthPoint{}
+	rangeEnd := dateTo.AddDate(0, 0, 1)
+
+	for start := truncateToPeriod(dateFrom, granularity); start.Before(rangeEnd); start = nextPeriod(start, granularity) {
+		key := start.Format(DateFormat)
+		m := byPeriod[key]
+
+		// Clip the period to the requested range for the consistency part of engagement
+		periodStart, periodEnd := start, nextPeriod(start, granularity)
+		if periodStart.Before(dateFrom) {
+			periodStart = dateFrom
+		}
+		if periodEnd.After(rangeEnd) {
+			periodEnd = rangeEnd
+		}
+		days := periodEnd.Sub(periodStart).Hours() / 24
+
+		avgDailyMinutes := 0.0
+		if m.ActiveDays > 0 {
+			avgDailyMinutes = m.TotalMinutes / float64(m.ActiveDays)
+		}
+
+		guard := h.newSampleGuard()
+		point := StudentGrowthPoint{
+			PeriodStart:     key,
+			AvgQuizScore:    guard.average("avg_quiz_score", m.AvgQuizScore, m.QuizCompletions),
+			EngagementScore: roundTo(h.calculateEngagementScore(avgDailyMinutes, m.ActiveDays, days), 2),
+			ActiveDays:      m.ActiveDays,
+			QuizCompletions: m.QuizCompletions,
+			ClassroomIDs:    classroomsByPeriod[key],
+		}
+		point.InsufficientSample = guard.flagged()
+		if point.ClassroomIDs == nil {
+			point.ClassroomIDs = []uuid.UUID{}
+		}
+
+		if len(series) > 0 {
+			prev := series[len(series)-1]
+			if point.AvgQuizScore != nil && prev.AvgQuizScore != nil {
+				delta := roundTo(*point.AvgQuizScore-*prev.AvgQuizScore, 2)
+				point.AvgQuizScoreDelta = &delta
+			}
+			engagementDelta := roundTo(point.EngagementScore-prev.EngagementScore, 2)
+			point.EngagementDelta = &engagementDelta
+			activeDelta := point.ActiveDays - prev.ActiveDays
+			point.ActiveDaysDelta = &activeDelta
+		}
+
+		series = append(series, point)
+	}
+
+	return series
+}
+
+// truncateToPeriod returns the start of the week (Monday, as DATE_TRUNC) or month containing t
+func truncateToPeriod(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if granularity == "week" {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// nextPeriod returns the start of the period after start
+func nextPeriod(start time.Time, granularity string) time.Time {
+	if granularity == "week" {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
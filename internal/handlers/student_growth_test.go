@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGetStudentGrowth(t *testing.T) {
+	studentID := uuid.New()
+	firstClassroom, secondClassroom := uuid.New(), uuid.New()
+	month := func(m time.Month) time.Time { return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC) }
+	route := "/students/:id/growth"
+	target := "/students/" + studentID.String() + "/growth?date_from=2024-01-01&date_to=2024-04-30"
+
+	t.Run("monthly series with gaps and deltas", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		// No activity in March; the student moved classrooms in February
+		fake.rows([]string{"daily_user_metrics", "DATE_TRUNC"},
+			[]string{"period", "avg_quiz_score", "quiz_completions", "active_days", "total_minutes"},
+			[]driver.Value{month(time.January), 80.0, int64(5), int64(10), 300.0},
+			[]driver.Value{month(time.February), 86.5, int64(4), int64(12), 480.0},
+			[]driver.Value{month(time.April), 90.0, int64(6), int64(8), 160.0})
+		fake.rows([]string{"DISTINCT DATE_TRUNC", "sessions"}, []string{"period", "classroom_id"},
+			[]driver.Value{month(time.January), firstClassroom.String()},
+			[]driver.Value{month(time.February), firstClassroom.String()},
+			[]driver.Value{month(time.February), secondClassroom.String()},
+			[]driver.Value{month(time.April), secondClassroom.String()})
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentGrowth, route, http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		series := decodeBody(t, w)["series"].([]interface{})
+		if len(series) != 4 {
+			t.Fatalf("series has %d points, want January through April", len(series))
+		}
+		point := func(i int) map[string]interface{} { return series[i].(map[string]interface{}) }
+
+		jan, feb, mar, apr := point(0), point(1), point(2), point(3)
+		for i, want := range []string{"2024-01-01", "2024-02-01", "2024-03-01", "2024-04-01"} {
+			if point(i)["period_start"] != want {
+				t.Errorf("series[%d] starts %v, want %s", i, point(i)["period_start"], want)
+			}
+		}
+		if jan["avg_quiz_score_delta"] != nil || jan["engagement_delta"] != nil || jan["active_days_delta"] != nil {
+			t.Errorf("first point = %v, want no deltas", jan)
+		}
+		if feb["avg_quiz_score_delta"] != 6.5 || feb["active_days_delta"] != 2.0 {
+			t.Errorf("February deltas = %v, %v, want 6.5 and 2", feb["avg_quiz_score_delta"], feb["active_days_delta"])
+		}
+		if len(feb["classroom_ids"].([]interface{})) != 2 {
+			t.Errorf("February classrooms = %v, want both", feb["classroom_ids"])
+		}
+
+		// The empty month is filled with zeros and breaks the quiz delta chain
+		if mar["avg_quiz_score"] != nil || mar["active_days"] != 0.0 || mar["engagement_score"] != 0.0 || len(mar["classroom_ids"].([]interface{})) != 0 {
+			t.Errorf("gap month = %v, want zeros", mar)
+		}
+		if mar["active_days_delta"] != -12.0 || mar["engagement_delta"] != -feb["engagement_score"].(float64) {
+			t.Errorf("gap month deltas = %v, %v", mar["active_days_delta"], mar["engagement_delta"])
+		}
+		if apr["avg_quiz_score_delta"] != nil || apr["active_days_delta"] != 8.0 {
+			t.Errorf("April deltas = %v, %v, want no quiz delta after the gap and +8 active days", apr["avg_quiz_score_delta"], apr["active_days_delta"])
+		}
+
+		wantEngagement := roundTo(h.calculateEngagementScore(20, 8, 30), 2)
+		if apr["engagement_score"] != wantEngagement || apr["engagement_delta"] != wantEngagement {
+			t.Errorf("April engagement = %v (delta %v), want %v", apr["engagement_score"], apr["engagement_delta"], wantEngagement)
+		}
+	})
+
+	t.Run("another student's token", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		values := map[string]interface{}{"user_id": uuid.New(), "user_role": "student"}
+		w := testRequest(h.GetStudentGrowth, route, http.MethodGet, target, "", values)
+		expectStatus(t, w, http.StatusForbidden)
+		if len(fake.ran()) != 0 {
+			t.Error("a forbidden request reached the database")
+		}
+	})
+
+	t.Run("invalid granularity", func(t *testing.T) {
+		_, db := newFakeDB(t)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetStudentGrowth, route, http.MethodGet, target+"&granularity=day", "", nil)
+		expectStatus(t, w, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Teacher load levels
+const (
+	TeacherLoadLow      = "low"
+	TeacherLoadModerate = "moderate"
+	TeacherLoadHigh     = "high"
+)
+
+// Cutoffs for the teacher load indicator; reaching either the classroom or
+// the student cutoff is enough to reach the level
+const (
+	moderateLoadClassrooms = 4
+	moderateLoadStudents   = 90
+	highLoadClassrooms     = 6
+	highLoadStudents       = 150
+)
+
+// TeacherLoad summarizes how much a teacher is responsible for
+type TeacherLoad struct {
+	TeacherID       uuid.UUID `json:"teacher_id"`
+	Classrooms      int       `json:"classrooms"`
+	TotalStudents   int       `json:"total_students"`    // distinct active students across the classrooms
+	QuizzesAuthored int       `json:"quizzes_authored"`  // created in the period
+	ContentCreated  int       `json:"content_created"`   // created in the period
+	StudentsPerRoom *float64  `json:"students_per_room"` // null without classrooms
+	Load            string    `json:"load"`
+}
+
+// GetTeacherLoad reports a teacher's classrooms, the students across them and
+// what they authored over the period, with a low/moderate/high load level.
+// Only admins and the teacher themselves may read it over a user token.
+func (h *ReportingHandler) GetTeacherLoad(c *gin.Context) {
+	teacherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, teacherID, adminRole, "Not allowed to view this teacher's load") {
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var teacher struct{ Role string }
+	err = h.db.Table("users").Select("role").Where("id = ?", teacherID).Take(&teacher).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && teacher.Role != "teacher") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Teacher not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teacher", "details": err.Error()})
+		return
+	}
+
+	var load TeacherLoad
+	err = h.db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM classrooms WHERE teacher_id = @teacher) as classrooms,
+			(SELECT COUNT(DISTINCT uc.user_id) FROM user_classrooms uc
+				JOIN classrooms cl ON cl.id = uc.classroom_id
+				WHERE cl.teacher_id = @teacher AND uc.role = 'student' AND uc.is_active = true) as total_students,
+			(SELECT COUNT(*) FROM quizzes
+				WHERE creator_id = @teacher AND created_at BETWEEN @from AND @to) as quizzes_authored,
+			(SELECT COUNT(*) FROM content
+				WHERE creator_id = @teacher AND created_at BETWEEN @from AND @to) as content_created
+	`, map[string]interface{}{"teacher": teacherID, "from": dateFrom, "to": dateTo}).Scan(&load).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate teacher load", "details": err.Error()})
+		return
+	}
+	load.TeacherID = teacherID
+
+	if load.Classrooms > 0 {
+		perRoom := roundTo(float64(load.TotalStudents)/float64(load.Classrooms), 2)
+		load.StudentsPerRoom = &perRoom
+	}
+	load.Load = teacherLoadLevel(load.Classrooms, load.TotalStudents)
+
+	c.JSON(http.StatusOK, gin.H{
+		"period": gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"load":   load,
+		"thresholds_applied": gin.H{
+			"moderate": gin.H{"classrooms": moderateLoadClassrooms, "students": moderateLoadStudents},
+			"high":     gin.H{"classrooms": highLoadClassrooms, "students": highLoadStudents},
+		},
+	})
+}
+
+func teacherLoadLevel(classrooms, students int) string {
+	switch {
+	case classrooms >= highLoadClassrooms || students >= highLoadStudents:
+		return TeacherLoadHigh
+	case classrooms >= moderateLoadClassrooms || students >= moderateLoadStudents:
+		return TeacherLoadModerate
+	default:
+		return TeacherLoadLow
+	}
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SubjectBreakdown aggregates a teacher's classrooms that share a subject
+type SubjectBreakdown struct {
+	Subject            *string  `json:"subject"` // null for classrooms without a subject
+	Classrooms         int      `json:"classrooms"`
+	AvgClassScore      *float64 `json:"avg_class_score"`    // weighted by quiz sessions
+	ParticipationRate  *float64 `json:"participation_rate"` // average daily participation
+	QuizCount          int      `json:"quiz_count"`         // created in the period
+	ClassroomDays      int      `json:"classroom_days"`
+	QuizSessions       int      `json:"quiz_sessions"`
+	InsufficientSample []string `json:"insufficient_sample"`
+}
+
+// GetTeacherSubjectBreakdown aggregates the daily classroom metrics and
+// quizzes of a teacher's classrooms by subject, so a teacher with several
+// subjects can see which one lags. lagging_subject is the subject with the
+// lowest average class score, or null when fewer than two subjects have one.
+// min_/max_ participation and avg_score bounds filter the returned subjects
+// after the lagging subject is chosen. Only admins and the teacher themselves
+// may read it over a user token.
+func (h *ReportingHandler) GetTeacherSubjectBreakdown(c *gin.Context) {
+	teacherID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid teacher_id format"})
+		return
+	}
+
+	if !authorizeViewer(c, teacherID, adminRole, "Not allowed to view this teacher's subjects") {
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -30, h.reportLocation)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bounds, err := parseMeasureBounds(c, "participation", "avg_score")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var teacher struct{ Role string }
+	err = h.db.Table("users").Select("role").Where("id = ?", teacherID).Take(&teacher).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && teacher.Role != "teacher") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Teacher not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teacher", "details": err.Error()})
+		return
+	}
+
+	subjects := []SubjectBreakdown{}
+	err = h.db.Raw(`
+		SELECT
+			NULLIF(TRIM(cl.subject), '') as subject,
+			COUNT(*) as classrooms,
+			SUM(m.score_total) / NULLIF(SUM(m.quiz_sessions), 0) as avg_class_score,
+			SUM(m.participation_total) / NULLIF(SUM(m.classroom_days), 0) as participation_rate,
+			COALESCE(SUM(m.classroom_days), 0) as classroom_days,
+			COALESCE(SUM(m.quiz_sessions), 0) as quiz_sessions,
+			COALESCE(SUM(q.quiz_count), 0) as quiz_count
+		FROM classrooms cl
+		LEFT JOIN (
+			SELECT
+				classroom_id,
+				SUM(avg_class_quiz_score * total_quiz_sessions) as score_total,
+				SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END) as quiz_sessions,
+				SUM(participation_rate) as participation_total,
+				COUNT(*) as classroom_days
+			FROM daily_classroom_metrics
+			WHERE date BETWEEN @from AND @to
+			GROUP BY classroom_id
+		) m ON m.classroom_id = cl.id
+		LEFT JOIN (
+			SELECT classroom_id, COUNT(*) as quiz_count
+			FROM quizzes
+			WHERE created_at BETWEEN @from AND @to
+			GROUP BY classroom_id
+		) q ON q.classroom_id = cl.id
+		WHERE cl.teacher_id = @teacher
+		GROUP BY NULLIF(TRIM(cl.subject), '')
+		ORDER BY subject NULLS LAST
+	`, map[string]interface{}{"teacher": teacherID, "from": dateFrom, "to": dateTo}).Scan(&subjects).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to break down subjects", "details": err.Error()})
+		return
+	}
+
+	var lagging *SubjectBreakdown
+	scored := 0
+	for i := range subjects {
+		s := &subjects[i]
+		guard := h.newSampleGuard()
+		s.AvgClassScore = guard.average("avg_class_score", s.AvgClassScore, s.QuizSessions)
+		s.ParticipationRate = guard.average("participation_rate", s.ParticipationRate, s.ClassroomDays)
+		s.InsufficientSample = guard.flagged()
+		for _, v := range []*float64{s.AvgClassScore, s.ParticipationRate} {
+			if v != nil {
+				*v = roundTo(*v, 2)
+			}
+		}
+		if s.AvgClassScore == nil {
+			continue
+		}
+		scored++
+		if lagging == nil || *s.AvgClassScore < *lagging.AvgClassScore {
+			lagging = s
+		}
+	}
+
+	var laggingSubject *string
+	if scored >= 2 {
+		laggingSubject = lagging.Subject
+	}
+
+	filtered := []SubjectBreakdown{}
+	for _, s := range subjects {
+		if bounds.allows("participation", s.ParticipationRate) && bounds.allows("avg_score", s.AvgClassScore) {
+			filtered = append(filtered, s)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teacher_id":         teacherID,
+		"period":             gin.H{"from": dateFrom.Format(DateFormat), "to": dateTo.Format(DateFormat)},
+		"subjects":           filtered,
+		"lagging_subject":    laggingSubject,
+		"thresholds_applied": h.thresholdsApplied(),
+		"meta": gin.H{
+			"total_before_filter": len(subjects),
+			"returned":            len(filtered),
+			"filters":             bounds,
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetTeacherSubjectBreakdown(t *testing.T) {
+	teacherID := uuid.New()
+	route := "/reports/teacher/:id/subject-breakdown"
+	target := "/reports/teacher/" + teacherID.String() + "/subject-breakdown?date_from=2024-01-01&date_to=2024-01-31"
+	columns := []string{"subject", "classrooms", "avg_class_score", "participation_rate", "classroom_days", "quiz_sessions", "quiz_count"}
+	subjects := func(fake *fakeDB) {
+		fake.rows([]string{`FROM "users"`}, []string{"role"}, []driver.Value{"teacher"})
+		fake.rows([]string{"GROUP BY NULLIF(TRIM(cl.subject), '')"}, columns,
+			[]driver.Value{"Math", int64(2), 62.0, 55.0, int64(40), int64(30), int64(4)},
+			[]driver.Value{"Science", int64(1), 81.0, 90.0, int64(20), int64(12), int64(2)},
+			[]driver.Value{nil, int64(1), nil, 65.0, int64(20), int64(0), int64(0)})
+	}
+	names := func(body map[string]interface{}) []interface{} {
+		var names []interface{}
+		for _, s := range body["subjects"].([]interface{}) {
+			names = append(names, s.(map[string]interface{})["subject"])
+		}
+		return names
+	}
+
+	t.Run("breaks down by subject", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		subjects(fake)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetTeacherSubjectBreakdown, route, http.MethodGet, target, "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		if got := names(body); len(got) != 3 {
+			t.Errorf("subjects = %v, want all three", got)
+		}
+		if body["lagging_subject"] != "Math" {
+			t.Errorf("lagging_subject = %v, want Math", body["lagging_subject"])
+		}
+		if meta := body["meta"].(map[string]interface{}); meta["total_before_filter"] != 3.0 || meta["returned"] != 3.0 {
+			t.Errorf("meta = %v, want 3 of 3", meta)
+		}
+	})
+
+	t.Run("filters to low participation", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		subjects(fake)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetTeacherSubjectBreakdown, route, http.MethodGet, target+"&max_participation=70", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		body := decodeBody(t, w)
+		if got := names(body); len(got) != 2 || got[0] != "Math" || got[1] != nil {
+			t.Errorf("subjects = %v, want Math and the unlabelled subject", got)
+		}
+		// The lagging subject still considers every subject
+		if body["lagging_subject"] != "Math" {
+			t.Errorf("lagging_subject = %v, want Math", body["lagging_subject"])
+		}
+		meta := body["meta"].(map[string]interface{})
+		if meta["total_before_filter"] != 3.0 || meta["returned"] != 2.0 {
+			t.Errorf("meta = %v, want 2 of 3", meta)
+		}
+	})
+
+	t.Run("score bound drops subjects without a score", func(t *testing.T) {
+		fake, db := newFakeDB(t)
+		subjects(fake)
+		h := NewReportingHandler(db)
+
+		w := testRequest(h.GetTeacherSubjectBreakdown, route, http.MethodGet, target+"&min_avg_score=60", "", nil)
+		expectStatus(t, w, http.StatusOK)
+		if got := names(decodeBody(t, w)); len(got) != 2 || got[0] != "Math" || got[1] != "Science" {
+			t.Errorf("subjects = %v, want Math and Science", got)
+		}
+	})
+
+	t.Run("invalid bounds", func(t *testing.T) {
+		for _, query := range []string{"&max_participation=120", "&min_avg_score=80&max_avg_score=60", "&min_avg_score=high"} {
+			fake, db := newFakeDB(t)
+			h := NewReportingHandler(db)
+
+			w := testRequest(h.GetTeacherSubjectBreakdown, route, http.MethodGet, target+query, "", nil)
+			expectStatus(t, w, http.StatusBadRequest)
+			if len(fake.ran()) != 0 {
+				t.Errorf("%s reached the database", query)
+			}
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Values of the time_format query parameter. ISO keeps Go's RFC 3339
+// timestamps; the epoch formats replace them with Unix milliseconds or
+// seconds.
+const (
+	TimeFormatISO          = "iso"
+	TimeFormatEpochMillis  = "epoch_ms"
+	TimeFormatEpochSeconds = "epoch_s"
+)
+
+// timeFormatWriter holds back a JSON response body so its timestamps can be
+// rewritten once the handler is done. Other content types, such as file
+// exports and streams, are written through untouched.
+type timeFormatWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	decided   bool
+	buffering bool
+}
+
+func (w *timeFormatWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	if !w.buffering {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeFormatWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// timeFormat applies ?time_format= to every timestamp in JSON responses,
+// including those scanned straight from the database into maps. Timestamps
+// are recognized as RFC 3339 strings with a time of day; plain dates such as
+// period bounds stay as they are.
+func (h *ReportingHandler) timeFormat() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("time_format", TimeFormatISO)
+		switch format {
+		case TimeFormatISO:
+			c.Next()
+			return
+		case TimeFormatEpochMillis, TimeFormatEpochSeconds:
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid time_format %q, expected %s, %s or %s",
+				format, TimeFormatISO, TimeFormatEpochMillis, TimeFormatEpochSeconds)})
+			return
+		}
+
+		original := c.Writer
+		w := &timeFormatWriter{ResponseWriter: original}
+		c.Writer = w
+		c.Next()
+		c.Writer = original
+
+		if !w.buffering {
+			return
+		}
+		body, err := formatJSONTimes(w.body.Bytes(), format)
+		if err != nil {
+			// Not valid JSON after all; send it as the handler wrote it
+			body = w.body.Bytes()
+		}
+		original.Write(body)
+	}
+}
+
+// formatJSONTimes re-encodes a JSON document with its timestamps in format
+func formatJSONTimes(data []byte, format string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(formatTimeValues(doc, format))
+}
+
+// formatTimeValues replaces timestamp strings anywhere in a decoded JSON
+// value with epoch numbers
+func formatTimeValues(v interface{}, format string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = formatTimeValues(item, format)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = formatTimeValues(item, format)
+		}
+	case string:
+		if t, ok := parseJSONTimestamp(value); ok {
+			if format == TimeFormatEpochSeconds {
+				return t.Unix()
+			}
+			return t.UnixMilli()
+		}
+	}
+	return v
+}
+
+// parseJSONTimestamp parses a time.Time as encoding/json writes it
+func parseJSONTimestamp(s string) (time.Time, bool) {
+	if len(s) < len("2006-01-02T15:04:05Z") || s[10] != 'T' {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestTimeFormatSerializesReportBothWays(t *testing.T) {
+	fake, db := newFakeDB(t)
+	created := time.Date(2026, 2, 10, 9, 0, 0, 0, time.UTC)
+	fake.rows([]string{"FROM content c"},
+		[]string{"content_id", "title", "content_type", "classroom_id", "classroom_name", "effectiveness_score", "unique_viewers", "created_at"},
+		[]driver.Value{uuid.NewString(), "Fractions", "notebook", uuid.NewString(), "Maths", 0.92, int64(18), created})
+	h := NewReportingHandler(db)
+	router := gin.New()
+	router.GET("/students/:id/recommended-content", h.timeFormat(), h.GetStudentRecommendedContent)
+	createdAt := func(query string) interface{} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/students/"+uuid.NewString()+"/recommended-content"+query, nil))
+		expectStatus(t, w, http.StatusOK)
+		var body map[string]interface{}
+		decoder := json.NewDecoder(w.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&body); err != nil {
+			t.Fatalf("decode %s: %v", query, err)
+		}
+		return body["recommendations"].([]interface{})[0].(map[string]interface{})["created_at"]
+	}
+
+	for _, query := range []string{"", "?time_format=iso"} {
+		if got := createdAt(query); got != "2026-02-10T09:00:00Z" {
+			t.Errorf("created_at with %q = %v, want RFC 3339", query, got)
+		}
+	}
+	if got := createdAt("?time_format=epoch_ms"); got != json.Number("1770714000000") {
+		t.Errorf("created_at in epoch_ms = %v, want %d", got, created.UnixMilli())
+	}
+	if got := createdAt("?time_format=epoch_s"); got != json.Number("1770714000") {
+		t.Errorf("created_at in epoch_s = %v, want %d", got, created.Unix())
+	}
+}
+
+func TestFormatJSONTimes(t *testing.T) {
+	in := `{"at":"2026-02-10T10:00:00.5+01:00","period":{"from":"2026-02-01"},"rows":[{"seen":"2026-02-10T09:00:00Z","n":12345678901234567}],"name":"T"}`
+	got, err := formatJSONTimes([]byte(in), TimeFormatEpochMillis)
+	if err != nil {
+		t.Fatalf("formatJSONTimes: %v", err)
+	}
+	// Plain dates, other strings and large numbers are left alone
+	want := `{"at":1770714000500,"name":"T","period":{"from":"2026-02-01"},"rows":[{"n":12345678901234567,"seen":1770714000000}]}`
+	if string(got) != want {
+		t.Errorf("formatJSONTimes = %s, want %s", got, want)
+	}
+}
+
+func TestTimeFormatRejectsUnknownFormat(t *testing.T) {
+	h := NewReportingHandler(nil)
+	router := gin.New()
+	router.GET("/report", h.timeFormat(), func(c *gin.Context) {
+		t.Error("handler ran for an invalid time_format")
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/report?time_format=unix", nil))
+	expectStatus(t, w, http.StatusBadRequest)
+}
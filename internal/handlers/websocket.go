@@ -31,7 +31,10 @@ type ClassroomLiveData struct {
 	RecentEvents          []map[string]interface{} `json:"recent_events"`
 }
 
-func HandleWebSocket(c *gin.Context, db *gorm.DB) {
+// HandleWebSocket streams live classroom data. Connections to the same
+// classroom share one broadcaster, and connections beyond the hub's
+// per-classroom limit are closed with a try-again-later code.
+func (h *LiveHub) HandleWebSocket(c *gin.Context) {
 	classroomID := c.Param("id")
 	id, err := uuid.Parse(classroomID)
 	if err != nil {
@@ -49,12 +52,23 @@ func HandleWebSocket(c *gin.Context, db *gorm.DB) {
 	}
 	defer conn.Close()
 
-	// Send initial data
-	initialData, err := getClassroomLiveData(db, id)
-	if err != nil {
-		log.Printf("Failed to get initial data: %v", err)
+	sub, latest, ok := h.join(id)
+	if !ok {
+		busy := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "classroom connection limit reached")
+		conn.WriteControl(websocket.CloseMessage, busy, time.Now().Add(time.Second))
 		return
 	}
+	defer h.leave(id, sub)
+
+	// Send initial data, reusing the broadcaster's last computation when there is one
+	initialData := latest
+	if initialData == nil {
+		initialData, err = getClassroomLiveData(h.db, id, h.windows)
+		if err != nil {
+			log.Printf("Failed to get initial data: %v", err)
+			return
+		}
+	}
 
 	initialMessage := WebSocketMessage{
 		Type:      "initial_data",
@@ -67,10 +81,6 @@ func HandleWebSocket(c *gin.Context, db *gorm.DB) {
 		return
 	}
 
-	// Start periodic updates
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
 	// Channel to handle connection close
 	done := make(chan struct{})
 
@@ -88,25 +98,12 @@ func HandleWebSocket(c *gin.Context, db *gorm.DB) {
 		}
 	}()
 
-	// Send periodic updates
+	// Forward shared updates
 	for {
 		select {
 		case <-done:
 			return
-		case <-ticker.C:
-			// Get updated classroom data
-			liveData, err := getClassroomLiveData(db, id)
-			if err != nil {
-				log.Printf("Failed to get live data: %v", err)
-				continue
-			}
-
-			message := WebSocketMessage{
-				Type:      "live_update",
-				Data:      map[string]interface{}{"classroom_data": liveData},
-				Timestamp: time.Now(),
-			}
-
+		case message := <-sub:
 			if err := conn.WriteJSON(message); err != nil {
 				log.Printf("Failed to send update: %v", err)
 				return
@@ -115,39 +112,50 @@ func HandleWebSocket(c *gin.Context, db *gorm.DB) {
 	}
 }
 
-func getClassroomLiveData(db *gorm.DB, classroomID uuid.UUID) (*ClassroomLiveData, error) {
+// getClassroomLiveData computes live data for a classroom. Active students
+// and quiz participation share the activity window, and students who
+// answered a quiz in it count as active, so participation can't exceed 100%.
+func getClassroomLiveData(db *gorm.DB, classroomID uuid.UUID, windows liveWindows) (*ClassroomLiveData, error) {
 	now := time.Now()
-	oneHourAgo := now.Add(-1 * time.Hour)
+	since := now.Add(-windows.activity)
 
-	// Get active students in the last hour
+	// Responses in the window, whatever the quiz's age; bounding by response
+	// time keeps the join small on classrooms with long quiz histories
+	responses := db.Table("quiz_responses").
+		Select("quiz_responses.quiz_id, quiz_responses.student_id, quiz_responses.id, quiz_responses.time_taken_seconds").
+		Joins("JOIN quizzes ON quizzes.id = quiz_responses.quiz_id").
+		Where("quizzes.classroom_id = ?", classroomID).
+		Where("quiz_responses.created_at >= ?", since)
+
+	// Students with a session running during the window or a quiz response in it
 	var activeStudents int64
-	err := db.Table("sessions").
-		Distinct("user_id").
-		Where("classroom_id = ?", classroomID).
-		Where("start_time >= ?", oneHourAgo).
-		Count(&activeStudents).Error
+	err := db.Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT user_id FROM sessions
+			WHERE classroom_id = ? AND (start_time >= ? OR end_time >= ?)
+			UNION
+			SELECT student_id FROM (?) r
+		) active
+	`, classroomID, since, since, responses).Scan(&activeStudents).Error
 	if err != nil {
 		return nil, err
 	}
 
 	// Get quiz participation data
 	var quizData struct {
-		TotalQuizzes       int `json:"total_quizzes"`
-		ParticipatingUsers int `json:"participating_users"`
-		TotalResponses     int `json:"total_responses"`
+		TotalQuizzes       int     `json:"total_quizzes"`
+		ParticipatingUsers int     `json:"participating_users"`
+		TotalResponses     int     `json:"total_responses"`
 		AvgResponseTime    float64 `json:"avg_response_time"`
 	}
 
-	err = db.Table("quizzes").
+	err = db.Table("(?) r", responses).
 		Select(`
-			COUNT(DISTINCT quizzes.id) as total_quizzes,
-			COUNT(DISTINCT quiz_responses.student_id) as participating_users,
-			COUNT(quiz_responses.id) as total_responses,
-			AVG(quiz_responses.time_taken_seconds) as avg_response_time
+			COUNT(DISTINCT r.quiz_id) as total_quizzes,
+			COUNT(DISTINCT r.student_id) as participating_users,
+			COUNT(r.id) as total_responses,
+			COALESCE(AVG(r.time_taken_seconds), 0) as avg_response_time
 		`).
-		Joins("LEFT JOIN quiz_responses ON quizzes.id = quiz_responses.quiz_id").
-		Where("quizzes.classroom_id = ?", classroomID).
-		Where("quizzes.created_at >= ?", oneHourAgo).
 		Scan(&quizData).Error
 	if err != nil {
 		return nil, err
@@ -167,7 +175,7 @@ func getClassroomLiveData(db *gorm.DB, classroomID uuid.UUID) (*ClassroomLiveDat
 	err = db.Table("events").
 		Select("event_type, timestamp, payload").
 		Where("classroom_id = ?", classroomID).
-		Where("timestamp >= ?", now.Add(-10*time.Minute)).
+		Where("timestamp >= ?", now.Add(-windows.recentEvents)).
 		Order("timestamp DESC").
 		Limit(10).
 		Scan(&recentEvents).Error
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WeekdayEngagement is a classroom's average activity on one day of the week
+type WeekdayEngagement struct {
+	Weekday              string  `json:"weekday"`
+	DayOfWeek            int     `json:"day_of_week"` // 0 = Sunday, as EXTRACT(DOW ...)
+	Days                 int     `json:"days"`        // occurrences of the weekday in the period
+	ActiveDays           int     `json:"active_days"`
+	TotalSessions        int     `json:"total_sessions"`
+	AvgSessionsPerDay    float64 `json:"avg_sessions_per_day"`
+	AvgParticipationRate float64 `json:"avg_participation_rate"`
+	AvgEngagementScore   float64 `json:"avg_engagement_score"`
+}
+
+// SetReportLocation sets the timezone used to decide which local day an
+// activity falls on when neither the request nor the school names one
+func (h *ReportingHandler) SetReportLocation(loc *time.Location) {
+	h.reportLocation = loc
+}
+
+// SetWeekStart sets the first day of the week in weekday breakdowns
+func (h *ReportingHandler) SetWeekStart(day time.Weekday) {
+	h.weekStart = day
+}
+
+// ParseWeekStart parses "monday" or "sunday"; empty means Monday
+func ParseWeekStart(s string) (time.Weekday, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "monday":
+		return time.Monday, nil
+	case "sunday":
+		return time.Sunday, nil
+	default:
+		return 0, fmt.Errorf("week start must be monday or sunday, got %q", s)
+	}
+}
+
+// GetEngagementByWeekday returns a classroom's average sessions, participation
+// and engagement for each day of the week. Days are local days in the
+// classroom school's time zone, unless ?tz= overrides it; weekdays with no
+// activity in the period count as zero rather than being skipped, so quiet
+// weekdays pull their averages down.
+func (h *ReportingHandler) GetEngagementByWeekday(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Query("classroom_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Valid classroom_id is required"})
+		return
+	}
+
+	loc, err := h.requestLocation(c, h.classroomTimezone(classroomID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dateFrom, dateTo, err := h.parseDateRangeWithDefault(c.Query("date_from"), c.Query("date_to"), -28, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day()+1, 0, 0, 0, 0, loc)
+	if !start.Before(end) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must not be after date_to"})
+		return
+	}
+
+	var enrolled int64
+	err = h.db.Table("user_classrooms").
+		Where("classroom_id = ? AND role = 'student' AND is_active = true", classroomID).
+		Count(&enrolled).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count students", "details": err.Error()})
+		return
+	}
+
+	// Session timestamps are stored in UTC
+	var daily []struct {
+		Date           time.Time
+		Sessions       int
+		ActiveStudents int
+		TotalMinutes   float64
+	}
+	err = h.db.Table("sessions s").
+		Select(`
+			(s.start_time AT TIME ZONE 'UTC' AT TIME ZONE ?)::date as date,
+			COUNT(*) as sessions,
+			COUNT(DISTINCT s.user_id) as active_students,
+			COALESCE(SUM(s.duration_seconds), 0) / 60.0 as total_minutes
+		`, loc.String()).
+		Joins("JOIN user_classrooms uc ON uc.user_id = s.user_id AND uc.classroom_id = s.classroom_id AND uc.role = 'student'").
+		Where("s.classroom_id = ? AND s.start_time >= ? AND s.start_time < ?", classroomID, start.UTC(), end.UTC()).
+		Group("date").
+		Scan(&daily).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions", "details": err.Error()})
+		return
+	}
+
+	type dayTotals struct {
+		sessions      int
+		participation float64
+		engagement    float64
+	}
+	byDate := make(map[string]dayTotals, len(daily))
+	for _, d := range daily {
+		totals := dayTotals{sessions: d.Sessions}
+		if enrolled > 0 {
+			totals.participation = min(float64(d.ActiveStudents)*100/float64(enrolled), 100)
+		}
+		if d.ActiveStudents > 0 {
+			// Consistency is the share of the roster active that day, intensity
+			// the minutes per active student
+			totals.engagement = h.calculateEngagementScore(d.TotalMinutes/float64(d.ActiveStudents), d.ActiveStudents, float64(max(enrolled, int64(d.ActiveStudents))))
+		}
+		byDate[d.Date.Format(DateFormat)] = totals
+	}
+
+	weekdays := make([]WeekdayEngagement, 7)
+	for i := range weekdays {
+		day := time.Weekday((int(h.weekStart) + i) % 7)
+		weekdays[i] = WeekdayEngagement{Weekday: day.String(), DayOfWeek: int(day)}
+	}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		w := &weekdays[(int(day.Weekday())-int(h.weekStart)+7)%7]
+		w.Days++
+		totals, ok := byDate[day.Format(DateFormat)]
+		if !ok {
+			continue
+		}
+		w.ActiveDays++
+		w.TotalSessions += totals.sessions
+		w.AvgParticipationRate += totals.participation
+		w.AvgEngagementScore += totals.engagement
+	}
+	for i := range weekdays {
+		w := &weekdays[i]
+		if w.Days == 0 {
+			continue
+		}
+		w.AvgSessionsPerDay = roundTo(float64(w.TotalSessions)/float64(w.Days), 2)
+		w.AvgParticipationRate = roundTo(w.AvgParticipationRate/float64(w.Days), 2)
+		w.AvgEngagementScore = roundTo(w.AvgEngagementScore/float64(w.Days), 2)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"classroom_id":      classroomID,
+		"period":            gin.H{"from": start.Format(DateFormat), "to": end.AddDate(0, 0, -1).Format(DateFormat)},
+		"timezone":          loc.String(),
+		"week_start":        h.weekStart.String(),
+		"enrolled_students": enrolled,
+		"weekdays":          weekdays,
+	})
+}
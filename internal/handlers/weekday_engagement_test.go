@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseWeekStart(t *testing.T) {
+	for input, want := range map[string]time.Weekday{"": time.Monday, "Monday": time.Monday, " sunday ": time.Sunday} {
+		if got, err := ParseWeekStart(input); err != nil || got != want {
+			t.Errorf("ParseWeekStart(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseWeekStart("saturday"); err == nil {
+		t.Error("ParseWeekStart accepted saturday")
+	}
+}
+
+func TestEngagementByWeekday(t *testing.T) {
+	fake, db := newFakeDB(t)
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	fake.rows([]string{`FROM "user_classrooms"`, "count(*)"}, []string{"count"}, []driver.Value{int64(4)})
+	fake.rows([]string{"FROM sessions s", "active_students"}, []string{"date", "sessions", "active_students", "total_minutes"},
+		[]driver.Value{day(4), int64(6), int64(2), 120.0},
+		[]driver.Value{day(6), int64(4), int64(4), 120.0},
+		[]driver.Value{day(11), int64(2), int64(1), 30.0})
+	h := NewReportingHandler(db)
+	h.SetWeekStart(time.Sunday)
+
+	// Two weeks from Monday 4 March, so every weekday occurs twice
+	target := "/analytics/engagement-by-weekday?classroom_id=" + uuid.NewString() + "&date_from=2024-03-04&date_to=2024-03-17"
+	w := testRequest(h.GetEngagementByWeekday, "/analytics/engagement-by-weekday", http.MethodGet, target, "", nil)
+	expectStatus(t, w, http.StatusOK)
+	body := decodeBody(t, w)
+
+	weekdays := body["weekdays"].([]interface{})
+	if len(weekdays) != 7 || weekdays[0].(map[string]interface{})["weekday"] != "Sunday" {
+		t.Fatalf("weekdays = %v, want 7 starting on Sunday", weekdays)
+	}
+	// Engagement is 0.7 x the share of the roster active plus 0.3 x minutes
+	// per active student over 60: Mondays score 65 and 32.5, the Wednesday 85
+	want := map[string][5]float64{
+		"Monday":    {2, 2, 4, 37.5, 48.75},
+		"Wednesday": {2, 1, 2, 50, 42.5},
+		"Friday":    {2, 0, 0, 0, 0},
+	}
+	for _, raw := range weekdays {
+		weekday := raw.(map[string]interface{})
+		expected, ok := want[weekday["weekday"].(string)]
+		if !ok {
+			continue
+		}
+		got := [5]float64{
+			weekday["days"].(float64), weekday["active_days"].(float64), weekday["avg_sessions_per_day"].(float64),
+			weekday["avg_participation_rate"].(float64), weekday["avg_engagement_score"].(float64),
+		}
+		if got != expected {
+			t.Errorf("%s days, active days, sessions, participation, engagement = %v, want %v", weekday["weekday"], got, expected)
+		}
+	}
+
+	ran := fake.ran("FROM sessions s")
+	if len(ran) != 1 || !containsAll(ran[0].SQL, []string{`GROUP BY "date"`}) || ran[0].Args[0] != "UTC" {
+		t.Errorf("sessions query = %v, want local dates grouped by their alias", ran)
+	}
+}
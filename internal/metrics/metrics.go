@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes every metric exposed by the reporting servers
+const Namespace = "reporting"
+
+var (
+	// EventsIngested counts events stored by event and session batch ingestion
+	EventsIngested = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "events_ingested_total",
+		Help:      "Events stored by batch event and session ingestion.",
+	})
+
+	// SessionsCreated counts sessions stored by session batch ingestion
+	SessionsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "sessions_created_total",
+		Help:      "Sessions stored by session batch ingestion.",
+	})
+
+	// AggregationErrors counts failed attempts to update aggregated metrics
+	// for an ingested batch, including attempts that are retried
+	AggregationErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "aggregation_errors_total",
+		Help:      "Failed attempts to update aggregated metrics for an ingested batch.",
+	})
+
+	// AggregationBatchesDropped counts ingested batches whose aggregation was
+	// abandoned, after its last attempt or because the retry queue was full
+	AggregationBatchesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "aggregation_batches_dropped_total",
+		Help:      "Ingested batches whose aggregated metrics update was abandoned.",
+	})
+
+	// QueryExecutions counts generic query executions by status (success or error)
+	QueryExecutions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "query_executions_total",
+		Help:      "Generic query executions by status.",
+	}, []string{"status"})
+
+	// QueryDuration observes generic query latency in seconds
+	QueryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "query_duration_seconds",
+		Help:      "Generic query latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// ReportDuration observes report generation latency in seconds by report
+	ReportDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "report_duration_seconds",
+		Help:      "Report generation latency in seconds by report.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"report"})
+)
+
+// ObserveReport records the time since start against the named report
+func ObserveReport(report string, start time.Time) {
+	ReportDuration.WithLabelValues(report).Observe(time.Since(start).Seconds())
+}
+
+// ObserveQuery records a generic query's outcome and the time since start
+func ObserveQuery(start time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	QueryExecutions.WithLabelValues(status).Inc()
+	QueryDuration.Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the registered collectors in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
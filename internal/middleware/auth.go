@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -8,8 +10,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// AuthMiddleware accepts either a configured X-API-Key or a bearer JWT signed
+// with cfg.JWTSecret. A valid token sets user_id and, when the token carries
+// one, school_id as uuid.UUID and user_role as a string in the gin context.
+// Missing, malformed, invalid and expired credentials get a 401. Without a
+// JWTSecret only API keys are accepted.
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check for API key first
@@ -19,37 +27,56 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 				c.Next()
 				return
 			}
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			return
 		}
 
 		// Check for JWT token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
 		}
 
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			return
+		}
+		if cfg.JWTSecret == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Bearer tokens are not accepted", "details": "JWT_SECRET is not configured"})
+			return
+		}
+
+		claims, err := validateJWT(bearerToken[1], cfg.JWTSecret)
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		tokenString := bearerToken[1]
-		claims, err := validateJWT(tokenString, cfg.JWTSecret)
+		userID, err := uuidClaim(claims, "user_id")
+		if err != nil || userID == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": "user_id claim must be a UUID"})
+			return
+		}
+		schoolID, err := uuidClaim(claims, "school_id")
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": err.Error()})
 			return
 		}
 
 		// Set user context
-		c.Set("user_id", claims["user_id"])
-		c.Set("user_role", claims["role"])
+		c.Set("user_id", *userID)
+		if schoolID != nil {
+			c.Set("school_id", *schoolID)
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("user_role", role)
+		}
 		c.Next()
 	}
 }
@@ -63,10 +90,12 @@ func validateAPIKey(apiKey string, cfg *config.Config) bool {
 	return false
 }
 
+// validateJWT parses an HMAC-signed token, checking its signature and, when
+// present, its expiry and not-before times
 func validateJWT(tokenString, secret string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}))
 
 	if err != nil {
 		return nil, err
@@ -77,4 +106,21 @@ func validateJWT(tokenString, secret string) (jwt.MapClaims, error) {
 	}
 
 	return nil, jwt.ErrInvalidKey
-}
\ No newline at end of file
+}
+
+// uuidClaim returns the named claim as a UUID, or nil when it is absent
+func uuidClaim(claims jwt.MapClaims, name string) (*uuid.UUID, error) {
+	raw, ok := claims[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s claim must be a UUID", name)
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("%s claim must be a UUID", name)
+	}
+	return &id, nil
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"reporting-framework/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "test-secret"
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func signToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+// authenticate runs one request through AuthMiddleware and returns the
+// response with the gin context values it set
+func authenticate(cfg *config.Config, header, value string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	var values map[string]interface{}
+	router := gin.New()
+	router.GET("/", AuthMiddleware(cfg), func(c *gin.Context) {
+		values = c.Keys
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, values
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	cfg := &config.Config{JWTSecret: testSecret, APIKeys: map[string]string{"whiteboard": "wb_key"}}
+	userID, schoolID := uuid.New(), uuid.New()
+
+	t.Run("valid token sets the user context", func(t *testing.T) {
+		token := signToken(t, testSecret, jwt.MapClaims{
+			"user_id": userID.String(), "school_id": schoolID.String(), "role": "teacher",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		w, values := authenticate(cfg, "Authorization", "Bearer "+token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		if values["user_id"] != userID || values["school_id"] != schoolID || values["user_role"] != "teacher" {
+			t.Errorf("context = %v", values)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"api key", "X-API-Key", "wb_key", http.StatusOK},
+		{"unknown api key", "X-API-Key", "nope", http.StatusUnauthorized},
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"not a bearer token", "Authorization", "Basic abc", http.StatusUnauthorized},
+		{"wrong secret", "Authorization", "Bearer " + signToken(t, "other", jwt.MapClaims{"user_id": userID.String()}), http.StatusUnauthorized},
+		{"expired", "Authorization", "Bearer " + signToken(t, testSecret, jwt.MapClaims{"user_id": userID.String(), "exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized},
+		{"no user_id", "Authorization", "Bearer " + signToken(t, testSecret, jwt.MapClaims{"role": "admin"}), http.StatusUnauthorized},
+		{"malformed school_id", "Authorization", "Bearer " + signToken(t, testSecret, jwt.MapClaims{"user_id": userID.String(), "school_id": "school_1"}), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, _ := authenticate(cfg, tt.header, tt.value); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareWithoutJWTSecret(t *testing.T) {
+	cfg := &config.Config{APIKeys: map[string]string{"whiteboard": "wb_key"}}
+
+	// A token signed with the empty key must not be accepted
+	token := signToken(t, "", jwt.MapClaims{"user_id": uuid.NewString(), "role": "admin"})
+	if w, _ := authenticate(cfg, "Authorization", "Bearer "+token); w.Code != http.StatusUnauthorized {
+		t.Errorf("bearer token status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w, _ := authenticate(cfg, "X-API-Key", "wb_key"); w.Code != http.StatusOK {
+		t.Errorf("api key status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
@@ -31,6 +31,30 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// StringList is a string slice stored as a PostgreSQL JSONB array
+type StringList []string
+
+func (s StringList) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*s = StringList{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+
+	return json.Unmarshal(bytes, s)
+}
+
 // School represents an educational institution
 type School struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
@@ -160,6 +184,7 @@ type Quiz struct {
 	CreatedAt        time.Time `json:"created_at"`
 	PublishedAt      *time.Time `json:"published_at"`
 	Status           string    `gorm:"type:varchar(20);default:'draft'" json:"status"` // draft, published, completed, archived
+	Tags             StringList `gorm:"type:jsonb;default:'[]';index:idx_quizzes_tags,type:gin" json:"tags"`
 }
 
 func (q *Quiz) BeforeCreate(tx *gorm.DB) error {
@@ -244,4 +269,21 @@ type ClassroomAnalytics struct {
 func (ca *ClassroomAnalytics) BeforeCreate(tx *gorm.DB) error {
 	ca.ID = uuid.New()
 	return nil
-}
\ No newline at end of file
+}
+// QuizAnalyticsSnapshot freezes a quiz's analytics at the moment it is archived
+type QuizAnalyticsSnapshot struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	QuizID             uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"quiz_id"`
+	ParticipantCount   int       `json:"participant_count"`
+	ResponseCount      int       `json:"response_count"`
+	AverageScore       *float64  `gorm:"type:decimal(5,2)" json:"average_score"`
+	AverageTimeSeconds *float64  `gorm:"type:decimal(10,2)" json:"average_time_seconds"`
+	Questions          JSONB     `gorm:"type:jsonb" json:"questions"` // per-question response counts and correct rates
+	ArchivedAt         time.Time `json:"archived_at"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+func (qas *QuizAnalyticsSnapshot) BeforeCreate(tx *gorm.DB) error {
+	qas.ID = uuid.New()
+	return nil
+}
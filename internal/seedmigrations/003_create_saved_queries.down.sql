@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_saved_queries_updated_at ON saved_queries;
+DROP TABLE IF EXISTS saved_queries CASCADE;
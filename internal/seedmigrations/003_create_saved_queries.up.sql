@@ -0,0 +1,14 @@
+-- Named generic query definitions, scoped to the user who saved them
+CREATE TABLE saved_queries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    query JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_saved_queries_user_name UNIQUE (user_id, name)
+);
+
+CREATE TRIGGER update_saved_queries_updated_at BEFORE UPDATE ON saved_queries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
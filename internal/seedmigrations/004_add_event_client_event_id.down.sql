@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_events_user_client_event;
+ALTER TABLE events DROP COLUMN IF EXISTS client_event_id;
@@ -0,0 +1,4 @@
+-- Client-supplied idempotency keys, so retried event batches are stored once
+ALTER TABLE events ADD COLUMN client_event_id VARCHAR(100);
+
+CREATE UNIQUE INDEX idx_events_user_client_event ON events USING BTREE(user_id, client_event_id);
@@ -0,0 +1 @@
+ALTER TABLE schools DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,2 @@
+-- IANA time zone each school's daily metrics are bucketed in; NULL uses the report timezone
+ALTER TABLE schools ADD COLUMN timezone VARCHAR(64);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_quizzes_classroom_published;
+ALTER TABLE quizzes DROP COLUMN IF EXISTS published_at;
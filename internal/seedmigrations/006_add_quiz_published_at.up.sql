@@ -0,0 +1,4 @@
+-- When each quiz was published to its classroom; NULL while unpublished
+ALTER TABLE quizzes ADD COLUMN published_at TIMESTAMP;
+
+CREATE INDEX idx_quizzes_classroom_published ON quizzes USING BTREE(classroom_id, published_at);
@@ -0,0 +1,2 @@
+-- Where each session connected from: on_network, off_network or unknown
+ALTER TABLE sessions ADD COLUMN network_bucket VARCHAR(20);
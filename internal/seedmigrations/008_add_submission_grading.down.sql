@@ -0,0 +1,2 @@
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS graded_by;
+ALTER TABLE quiz_submissions DROP COLUMN IF EXISTS graded_at;
@@ -0,0 +1,3 @@
+-- When and by whom manually graded submissions were graded; NULL while pending
+ALTER TABLE quiz_submissions ADD COLUMN graded_at TIMESTAMP;
+ALTER TABLE quiz_submissions ADD COLUMN graded_by UUID REFERENCES users(id);
@@ -0,0 +1,2 @@
+-- Ingestion stats scan events by when they were received
+CREATE INDEX idx_events_created_at ON events USING BTREE(created_at);
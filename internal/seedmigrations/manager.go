@@ -22,15 +22,18 @@ type SeedMigration struct {
 
 // SeedMigrationManager handles the execution and tracking of seed migrations
 type SeedMigrationManager struct {
-	db     *gorm.DB
-	logger seedutils.Logger
+	db         *gorm.DB
+	logger     seedutils.Logger
+	randomSeed int64
 }
 
-// NewSeedMigrationManager creates a new seed migration manager
-func NewSeedMigrationManager(db *gorm.DB) *SeedMigrationManager {
+// NewSeedMigrationManager creates a new seed migration manager.
+// A non-zero randomSeed makes generated data reproducible across runs.
+func NewSeedMigrationManager(db *gorm.DB, randomSeed int64) *SeedMigrationManager {
 	return &SeedMigrationManager{
-		db:     db,
-		logger: seedutils.NewSimpleLogger(),
+		db:         db,
+		logger:     seedutils.NewSimpleLogger(),
+		randomSeed: randomSeed,
 	}
 }
 
@@ -175,7 +178,7 @@ func (smm *SeedMigrationManager) executeInitialEducationalData(db *gorm.DB, logg
 	}
 
 	// Create users (teachers and students)
-	userGen := seedutils.NewUserGenerator(db, logger)
+	userGen := seedutils.NewUserGenerator(db, logger, smm.randomSeed)
 
 	teachers, err := userGen.GenerateTeachers(schools, 25) // 5 teachers per school
 	if err != nil {
@@ -188,7 +191,7 @@ func (smm *SeedMigrationManager) executeInitialEducationalData(db *gorm.DB, logg
 	}
 
 	// Create classrooms
-	classGen := seedutils.NewClassroomGenerator(db, logger)
+	classGen := seedutils.NewClassroomGenerator(db, logger, smm.randomSeed)
 	academicConfig := seedutils.GetDefaultAcademicConfig()
 
 	classrooms, err := classGen.GenerateClassrooms(schools, teachers, 25, academicConfig) // 5 classrooms per school
@@ -204,7 +207,7 @@ func (smm *SeedMigrationManager) executeInitialEducationalData(db *gorm.DB, logg
 	}
 
 	// Create quizzes
-	quizGen := seedutils.NewQuizGenerator(db, logger)
+	quizGen := seedutils.NewQuizGenerator(db, logger, smm.randomSeed)
 	_, err = quizGen.GenerateQuizzesForClassrooms(classrooms, 6) // 6 quizzes per classroom
 	if err != nil {
 		return fmt.Errorf("failed to generate quizzes: %w", err)
@@ -226,7 +229,7 @@ func (smm *SeedMigrationManager) executeSampleQuizResponses(db *gorm.DB, logger
 	}
 
 	// Generate responses
-	responseGen := seedutils.NewResponseGenerator(db, logger)
+	responseGen := seedutils.NewResponseGenerator(db, logger, smm.randomSeed)
 	err = responseGen.GenerateResponsesForAllQuizzes(quizzes)
 	if err != nil {
 		return fmt.Errorf("failed to generate quiz responses: %w", err)
@@ -248,7 +251,7 @@ func (smm *SeedMigrationManager) executeUsageAnalyticsData(db *gorm.DB, logger s
 	}
 
 	// Generate sessions
-	sessionGen := seedutils.NewSessionGenerator(db, logger)
+	sessionGen := seedutils.NewSessionGenerator(db, logger, smm.randomSeed)
 	sessionPattern := seedutils.GetEducationalSessionPattern()
 	sessionPattern.SessionsPerUser = 10 // 10 sessions per student
 
@@ -258,7 +261,7 @@ func (smm *SeedMigrationManager) executeUsageAnalyticsData(db *gorm.DB, logger s
 	}
 
 	// Generate events
-	eventGen := seedutils.NewEventGenerator(db, logger)
+	eventGen := seedutils.NewEventGenerator(db, logger, smm.randomSeed)
 	eventPattern := seedutils.GetEducationalEventPattern()
 	err = eventGen.GenerateEventsForSessions(sessions, eventPattern)
 	if err != nil {
@@ -281,8 +284,8 @@ func (smm *SeedMigrationManager) GetSeedStatus() ([]SeedMigration, error) {
 
 // AutoSeedOnStartup runs the seed migration system during application startup
 // This ensures the system always has data available for development and testing
-func AutoSeedOnStartup(db *gorm.DB) {
-	manager := NewSeedMigrationManager(db)
+func AutoSeedOnStartup(db *gorm.DB, randomSeed int64) {
+	manager := NewSeedMigrationManager(db, randomSeed)
 
 	// Initialize the migration tracking system
 	err := manager.InitializeSeedMigrations()
@@ -11,15 +11,18 @@ import (
 // SeedCoordinator manages the overall data seeding process
 // It orchestrates the creation of educational data in a logical sequence
 type SeedCoordinator struct {
-	db     *gorm.DB
-	logger Logger
+	db         *gorm.DB
+	logger     Logger
+	randomSeed int64
 }
 
-// NewSeedCoordinator creates a new coordinator instance
-func NewSeedCoordinator(db *gorm.DB, logger Logger) *SeedCoordinator {
+// NewSeedCoordinator creates a new coordinator instance.
+// A non-zero randomSeed makes every generator reproducible.
+func NewSeedCoordinator(db *gorm.DB, logger Logger, randomSeed int64) *SeedCoordinator {
 	return &SeedCoordinator{
-		db:     db,
-		logger: logger,
+		db:         db,
+		logger:     logger,
+		randomSeed: randomSeed,
 	}
 }
 
@@ -32,6 +35,7 @@ type EducationalDataConfig struct {
 	QuizzesPerClassroom  int
 	SessionsPerStudent   int
 	LimitStudentsForDemo int // Limit session generation to avoid excessive data
+	GeneratorWorkers     int // Concurrent workers for session and event generation
 }
 
 // GetProductionDataConfig returns configuration suitable for production-scale testing
@@ -44,6 +48,7 @@ func GetProductionDataConfig() EducationalDataConfig {
 		QuizzesPerClassroom:  8,   // 2 months of weekly quizzes
 		SessionsPerStudent:   12,  // Represents active usage over time
 		LimitStudentsForDemo: 150, // Limit session generation for demo purposes
+		GeneratorWorkers:     8,   // Parallel session/event inserts
 	}
 }
 
@@ -115,7 +120,7 @@ func (sc *SeedCoordinator) createSchoolFoundation() ([]models.School, error) {
 func (sc *SeedCoordinator) createHumanResources(schools []models.School, config EducationalDataConfig) ([]models.User, []models.User, error) {
 	sc.logger.Info("Creating human resources")
 
-	userGen := NewUserGenerator(sc.db, sc.logger)
+	userGen := NewUserGenerator(sc.db, sc.logger, sc.randomSeed)
 
 	// Generate teaching staff
 	totalTeachers := len(schools) * config.TeachersPerSchool
@@ -140,7 +145,7 @@ func (sc *SeedCoordinator) createHumanResources(schools []models.School, config
 func (sc *SeedCoordinator) createLearningEnvironments(schools []models.School, teachers []models.User, config EducationalDataConfig) ([]models.Classroom, error) {
 	sc.logger.Info("Creating learning environments")
 
-	classGen := NewClassroomGenerator(sc.db, sc.logger)
+	classGen := NewClassroomGenerator(sc.db, sc.logger, sc.randomSeed)
 	academicConfig := GetDefaultAcademicConfig()
 
 	totalClassrooms := len(schools) * config.ClassroomsPerSchool
@@ -171,7 +176,7 @@ func (sc *SeedCoordinator) establishEnrollments(students []models.User, classroo
 func (sc *SeedCoordinator) createEducationalContent(classrooms []models.Classroom, config EducationalDataConfig) ([]models.Quiz, error) {
 	sc.logger.Info("Creating educational content")
 
-	quizGen := NewQuizGenerator(sc.db, sc.logger)
+	quizGen := NewQuizGenerator(sc.db, sc.logger, sc.randomSeed)
 	quizzes, err := quizGen.GenerateQuizzesForClassrooms(classrooms, config.QuizzesPerClassroom)
 	if err != nil {
 		return nil, fmt.Errorf("quiz generation failed: %w", err)
@@ -185,7 +190,7 @@ func (sc *SeedCoordinator) createEducationalContent(classrooms []models.Classroo
 func (sc *SeedCoordinator) simulateStudentEngagement(quizzes []models.Quiz) error {
 	sc.logger.Info("Simulating student engagement patterns")
 
-	responseGen := NewResponseGenerator(sc.db, sc.logger)
+	responseGen := NewResponseGenerator(sc.db, sc.logger, sc.randomSeed)
 	err := responseGen.GenerateResponsesForAllQuizzes(quizzes)
 	if err != nil {
 		return fmt.Errorf("response generation failed: %w", err)
@@ -200,9 +205,10 @@ func (sc *SeedCoordinator) generateUsageAnalytics(students []models.User, config
 	sc.logger.Info("Generating usage analytics data")
 
 	// Generate realistic session patterns
-	sessionGen := NewSessionGenerator(sc.db, sc.logger)
+	sessionGen := NewSessionGenerator(sc.db, sc.logger, sc.randomSeed)
 	sessionPattern := GetEducationalSessionPattern()
 	sessionPattern.SessionsPerUser = config.SessionsPerStudent
+	sessionPattern.Workers = config.GeneratorWorkers
 
 	// Limit session generation to avoid excessive data during development
 	limitedStudents := students
@@ -217,8 +223,9 @@ func (sc *SeedCoordinator) generateUsageAnalytics(students []models.User, config
 	}
 
 	// Generate events within sessions
-	eventGen := NewEventGenerator(sc.db, sc.logger)
+	eventGen := NewEventGenerator(sc.db, sc.logger, sc.randomSeed)
 	eventPattern := GetEducationalEventPattern()
+	eventPattern.Workers = config.GeneratorWorkers
 	err = eventGen.GenerateEventsForSessions(sessions, eventPattern)
 	if err != nil {
 		return fmt.Errorf("event generation failed: %w", err)
@@ -7,6 +7,7 @@ import (
 
 	"reporting-framework/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -66,6 +67,35 @@ func (sg *SchoolGenerator) GenerateSchools() ([]models.School, error) {
 	return schools, nil
 }
 
+// newGeneratorRand returns the random source for a generator. A non-zero seed
+// makes generation reproducible; zero falls back to a time-based seed. The
+// offset keeps generators that share a seed from drawing identical sequences.
+func newGeneratorRand(seed, offset int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed + offset))
+}
+
+// seededReferenceTime is the "now" that seeded runs date their data from,
+// so the same seed gives the same timestamps whenever it is run
+var seededReferenceTime = time.Date(2025, time.September, 1, 12, 0, 0, 0, time.UTC)
+
+// generatorNow returns the time a generator dates its data from: the fixed
+// reference time for a non-zero seed, the current time otherwise
+func generatorNow(seed int64) time.Time {
+	if seed == 0 {
+		return time.Now()
+	}
+	return seededReferenceTime
+}
+
+// seededUUID draws a version 4 UUID from rng, so seeded runs reuse the same ids
+func seededUUID(rng *rand.Rand) uuid.UUID {
+	id, _ := uuid.NewRandomFromReader(rng) // reading from a *rand.Rand never fails
+	return id
+}
+
 // UserGenerator handles creation of teachers and students
 type UserGenerator struct {
 	db     *gorm.DB
@@ -73,14 +103,13 @@ type UserGenerator struct {
 	rand   *rand.Rand
 }
 
-// NewUserGenerator creates a new user generator with its own random source
-func NewUserGenerator(db *gorm.DB, logger Logger) *UserGenerator {
-	// Create a new random source to avoid conflicts with other generators
-	source := rand.NewSource(time.Now().UnixNano())
+// NewUserGenerator creates a new user generator with its own random source.
+// Pass a non-zero seed for reproducible output.
+func NewUserGenerator(db *gorm.DB, logger Logger, seed int64) *UserGenerator {
 	return &UserGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 0),
 	}
 }
 
@@ -181,12 +210,11 @@ type ClassroomGenerator struct {
 }
 
 // NewClassroomGenerator creates a new classroom generator
-func NewClassroomGenerator(db *gorm.DB, logger Logger) *ClassroomGenerator {
-	source := rand.NewSource(time.Now().UnixNano() + 1000) // Offset to avoid collision
+func NewClassroomGenerator(db *gorm.DB, logger Logger, seed int64) *ClassroomGenerator {
 	return &ClassroomGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 1000), // Offset to avoid collision
 	}
 }
 
@@ -219,13 +247,28 @@ func (cg *ClassroomGenerator) GenerateClassrooms(schools []models.School, teache
 		return nil, fmt.Errorf("cannot generate classrooms: no teachers provided")
 	}
 
+	// Teachers may only teach classrooms in their own school
+	teachersBySchool := make(map[uuid.UUID][]models.User)
+	for _, teacher := range teachers {
+		if teacher.Role == "teacher" {
+			teachersBySchool[teacher.SchoolID] = append(teachersBySchool[teacher.SchoolID], teacher)
+		}
+	}
+
 	classrooms := make([]models.Classroom, 0, count)
+	assigned := make(map[uuid.UUID]int)
 
 	for i := 0; i < count; i++ {
 		subject := config.Subjects[i%len(config.Subjects)]
 		gradeLevel := config.GradeLevels[i%len(config.GradeLevels)]
 		school := schools[i%len(schools)]
-		teacher := teachers[i%len(teachers)]
+
+		schoolTeachers := teachersBySchool[school.ID]
+		if len(schoolTeachers) == 0 {
+			return nil, fmt.Errorf("cannot generate classrooms: school %s has no teachers", school.Name)
+		}
+		teacher := schoolTeachers[assigned[school.ID]%len(schoolTeachers)]
+		assigned[school.ID]++
 
 		// Generate realistic classroom names
 		classroomName := fmt.Sprintf("%s - %s (Room %d)", subject, gradeLevel, 100+i)
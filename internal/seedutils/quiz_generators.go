@@ -3,6 +3,7 @@ package seedutils
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	"reporting-framework/internal/models"
@@ -15,15 +16,16 @@ type QuizGenerator struct {
 	db     *gorm.DB
 	logger Logger
 	rand   *rand.Rand
+	now    time.Time
 }
 
 // NewQuizGenerator creates a new quiz generator instance
-func NewQuizGenerator(db *gorm.DB, logger Logger) *QuizGenerator {
-	source := rand.NewSource(time.Now().UnixNano() + 2000) // Unique offset
+func NewQuizGenerator(db *gorm.DB, logger Logger, seed int64) *QuizGenerator {
 	return &QuizGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 2000), // Unique offset
+		now:    generatorNow(seed),
 	}
 }
 
@@ -189,7 +191,7 @@ func (qg *QuizGenerator) generateQuizzesForSingleClassroom(classroom models.Clas
 
 		// Create realistic quiz timing - some recent, some older
 		daysAgo := qg.rand.Intn(60) // 0-60 days ago
-		publishedAt := time.Now().AddDate(0, 0, -daysAgo)
+		publishedAt := qg.now.AddDate(0, 0, -daysAgo)
 
 		quiz := models.Quiz{
 			Title:            fmt.Sprintf("%s Quiz #%d - %s", classroom.Subject, i+1, template.SubjectPattern),
@@ -250,15 +252,16 @@ type ResponseGenerator struct {
 	db     *gorm.DB
 	logger Logger
 	rand   *rand.Rand
+	now    time.Time
 }
 
 // NewResponseGenerator creates a new response generator
-func NewResponseGenerator(db *gorm.DB, logger Logger) *ResponseGenerator {
-	source := rand.NewSource(time.Now().UnixNano() + 3000)
+func NewResponseGenerator(db *gorm.DB, logger Logger, seed int64) *ResponseGenerator {
 	return &ResponseGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 3000),
+		now:    generatorNow(seed),
 	}
 }
 
@@ -358,7 +361,7 @@ func (rg *ResponseGenerator) generateResponsesForQuiz(quiz models.Quiz, pattern
 				hourOffset := rg.rand.Intn(24)
 				submittedAt = quiz.PublishedAt.AddDate(0, 0, dayOffset).Add(time.Duration(hourOffset) * time.Hour)
 			} else {
-				submittedAt = time.Now().AddDate(0, 0, -rg.rand.Intn(30))
+				submittedAt = rg.now.AddDate(0, 0, -rg.rand.Intn(30))
 			}
 
 			response := models.QuizResponse{
@@ -395,6 +398,9 @@ func (rg *ResponseGenerator) generateWrongAnswer(question models.QuizQuestion) s
 		}
 	}
 
+	// Map iteration order is random; sort so seeded runs pick the same answer
+	sort.Strings(wrongAnswers)
+
 	if len(wrongAnswers) == 0 {
 		// Fallback for questions without multiple choice options
 		return "incorrect_answer"
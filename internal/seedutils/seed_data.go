@@ -13,12 +13,15 @@ import (
 
 // SeedManager handles seeding the database with test data
 type SeedManager struct {
-	db *gorm.DB
+	db   *gorm.DB
+	rand *rand.Rand
+	now  time.Time // seeded data is dated relative to this
 }
 
-// NewSeedManager creates a new seed manager
-func NewSeedManager(db *gorm.DB) *SeedManager {
-	return &SeedManager{db: db}
+// NewSeedManager creates a new seed manager.
+// A non-zero randomSeed makes the seeded data reproducible.
+func NewSeedManager(db *gorm.DB, randomSeed int64) *SeedManager {
+	return &SeedManager{db: db, rand: newGeneratorRand(randomSeed, 0), now: generatorNow(randomSeed)}
 }
 
 // SeedAllData seeds the database with comprehensive test data
@@ -92,7 +95,7 @@ func (s *SeedManager) seedSchools(count int) ([]reporting.School, error) {
 
 	for i := 0; i < count; i++ {
 		school := reporting.School{
-			ID:       uuid.New(),
+			ID:       seededUUID(s.rand),
 			Name:     schoolNames[i%len(schoolNames)] + fmt.Sprintf(" #%d", i+1),
 			District: &districts[i%len(districts)],
 			Region:   &regions[i%len(regions)],
@@ -116,7 +119,7 @@ func (s *SeedManager) seedClassrooms(schools []reporting.School, classroomsPerSc
 	for _, school := range schools {
 		for i := 0; i < classroomsPerSchool; i++ {
 			classroom := reporting.Classroom{
-				ID:         uuid.New(),
+				ID:         seededUUID(s.rand),
 				SchoolID:   school.ID,
 				Name:       fmt.Sprintf("Room %d%02d", (i/10)+1, (i%10)+1),
 				Subject:    &subjects[i%len(subjects)],
@@ -141,7 +144,7 @@ func (s *SeedManager) seedUsers(schools []reporting.School, classrooms []reporti
 	// Create teachers (1 per classroom)
 	for _, classroom := range classrooms {
 		teacher := reporting.User{
-			ID:       uuid.New(),
+			ID:       seededUUID(s.rand),
 			SchoolID: classroom.SchoolID,
 			Username: fmt.Sprintf("teacher_%s", classroom.ID.String()[:8]),
 			Role:     "teacher",
@@ -155,7 +158,7 @@ func (s *SeedManager) seedUsers(schools []reporting.School, classrooms []reporti
 		teacher.LastName = &lastName
 		teacher.Email = &email
 		teacher.LastActive = &time.Time{}
-		*teacher.LastActive = time.Now().Add(-time.Duration(rand.Intn(24)) * time.Hour)
+		*teacher.LastActive = s.now.Add(-time.Duration(s.rand.Intn(24)) * time.Hour)
 
 		users = append(users, teacher)
 
@@ -177,7 +180,7 @@ func (s *SeedManager) seedUsers(schools []reporting.School, classrooms []reporti
 		for i := 0; i < 30; i++ {
 			studentCount++
 			student := reporting.User{
-				ID:       uuid.New(),
+				ID:       seededUUID(s.rand),
 				SchoolID: classroom.SchoolID,
 				Username: fmt.Sprintf("student_%d", studentCount),
 				Role:     "student",
@@ -191,7 +194,7 @@ func (s *SeedManager) seedUsers(schools []reporting.School, classrooms []reporti
 			student.LastName = &lastName
 			student.Email = &email
 			student.LastActive = &time.Time{}
-			*student.LastActive = time.Now().Add(-time.Duration(rand.Intn(72)) * time.Hour)
+			*student.LastActive = s.now.Add(-time.Duration(s.rand.Intn(72)) * time.Hour)
 
 			users = append(users, student)
 
@@ -239,43 +242,43 @@ func (s *SeedManager) seedQuizzes(classrooms []reporting.Classroom, users []repo
 		}
 
 		// Create 3-5 quizzes per classroom
-		numQuizzes := 3 + rand.Intn(3)
+		numQuizzes := 3 + s.rand.Intn(3)
 		for i := 0; i < numQuizzes; i++ {
 			quiz := reporting.Quiz{
-				ID:          uuid.New(),
+				ID:          seededUUID(s.rand),
 				CreatorID:   *classroom.TeacherID,
 				ClassroomID: classroom.ID,
-				Title:       quizTitles[rand.Intn(len(quizTitles))],
-				MaxAttempts: 1 + rand.Intn(3),
-				IsActive:    rand.Float32() < 0.3, // 30% chance of being active
+				Title:       quizTitles[s.rand.Intn(len(quizTitles))],
+				MaxAttempts: 1 + s.rand.Intn(3),
+				IsActive:    s.rand.Float32() < 0.3, // 30% chance of being active
 			}
 
 			description := fmt.Sprintf("Assessment for %s classroom", *classroom.Subject)
 			quiz.Description = &description
 
 			if quiz.IsActive {
-				startTime := time.Now().Add(-time.Duration(rand.Intn(24)) * time.Hour)
-				endTime := startTime.Add(time.Duration(60+rand.Intn(120)) * time.Minute)
+				startTime := s.now.Add(-time.Duration(s.rand.Intn(24)) * time.Hour)
+				endTime := startTime.Add(time.Duration(60+s.rand.Intn(120)) * time.Minute)
 				quiz.StartTime = &startTime
 				quiz.EndTime = &endTime
 			}
 
-			timeLimit := 30 + rand.Intn(90)
+			timeLimit := 30 + s.rand.Intn(90)
 			quiz.TimeLimitMinutes = &timeLimit
 
 			quizzes = append(quizzes, quiz)
 
 			// Create 5-10 questions per quiz
-			numQuestions := 5 + rand.Intn(6)
+			numQuestions := 5 + s.rand.Intn(6)
 			quiz.TotalQuestions = numQuestions
 
 			for j := 0; j < numQuestions; j++ {
 				question := reporting.QuizQuestion{
-					ID:           uuid.New(),
+					ID:           seededUUID(s.rand),
 					QuizID:       quiz.ID,
 					QuestionText: fmt.Sprintf("Question %d for %s", j+1, quiz.Title),
-					QuestionType: []string{"multiple_choice", "true_false", "short_answer"}[rand.Intn(3)],
-					Points:       1 + rand.Intn(5),
+					QuestionType: []string{"multiple_choice", "true_false", "short_answer"}[s.rand.Intn(3)],
+					Points:       1 + s.rand.Intn(5),
 					OrderIndex:   j + 1,
 				}
 
@@ -288,11 +291,11 @@ func (s *SeedManager) seedQuizzes(classrooms []reporting.Classroom, users []repo
 					}
 					question.Options = reporting.JSONB(options)
 					answers := []string{"A", "B", "C", "D"}
-					correctAnswer := answers[rand.Intn(len(answers))]
+					correctAnswer := answers[s.rand.Intn(len(answers))]
 					question.CorrectAnswer = &correctAnswer
 				} else if question.QuestionType == "true_false" {
 					answers := []string{"true", "false"}
-					correctAnswer := answers[rand.Intn(len(answers))]
+					correctAnswer := answers[s.rand.Intn(len(answers))]
 					question.CorrectAnswer = &correctAnswer
 				}
 
@@ -327,21 +330,21 @@ func (s *SeedManager) seedSessionsAndEvents(users []reporting.User, classrooms [
 	}
 
 	for d := 0; d < days; d++ {
-		date := time.Now().AddDate(0, 0, -days+d)
+		date := s.now.AddDate(0, 0, -days+d)
 
 		// Simulate 60-80% of users being active each day
-		activeUsers := users[0:int(float64(len(users)) * (0.6 + rand.Float64()*0.2))]
+		activeUsers := users[0:int(float64(len(users)) * (0.6 + s.rand.Float64()*0.2))]
 
 		for _, user := range activeUsers {
 			// Each active user has 1-3 sessions per day
-			numSessions := 1 + rand.Intn(3)
+			numSessions := 1 + s.rand.Intn(3)
 
 			for i := 0; i < numSessions; i++ {
 				session := reporting.Session{
-					ID:          uuid.New(),
+					ID:          seededUUID(s.rand),
 					UserID:      user.ID,
-					Application: applications[rand.Intn(len(applications))],
-					StartTime:   date.Add(time.Duration(8+rand.Intn(10)) * time.Hour),
+					Application: applications[s.rand.Intn(len(applications))],
+					StartTime:   date.Add(time.Duration(8+s.rand.Intn(10)) * time.Hour),
 				}
 
 				// Assign classroom based on user role
@@ -358,7 +361,7 @@ func (s *SeedManager) seedSessionsAndEvents(users []reporting.User, classrooms [
 				}
 
 				// Session duration: 15-120 minutes
-				duration := 15 + rand.Intn(105)
+				duration := 15 + s.rand.Intn(105)
 				endTime := session.StartTime.Add(time.Duration(duration) * time.Minute)
 				session.EndTime = &endTime
 				session.DurationSeconds = &[]int{duration * 60}[0]
@@ -374,11 +377,11 @@ func (s *SeedManager) seedSessionsAndEvents(users []reporting.User, classrooms [
 				sessions = append(sessions, session)
 
 				// Generate 5-20 events per session
-				numEvents := 5 + rand.Intn(16)
+				numEvents := 5 + s.rand.Intn(16)
 				for j := 0; j < numEvents; j++ {
 					event := reporting.Event{
-						ID:          uuid.New(),
-						EventType:   eventTypes[rand.Intn(len(eventTypes))],
+						ID:          seededUUID(s.rand),
+						EventType:   eventTypes[s.rand.Intn(len(eventTypes))],
 						UserID:      &user.ID,
 						SessionID:   &session.ID,
 						ClassroomID: session.ClassroomID,
@@ -389,8 +392,8 @@ func (s *SeedManager) seedSessionsAndEvents(users []reporting.User, classrooms [
 
 					// Event metadata
 					metadata := map[string]interface{}{
-						"duration": rand.Intn(300),
-						"action_count": rand.Intn(50),
+						"duration": s.rand.Intn(300),
+						"action_count": s.rand.Intn(50),
 					}
 					event.Metadata = reporting.JSONB(metadata)
 					event.DeviceInfo = session.DeviceInfo
@@ -433,7 +436,7 @@ func (s *SeedManager) seedQuizData(quizzes []reporting.Quiz, users []reporting.U
 		}
 
 		// 60-90% of students participate in each quiz
-		participationRate := 0.6 + rand.Float64()*0.3
+		participationRate := 0.6 + s.rand.Float64()*0.3
 		participatingStudents := students[0:int(float64(len(students)) * participationRate)]
 
 		// Get quiz questions
@@ -443,10 +446,10 @@ func (s *SeedManager) seedQuizData(quizzes []reporting.Quiz, users []reporting.U
 		for _, student := range participatingStudents {
 			// Create quiz session
 			session := reporting.QuizSession{
-				ID:        uuid.New(),
+				ID:        seededUUID(s.rand),
 				QuizID:    quiz.ID,
 				StudentID: student.ID,
-				StartedAt: time.Now().Add(-time.Duration(rand.Intn(168)) * time.Hour),
+				StartedAt: s.now.Add(-time.Duration(s.rand.Intn(168)) * time.Hour),
 			}
 
 			totalScore := 0
@@ -455,17 +458,17 @@ func (s *SeedManager) seedQuizData(quizzes []reporting.Quiz, users []reporting.U
 			// Create submissions for each question
 			for _, question := range questions {
 				submission := reporting.QuizSubmission{
-					ID:         uuid.New(),
+					ID:         seededUUID(s.rand),
 					QuizID:     quiz.ID,
 					StudentID:  student.ID,
 					QuestionID: question.ID,
-					TimeSpentSeconds: func() *int { v := 30 + rand.Intn(120); return &v }(),
+					TimeSpentSeconds: func() *int { v := 30 + s.rand.Intn(120); return &v }(),
 				}
 
 				maxScore += question.Points
 
 				// Simulate answer correctness (70% correct on average)
-				isCorrect := rand.Float32() < 0.7
+				isCorrect := s.rand.Float32() < 0.7
 				submission.IsCorrect = &isCorrect
 
 				if isCorrect {
@@ -479,7 +482,7 @@ func (s *SeedManager) seedQuizData(quizzes []reporting.Quiz, users []reporting.U
 					if isCorrect && question.CorrectAnswer != nil {
 						submission.SubmittedAnswer = question.CorrectAnswer
 					} else {
-						answer := answers[rand.Intn(len(answers))]
+						answer := answers[s.rand.Intn(len(answers))]
 						submission.SubmittedAnswer = &answer
 					}
 				} else if question.QuestionType == "true_false" {
@@ -487,7 +490,7 @@ func (s *SeedManager) seedQuizData(quizzes []reporting.Quiz, users []reporting.U
 						submission.SubmittedAnswer = question.CorrectAnswer
 					} else {
 						answers := []string{"true", "false"}
-						answer := answers[rand.Intn(len(answers))]
+						answer := answers[s.rand.Intn(len(answers))]
 						submission.SubmittedAnswer = &answer
 					}
 				} else {
@@ -540,15 +543,15 @@ func (s *SeedManager) seedContent(users []reporting.User, classrooms []reporting
 
 		// Each user creates 5-15 pieces of content
 		for _, user := range classroomUsers {
-			numContent := 5 + rand.Intn(11)
+			numContent := 5 + s.rand.Intn(11)
 			for i := 0; i < numContent; i++ {
 				item := reporting.Content{
-					ID:          uuid.New(),
+					ID:          seededUUID(s.rand),
 					CreatorID:   user.ID,
 					ClassroomID: &classroom.ID,
-					ContentType: contentTypes[rand.Intn(len(contentTypes))],
-					IsShared:    rand.Float32() < 0.3, // 30% shared
-					FileSizeBytes: int64(1024 + rand.Intn(1024*1024)), // 1KB to 1MB
+					ContentType: contentTypes[s.rand.Intn(len(contentTypes))],
+					IsShared:    s.rand.Float32() < 0.3, // 30% shared
+					FileSizeBytes: int64(1024 + s.rand.Intn(1024*1024)), // 1KB to 1MB
 				}
 
 				title := fmt.Sprintf("%s Content %d", item.ContentType, i+1)
@@ -587,7 +590,7 @@ func (s *SeedManager) generateAggregatedMetrics() error {
 
 	// Generate daily user metrics for the past 30 days
 	for d := 0; d < 30; d++ {
-		date := time.Now().AddDate(0, 0, -30+d)
+		date := s.now.AddDate(0, 0, -30+d)
 
 		// This is a simplified version - in production, this would be calculated from actual events
 		err := s.db.Exec(`
@@ -625,7 +628,7 @@ func (s *SeedManager) generateAggregatedMetrics() error {
 		SELECT
 			c.id as classroom_id,
 			c.school_id,
-			CURRENT_DATE - INTERVAL '1 day' * generate_series(0, 29) as date,
+			?::date - INTERVAL '1 day' * generate_series(0, 29) as date,
 			30 as total_students,
 			(20 + FLOOR(RANDOM() * 10))::int as active_students_count,
 			(65 + RANDOM() * 30)::decimal(5,2) as participation_rate,
@@ -633,7 +636,7 @@ func (s *SeedManager) generateAggregatedMetrics() error {
 			(70 + RANDOM() * 25)::decimal(5,2) as engagement_score
 		FROM classrooms c
 		ON CONFLICT (classroom_id, date) DO NOTHING
-	`).Error
+	`, s.now).Error
 
 	return err
 }
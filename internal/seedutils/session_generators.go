@@ -1,8 +1,11 @@
 package seedutils
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"reporting-framework/internal/models"
@@ -10,20 +13,23 @@ import (
 	"gorm.io/gorm"
 )
 
-// SessionGenerator handles creation of realistic user session data
+// SessionGenerator handles creation of realistic user session data.
+// A generator's own random source is only read before workers start, so
+// one generator must not run concurrent Generate calls.
 type SessionGenerator struct {
 	db     *gorm.DB
 	logger Logger
 	rand   *rand.Rand
+	now    time.Time
 }
 
 // NewSessionGenerator creates a new session generator
-func NewSessionGenerator(db *gorm.DB, logger Logger) *SessionGenerator {
-	source := rand.NewSource(time.Now().UnixNano() + 4000)
+func NewSessionGenerator(db *gorm.DB, logger Logger, seed int64) *SessionGenerator {
 	return &SessionGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 4000),
+		now:    generatorNow(seed),
 	}
 }
 
@@ -36,6 +42,8 @@ type SessionPattern struct {
 	AppVersions     []string // Different app versions
 	SessionsPerUser int      // Average sessions per user
 	DaysBack        int      // How many days back to generate sessions
+	Workers         int      // Number of concurrent generation workers
+	BatchSize       int      // Rows per batched insert within a worker
 }
 
 // GetEducationalSessionPattern returns realistic patterns for educational app usage
@@ -48,6 +56,8 @@ func GetEducationalSessionPattern() SessionPattern {
 		AppVersions:     []string{"2.0.1", "2.1.0", "2.1.1", "2.2.0"},
 		SessionsPerUser: 15, // 15 sessions per user over the time period
 		DaysBack:        30, // Generate sessions over last 30 days
+		Workers:         4,
+		BatchSize:       100,
 	}
 }
 
@@ -59,9 +69,6 @@ func (sg *SessionGenerator) GenerateSessionsForUsers(users []models.User, patter
 		return nil, fmt.Errorf("cannot generate sessions: no users provided")
 	}
 
-	var allSessions []models.Session
-	totalSessions := 0
-
 	// Limit to first 200 users to avoid excessive data during development
 	maxUsers := 200
 	if len(users) > maxUsers {
@@ -69,62 +76,82 @@ func (sg *SessionGenerator) GenerateSessionsForUsers(users []models.User, patter
 		sg.logger.Info("Limiting session generation to avoid excessive data", "limitedTo", maxUsers)
 	}
 
-	for i, user := range users {
-		userSessions, err := sg.generateSessionsForUser(user, pattern)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate sessions for user %s: %w", user.Username, err)
-		}
+	workers := workerCount(pattern.Workers, len(users))
+	rngs := workerRands(sg.rand, workers)
+
+	// Each worker owns a fixed stripe of users so output is stable for a given
+	// seed; workers only write their own users' slots, so no locking is needed
+	sessionsByUser := make([][]models.Session, len(users))
+
+	group := newWorkerGroup()
+	for w := 0; w < workers; w++ {
+		worker := w
+		group.Go(func(ctx context.Context) error {
+			var owned []*models.Session
+			for i := worker; i < len(users); i += workers {
+				sessionsByUser[i] = sg.buildSessionsForUser(users[i], pattern, rngs[worker])
+				for j := range sessionsByUser[i] {
+					owned = append(owned, &sessionsByUser[i][j])
+				}
+			}
+
+			if len(owned) == 0 {
+				return nil
+			}
+
+			// Batched insert populates IDs in place so events can reference them
+			if err := sg.db.WithContext(ctx).CreateInBatches(owned, batchSize(pattern.BatchSize)).Error; err != nil {
+				return fmt.Errorf("worker %d failed to save sessions: %w", worker, err)
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
 
+	var allSessions []models.Session
+	for _, userSessions := range sessionsByUser {
 		allSessions = append(allSessions, userSessions...)
-		totalSessions += len(userSessions)
-
-		if (i+1)%50 == 0 {
-			sg.logger.Debug("Session generation progress", "users_processed", i+1, "total_sessions", totalSessions)
-		}
 	}
 
-	sg.logger.Info("Successfully generated all sessions", "totalSessions", totalSessions)
+	sg.logger.Info("Successfully generated all sessions", "totalSessions", len(allSessions), "workers", workers)
 	return allSessions, nil
 }
 
-// generateSessionsForUser creates sessions for a single user
-func (sg *SessionGenerator) generateSessionsForUser(user models.User, pattern SessionPattern) ([]models.Session, error) {
-	var sessions []models.Session
+// buildSessionsForUser creates in-memory sessions for a single user
+func (sg *SessionGenerator) buildSessionsForUser(user models.User, pattern SessionPattern, rng *rand.Rand) []models.Session {
+	sessions := make([]models.Session, 0, pattern.SessionsPerUser)
 
 	for i := 0; i < pattern.SessionsPerUser; i++ {
-		session, err := sg.createRealisticSession(user, pattern)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create session %d for user %s: %w", i+1, user.Username, err)
-		}
-
-		sessions = append(sessions, session)
+		sessions = append(sessions, sg.buildRealisticSession(user, pattern, rng))
 	}
 
-	return sessions, nil
+	return sessions
 }
 
-// createRealisticSession generates a single realistic session
-func (sg *SessionGenerator) createRealisticSession(user models.User, pattern SessionPattern) (models.Session, error) {
+// buildRealisticSession generates a single realistic session without saving it
+func (sg *SessionGenerator) buildRealisticSession(user models.User, pattern SessionPattern, rng *rand.Rand) models.Session {
 	// Generate random start time within the specified range
-	daysAgo := sg.rand.Intn(pattern.DaysBack)
-	hour := 8 + sg.rand.Intn(10) // Sessions mostly during school hours (8 AM - 6 PM)
-	minute := sg.rand.Intn(60)
+	daysAgo := rng.Intn(pattern.DaysBack)
+	hour := 8 + rng.Intn(10) // Sessions mostly during school hours (8 AM - 6 PM)
+	minute := rng.Intn(60)
 
-	startTime := time.Now().
+	startTime := sg.now.
 		AddDate(0, 0, -daysAgo).
 		Truncate(24 * time.Hour). // Start of day
 		Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
 
 	// Generate session duration
-	duration := pattern.MinDuration + sg.rand.Intn(pattern.MaxDuration-pattern.MinDuration)
+	duration := pattern.MinDuration + rng.Intn(pattern.MaxDuration-pattern.MinDuration)
 	endTime := startTime.Add(time.Duration(duration) * time.Second)
 
 	// Select random application and device
-	application := pattern.Applications[sg.rand.Intn(len(pattern.Applications))]
-	deviceType := pattern.DeviceTypes[sg.rand.Intn(len(pattern.DeviceTypes))]
-	appVersion := pattern.AppVersions[sg.rand.Intn(len(pattern.AppVersions))]
+	application := pattern.Applications[rng.Intn(len(pattern.Applications))]
+	deviceType := pattern.DeviceTypes[rng.Intn(len(pattern.DeviceTypes))]
+	appVersion := pattern.AppVersions[rng.Intn(len(pattern.AppVersions))]
 
-	session := models.Session{
+	return models.Session{
 		UserID:          user.ID,
 		Application:     application,
 		StartTime:       startTime,
@@ -133,28 +160,90 @@ func (sg *SessionGenerator) createRealisticSession(user models.User, pattern Ses
 		DeviceType:      deviceType,
 		AppVersion:      appVersion,
 	}
+}
 
-	if err := sg.db.Create(&session).Error; err != nil {
-		return models.Session{}, fmt.Errorf("failed to save session to database: %w", err)
+// workerCount clamps the configured worker count to the available work
+func workerCount(configured, items int) int {
+	if configured < 1 {
+		configured = 1
+	}
+	if items > 0 && configured > items {
+		configured = items
 	}
+	return configured
+}
 
-	return session, nil
+// workerGroup runs seed workers against context-scoped db sessions. The first
+// failure cancels the context so the remaining workers' inserts stop early.
+type workerGroup struct {
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	err    error
+}
+
+func newWorkerGroup() *workerGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &workerGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a new goroutine. fn must scope its db work to ctx, e.g. with
+// db.WithContext(ctx), so each worker writes through its own session.
+func (g *workerGroup) Go(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(g.ctx); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
 }
 
-// EventGenerator creates realistic user interaction events
+// Wait blocks until every worker returns and reports the first failure
+func (g *workerGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// workerRands derives one random source per worker from the generator's source,
+// keeping each worker's output deterministic relative to the generator seed
+func workerRands(parent *rand.Rand, workers int) []*rand.Rand {
+	rngs := make([]*rand.Rand, workers)
+	for i := range rngs {
+		rngs[i] = rand.New(rand.NewSource(parent.Int63()))
+	}
+	return rngs
+}
+
+// batchSize returns the configured insert batch size or a sensible default
+func batchSize(configured int) int {
+	if configured < 1 {
+		return 100
+	}
+	return configured
+}
+
+// EventGenerator creates realistic user interaction events. Like
+// SessionGenerator, one generator must not run concurrent Generate calls.
 type EventGenerator struct {
 	db     *gorm.DB
 	logger Logger
 	rand   *rand.Rand
+	now    time.Time
 }
 
 // NewEventGenerator creates a new event generator
-func NewEventGenerator(db *gorm.DB, logger Logger) *EventGenerator {
-	source := rand.NewSource(time.Now().UnixNano() + 5000)
+func NewEventGenerator(db *gorm.DB, logger Logger, seed int64) *EventGenerator {
 	return &EventGenerator{
 		db:     db,
 		logger: logger,
-		rand:   rand.New(source),
+		rand:   newGeneratorRand(seed, 5000),
+		now:    generatorNow(seed),
 	}
 }
 
@@ -163,6 +252,8 @@ type EventPattern struct {
 	EventTypes       []string
 	MinEventsPerSession int
 	MaxEventsPerSession int
+	Workers             int // Number of concurrent generation workers
+	BatchSize           int // Rows per batched insert within a worker
 }
 
 // GetEducationalEventPattern returns realistic event patterns for educational apps
@@ -181,6 +272,8 @@ func GetEducationalEventPattern() EventPattern {
 		},
 		MinEventsPerSession: 5,
 		MaxEventsPerSession: 25,
+		Workers:             4,
+		BatchSize:           500,
 	}
 }
 
@@ -188,111 +281,122 @@ func GetEducationalEventPattern() EventPattern {
 func (eg *EventGenerator) GenerateEventsForSessions(sessions []models.Session, pattern EventPattern) error {
 	eg.logger.Info("Starting event generation for sessions", "sessionCount", len(sessions))
 
-	totalEvents := 0
-
-	for i, session := range sessions {
-		events, err := eg.generateEventsForSession(session, pattern)
-		if err != nil {
-			return fmt.Errorf("failed to generate events for session %s: %w", session.ID, err)
-		}
-
-		totalEvents += events
-
-		if (i+1)%100 == 0 {
-			eg.logger.Debug("Event generation progress", "sessions_processed", i+1, "total_events", totalEvents)
-		}
+	workers := workerCount(pattern.Workers, len(sessions))
+	rngs := workerRands(eg.rand, workers)
+
+	var totalEvents atomic.Int64
+
+	group := newWorkerGroup()
+	for w := 0; w < workers; w++ {
+		worker := w
+		group.Go(func(ctx context.Context) error {
+			var events []models.Event
+			for i := worker; i < len(sessions); i += workers {
+				events = append(events, eg.buildEventsForSession(sessions[i], pattern, rngs[worker])...)
+			}
+
+			if len(events) == 0 {
+				return nil
+			}
+
+			if err := eg.db.WithContext(ctx).CreateInBatches(events, batchSize(pattern.BatchSize)).Error; err != nil {
+				return fmt.Errorf("worker %d failed to save events: %w", worker, err)
+			}
+			totalEvents.Add(int64(len(events)))
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
 	}
 
-	eg.logger.Info("Successfully generated events for all sessions", "totalEvents", totalEvents)
+	eg.logger.Info("Successfully generated events for all sessions", "totalEvents", totalEvents.Load(), "workers", workers)
 	return nil
 }
 
-// generateEventsForSession creates events for a single session
-func (eg *EventGenerator) generateEventsForSession(session models.Session, pattern EventPattern) (int, error) {
+// buildEventsForSession creates in-memory events for a single saved session
+func (eg *EventGenerator) buildEventsForSession(session models.Session, pattern EventPattern, rng *rand.Rand) []models.Event {
 	if session.EndTime == nil || session.DurationSeconds == nil {
 		// Skip incomplete sessions
-		return 0, nil
+		return nil
 	}
 
-	eventCount := pattern.MinEventsPerSession + eg.rand.Intn(pattern.MaxEventsPerSession-pattern.MinEventsPerSession+1)
+	eventCount := pattern.MinEventsPerSession + rng.Intn(pattern.MaxEventsPerSession-pattern.MinEventsPerSession+1)
 	sessionDuration := time.Duration(*session.DurationSeconds) * time.Second
+	events := make([]models.Event, 0, eventCount)
 
 	for i := 0; i < eventCount; i++ {
 		// Generate event time within session duration
-		eventOffset := time.Duration(eg.rand.Int63n(int64(sessionDuration)))
+		eventOffset := time.Duration(rng.Int63n(int64(sessionDuration)))
 		eventTime := session.StartTime.Add(eventOffset)
 
-		eventType := pattern.EventTypes[eg.rand.Intn(len(pattern.EventTypes))]
+		eventType := pattern.EventTypes[rng.Intn(len(pattern.EventTypes))]
 
-		event := models.Event{
+		events = append(events, models.Event{
 			EventType:   eventType,
 			UserID:      session.UserID,
 			SessionID:   session.ID,
 			Timestamp:   eventTime,
 			Application: session.Application,
-			Payload:     eg.generateEventPayload(eventType, session.Application),
-			Metadata:    eg.generateEventMetadata(session),
-		}
-
-		if err := eg.db.Create(&event).Error; err != nil {
-			return i, fmt.Errorf("failed to create event %d: %w", i+1, err)
-		}
+			Payload:     eg.generateEventPayload(eventType, session.Application, rng),
+			Metadata:    eg.generateEventMetadata(session, rng),
+		})
 	}
 
-	return eventCount, nil
+	return events
 }
 
 // generateEventPayload creates realistic payload data based on event type
-func (eg *EventGenerator) generateEventPayload(eventType, application string) models.JSONB {
+func (eg *EventGenerator) generateEventPayload(eventType, application string, rng *rand.Rand) models.JSONB {
 	basePayload := models.JSONB{
-		"timestamp": time.Now().Unix(),
+		"timestamp": eg.now.Unix(),
 		"application": application,
 	}
 
 	switch eventType {
 	case "page_view":
-		basePayload["page_id"] = fmt.Sprintf("page_%d", eg.rand.Intn(100))
-		basePayload["view_duration"] = 30 + eg.rand.Intn(300) // 30 seconds to 5 minutes
+		basePayload["page_id"] = fmt.Sprintf("page_%d", rng.Intn(100))
+		basePayload["view_duration"] = 30 + rng.Intn(300) // 30 seconds to 5 minutes
 
 	case "quiz_answer_submitted":
-		basePayload["quiz_id"] = fmt.Sprintf("quiz_%d", eg.rand.Intn(50))
-		basePayload["question_id"] = fmt.Sprintf("q_%d", eg.rand.Intn(10))
-		basePayload["answer"] = []string{"A", "B", "C", "D"}[eg.rand.Intn(4)]
-		basePayload["time_taken_seconds"] = 15 + eg.rand.Intn(120)
+		basePayload["quiz_id"] = fmt.Sprintf("quiz_%d", rng.Intn(50))
+		basePayload["question_id"] = fmt.Sprintf("q_%d", rng.Intn(10))
+		basePayload["answer"] = []string{"A", "B", "C", "D"}[rng.Intn(4)]
+		basePayload["time_taken_seconds"] = 15 + rng.Intn(120)
 
 	case "content_created":
 		contentTypes := []string{"note", "drawing", "document", "presentation"}
-		basePayload["content_type"] = contentTypes[eg.rand.Intn(len(contentTypes))]
-		basePayload["content_size"] = 100 + eg.rand.Intn(10000) // Size in characters/bytes
+		basePayload["content_type"] = contentTypes[rng.Intn(len(contentTypes))]
+		basePayload["content_size"] = 100 + rng.Intn(10000) // Size in characters/bytes
 
 	case "note_taken":
-		basePayload["note_length"] = 50 + eg.rand.Intn(500)
-		basePayload["note_category"] = []string{"lesson", "homework", "reminder", "idea"}[eg.rand.Intn(4)]
+		basePayload["note_length"] = 50 + rng.Intn(500)
+		basePayload["note_category"] = []string{"lesson", "homework", "reminder", "idea"}[rng.Intn(4)]
 
 	case "drawing_created":
-		basePayload["drawing_tools_used"] = eg.rand.Intn(5) + 1
-		basePayload["drawing_time"] = 60 + eg.rand.Intn(600) // 1-10 minutes
+		basePayload["drawing_tools_used"] = rng.Intn(5) + 1
+		basePayload["drawing_time"] = 60 + rng.Intn(600) // 1-10 minutes
 
 	case "video_watched":
-		basePayload["video_id"] = fmt.Sprintf("video_%d", eg.rand.Intn(200))
-		basePayload["watch_duration"] = 30 + eg.rand.Intn(1800) // 30 seconds to 30 minutes
-		basePayload["completion_percentage"] = eg.rand.Float64() * 100
+		basePayload["video_id"] = fmt.Sprintf("video_%d", rng.Intn(200))
+		basePayload["watch_duration"] = 30 + rng.Intn(1800) // 30 seconds to 30 minutes
+		basePayload["completion_percentage"] = rng.Float64() * 100
 
 	case "collaborative_edit":
-		basePayload["document_id"] = fmt.Sprintf("doc_%d", eg.rand.Intn(100))
-		basePayload["edit_type"] = []string{"text_added", "text_deleted", "formatting", "comment"}[eg.rand.Intn(4)]
-		basePayload["collaborators"] = eg.rand.Intn(5) + 1
+		basePayload["document_id"] = fmt.Sprintf("doc_%d", rng.Intn(100))
+		basePayload["edit_type"] = []string{"text_added", "text_deleted", "formatting", "comment"}[rng.Intn(4)]
+		basePayload["collaborators"] = rng.Intn(5) + 1
 
 	default:
 		basePayload["action"] = "generic_interaction"
-		basePayload["duration"] = eg.rand.Intn(300)
+		basePayload["duration"] = rng.Intn(300)
 	}
 
 	return basePayload
 }
 
 // generateEventMetadata creates realistic metadata for events
-func (eg *EventGenerator) generateEventMetadata(session models.Session) models.JSONB {
+func (eg *EventGenerator) generateEventMetadata(session models.Session, rng *rand.Rand) models.JSONB {
 	userAgents := []string{
 		"Mozilla/5.0 (iPad; CPU OS 14_0 like Mac OS X) AppleWebKit/605.1.15",
 		"Mozilla/5.0 (X11; CrOS armv7l 13904.77.0) AppleWebKit/537.36",
@@ -304,15 +408,15 @@ func (eg *EventGenerator) generateEventMetadata(session models.Session) models.J
 		"192.168.1.", "10.0.0.", "172.16.1.", "192.168.100.",
 	}
 
-	selectedIP := ipRanges[eg.rand.Intn(len(ipRanges))] + fmt.Sprintf("%d", 1+eg.rand.Intn(254))
+	selectedIP := ipRanges[rng.Intn(len(ipRanges))] + fmt.Sprintf("%d", 1+rng.Intn(254))
 
 	return models.JSONB{
-		"user_agent":      userAgents[eg.rand.Intn(len(userAgents))],
+		"user_agent":      userAgents[rng.Intn(len(userAgents))],
 		"ip_address":      selectedIP,
 		"device_type":     session.DeviceType,
 		"app_version":     session.AppVersion,
-		"screen_size":     fmt.Sprintf("%dx%d", 1024+eg.rand.Intn(1920), 768+eg.rand.Intn(1080)),
-		"connection_type": []string{"wifi", "ethernet", "cellular"}[eg.rand.Intn(3)],
+		"screen_size":     fmt.Sprintf("%dx%d", 1024+rng.Intn(1920), 768+rng.Intn(1080)),
+		"connection_type": []string{"wifi", "ethernet", "cellular"}[rng.Intn(3)],
 		"location":        "school_network",
 	}
 }
\ No newline at end of file
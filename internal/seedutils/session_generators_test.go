@@ -0,0 +1,179 @@
+package seedutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"reporting-framework/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// nopLogger discards generator logging
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+func testUsers(n int) []models.User {
+	users := make([]models.User, n)
+	for i := range users {
+		users[i] = models.User{ID: uuid.New(), Role: "student"}
+	}
+	return users
+}
+
+func TestSessionGeneratorSameSeedSameSessions(t *testing.T) {
+	user := testUsers(1)[0]
+	pattern := GetEducationalSessionPattern()
+
+	build := func(seed int64) []models.Session {
+		sg := NewSessionGenerator(nil, nopLogger{}, seed)
+		return sg.buildSessionsForUser(user, pattern, workerRands(sg.rand, 1)[0])
+	}
+
+	first, second := build(7), build(7)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("sessions differ between runs with the same seed")
+	}
+	if reflect.DeepEqual(first, build(8)) {
+		t.Error("a different seed produced identical sessions")
+	}
+}
+
+// BenchmarkGenerateSessionsAndEvents compares serial generation with worker
+// pools. Each insert waits a simulated server round trip, which is what the
+// workers overlap.
+func BenchmarkGenerateSessionsAndEvents(b *testing.B) {
+	const roundTrip = 2 * time.Millisecond
+	users := testUsers(200)
+
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			db, _ := dryRunDB(b)
+			err := db.Callback().Create().After("gorm:create").Register("bench:round_trip", func(*gorm.DB) {
+				time.Sleep(roundTrip)
+			})
+			if err != nil {
+				b.Fatalf("register callback: %v", err)
+			}
+
+			sessionPattern := GetEducationalSessionPattern()
+			sessionPattern.Workers = workers
+			eventPattern := GetEducationalEventPattern()
+			eventPattern.Workers = workers
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sessions, err := NewSessionGenerator(db, nopLogger{}, 1).GenerateSessionsForUsers(users, sessionPattern)
+				if err != nil {
+					b.Fatalf("generate sessions: %v", err)
+				}
+				if err := NewEventGenerator(db, nopLogger{}, 1).GenerateEventsForSessions(sessions, eventPattern); err != nil {
+					b.Fatalf("generate events: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestParallelGenerationCounts runs the session and event workers together;
+// run it with -race to check they share nothing unsynchronized
+func TestParallelGenerationCounts(t *testing.T) {
+	db, _ := dryRunDB(t)
+	var mu sync.Mutex
+	var savedSessions, savedEvents int
+	eventSessions := make(map[uuid.UUID]int)
+	err := db.Callback().Create().After("gorm:create").Register("test:count_rows", func(tx *gorm.DB) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch rows := tx.Statement.Dest.(type) {
+		case []*models.Session:
+			savedSessions += len(rows)
+		case []models.Event:
+			savedEvents += len(rows)
+			for _, event := range rows {
+				eventSessions[event.SessionID]++
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+
+	users := testUsers(50)
+	sessionPattern := GetEducationalSessionPattern()
+	sessionPattern.Workers = 8
+	sessionPattern.BatchSize = 20
+	eventPattern := GetEducationalEventPattern()
+	eventPattern.Workers = 8
+	eventPattern.BatchSize = 50
+
+	sessions, err := NewSessionGenerator(db, nopLogger{}, 3).GenerateSessionsForUsers(users, sessionPattern)
+	if err != nil {
+		t.Fatalf("generate sessions: %v", err)
+	}
+	if err := NewEventGenerator(db, nopLogger{}, 3).GenerateEventsForSessions(sessions, eventPattern); err != nil {
+		t.Fatalf("generate events: %v", err)
+	}
+
+	want := len(users) * sessionPattern.SessionsPerUser
+	if len(sessions) != want || savedSessions != want {
+		t.Errorf("returned %d and saved %d sessions, want %d", len(sessions), savedSessions, want)
+	}
+
+	// Every event belongs to a saved session, and every session got its events
+	sessionIDs := make(map[uuid.UUID]bool, len(sessions))
+	for _, session := range sessions {
+		if session.ID == uuid.Nil {
+			t.Fatal("a returned session has no id")
+		}
+		sessionIDs[session.ID] = true
+	}
+	total := 0
+	for id, count := range eventSessions {
+		if !sessionIDs[id] {
+			t.Errorf("%d events reference unknown session %s", count, id)
+		}
+		if count < eventPattern.MinEventsPerSession || count > eventPattern.MaxEventsPerSession {
+			t.Errorf("session %s has %d events", id, count)
+		}
+		total += count
+	}
+	if len(eventSessions) != len(sessions) || total != savedEvents {
+		t.Errorf("events cover %d of %d sessions, %d of %d events counted", len(eventSessions), len(sessions), total, savedEvents)
+	}
+}
+
+func TestWorkerGroupFirstFailureCancelsOthers(t *testing.T) {
+	failure := errors.New("insert failed")
+	group := newWorkerGroup()
+	var cancelled atomic.Int32
+	for w := 0; w < 8; w++ {
+		worker := w
+		group.Go(func(ctx context.Context) error {
+			if worker == 0 {
+				return failure
+			}
+			<-ctx.Done()
+			cancelled.Add(1)
+			return fmt.Errorf("worker %d: %w", worker, ctx.Err())
+		})
+	}
+
+	if err := group.Wait(); !errors.Is(err, failure) {
+		t.Errorf("Wait = %v, want the first failure", err)
+	}
+	if got := cancelled.Load(); got != 7 {
+		t.Errorf("%d workers saw the cancellation, want 7", got)
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// engagementDropWindowDays is the length of the recent and prior windows
+// compared when looking for engagement drops
+const engagementDropWindowDays = 7
+
+// StudentDailyEngagement is a student's engagement score on one day they
+// were active
+type StudentDailyEngagement struct {
+	StudentID       uuid.UUID
+	Date            time.Time
+	EngagementScore float64
+}
+
+// FindAtRiskStudents flags the students whose average daily engagement over
+// the 7 days ending asOf fell more than the classroom EngagementDropPercent
+// threshold below their average over the 7 days before. Days without an
+// entry in daily count as zero engagement; students with no prior engagement
+// are never flagged. The flagged summaries carry DropPercent and are ordered
+// by the largest drop first.
+func (rs *ReportsService) FindAtRiskStudents(students []StudentEngagementSummary, daily []StudentDailyEngagement, asOf time.Time) []StudentEngagementSummary {
+	end := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	recentStart := end.AddDate(0, 0, -(engagementDropWindowDays - 1))
+	priorStart := recentStart.AddDate(0, 0, -engagementDropWindowDays)
+
+	recent := make(map[uuid.UUID]float64)
+	prior := make(map[uuid.UUID]float64)
+	for _, day := range daily {
+		date := time.Date(day.Date.Year(), day.Date.Month(), day.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if date.Before(priorStart) || date.After(end) {
+			continue
+		}
+		if date.Before(recentStart) {
+			prior[day.StudentID] += day.EngagementScore
+		} else {
+			recent[day.StudentID] += day.EngagementScore
+		}
+	}
+
+	atRisk := []StudentEngagementSummary{}
+	for _, student := range students {
+		priorAvg := prior[student.StudentID] / engagementDropWindowDays
+		if priorAvg <= 0 {
+			continue
+		}
+		recentAvg := recent[student.StudentID] / engagementDropWindowDays
+		drop := (priorAvg - recentAvg) / priorAvg * 100
+		if drop <= rs.thresholds.Classroom.EngagementDropPercent {
+			continue
+		}
+		drop = math.Round(drop*10) / 10
+		student.DropPercent = &drop
+		atRisk = append(atRisk, student)
+	}
+
+	sort.SliceStable(atRisk, func(i, j int) bool {
+		return *atRisk[i].DropPercent > *atRisk[j].DropPercent
+	})
+	return atRisk
+}
+
+// getClassroomDailyEngagement returns the daily engagement of the
+// classroom's active students over the two drop windows ending asOf. A day's
+// score treats the day as the whole period, so any activity earns the
+// consistency part.
+func (rs *ReportsService) getClassroomDailyEngagement(classroomID uuid.UUID, asOf time.Time) ([]StudentDailyEngagement, error) {
+	var rows []struct {
+		UserID       uuid.UUID
+		Date         time.Time
+		DailyMinutes float64
+	}
+	from := asOf.AddDate(0, 0, -(2*engagementDropWindowDays - 1))
+	err := rs.db.Table("daily_user_metrics dum").
+		Select("dum.user_id, dum.date, dum.total_session_duration_seconds / 60.0 as daily_minutes").
+		Joins("JOIN user_classrooms uc ON uc.user_id = dum.user_id AND uc.classroom_id = ? AND uc.role = 'student' AND uc.is_active = true", classroomID).
+		Where("dum.date BETWEEN ? AND ?", from.Format("2006-01-02"), asOf.Format("2006-01-02")).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	daily := make([]StudentDailyEngagement, 0, len(rows))
+	for _, row := range rows {
+		daily = append(daily, StudentDailyEngagement{
+			StudentID:       row.UserID,
+			Date:            row.Date,
+			EngagementScore: rs.calculateEngagementScore(row.DailyMinutes, 1, 1),
+		})
+	}
+	return daily, nil
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Canned query names
+const (
+	CannedQueryEventsByApplication      = "events_by_application"
+	CannedQueryDailyEngagement          = "daily_engagement"
+	CannedQueryClassroomQuizPerformance = "classroom_quiz_performance"
+	CannedQuerySparseQuizCount          = "sparse_quiz_count"
+)
+
+// ErrUnknownCannedQuery is returned when running a query that is not registered
+var ErrUnknownCannedQuery = errors.New("unknown canned query")
+
+// CannedQueryParamError reports a missing or out-of-range parameter value
+type CannedQueryParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *CannedQueryParamError) Error() string {
+	return fmt.Sprintf("parameter %s %s", e.Param, e.Reason)
+}
+
+// CannedQueryAllowList maps each table canned queries may read to the
+// columns they may reference in it
+type CannedQueryAllowList map[string][]string
+
+// DefaultCannedQueryAllowList allows the event, session and quiz columns the
+// default canned queries read; user names, emails and metadata stay out
+func DefaultCannedQueryAllowList() CannedQueryAllowList {
+	return CannedQueryAllowList{
+		"events":        {"application", "user_id", "session_id", "timestamp"},
+		"quiz_sessions": {"id", "quiz_id", "is_completed", "percentage_score", "time_spent_seconds"},
+		"quizzes":       {"id", "classroom_id"},
+		"classrooms":    {"id", "name", "subject"},
+	}
+}
+
+// CannedQueryParam is an integer parameter of a canned query, bound to the
+// template's placeholders in declaration order
+type CannedQueryParam struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     int    `json:"default"`
+	Min         int    `json:"min"`
+	Max         int    `json:"max"`
+}
+
+// CannedQuery is a named, parameterized query. Its template references
+// tables as {{table}} and columns as {{table.column}}, both checked against
+// the allow-list, and takes values only through ? placeholders.
+type CannedQuery struct {
+	Name        string
+	Description string
+	Params      []CannedQueryParam
+	Template    string
+	NewRows     func() interface{} // pointer to an empty slice of the typed rows
+}
+
+// CannedQueryInfo describes a registered query
+type CannedQueryInfo struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Params      []CannedQueryParam `json:"params"`
+}
+
+// CannedQueryRegistry holds canned queries whose templates were validated
+// against an allow-list
+type CannedQueryRegistry struct {
+	queries map[string]compiledCannedQuery
+}
+
+type compiledCannedQuery struct {
+	CannedQuery
+	sql string
+}
+
+var (
+	cannedQueryReference = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+	sqlIdentifier        = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+)
+
+// NewCannedQueryRegistry validates each query's table and column references
+// against allowList and registers it. Any reference outside the allow-list,
+// a placeholder count that differs from the declared parameters, or a
+// duplicate name is an error.
+func NewCannedQueryRegistry(allowList CannedQueryAllowList, queries ...CannedQuery) (*CannedQueryRegistry, error) {
+	allowed := make(map[string]map[string]bool, len(allowList))
+	for table, columns := range allowList {
+		if !sqlIdentifier.MatchString(table) {
+			return nil, fmt.Errorf("allow-list table %q is not a plain identifier", table)
+		}
+		allowed[table] = make(map[string]bool, len(columns))
+		for _, column := range columns {
+			if !sqlIdentifier.MatchString(column) {
+				return nil, fmt.Errorf("allow-list column %s.%q is not a plain identifier", table, column)
+			}
+			allowed[table][column] = true
+		}
+	}
+
+	r := &CannedQueryRegistry{queries: make(map[string]compiledCannedQuery, len(queries))}
+	for _, query := range queries {
+		if _, exists := r.queries[query.Name]; exists {
+			return nil, fmt.Errorf("canned query %q is registered twice", query.Name)
+		}
+		sql, err := compileCannedQuery(query.Template, allowed)
+		if err != nil {
+			return nil, fmt.Errorf("canned query %q: %w", query.Name, err)
+		}
+		if placeholders := strings.Count(sql, "?"); placeholders != len(query.Params) {
+			return nil, fmt.Errorf("canned query %q has %d placeholders but %d params", query.Name, placeholders, len(query.Params))
+		}
+		r.queries[query.Name] = compiledCannedQuery{CannedQuery: query, sql: sql}
+	}
+	return r, nil
+}
+
+// DefaultCannedQueryRegistry registers the default canned queries against the
+// default allow-list. It panics if they do not validate, which is a bug.
+func DefaultCannedQueryRegistry() *CannedQueryRegistry {
+	r, err := NewCannedQueryRegistry(DefaultCannedQueryAllowList(), DefaultCannedQueries()...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// compileCannedQuery replaces each {{table}} and {{table.column}} reference
+// with the identifier, failing on the first one outside the allow-list
+func compileCannedQuery(template string, allowed map[string]map[string]bool) (string, error) {
+	var compileErr error
+	sql := cannedQueryReference.ReplaceAllStringFunc(template, func(match string) string {
+		ref := cannedQueryReference.FindStringSubmatch(match)[1]
+		table, column, qualified := strings.Cut(ref, ".")
+		columns, ok := allowed[table]
+		switch {
+		case !ok:
+			compileErr = errors.Join(compileErr, fmt.Errorf("table %q is not allowed", table))
+		case qualified && !columns[column]:
+			compileErr = errors.Join(compileErr, fmt.Errorf("column %s.%s is not allowed", table, column))
+		}
+		return ref
+	})
+	if compileErr != nil {
+		return "", compileErr
+	}
+	if strings.Contains(sql, "{{") || strings.Contains(sql, "}}") {
+		return "", fmt.Errorf("template has an unterminated reference")
+	}
+	return sql, nil
+}
+
+// Queries lists the registered queries by name
+func (r *CannedQueryRegistry) Queries() []CannedQueryInfo {
+	infos := make([]CannedQueryInfo, 0, len(r.queries))
+	for _, query := range r.queries {
+		infos = append(infos, CannedQueryInfo{Name: query.Name, Description: query.Description, Params: query.Params})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Run executes a registered query with its parameters read from params,
+// using each parameter's default when it is absent, and returns a pointer to
+// the typed result slice
+func (r *CannedQueryRegistry) Run(db *gorm.DB, name string, params map[string]string) (interface{}, error) {
+	query, ok := r.queries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCannedQuery, name)
+	}
+
+	args := make([]interface{}, 0, len(query.Params))
+	for _, param := range query.Params {
+		value := param.Default
+		if raw, ok := params[param.Name]; ok && raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, &CannedQueryParamError{Param: param.Name, Reason: "must be an integer"}
+			}
+			value = parsed
+		}
+		if value < param.Min || value > param.Max {
+			return nil, &CannedQueryParamError{Param: param.Name, Reason: fmt.Sprintf("must be between %d and %d", param.Min, param.Max)}
+		}
+		args = append(args, value)
+	}
+
+	rows := query.NewRows()
+	if err := db.Raw(query.sql, args...).Scan(rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RunCannedQuery runs a registered query and returns its rows as Row values
+func RunCannedQuery[Row any](r *CannedQueryRegistry, db *gorm.DB, name string, params map[string]string) ([]Row, error) {
+	rows, err := r.Run(db, name, params)
+	if err != nil {
+		return nil, err
+	}
+	typed, ok := rows.(*[]Row)
+	if !ok {
+		return nil, fmt.Errorf("canned query %q returns %T, not %T", name, rows, typed)
+	}
+	return *typed, nil
+}
+
+// ApplicationEventCount is one row of events_by_application
+type ApplicationEventCount struct {
+	Application string `json:"application"`
+	EventCount  int    `json:"event_count"`
+	UniqueUsers int    `json:"unique_users"`
+}
+
+// DailyEngagementRow is one row of daily_engagement
+type DailyEngagementRow struct {
+	Date        time.Time `json:"date"`
+	DailyEvents int       `json:"daily_events"`
+	ActiveUsers int       `json:"active_users"`
+	Sessions    int       `json:"sessions"`
+}
+
+// ClassroomQuizPerformance is one row of classroom_quiz_performance
+type ClassroomQuizPerformance struct {
+	ClassroomName  string   `json:"classroom_name"`
+	Subject        *string  `json:"subject"`
+	QuizSessions   int      `json:"quiz_sessions"`
+	AvgScore       *float64 `json:"avg_score"`
+	AvgTimeMinutes *float64 `json:"avg_time_minutes"`
+}
+
+// SparseQuizCount is the single row of sparse_quiz_count
+type SparseQuizCount struct {
+	Quizzes int `json:"quizzes"`
+}
+
+var (
+	daysParam = CannedQueryParam{Name: "days", Description: "Days of events to include, up to today", Default: 7, Min: 1, Max: 365}
+	// minSessionsParam defaults to the content threshold's MinQuizSessions
+	minSessionsParam = CannedQueryParam{Name: "min_sessions", Description: "Completed sessions a quiz needs to be ranked", Default: 3, Min: 1, Max: 1000}
+)
+
+// DefaultCannedQueries returns the queries the demo runs, also offered as
+// canned reports
+func DefaultCannedQueries() []CannedQuery {
+	return []CannedQuery{
+		{
+			Name:        CannedQueryEventsByApplication,
+			Description: "Event counts and unique users by application",
+			Params:      []CannedQueryParam{daysParam},
+			Template: `
+				SELECT
+					COALESCE({{events.application}}, 'unknown') as application,
+					COUNT(*) as event_count,
+					COUNT(DISTINCT {{events.user_id}}) as unique_users
+				FROM {{events}}
+				WHERE {{events.timestamp}} >= NOW() - make_interval(days => ?)
+				GROUP BY {{events.application}}
+				ORDER BY event_count DESC
+			`,
+			NewRows: func() interface{} { return &[]ApplicationEventCount{} },
+		},
+		{
+			Name:        CannedQueryDailyEngagement,
+			Description: "Events, active users and sessions per day",
+			Params:      []CannedQueryParam{daysParam},
+			Template: `
+				SELECT
+					DATE({{events.timestamp}}) as date,
+					COUNT(*) as daily_events,
+					COUNT(DISTINCT {{events.user_id}}) as active_users,
+					COUNT(DISTINCT {{events.session_id}}) as sessions
+				FROM {{events}}
+				WHERE {{events.timestamp}} >= NOW() - make_interval(days => ?)
+				GROUP BY DATE({{events.timestamp}})
+				ORDER BY date DESC
+			`,
+			NewRows: func() interface{} { return &[]DailyEngagementRow{} },
+		},
+		{
+			Name:        CannedQueryClassroomQuizPerformance,
+			Description: "Top classrooms by average completed quiz score, counting only quizzes with enough completed sessions",
+			Params: []CannedQueryParam{
+				minSessionsParam,
+				{Name: "limit", Description: "Classrooms to return", Default: 5, Min: 1, Max: 100},
+			},
+			Template: `
+				SELECT
+					{{classrooms.name}} as classroom_name,
+					{{classrooms.subject}} as subject,
+					COUNT({{quiz_sessions.id}}) as quiz_sessions,
+					AVG({{quiz_sessions.percentage_score}}) as avg_score,
+					AVG({{quiz_sessions.time_spent_seconds}} / 60.0) as avg_time_minutes
+				FROM {{quiz_sessions}}
+				JOIN {{quizzes}} ON {{quizzes.id}} = {{quiz_sessions.quiz_id}}
+				JOIN {{classrooms}} ON {{classrooms.id}} = {{quizzes.classroom_id}}
+				WHERE {{quiz_sessions.is_completed}} = true
+				AND {{quiz_sessions.quiz_id}} IN (
+					SELECT {{quiz_sessions.quiz_id}}
+					FROM {{quiz_sessions}}
+					WHERE {{quiz_sessions.is_completed}} = true
+					GROUP BY {{quiz_sessions.quiz_id}}
+					HAVING COUNT(*) >= ?
+				)
+				GROUP BY {{classrooms.id}}, {{classrooms.name}}, {{classrooms.subject}}
+				ORDER BY avg_score DESC
+				LIMIT ?
+			`,
+			NewRows: func() interface{} { return &[]ClassroomQuizPerformance{} },
+		},
+		{
+			Name:        CannedQuerySparseQuizCount,
+			Description: "Quizzes left out of classroom_quiz_performance for having too few completed sessions",
+			Params:      []CannedQueryParam{minSessionsParam},
+			Template: `
+				SELECT COUNT(*) as quizzes FROM (
+					SELECT {{quiz_sessions.quiz_id}}
+					FROM {{quiz_sessions}}
+					WHERE {{quiz_sessions.is_completed}} = true
+					GROUP BY {{quiz_sessions.quiz_id}}
+					HAVING COUNT(*) < ?
+				) sparse_quizzes
+			`,
+			NewRows: func() interface{} { return &[]SparseQuizCount{} },
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultCannedQueryRegistry(t *testing.T) {
+	registry := DefaultCannedQueryRegistry()
+	infos := registry.Queries()
+	if len(infos) != len(DefaultCannedQueries()) {
+		t.Fatalf("registered %d queries, want %d", len(infos), len(DefaultCannedQueries()))
+	}
+	for i := 1; i < len(infos); i++ {
+		if infos[i-1].Name >= infos[i].Name {
+			t.Errorf("queries are not sorted by name: %s before %s", infos[i-1].Name, infos[i].Name)
+		}
+	}
+	for _, query := range registry.queries {
+		if strings.Contains(query.sql, "{{") {
+			t.Errorf("%s still has template references: %s", query.Name, query.sql)
+		}
+	}
+}
+
+func TestNewCannedQueryRegistryRejects(t *testing.T) {
+	allowList := CannedQueryAllowList{"events": {"user_id", "timestamp"}}
+	query := func(template string, params ...CannedQueryParam) CannedQuery {
+		return CannedQuery{Name: "q", Template: template, Params: params}
+	}
+	tests := []struct {
+		name      string
+		allowList CannedQueryAllowList
+		queries   []CannedQuery
+		want      string
+	}{
+		{"table outside the allow-list", allowList, []CannedQuery{query("SELECT * FROM {{users}}")}, `table "users" is not allowed`},
+		{"column outside the allow-list", allowList, []CannedQuery{query("SELECT {{events.metadata}} FROM {{events}}")}, "column events.metadata is not allowed"},
+		{"unterminated reference", allowList, []CannedQuery{query("SELECT {{events.user_id FROM {{events}}")}, "unterminated reference"},
+		{"placeholders without params", allowList, []CannedQuery{query("SELECT 1 FROM {{events}} WHERE {{events.user_id}} = ?")}, "1 placeholders but 0 params"},
+		{"registered twice", allowList, []CannedQuery{query("SELECT 1"), query("SELECT 2")}, "registered twice"},
+		{"allow-list identifier", CannedQueryAllowList{"events; DROP TABLE users": nil}, nil, "not a plain identifier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCannedQueryRegistry(tt.allowList, tt.queries...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewCannedQueryRegistry error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCannedQueryRunValidatesParams(t *testing.T) {
+	registry := DefaultCannedQueryRegistry()
+	tests := []struct {
+		name   string
+		query  string
+		params map[string]string
+		want   string
+	}{
+		{"not an integer", CannedQueryEventsByApplication, map[string]string{"days": "week"}, "parameter days must be an integer"},
+		{"below the minimum", CannedQueryEventsByApplication, map[string]string{"days": "0"}, "parameter days must be between 1 and 365"},
+		{"above the maximum", CannedQueryClassroomQuizPerformance, map[string]string{"limit": "101"}, "parameter limit must be between 1 and 100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Run(nil, tt.query, tt.params)
+			var paramErr *CannedQueryParamError
+			if !errors.As(err, &paramErr) || err.Error() != tt.want {
+				t.Errorf("Run error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := registry.Run(nil, "drop_everything", nil); !errors.Is(err, ErrUnknownCannedQuery) {
+		t.Errorf("Run of an unknown query = %v, want ErrUnknownCannedQuery", err)
+	}
+}
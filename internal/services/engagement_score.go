@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// EngagementScoreConfig weights a student's engagement score between
+// consistency (share of days active) and intensity (daily minutes against a
+// cap that scores 100)
+type EngagementScoreConfig struct {
+	ConsistencyWeight   float64 `json:"consistency_weight"`
+	IntensityWeight     float64 `json:"intensity_weight"`
+	IntensityCapMinutes float64 `json:"intensity_cap_minutes"`
+}
+
+// DefaultEngagementScoreConfig weights consistency 70% and intensity 30%,
+// with an hour a day as full intensity
+func DefaultEngagementScoreConfig() EngagementScoreConfig {
+	return EngagementScoreConfig{
+		ConsistencyWeight:   0.7,
+		IntensityWeight:     0.3,
+		IntensityCapMinutes: 60,
+	}
+}
+
+// Validate checks that the weights are non-negative and sum to 1 and that
+// the intensity cap is positive
+func (cfg EngagementScoreConfig) Validate() error {
+	if cfg.ConsistencyWeight < 0 || cfg.IntensityWeight < 0 {
+		return fmt.Errorf("engagement score weights must not be negative")
+	}
+	if sum := cfg.ConsistencyWeight + cfg.IntensityWeight; math.Abs(sum-1) > 1e-9 {
+		return fmt.Errorf("engagement score weights must sum to 1, got %g", sum)
+	}
+	if cfg.IntensityCapMinutes <= 0 {
+		return fmt.Errorf("engagement intensity cap must be positive")
+	}
+	return nil
+}
+
+// Score returns the 0-100 engagement score for activeDays out of totalDays
+// at avgDailyMinutes a day; minutes beyond the cap add nothing, and neither
+// do active days beyond totalDays
+func (cfg EngagementScoreConfig) Score(avgDailyMinutes float64, activeDays int, totalDays float64) float64 {
+	if totalDays <= 0 {
+		return 0
+	}
+
+	consistencyScore := math.Min(float64(activeDays)/totalDays*100, 100)
+	intensityScore := avgDailyMinutes / cfg.IntensityCapMinutes * 100
+	if intensityScore > 100 {
+		intensityScore = 100
+	}
+
+	return consistencyScore*cfg.ConsistencyWeight + intensityScore*cfg.IntensityWeight
+}
+
+// EnrolledPeriodDays returns the engagement denominator for a student over
+// dateFrom..dateTo: the period's length in days, measured from the local day
+// of enrolledAt instead when the student enrolled after dateFrom, so days
+// before enrollment don't count as inactive. A nil enrolledAt is treated as
+// enrolled throughout.
+func EnrolledPeriodDays(dateFrom, dateTo time.Time, enrolledAt *time.Time) float64 {
+	start := dateFrom
+	if enrolledAt != nil {
+		local := enrolledAt.In(dateFrom.Location())
+		enrolledDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, dateFrom.Location())
+		if enrolledDay.After(start) {
+			start = enrolledDay
+		}
+	}
+	if start.After(dateTo) {
+		return 0
+	}
+	return dateTo.Sub(start).Hours() / 24
+}
+
+// SetEngagementScoreConfig overrides the engagement score weighting
+func (rs *ReportsService) SetEngagementScoreConfig(cfg EngagementScoreConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	rs.engagementScore = cfg
+	return nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEngagementScoreConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  EngagementScoreConfig
+		ok   bool
+	}{
+		{"default", DefaultEngagementScoreConfig(), true},
+		{"consistency only", EngagementScoreConfig{ConsistencyWeight: 1, IntensityCapMinutes: 30}, true},
+		{"float rounding", EngagementScoreConfig{ConsistencyWeight: 0.1 + 0.2, IntensityWeight: 0.7, IntensityCapMinutes: 60}, true},
+		{"sum below 1", EngagementScoreConfig{ConsistencyWeight: 0.5, IntensityWeight: 0.4, IntensityCapMinutes: 60}, false},
+		{"sum above 1", EngagementScoreConfig{ConsistencyWeight: 0.7, IntensityWeight: 0.7, IntensityCapMinutes: 60}, false},
+		{"negative weight", EngagementScoreConfig{ConsistencyWeight: 1.2, IntensityWeight: -0.2, IntensityCapMinutes: 60}, false},
+		{"zero cap", EngagementScoreConfig{ConsistencyWeight: 0.7, IntensityWeight: 0.3}, false},
+		{"negative cap", EngagementScoreConfig{ConsistencyWeight: 0.7, IntensityWeight: 0.3, IntensityCapMinutes: -60}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSetEngagementScoreConfig(t *testing.T) {
+	rs := NewReportsService(nil)
+	if err := rs.SetEngagementScoreConfig(EngagementScoreConfig{ConsistencyWeight: 0.5, IntensityWeight: 0.6, IntensityCapMinutes: 60}); err == nil {
+		t.Error("invalid config accepted")
+	}
+	if rs.engagementScore != DefaultEngagementScoreConfig() {
+		t.Errorf("rejected config replaced the default: %+v", rs.engagementScore)
+	}
+}
+
+func TestEngagementScore(t *testing.T) {
+	cfg := DefaultEngagementScoreConfig()
+	tests := []struct {
+		name       string
+		minutes    float64
+		activeDays int
+		totalDays  float64
+		want       float64
+	}{
+		{"half the days at half intensity", 30, 15, 30, 0.7*50 + 0.3*50},
+		{"intensity capped", 240, 15, 30, 0.7*50 + 0.3*100},
+		{"exactly at the cap", 60, 30, 30, 100},
+		{"active days beyond the period", 30, 40, 30, 0.7*100 + 0.3*50},
+		{"inactive", 0, 0, 30, 0},
+		{"empty period", 60, 5, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Score(tt.minutes, tt.activeDays, tt.totalDays); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	custom := EngagementScoreConfig{ConsistencyWeight: 0.2, IntensityWeight: 0.8, IntensityCapMinutes: 20}
+	if got := custom.Score(10, 30, 30); math.Abs(got-(0.2*100+0.8*50)) > 1e-9 {
+		t.Errorf("custom Score = %v, want weights and cap applied", got)
+	}
+}
+
+func TestEnrolledPeriodDays(t *testing.T) {
+	local := time.FixedZone("UTC-5", -5*60*60)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, local)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, local)
+	at := func(t time.Time) *time.Time { return &t }
+	tests := []struct {
+		name     string
+		enrolled *time.Time
+		want     float64
+	}{
+		{"no enrollment date", nil, 30},
+		{"enrolled before the period", at(time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)), 30},
+		{"enrolled mid-period", at(time.Date(2024, 3, 16, 15, 30, 0, 0, local)), 15},
+		// 02:00 UTC on the 16th is still the 15th in the report's zone
+		{"enrolled late in the local day", at(time.Date(2024, 3, 16, 2, 0, 0, 0, time.UTC)), 16},
+		{"enrolled on the last day", at(time.Date(2024, 3, 31, 9, 0, 0, 0, local)), 0},
+		{"enrolled after the period", at(time.Date(2024, 4, 2, 0, 0, 0, 0, local)), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnrolledPeriodDays(from, to, tt.enrolled); got != tt.want {
+				t.Errorf("EnrolledPeriodDays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeDB is a database/sql driver that answers statements from canned
+// results, so report queries can be exercised without a Postgres server. A
+// statement gets the result of the first rule whose every fragment appears in
+// its SQL; statements no rule matches return no rows and affect one row.
+type fakeDB struct {
+	mu         sync.Mutex
+	rules      []*fakeRule
+	statements []fakeStatement
+}
+
+type fakeRule struct {
+	fragments []string
+	columns   []string
+	rows      [][]driver.Value
+	affected  int64
+	err       error
+	remaining int // uses left before the rule stops matching, 0 for unlimited
+}
+
+// fakeStatement is a statement the service ran, with its bound arguments
+type fakeStatement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// newFakeDB returns a fake and a gorm handle backed by it
+func newFakeDB(t *testing.T) (*fakeDB, *gorm.DB) {
+	t.Helper()
+	f := &fakeDB{}
+	sqlDB := sql.OpenDB(fakeConnector{f})
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	return f, db
+}
+
+// rows answers queries containing every fragment with the given rows. The
+// fragments are matched against the SQL with whitespace collapsed.
+func (f *fakeDB) rows(fragments []string, columns []string, rows ...[]driver.Value) *fakeRule {
+	return f.add(&fakeRule{fragments: fragments, columns: columns, rows: rows})
+}
+
+// fail answers statements containing every fragment with err
+func (f *fakeDB) fail(fragments []string, err error) *fakeRule {
+	return f.add(&fakeRule{fragments: fragments, err: err})
+}
+
+// affects answers statements containing every fragment as affecting n rows
+func (f *fakeDB) affects(fragments []string, n int64) *fakeRule {
+	return f.add(&fakeRule{fragments: fragments, affected: n})
+}
+
+// times limits the rule to its next n uses
+func (r *fakeRule) times(n int) *fakeRule {
+	r.remaining = n
+	return r
+}
+
+func (f *fakeDB) add(rule *fakeRule) *fakeRule {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule)
+	return rule
+}
+
+// ran returns the statements containing every fragment, in order
+func (f *fakeDB) ran(fragments ...string) []fakeStatement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var matched []fakeStatement
+	for _, statement := range f.statements {
+		if containsAll(statement.SQL, fragments) {
+			matched = append(matched, statement)
+		}
+	}
+	return matched
+}
+
+func (f *fakeDB) answer(query string, args []driver.NamedValue) (*fakeRule, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query = strings.Join(strings.Fields(query), " ")
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	f.statements = append(f.statements, fakeStatement{SQL: query, Args: values})
+
+	for _, rule := range f.rules {
+		if rule.remaining < 0 || !containsAll(query, rule.fragments) {
+			continue
+		}
+		if rule.remaining > 0 {
+			rule.remaining--
+			if rule.remaining == 0 {
+				rule.remaining = -1
+			}
+		}
+		return rule, rule.err
+	}
+	return &fakeRule{affected: 1}, nil
+}
+
+func containsAll(query string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if !strings.Contains(query, fragment) {
+			return false
+		}
+	}
+	return true
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fake driver connections come from a connector")
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fake driver does not prepare statements")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+// CheckNamedValue passes every argument through unconverted, so tests see
+// what the service bound
+func (c fakeConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rule, err := c.db.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: rule.columns, rows: rule.rows}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rule, err := c.db.answer(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(rule.affected), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"math"
+	"time"
+)
+
+// CompareToPrevious selects the window of equal length that ends the day
+// before the report period as the comparison period
+const CompareToPrevious = "previous"
+
+// PreviousPeriod returns the window of as many days as dateFrom..dateTo
+// ending the day before dateFrom
+func PreviousPeriod(dateFrom, dateTo time.Time) ReportPeriod {
+	days := int(math.Round(dateTo.Sub(dateFrom).Hours() / 24))
+	to := dateFrom.AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, -days)
+	return ReportPeriod{From: from, To: to, Days: days}
+}
+
+// MetricChange is a metric over the report period against the comparison
+// period
+type MetricChange struct {
+	Current       *float64 `json:"current"`
+	Previous      *float64 `json:"previous"`
+	PercentChange *float64 `json:"percent_change"` // nil when either value is missing or the previous value is zero
+}
+
+// NewMetricChange returns the change from previous to current as a percentage
+// of previous, rounded to one decimal
+func NewMetricChange(current, previous *float64) MetricChange {
+	change := MetricChange{Current: current, Previous: previous}
+	if current != nil && previous != nil && *previous != 0 {
+		pct := math.Round((*current-*previous)/math.Abs(*previous)*1000) / 10
+		change.PercentChange = &pct
+	}
+	return change
+}
+
+// StudentPeriodComparison compares a student's headline stats with an
+// earlier period
+type StudentPeriodComparison struct {
+	Period          ReportPeriod `json:"period"`
+	AvgQuizScore    MetricChange `json:"avg_quiz_score"`
+	AvgDailyMinutes MetricChange `json:"avg_daily_minutes"`
+	ActiveDays      MetricChange `json:"active_days"`
+	EngagementScore MetricChange `json:"engagement_score"`
+}
@@ -0,0 +1,61 @@
+package services
+
+import "fmt"
+
+// ReportMode controls how report builders react to a failing section
+type ReportMode int
+
+const (
+	// ReportModeStrict fails the whole report when any section fails
+	ReportModeStrict ReportMode = iota
+	// ReportModeResilient returns the sections that succeeded and records the failures
+	ReportModeResilient
+)
+
+// ParseReportMode reads a report mode as given in a request; empty means strict
+func ParseReportMode(mode string) (ReportMode, error) {
+	switch mode {
+	case "", "strict":
+		return ReportModeStrict, nil
+	case "resilient":
+		return ReportModeResilient, nil
+	}
+	return ReportModeStrict, fmt.Errorf("invalid mode %q, expected \"strict\" or \"resilient\"", mode)
+}
+
+// SectionCollector tracks per-section failures while a report is assembled
+type SectionCollector struct {
+	mode   ReportMode
+	errors map[string]string
+}
+
+// NewSectionCollector returns a collector for a report built in mode
+func NewSectionCollector(mode ReportMode) *SectionCollector {
+	return &SectionCollector{mode: mode, errors: make(map[string]string)}
+}
+
+// Record notes the outcome of a section. In strict mode a failure is returned
+// wrapped with context; in resilient mode it is captured and nil is returned.
+func (sc *SectionCollector) Record(section, context string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if sc.mode == ReportModeStrict {
+		return fmt.Errorf("%s: %w", context, err)
+	}
+	sc.errors[section] = err.Error()
+	return nil
+}
+
+// Skip records a section that was not computed because a dependency failed
+func (sc *SectionCollector) Skip(section, dependency string) {
+	sc.errors[section] = fmt.Sprintf("skipped: %s unavailable", dependency)
+}
+
+// Result reports whether any section failed, along with the per-section errors
+func (sc *SectionCollector) Result() (bool, map[string]string) {
+	if len(sc.errors) == 0 {
+		return false, nil
+	}
+	return true, sc.errors
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseReportMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ReportMode
+		wantErr bool
+	}{
+		{"", ReportModeStrict, false},
+		{"strict", ReportModeStrict, false},
+		{"resilient", ReportModeResilient, false},
+		{"Resilient", ReportModeStrict, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReportMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseReportMode(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSectionCollector(t *testing.T) {
+	failure := errors.New("connection reset")
+
+	strict := NewSectionCollector(ReportModeStrict)
+	if err := strict.Record("trends", "failed to get trends", failure); !errors.Is(err, failure) {
+		t.Fatalf("strict Record = %v, want the wrapped failure", err)
+	}
+
+	resilient := NewSectionCollector(ReportModeResilient)
+	if err := resilient.Record("summary", "failed to get summary", nil); err != nil {
+		t.Fatalf("Record of a success = %v", err)
+	}
+	if partial, errs := resilient.Result(); partial || errs != nil {
+		t.Fatalf("Result before any failure = %v, %v", partial, errs)
+	}
+	if err := resilient.Record("trends", "failed to get trends", failure); err != nil {
+		t.Fatalf("resilient Record = %v, want the failure captured", err)
+	}
+	resilient.Skip("recommendations", "trends")
+
+	partial, errs := resilient.Result()
+	if !partial {
+		t.Error("Result is not partial after a failure")
+	}
+	if errs["trends"] != "connection reset" || errs["recommendations"] != "skipped: trends unavailable" {
+		t.Errorf("errors = %v", errs)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,12 +13,14 @@ import (
 
 // ReportsService handles the generation of educational reports
 type ReportsService struct {
-	db *gorm.DB
+	db              *gorm.DB
+	thresholds      ReportThresholds
+	engagementScore EngagementScoreConfig
 }
 
 // NewReportsService creates a new reports service
 func NewReportsService(db *gorm.DB) *ReportsService {
-	return &ReportsService{db: db}
+	return &ReportsService{db: db, thresholds: DefaultReportThresholds(), engagementScore: DefaultEngagementScoreConfig()}
 }
 
 // StudentPerformanceReport represents a comprehensive student performance analysis
@@ -28,9 +31,12 @@ type StudentPerformanceReport struct {
 	ClassroomName    string                     `json:"classroom_name"`
 	Period           ReportPeriod               `json:"period"`
 	OverallStats     StudentOverallStats        `json:"overall_stats"`
+	Comparison       *StudentPeriodComparison   `json:"comparison,omitempty"` // set when a comparison period was requested
 	QuizPerformance  []QuizPerformanceDetail    `json:"quiz_performance"`
+	QuizRetakes      []QuizRetakeSummary        `json:"quiz_retakes"`
 	LearningProgression []LearningProgressPoint `json:"learning_progression"`
 	Recommendations  []string                   `json:"recommendations"`
+	ThresholdsApplied StudentThresholds      `json:"thresholds_applied"`
 	GeneratedAt      time.Time                  `json:"generated_at"`
 }
 
@@ -44,6 +50,7 @@ type StudentOverallStats struct {
 	ActiveDays           int     `json:"active_days"`
 	EngagementScore      float64 `json:"engagement_score"`
 	PerformanceTrend     string  `json:"performance_trend"` // "improving", "declining", "stable"
+	PercentileRank       float64 `json:"percentile_rank"`   // avg quiz score vs classmates, 0-100
 }
 
 type QuizPerformanceDetail struct {
@@ -58,6 +65,16 @@ type QuizPerformanceDetail struct {
 	Difficulty      string    `json:"difficulty"` // "easy", "medium", "hard"
 }
 
+// QuizRetakeSummary compares a student's attempts at a quiz they completed more than once
+type QuizRetakeSummary struct {
+	QuizID                 uuid.UUID `json:"quiz_id"`
+	QuizTitle              string    `json:"quiz_title"`
+	Attempts               int       `json:"attempts"`
+	FirstAttemptPercentage float64   `json:"first_attempt_percentage"`
+	BestAttemptPercentage  float64   `json:"best_attempt_percentage"`
+	Improvement            float64   `json:"improvement"` // best minus first attempt, in percentage points
+}
+
 type LearningProgressPoint struct {
 	Date            time.Time `json:"date"`
 	AvgQuizScore    float64   `json:"avg_quiz_score"`
@@ -78,6 +95,7 @@ type ClassroomEngagementReport struct {
 	TimelineData       []EngagementTimelinePoint    `json:"timeline_data"`
 	TopPerformers      []StudentEngagementSummary   `json:"top_performers"`
 	StudentsNeedingHelp []StudentEngagementSummary  `json:"students_needing_help"`
+	AtRiskStudents     []StudentEngagementSummary   `json:"at_risk_students"` // engagement dropped sharply week over week
 	Insights           []string                     `json:"insights"`
 	GeneratedAt        time.Time                    `json:"generated_at"`
 }
@@ -106,7 +124,8 @@ type StudentEngagementSummary struct {
 	ActiveDays      int       `json:"active_days"`
 	EngagementScore float64   `json:"engagement_score"`
 	LastActive      time.Time `json:"last_active"`
-	Status          string    `json:"status"` // "excellent", "good", "needs_attention"
+	Status          string    `json:"status"`                 // "excellent", "good", "needs_attention"
+	DropPercent     *float64  `json:"drop_percent,omitempty"` // at-risk students only: week-over-week engagement drop
 }
 
 type EngagementTimelinePoint struct {
@@ -127,7 +146,13 @@ type ContentEffectivenessReport struct {
 	ContentTypeBreakdown []ContentTypeMetrics       `json:"content_type_breakdown"`
 	EngagementTrends     []ContentEngagementTrend   `json:"engagement_trends"`
 	Recommendations      []ContentRecommendation    `json:"recommendations"`
+	ThresholdsApplied    ContentThresholds          `json:"thresholds_applied"`
 	GeneratedAt          time.Time                  `json:"generated_at"`
+
+	// Partial is set in resilient mode when one or more sections failed;
+	// Errors maps each failed section to its error message
+	Partial bool              `json:"partial"`
+	Errors  map[string]string `json:"errors,omitempty"`
 }
 
 type ContentAnalyticsSummary struct {
@@ -182,8 +207,9 @@ type ReportPeriod struct {
 	Days int       `json:"days"`
 }
 
-// GenerateStudentPerformanceReport creates a comprehensive student performance report
-func (rs *ReportsService) GenerateStudentPerformanceReport(studentID uuid.UUID, classroomID *uuid.UUID, dateFrom, dateTo time.Time) (*StudentPerformanceReport, error) {
+// GenerateStudentPerformanceReport creates a comprehensive student performance report.
+// A non-nil comparison adds how the headline stats changed against that period.
+func (rs *ReportsService) GenerateStudentPerformanceReport(studentID uuid.UUID, classroomID *uuid.UUID, dateFrom, dateTo time.Time, comparison *ReportPeriod) (*StudentPerformanceReport, error) {
 	// Get student basic info
 	var student reporting.User
 	query := rs.db.Preload("School").Where("id = ? AND role = 'student'", studentID)
@@ -200,7 +226,7 @@ func (rs *ReportsService) GenerateStudentPerformanceReport(studentID uuid.UUID,
 	}
 
 	// Calculate overall stats
-	overallStats, err := rs.calculateStudentOverallStats(studentID, dateFrom, dateTo)
+	overallStats, periodComparison, err := rs.calculateStudentOverallStats(studentID, classroomID, dateFrom, dateTo, comparison)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate overall stats: %w", err)
 	}
@@ -211,6 +237,8 @@ func (rs *ReportsService) GenerateStudentPerformanceReport(studentID uuid.UUID,
 		return nil, fmt.Errorf("failed to get quiz performance: %w", err)
 	}
 
+	quizRetakes := summarizeQuizRetakes(quizPerformance)
+
 	// Get learning progression
 	learningProgression, err := rs.getStudentLearningProgression(studentID, dateFrom, dateTo)
 	if err != nil {
@@ -225,9 +253,12 @@ func (rs *ReportsService) GenerateStudentPerformanceReport(studentID uuid.UUID,
 		StudentName:         fmt.Sprintf("%s %s", *student.FirstName, *student.LastName),
 		Period:              ReportPeriod{From: dateFrom, To: dateTo, Days: int(dateTo.Sub(dateFrom).Hours() / 24)},
 		OverallStats:        *overallStats,
+		Comparison:          periodComparison,
 		QuizPerformance:     quizPerformance,
+		QuizRetakes:         quizRetakes,
 		LearningProgression: learningProgression,
 		Recommendations:     recommendations,
+		ThresholdsApplied:   rs.thresholds.Student,
 		GeneratedAt:         time.Now(),
 	}
 
@@ -268,6 +299,13 @@ func (rs *ReportsService) GenerateClassroomEngagementReport(classroomID uuid.UUI
 	// Identify top performers and students needing help
 	topPerformers, studentsNeedingHelp := rs.categorizeStudentPerformance(studentBreakdown)
 
+	// Flag students whose engagement fell sharply in the last week of the period
+	dailyEngagement, err := rs.getClassroomDailyEngagement(classroomID, dateTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily engagement: %w", err)
+	}
+	atRiskStudents := rs.FindAtRiskStudents(studentBreakdown, dailyEngagement, dateTo)
+
 	// Generate insights
 	insights := rs.generateClassroomInsights(engagementMetrics, studentBreakdown, timelineData)
 
@@ -287,6 +325,7 @@ func (rs *ReportsService) GenerateClassroomEngagementReport(classroomID uuid.UUI
 		TimelineData:        timelineData,
 		TopPerformers:       topPerformers,
 		StudentsNeedingHelp: studentsNeedingHelp,
+		AtRiskStudents:      atRiskStudents,
 		Insights:            insights,
 		GeneratedAt:         time.Now(),
 	}
@@ -294,76 +333,117 @@ func (rs *ReportsService) GenerateClassroomEngagementReport(classroomID uuid.UUI
 	return report, nil
 }
 
-// GenerateContentEffectivenessReport creates a comprehensive content effectiveness report
+// GenerateContentEffectivenessReport creates a comprehensive content effectiveness report.
+// It runs in strict mode: any failing section fails the whole report.
 func (rs *ReportsService) GenerateContentEffectivenessReport(schoolID *uuid.UUID, classroomID *uuid.UUID, contentType string, dateFrom, dateTo time.Time) (*ContentEffectivenessReport, error) {
+	return rs.GenerateContentEffectivenessReportWithMode(schoolID, classroomID, contentType, dateFrom, dateTo, ReportModeStrict)
+}
+
+// GenerateContentEffectivenessReportWithMode creates a content effectiveness report.
+// In ReportModeResilient each section is computed independently; failed sections
+// are left empty, recorded in Errors, and the report is marked Partial.
+func (rs *ReportsService) GenerateContentEffectivenessReportWithMode(schoolID *uuid.UUID, classroomID *uuid.UUID, contentType string, dateFrom, dateTo time.Time, mode ReportMode) (*ContentEffectivenessReport, error) {
+	report := &ContentEffectivenessReport{
+		Period:            ReportPeriod{From: dateFrom, To: dateTo, Days: int(dateTo.Sub(dateFrom).Hours() / 24)},
+		SchoolID:          schoolID,
+		ClassroomID:       classroomID,
+		ThresholdsApplied: rs.thresholds.Content,
+		GeneratedAt:       time.Now(),
+	}
+	sections := NewSectionCollector(mode)
+
 	// Calculate content analytics summary
 	analytics, err := rs.calculateContentAnalyticsSummary(schoolID, classroomID, contentType, dateFrom, dateTo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to calculate content analytics: %w", err)
+	if err := sections.Record("content_analytics", "failed to calculate content analytics", err); err != nil {
+		return nil, err
+	}
+	if analytics != nil {
+		report.ContentAnalytics = *analytics
 	}
 
 	// Get most engaging content
-	mostEngaging, err := rs.getMostEngagingContent(schoolID, classroomID, contentType, dateFrom, dateTo, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get most engaging content: %w", err)
+	report.MostEngagingContent, err = rs.getMostEngagingContent(schoolID, classroomID, contentType, dateFrom, dateTo, 10)
+	if err := sections.Record("most_engaging_content", "failed to get most engaging content", err); err != nil {
+		return nil, err
 	}
 
 	// Get content type breakdown
-	typeBreakdown, err := rs.getContentTypeBreakdown(schoolID, classroomID, dateFrom, dateTo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get content type breakdown: %w", err)
+	report.ContentTypeBreakdown, err = rs.getContentTypeBreakdown(schoolID, classroomID, dateFrom, dateTo)
+	if err := sections.Record("content_type_breakdown", "failed to get content type breakdown", err); err != nil {
+		return nil, err
 	}
 
 	// Get engagement trends
-	trends, err := rs.getContentEngagementTrends(schoolID, classroomID, dateFrom, dateTo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get engagement trends: %w", err)
+	report.EngagementTrends, err = rs.getContentEngagementTrends(schoolID, classroomID, dateFrom, dateTo)
+	if err := sections.Record("engagement_trends", "failed to get engagement trends", err); err != nil {
+		return nil, err
 	}
 
-	// Generate recommendations
-	recommendations := rs.generateContentRecommendations(analytics, typeBreakdown, trends)
-
-	report := &ContentEffectivenessReport{
-		Period:               ReportPeriod{From: dateFrom, To: dateTo, Days: int(dateTo.Sub(dateFrom).Hours() / 24)},
-		SchoolID:             schoolID,
-		ClassroomID:          classroomID,
-		ContentAnalytics:     *analytics,
-		MostEngagingContent:  mostEngaging,
-		ContentTypeBreakdown: typeBreakdown,
-		EngagementTrends:     trends,
-		Recommendations:      recommendations,
-		GeneratedAt:          time.Now(),
+	// Recommendations are derived from the summary, so they need it to have succeeded
+	if analytics != nil {
+		report.Recommendations = rs.generateContentRecommendations(analytics, report.ContentTypeBreakdown, report.EngagementTrends)
+	} else {
+		sections.Skip("recommendations", "content_analytics")
 	}
 
+	report.Partial, report.Errors = sections.Result()
 	return report, nil
 }
 
 // Helper methods for calculations and data retrieval
 
-func (rs *ReportsService) calculateStudentOverallStats(studentID uuid.UUID, dateFrom, dateTo time.Time) (*StudentOverallStats, error) {
-	var result struct {
-		AvgQuizScore         *float64 `json:"avg_quiz_score"`
-		TotalQuizAttempts    int      `json:"total_quiz_attempts"`
-		TotalQuizCompletions int      `json:"total_quiz_completions"`
-		AvgDailyMinutes      float64  `json:"avg_daily_minutes"`
-		TotalEvents          int      `json:"total_events"`
-		ActiveDays           int      `json:"active_days"`
-	}
+// StudentWindowStats are a student's daily metric totals over one window
+type StudentWindowStats struct {
+	PeriodWindow         string
+	AvgQuizScore         *float64
+	TotalQuizAttempts    int
+	TotalQuizCompletions int
+	AvgDailyMinutes      float64
+	TotalEvents          int
+	ActiveDays           int
+}
 
-	err := rs.db.Table("daily_user_metrics").
+// GetStudentWindowStats aggregates the student's daily metrics over the
+// report period and, when given, the comparison period in a single pass.
+// Days inside both windows count toward the report period.
+func (rs *ReportsService) GetStudentWindowStats(studentID uuid.UUID, dateFrom, dateTo time.Time, comparison *ReportPeriod) (current, previous StudentWindowStats, err error) {
+	query := rs.db.Table("daily_user_metrics").
 		Select(`
+			CASE WHEN date BETWEEN ? AND ? THEN 'current' ELSE 'comparison' END as period_window,
 			AVG(avg_quiz_score) as avg_quiz_score,
 			SUM(quiz_attempts) as total_quiz_attempts,
 			SUM(quiz_completions) as total_quiz_completions,
 			AVG(total_session_duration_seconds / 60.0) as avg_daily_minutes,
 			SUM(events_count) as total_events,
 			COUNT(date) as active_days
-		`).
-		Where("user_id = ? AND date BETWEEN ? AND ?", studentID, dateFrom, dateTo).
-		Scan(&result).Error
+		`, dateFrom, dateTo).
+		Where("user_id = ?", studentID)
+	if comparison != nil {
+		query = query.Where("(date BETWEEN ? AND ? OR date BETWEEN ? AND ?)", dateFrom, dateTo, comparison.From, comparison.To)
+	} else {
+		query = query.Where("date BETWEEN ? AND ?", dateFrom, dateTo)
+	}
 
+	var rows []StudentWindowStats
+	if err := query.Group("period_window").Scan(&rows).Error; err != nil {
+		return current, previous, err
+	}
+	for _, row := range rows {
+		if row.PeriodWindow == "current" {
+			current = row
+		} else {
+			previous = row
+		}
+	}
+	return current, previous, nil
+}
+
+// calculateStudentOverallStats computes the student's stats over the period
+// and, when comparison is given, how they changed against that period
+func (rs *ReportsService) calculateStudentOverallStats(studentID uuid.UUID, classroomID *uuid.UUID, dateFrom, dateTo time.Time, comparison *ReportPeriod) (*StudentOverallStats, *StudentPeriodComparison, error) {
+	result, previous, err := rs.GetStudentWindowStats(studentID, dateFrom, dateTo, comparison)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	completionRate := float64(0)
@@ -376,19 +456,28 @@ func (rs *ReportsService) calculateStudentOverallStats(studentID uuid.UUID, date
 		avgQuizScore = *result.AvgQuizScore
 	}
 
-	// Calculate engagement score
-	totalDays := float64(dateTo.Sub(dateFrom).Hours() / 24)
+	// Calculate engagement score over the days the student was enrolled
+	enrolledAt, err := rs.StudentEnrolledAt(studentID, classroomID)
+	if err != nil {
+		return nil, nil, err
+	}
+	totalDays := EnrolledPeriodDays(dateFrom, dateTo, enrolledAt)
 	engagementScore := rs.calculateEngagementScore(result.AvgDailyMinutes, result.ActiveDays, totalDays)
 
-	// Determine performance trend (simplified)
-	trend := "stable"
-	if engagementScore > 80 {
-		trend = "improving"
-	} else if engagementScore < 50 {
-		trend = "declining"
+	trend, err := rs.calculatePerformanceTrend(studentID, dateFrom, dateTo)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	percentileRank := float64(0)
+	if classroomID != nil && result.AvgQuizScore != nil {
+		percentileRank, err = rs.classroomPercentileRank(studentID, *classroomID, avgQuizScore, dateFrom, dateTo)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return &StudentOverallStats{
+	stats := &StudentOverallStats{
 		AvgQuizScore:         avgQuizScore,
 		TotalQuizAttempts:    result.TotalQuizAttempts,
 		TotalQuizCompletions: result.TotalQuizCompletions,
@@ -398,7 +487,118 @@ func (rs *ReportsService) calculateStudentOverallStats(studentID uuid.UUID, date
 		ActiveDays:           result.ActiveDays,
 		EngagementScore:      engagementScore,
 		PerformanceTrend:     trend,
-	}, nil
+		PercentileRank:       percentileRank,
+	}
+	if comparison == nil {
+		return stats, nil, nil
+	}
+
+	return stats, rs.CompareStudentWindows(result, previous, engagementScore, *comparison, enrolledAt), nil
+}
+
+// CompareStudentWindows reports how the student's stats changed from the
+// comparison window to the current one, given the current engagement score.
+// Engagement in the comparison window is scored over the days the student
+// was enrolled in it.
+func (rs *ReportsService) CompareStudentWindows(current, previous StudentWindowStats, engagementScore float64, comparison ReportPeriod, enrolledAt *time.Time) *StudentPeriodComparison {
+	previousEngagement := rs.calculateEngagementScore(previous.AvgDailyMinutes, previous.ActiveDays,
+		EnrolledPeriodDays(comparison.From, comparison.To, enrolledAt))
+	currentActiveDays, previousActiveDays := float64(current.ActiveDays), float64(previous.ActiveDays)
+	var currentMinutes, previousMinutes *float64
+	if current.ActiveDays > 0 {
+		currentMinutes = &current.AvgDailyMinutes
+	}
+	if previous.ActiveDays > 0 {
+		previousMinutes = &previous.AvgDailyMinutes
+	}
+	return &StudentPeriodComparison{
+		Period:          comparison,
+		AvgQuizScore:    NewMetricChange(current.AvgQuizScore, previous.AvgQuizScore),
+		AvgDailyMinutes: NewMetricChange(currentMinutes, previousMinutes),
+		ActiveDays:      NewMetricChange(&currentActiveDays, &previousActiveDays),
+		EngagementScore: NewMetricChange(&engagementScore, &previousEngagement),
+	}
+}
+
+// minTrendPoints is the fewest days with quiz scores needed to call a trend
+const minTrendPoints = 3
+
+// calculatePerformanceTrend fits a least-squares line through the student's
+// daily average quiz scores over the period, with days since dateFrom as x.
+// A slope beyond the TrendSlope threshold in either direction is "improving"
+// or "declining"; anything flatter, or fewer than minTrendPoints days, is "stable".
+func (rs *ReportsService) calculatePerformanceTrend(studentID uuid.UUID, dateFrom, dateTo time.Time) (string, error) {
+	var points []struct {
+		Date         time.Time
+		AvgQuizScore float64
+	}
+	err := rs.db.Table("daily_user_metrics").
+		Select("date, avg_quiz_score").
+		Where("user_id = ? AND date BETWEEN ? AND ? AND avg_quiz_score IS NOT NULL", studentID, dateFrom, dateTo).
+		Order("date ASC").
+		Scan(&points).Error
+	if err != nil {
+		return "", err
+	}
+	if len(points) < minTrendPoints {
+		return "stable", nil
+	}
+
+	n := float64(len(points))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Date.Sub(dateFrom).Hours() / 24
+		sumX += x
+		sumY += p.AvgQuizScore
+		sumXY += x * p.AvgQuizScore
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return "stable", nil
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+
+	switch {
+	case slope > rs.thresholds.Student.TrendSlope:
+		return "improving", nil
+	case slope < -rs.thresholds.Student.TrendSlope:
+		return "declining", nil
+	default:
+		return "stable", nil
+	}
+}
+
+// minPercentilePeers is the fewest classmates with quiz scores needed to rank a student
+const minPercentilePeers = 3
+
+// classroomPercentileRank places a student's average quiz score among their
+// classmates' averages over the same period, counting ties as half below.
+// It returns 0 when fewer than minPercentilePeers classmates have scores.
+func (rs *ReportsService) classroomPercentileRank(studentID, classroomID uuid.UUID, avgQuizScore float64, dateFrom, dateTo time.Time) (float64, error) {
+	var peerScores []float64
+	err := rs.db.Table("user_classrooms uc").
+		Joins("JOIN daily_user_metrics dum ON dum.user_id = uc.user_id").
+		Where("uc.classroom_id = ? AND uc.role = 'student' AND uc.is_active = true AND uc.user_id <> ?", classroomID, studentID).
+		Where("dum.date BETWEEN ? AND ? AND dum.avg_quiz_score IS NOT NULL", dateFrom, dateTo).
+		Group("uc.user_id").
+		Pluck("AVG(dum.avg_quiz_score)", &peerScores).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(peerScores) < minPercentilePeers {
+		return 0, nil
+	}
+
+	below := float64(0)
+	for _, score := range peerScores {
+		if score < avgQuizScore {
+			below++
+		} else if score == avgQuizScore {
+			below += 0.5
+		}
+	}
+	return below / float64(len(peerScores)) * 100, nil
 }
 
 func (rs *ReportsService) getStudentQuizPerformance(studentID uuid.UUID, dateFrom, dateTo time.Time) ([]QuizPerformanceDetail, error) {
@@ -422,9 +622,9 @@ func (rs *ReportsService) getStudentQuizPerformance(studentID uuid.UUID, dateFro
 
 	// Add difficulty assessment
 	for i := range performances {
-		if performances[i].PercentageScore >= 80 {
+		if performances[i].PercentageScore >= rs.thresholds.Student.EasyQuizScore {
 			performances[i].Difficulty = "easy"
-		} else if performances[i].PercentageScore >= 60 {
+		} else if performances[i].PercentageScore >= rs.thresholds.Student.MediumQuizScore {
 			performances[i].Difficulty = "medium"
 		} else {
 			performances[i].Difficulty = "hard"
@@ -434,6 +634,52 @@ func (rs *ReportsService) getStudentQuizPerformance(studentID uuid.UUID, dateFro
 	return performances, nil
 }
 
+// summarizeQuizRetakes groups completed sessions by quiz and, for quizzes
+// attempted more than once, compares the first attempt (lowest attempt
+// number) with the best. Quizzes are ordered by title.
+func summarizeQuizRetakes(performances []QuizPerformanceDetail) []QuizRetakeSummary {
+	type attempts struct {
+		summary QuizRetakeSummary
+		first   QuizPerformanceDetail
+	}
+	byQuiz := make(map[uuid.UUID]*attempts)
+	for _, p := range performances {
+		a, ok := byQuiz[p.QuizID]
+		if !ok {
+			a = &attempts{
+				summary: QuizRetakeSummary{QuizID: p.QuizID, QuizTitle: p.QuizTitle, BestAttemptPercentage: p.PercentageScore},
+				first:   p,
+			}
+			byQuiz[p.QuizID] = a
+		}
+		a.summary.Attempts++
+		if p.PercentageScore > a.summary.BestAttemptPercentage {
+			a.summary.BestAttemptPercentage = p.PercentageScore
+		}
+		if p.AttemptNumber < a.first.AttemptNumber ||
+			(p.AttemptNumber == a.first.AttemptNumber && p.CompletedAt.Before(a.first.CompletedAt)) {
+			a.first = p
+		}
+	}
+
+	retakes := []QuizRetakeSummary{}
+	for _, a := range byQuiz {
+		if a.summary.Attempts < 2 {
+			continue
+		}
+		a.summary.FirstAttemptPercentage = a.first.PercentageScore
+		a.summary.Improvement = a.summary.BestAttemptPercentage - a.first.PercentageScore
+		retakes = append(retakes, a.summary)
+	}
+	sort.Slice(retakes, func(i, j int) bool {
+		if retakes[i].QuizTitle != retakes[j].QuizTitle {
+			return retakes[i].QuizTitle < retakes[j].QuizTitle
+		}
+		return retakes[i].QuizID.String() < retakes[j].QuizID.String()
+	})
+	return retakes
+}
+
 func (rs *ReportsService) getStudentLearningProgression(studentID uuid.UUID, dateFrom, dateTo time.Time) ([]LearningProgressPoint, error) {
 	var progression []LearningProgressPoint
 
@@ -453,9 +699,9 @@ func (rs *ReportsService) getStudentLearningProgression(studentID uuid.UUID, dat
 
 	// Add engagement level assessment
 	for i := range progression {
-		if progression[i].DailyMinutes >= 60 {
+		if progression[i].DailyMinutes >= rs.thresholds.Student.HighEngagementMinutes {
 			progression[i].EngagementLevel = "high"
-		} else if progression[i].DailyMinutes >= 30 {
+		} else if progression[i].DailyMinutes >= rs.thresholds.Student.MediumEngagementMinutes {
 			progression[i].EngagementLevel = "medium"
 		} else {
 			progression[i].EngagementLevel = "low"
@@ -468,15 +714,15 @@ func (rs *ReportsService) getStudentLearningProgression(studentID uuid.UUID, dat
 func (rs *ReportsService) generateStudentRecommendations(stats *StudentOverallStats, quizPerformance []QuizPerformanceDetail) []string {
 	var recommendations []string
 
-	if stats.EngagementScore < 50 {
+	if stats.EngagementScore < rs.thresholds.Student.LowEngagementScore {
 		recommendations = append(recommendations, "Student shows low engagement. Consider more interactive content and regular check-ins.")
 	}
 
-	if stats.AvgQuizScore < 70 {
+	if stats.AvgQuizScore < rs.thresholds.Student.NeedsImprovementQuizScore {
 		recommendations = append(recommendations, "Quiz performance needs improvement. Provide additional practice materials and review sessions.")
 	}
 
-	if stats.CompletionRate < 80 {
+	if stats.CompletionRate < rs.thresholds.Student.LowCompletionRate {
 		recommendations = append(recommendations, "Low quiz completion rate. Consider shorter quizzes or extended time limits.")
 	}
 
@@ -491,48 +737,155 @@ func (rs *ReportsService) generateStudentRecommendations(stats *StudentOverallSt
 	return recommendations
 }
 
-func (rs *ReportsService) calculateEngagementScore(avgDailyMinutes float64, activeDays int, totalDays float64) float64 {
-	if totalDays == 0 {
-		return 0
+// StudentEnrolledAt returns when the student joined the classroom, or their
+// first active classroom when classroomID is nil; nil if not enrolled
+func (rs *ReportsService) StudentEnrolledAt(studentID uuid.UUID, classroomID *uuid.UUID) (*time.Time, error) {
+	var enrolledAt *time.Time
+	query := rs.db.Table("user_classrooms").
+		Select("MIN(enrolled_at)").
+		Where("user_id = ? AND role = 'student' AND is_active = true", studentID)
+	if classroomID != nil {
+		query = query.Where("classroom_id = ?", *classroomID)
 	}
-
-	// Consistency score (weight: 70%)
-	consistencyScore := float64(activeDays) / totalDays * 100
-
-	// Intensity score (weight: 30%) - normalize to 60 minutes per day
-	intensityScore := (avgDailyMinutes / 60) * 100
-	if intensityScore > 100 {
-		intensityScore = 100
+	if err := query.Scan(&enrolledAt).Error; err != nil {
+		return nil, err
 	}
+	return enrolledAt, nil
+}
 
-	return (consistencyScore * 0.7) + (intensityScore * 0.3)
+func (rs *ReportsService) calculateEngagementScore(avgDailyMinutes float64, activeDays int, totalDays float64) float64 {
+	return rs.engagementScore.Score(avgDailyMinutes, activeDays, totalDays)
 }
 
 // Additional helper methods would continue here for classroom and content calculations...
 // For brevity, I'm showing the structure and key methods.
 
 func (rs *ReportsService) calculateClassroomEngagementMetrics(classroomID uuid.UUID, dateFrom, dateTo time.Time) (*ClassroomEngagementMetrics, error) {
-	// Simplified implementation - would have full calculations
-	return &ClassroomEngagementMetrics{
-		TotalStudents:           30,
-		ActiveStudents:          25,
-		ParticipationRate:       83.3,
-		AvgSessionDuration:      45.2,
-		TotalQuizSessions:       45,
-		AvgQuizCompletionRate:   87.5,
-		AvgClassScore:           78.4,
-		CollaborationEvents:     234,
-		ContentSharingFrequency: 12.3,
-		WhiteboardUsageMinutes:  680,
-		NotebookUsageMinutes:    920,
-		SyncEventsCount:         156,
-		OverallEngagementScore:  82.1,
-	}, nil
+	var metrics ClassroomEngagementMetrics
+
+	// Enrolled students, and those with at least one session in the classroom during the period
+	err := rs.db.Table("user_classrooms uc").
+		Select(`
+			COUNT(*) as total_students,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM sessions s
+				WHERE s.user_id = uc.user_id AND s.classroom_id = uc.classroom_id
+				AND s.start_time BETWEEN ? AND ?
+			)) as active_students
+		`, dateFrom, dateTo).
+		Where("uc.classroom_id = ? AND uc.role = 'student' AND uc.is_active = true", classroomID).
+		Scan(&metrics).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Daily aggregates; COALESCE keeps classrooms without metrics at zero
+	err = rs.db.Table("daily_classroom_metrics").
+		Select(`
+			COALESCE(AVG(participation_rate), 0) as participation_rate,
+			COALESCE(AVG(avg_session_duration_minutes), 0) as avg_session_duration,
+			COALESCE(SUM(total_quiz_sessions), 0) as total_quiz_sessions,
+			COALESCE(AVG(avg_quiz_completion_rate), 0) as avg_quiz_completion_rate,
+			COALESCE(SUM(avg_class_quiz_score * total_quiz_sessions) /
+				NULLIF(SUM(CASE WHEN avg_class_quiz_score IS NOT NULL THEN total_quiz_sessions ELSE 0 END), 0), 0) as avg_class_score,
+			COALESCE(SUM(sync_events_count + content_shared_count), 0) as collaboration_events,
+			COALESCE(AVG(content_shared_count), 0) as content_sharing_frequency,
+			COALESCE(SUM(sync_events_count), 0) as sync_events_count,
+			COALESCE(AVG(engagement_score), 0) as overall_engagement_score
+		`).
+		Where("classroom_id = ? AND date BETWEEN ? AND ?", classroomID, dateFrom, dateTo).
+		Scan(&metrics).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Usage minutes per application from the classroom's sessions
+	var usage []struct {
+		Application string
+		Minutes     int
+	}
+	err = rs.db.Table("sessions").
+		Select("application, (COALESCE(SUM(duration_seconds), 0) / 60)::int as minutes").
+		Where("classroom_id = ? AND start_time BETWEEN ? AND ?", classroomID, dateFrom, dateTo).
+		Group("application").
+		Scan(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range usage {
+		switch u.Application {
+		case "whiteboard":
+			metrics.WhiteboardUsageMinutes = u.Minutes
+		case "notebook":
+			metrics.NotebookUsageMinutes = u.Minutes
+		}
+	}
+
+	return &metrics, nil
 }
 
 func (rs *ReportsService) getClassroomStudentBreakdown(classroomID uuid.UUID, dateFrom, dateTo time.Time) ([]StudentEngagementSummary, error) {
-	// Simplified implementation
-	return []StudentEngagementSummary{}, nil
+	var rows []struct {
+		StudentID    uuid.UUID
+		StudentName  string
+		AvgQuizScore *float64
+		DailyMinutes float64
+		ActiveDays   int
+		LastActive   *time.Time
+		EnrolledAt   *time.Time
+	}
+
+	// One row per active enrollment; the LEFT JOIN keeps students with no
+	// metrics in the period so they show up as needing attention
+	err := rs.db.Table("user_classrooms uc").
+		Select(`
+			u.id as student_id,
+			TRIM(COALESCE(u.first_name, '') || ' ' || COALESCE(u.last_name, '')) as student_name,
+			AVG(dum.avg_quiz_score) as avg_quiz_score,
+			COALESCE(AVG(dum.total_session_duration_seconds / 60.0), 0) as daily_minutes,
+			COUNT(dum.date) as active_days,
+			u.last_active,
+			uc.enrolled_at
+		`).
+		Joins("JOIN users u ON u.id = uc.user_id").
+		Joins("LEFT JOIN daily_user_metrics dum ON dum.user_id = u.id AND dum.date BETWEEN ? AND ?", dateFrom, dateTo).
+		Where("uc.classroom_id = ? AND uc.role = 'student' AND uc.is_active = true", classroomID).
+		Group("u.id, u.first_name, u.last_name, u.last_active, uc.enrolled_at").
+		Order("student_name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	students := make([]StudentEngagementSummary, 0, len(rows))
+	for _, row := range rows {
+		// Students who joined mid-period are scored only on the days since
+		totalDays := EnrolledPeriodDays(dateFrom, dateTo, row.EnrolledAt)
+		summary := StudentEngagementSummary{
+			StudentID:       row.StudentID,
+			StudentName:     row.StudentName,
+			DailyMinutes:    row.DailyMinutes,
+			ActiveDays:      row.ActiveDays,
+			EngagementScore: rs.calculateEngagementScore(row.DailyMinutes, row.ActiveDays, totalDays),
+		}
+		if row.AvgQuizScore != nil {
+			summary.AvgQuizScore = *row.AvgQuizScore
+		}
+		if row.LastActive != nil {
+			summary.LastActive = *row.LastActive
+		}
+
+		if summary.EngagementScore >= rs.thresholds.Student.HighEngagementScore {
+			summary.Status = "excellent"
+		} else if summary.EngagementScore >= rs.thresholds.Student.LowEngagementScore {
+			summary.Status = "good"
+		} else {
+			summary.Status = "needs_attention"
+		}
+		students = append(students, summary)
+	}
+
+	return students, nil
 }
 
 func (rs *ReportsService) getClassroomEngagementTimeline(classroomID uuid.UUID, dateFrom, dateTo time.Time) ([]EngagementTimelinePoint, error) {
@@ -540,19 +893,38 @@ func (rs *ReportsService) getClassroomEngagementTimeline(classroomID uuid.UUID,
 	return []EngagementTimelinePoint{}, nil
 }
 
+// categorizeStudentPerformance splits out excellent students, most engaged
+// first, and students needing attention, least engaged first
 func (rs *ReportsService) categorizeStudentPerformance(students []StudentEngagementSummary) ([]StudentEngagementSummary, []StudentEngagementSummary) {
-	// Simplified implementation
-	return []StudentEngagementSummary{}, []StudentEngagementSummary{}
+	topPerformers := []StudentEngagementSummary{}
+	needingHelp := []StudentEngagementSummary{}
+	for _, student := range students {
+		switch student.Status {
+		case "excellent":
+			topPerformers = append(topPerformers, student)
+		case "needs_attention":
+			needingHelp = append(needingHelp, student)
+		}
+	}
+
+	sort.SliceStable(topPerformers, func(i, j int) bool {
+		return topPerformers[i].EngagementScore > topPerformers[j].EngagementScore
+	})
+	sort.SliceStable(needingHelp, func(i, j int) bool {
+		return needingHelp[i].EngagementScore < needingHelp[j].EngagementScore
+	})
+
+	return topPerformers, needingHelp
 }
 
 func (rs *ReportsService) generateClassroomInsights(metrics *ClassroomEngagementMetrics, students []StudentEngagementSummary, timeline []EngagementTimelinePoint) []string {
 	var insights []string
 
-	if metrics.ParticipationRate > 85 {
+	if metrics.ParticipationRate > rs.thresholds.Classroom.ExcellentParticipationRate {
 		insights = append(insights, "Excellent classroom participation rate indicates high student engagement")
 	}
 
-	if metrics.AvgClassScore > 75 {
+	if metrics.AvgClassScore > rs.thresholds.Classroom.StrongClassScore {
 		insights = append(insights, "Strong academic performance across the classroom")
 	}
 
@@ -577,20 +949,125 @@ func (rs *ReportsService) getMostEngagingContent(schoolID *uuid.UUID, classroomI
 	return []ContentEffectivenessItem{}, nil
 }
 
+// getContentTypeBreakdown aggregates content created in the period per content
+// type, most popular first. PopularityRank orders the types by average views
+// (1 = most viewed), with ties broken by content type name.
 func (rs *ReportsService) getContentTypeBreakdown(schoolID *uuid.UUID, classroomID *uuid.UUID, dateFrom, dateTo time.Time) ([]ContentTypeMetrics, error) {
-	// Simplified implementation
-	return []ContentTypeMetrics{}, nil
+	query := rs.db.Table("content c").
+		Select(`
+			c.content_type,
+			COUNT(c.id) as total_count,
+			COALESCE(AVG(cm.view_count), 0) as avg_views,
+			COALESCE(AVG(cm.avg_view_duration_seconds), 0) as avg_view_duration,
+			COALESCE(AVG(cm.effectiveness_score), 0) as avg_effectiveness
+		`).
+		Joins("LEFT JOIN content_metrics cm ON cm.content_id = c.id").
+		Where("c.deleted_at IS NULL AND c.created_at BETWEEN ? AND ?", dateFrom, dateTo)
+
+	if schoolID != nil {
+		query = query.Joins("JOIN classrooms cl ON cl.id = c.classroom_id").
+			Where("cl.school_id = ?", *schoolID)
+	}
+	if classroomID != nil {
+		query = query.Where("c.classroom_id = ?", *classroomID)
+	}
+
+	breakdown := []ContentTypeMetrics{}
+	if err := query.Group("c.content_type").Scan(&breakdown).Error; err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(breakdown, func(i, j int) bool {
+		if breakdown[i].AvgViews != breakdown[j].AvgViews {
+			return breakdown[i].AvgViews > breakdown[j].AvgViews
+		}
+		return breakdown[i].ContentType < breakdown[j].ContentType
+	})
+	for i := range breakdown {
+		breakdown[i].PopularityRank = i + 1
+	}
+
+	return breakdown, nil
 }
 
+// getContentEngagementTrends returns one point per day of the period: the
+// content created that day with the average effectiveness score of that
+// content, and the content_viewed events that day for content in scope.
+// Days without activity are zero so the series is continuous.
 func (rs *ReportsService) getContentEngagementTrends(schoolID *uuid.UUID, classroomID *uuid.UUID, dateFrom, dateTo time.Time) ([]ContentEngagementTrend, error) {
-	// Simplified implementation
-	return []ContentEngagementTrend{}, nil
+	scoped := func(query *gorm.DB) *gorm.DB {
+		query = query.Where("c.deleted_at IS NULL")
+		if schoolID != nil {
+			query = query.Joins("JOIN classrooms cl ON cl.id = c.classroom_id").
+				Where("cl.school_id = ?", *schoolID)
+		}
+		if classroomID != nil {
+			query = query.Where("c.classroom_id = ?", *classroomID)
+		}
+		return query
+	}
+
+	var created []struct {
+		Date          time.Time
+		Count         int
+		AvgEngagement *float64
+	}
+	err := scoped(rs.db.Table("content c")).
+		Select("DATE(c.created_at) as date, COUNT(c.id) as count, AVG(cm.effectiveness_score) as avg_engagement").
+		Joins("LEFT JOIN content_metrics cm ON cm.content_id = c.id").
+		Where("c.created_at BETWEEN ? AND ?", dateFrom, dateTo).
+		Group("DATE(c.created_at)").
+		Scan(&created).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var viewed []struct {
+		Date  time.Time
+		Count int
+	}
+	err = scoped(rs.db.Table("events e")).
+		Select("DATE(e.timestamp) as date, COUNT(e.id) as count").
+		Joins("JOIN content c ON e.metadata->>'content_id' = c.id::text").
+		Where("e.event_type = 'content_viewed' AND e.timestamp BETWEEN ? AND ?", dateFrom, dateTo).
+		Group("DATE(e.timestamp)").
+		Scan(&viewed).Error
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]*ContentEngagementTrend)
+	trends := []ContentEngagementTrend{}
+	first := time.Date(dateFrom.Year(), dateFrom.Month(), dateFrom.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day(), 0, 0, 0, 0, time.UTC)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		trends = append(trends, ContentEngagementTrend{Date: day})
+	}
+	for i := range trends {
+		days[trends[i].Date.Format("2006-01-02")] = &trends[i]
+	}
+
+	for _, row := range created {
+		if point, ok := days[row.Date.Format("2006-01-02")]; ok {
+			point.ContentCreated = row.Count
+			if row.AvgEngagement != nil {
+				point.AvgEngagement = *row.AvgEngagement
+			}
+		}
+	}
+	for _, row := range viewed {
+		if point, ok := days[row.Date.Format("2006-01-02")]; ok {
+			point.ContentViewed = row.Count
+		}
+	}
+
+	return trends, nil
 }
 
 func (rs *ReportsService) generateContentRecommendations(analytics *ContentAnalyticsSummary, breakdown []ContentTypeMetrics, trends []ContentEngagementTrend) []ContentRecommendation {
 	var recommendations []ContentRecommendation
 
-	if analytics.AvgEngagementScore < 60 {
+	if analytics.AvgEngagementScore < rs.thresholds.Content.LowEngagementScore {
 		recommendations = append(recommendations, ContentRecommendation{
 			Type:        "improve_existing",
 			Description: "Focus on creating more interactive and engaging content formats",
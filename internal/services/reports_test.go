@@ -0,0 +1,152 @@
+package services
+
+import (
+	"database/sql/driver"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClassroomStudentBreakdownEnrolledPeriod(t *testing.T) {
+	fake, db := newFakeDB(t)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	veteran, transfer := uuid.New(), uuid.New()
+	// Both students were active 15 days at an hour a day; the transfer
+	// enrolled halfway through the window and was active every day since
+	fake.rows([]string{"FROM user_classrooms uc", "uc.enrolled_at"},
+		[]string{"student_id", "student_name", "avg_quiz_score", "daily_minutes", "active_days", "last_active", "enrolled_at"},
+		[]driver.Value{veteran.String(), "Ada Lovelace", 80.0, 60.0, int64(15), nil, time.Date(2023, 9, 1, 8, 0, 0, 0, time.UTC)},
+		[]driver.Value{transfer.String(), "Grace Hopper", 85.0, 60.0, int64(15), nil, time.Date(2024, 3, 16, 8, 0, 0, 0, time.UTC)})
+	rs := NewReportsService(db)
+
+	students, err := rs.getClassroomStudentBreakdown(uuid.New(), from, to)
+	if err != nil {
+		t.Fatalf("getClassroomStudentBreakdown: %v", err)
+	}
+	if len(students) != 2 {
+		t.Fatalf("got %d students, want 2", len(students))
+	}
+
+	if want := 0.7*50 + 0.3*100; math.Abs(students[0].EngagementScore-want) > 1e-9 {
+		t.Errorf("full-period score = %v, want %v", students[0].EngagementScore, want)
+	}
+	// Only the 15 enrolled days count, so the transfer isn't penalized for
+	// the half of the window before they joined
+	if students[1].StudentID != transfer || math.Abs(students[1].EngagementScore-100) > 1e-9 {
+		t.Errorf("transfer = %+v, want a full score over the enrolled days", students[1])
+	}
+	if students[1].Status != "excellent" {
+		t.Errorf("transfer status = %q, want excellent", students[1].Status)
+	}
+}
+
+func TestCalculatePerformanceTrend(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return from.AddDate(0, 0, n) }
+	tests := []struct {
+		name   string
+		days   []int
+		scores []float64
+		want   string
+	}{
+		{"too few points", []int{0, 5}, []float64{40, 90}, "stable"},
+		{"rising", []int{0, 1, 2}, []float64{60, 62, 64}, "improving"},
+		{"falling", []int{0, 2, 4}, []float64{80, 78, 76}, "declining"},
+		{"just over the threshold", []int{0, 1, 2}, []float64{70, 70.6, 71.2}, "improving"},
+		{"just under the threshold", []int{0, 1, 2}, []float64{70, 69.6, 69.2}, "stable"},
+		{"flat", []int{0, 3, 6, 9}, []float64{75, 75, 75, 75}, "stable"},
+		{"one day repeated", []int{4, 4, 4}, []float64{50, 70, 90}, "stable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			rows := make([][]driver.Value, len(tt.days))
+			for i, d := range tt.days {
+				rows[i] = []driver.Value{day(d), tt.scores[i]}
+			}
+			fake.rows([]string{`FROM "daily_user_metrics"`, "avg_quiz_score IS NOT NULL"}, []string{"date", "avg_quiz_score"}, rows...)
+
+			got, err := NewReportsService(db).calculatePerformanceTrend(uuid.New(), from, day(30))
+			if err != nil || got != tt.want {
+				t.Errorf("calculatePerformanceTrend = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassroomPercentileRank(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		peers []float64
+		score float64
+		want  float64
+	}{
+		{"too few peers", []float64{50, 60}, 90, 0},
+		{"middle", []float64{70, 80, 90}, 85, 200.0 / 3},
+		{"top", []float64{70, 80, 90}, 95, 100},
+		{"bottom", []float64{70, 80, 90}, 60, 0},
+		// One peer below and three tied, counted as half below
+		{"ties", []float64{80, 80, 80, 60}, 80, 62.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+			rows := make([][]driver.Value, len(tt.peers))
+			for i, score := range tt.peers {
+				rows[i] = []driver.Value{score}
+			}
+			fake.rows([]string{"AVG(dum.avg_quiz_score)", "uc.user_id <> $2"}, []string{"avg"}, rows...)
+
+			got, err := NewReportsService(db).classroomPercentileRank(uuid.New(), uuid.New(), tt.score, from, to)
+			if err != nil || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("classroomPercentileRank = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStudentWindowStats(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rows([]string{`FROM "daily_user_metrics"`, "period_window"},
+		[]string{"period_window", "avg_quiz_score", "total_quiz_attempts", "total_quiz_completions", "avg_daily_minutes", "total_events", "active_days"},
+		[]driver.Value{"comparison", 70.0, int64(4), int64(3), 20.0, int64(80), int64(5)},
+		[]driver.Value{"current", 82.5, int64(6), int64(6), 35.0, int64(150), int64(9)})
+	studentID := uuid.New()
+	from, to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	comparison := PreviousPeriod(from, to)
+
+	current, previous, err := NewReportsService(db).GetStudentWindowStats(studentID, from, to, &comparison)
+	if err != nil {
+		t.Fatalf("GetStudentWindowStats: %v", err)
+	}
+	if current.ActiveDays != 9 || current.AvgQuizScore == nil || *current.AvgQuizScore != 82.5 {
+		t.Errorf("current = %+v, want the current window row", current)
+	}
+	if previous.ActiveDays != 5 || previous.TotalEvents != 80 {
+		t.Errorf("previous = %+v, want the comparison window row", previous)
+	}
+
+	// Postgres reads a quoted "1" as a column, so the windows group by their alias
+	ran := fake.ran(`FROM "daily_user_metrics"`)
+	if len(ran) != 1 || !strings.Contains(ran[0].SQL, `GROUP BY "period_window"`) || !strings.Contains(ran[0].SQL, "OR date BETWEEN") {
+		t.Errorf("statements = %v, want both windows in one query grouped by period_window", ran)
+	}
+}
+
+func TestStudentWindowStatsWithoutComparison(t *testing.T) {
+	fake, db := newFakeDB(t)
+	from, to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)
+	current, previous, err := NewReportsService(db).GetStudentWindowStats(uuid.New(), from, to, nil)
+	if err != nil || current.ActiveDays != 0 || previous.PeriodWindow != "" {
+		t.Errorf("GetStudentWindowStats = %+v, %+v, %v, want empty windows", current, previous, err)
+	}
+	if ran := fake.ran(`FROM "daily_user_metrics"`); len(ran) != 1 || strings.Contains(ran[0].SQL, "OR date BETWEEN") {
+		t.Errorf("statements = %v, want only the report period", ran)
+	}
+}
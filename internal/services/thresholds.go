@@ -0,0 +1,72 @@
+package services
+
+// ReportThresholds holds the cutoffs reports use to classify results and
+// trigger recommendations. Reports echo the relevant group back as
+// thresholds_applied so clients can explain and reproduce them.
+type ReportThresholds struct {
+	Student   StudentThresholds   `json:"student"`
+	Classroom ClassroomThresholds `json:"classroom"`
+	Content   ContentThresholds   `json:"content"`
+}
+
+// StudentThresholds are the cutoffs used by the student performance report
+type StudentThresholds struct {
+	LowEngagementScore        float64 `json:"low_engagement_score"`         // below: low engagement
+	HighEngagementScore       float64 `json:"high_engagement_score"`        // at or above: excellent engagement
+	NeedsImprovementQuizScore float64 `json:"needs_improvement_quiz_score"` // below: quiz performance needs improvement
+	LowCompletionRate         float64 `json:"low_completion_rate"`          // below: low quiz completion rate
+	EasyQuizScore             float64 `json:"easy_quiz_score"`              // at or above: quiz rated easy
+	MediumQuizScore           float64 `json:"medium_quiz_score"`            // at or above: quiz rated medium, otherwise hard
+	HighEngagementMinutes     float64 `json:"high_engagement_minutes"`      // daily minutes at or above: high engagement
+	MediumEngagementMinutes   float64 `json:"medium_engagement_minutes"`    // daily minutes at or above: medium engagement
+	TrendSlope                float64 `json:"trend_slope"`                  // quiz score points per day beyond which the trend is improving or declining
+}
+
+// ClassroomThresholds are the cutoffs used by the classroom engagement report
+type ClassroomThresholds struct {
+	ExcellentParticipationRate float64 `json:"excellent_participation_rate"`
+	StrongClassScore           float64 `json:"strong_class_score"`
+	EngagementDropPercent      float64 `json:"engagement_drop_percent"` // recent week's engagement this far below the prior week's: student at risk
+}
+
+// ContentThresholds are the cutoffs used by the content effectiveness report
+type ContentThresholds struct {
+	LowEngagementScore float64 `json:"low_engagement_score"` // below: recommend improving existing content
+	MinQuizSessions    int     `json:"min_quiz_sessions"`    // completed sessions a quiz needs to appear in effectiveness rankings
+}
+
+// DefaultReportThresholds returns the cutoffs reports have always used
+func DefaultReportThresholds() ReportThresholds {
+	return ReportThresholds{
+		Student: StudentThresholds{
+			LowEngagementScore:        50,
+			HighEngagementScore:       80,
+			NeedsImprovementQuizScore: 70,
+			LowCompletionRate:         80,
+			EasyQuizScore:             80,
+			MediumQuizScore:           60,
+			HighEngagementMinutes:     60,
+			MediumEngagementMinutes:   30,
+			TrendSlope:                0.5,
+		},
+		Classroom: ClassroomThresholds{
+			ExcellentParticipationRate: 85,
+			StrongClassScore:           75,
+			EngagementDropPercent:      30,
+		},
+		Content: ContentThresholds{
+			LowEngagementScore: 60,
+			MinQuizSessions:    3,
+		},
+	}
+}
+
+// SetThresholds overrides the report cutoffs
+func (rs *ReportsService) SetThresholds(thresholds ReportThresholds) {
+	rs.thresholds = thresholds
+}
+
+// Thresholds returns the cutoffs currently in effect
+func (rs *ReportsService) Thresholds() ReportThresholds {
+	return rs.thresholds
+}